@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"text/template"
+)
+
+// Schema describes the field keys of an application.
+type Schema struct {
+	// Package is the name of the generated package.
+	Package string `json:"package"`
+
+	// Fields lists the keys to generate constants and constructors for.
+	Fields []SchemaField `json:"fields"`
+}
+
+// SchemaField describes a single field key.
+type SchemaField struct {
+	// Key is the key as it appears in the log output, e.g. "user_id".
+	Key string `json:"key"`
+
+	// Name is the Go identifier used for the constant and constructor,
+	// e.g. "UserID" produces KeyUserID and UserID(v).
+	Name string `json:"name"`
+
+	// Type is the value type: string, int, int64, float64, bool or secret.
+	Type string `json:"type"`
+
+	// Doc is an optional description copied into the generated comments.
+	Doc string `json:"doc,omitempty"`
+}
+
+// goTypes maps schema types to the parameter type of the constructor.
+var goTypes = map[string]string{
+	"string":  "string",
+	"int":     "int",
+	"int64":   "int64",
+	"float64": "float64",
+	"bool":    "bool",
+	"secret":  "string",
+}
+
+// parseSchema decodes a schema. Call validate before generating code.
+func parseSchema(r io.Reader) (*Schema, error) {
+	var schema Schema
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("decode schema: %w", err)
+	}
+
+	return &schema, nil
+}
+
+func (s *Schema) validate() error {
+	if !token.IsIdentifier(s.Package) {
+		return fmt.Errorf("invalid package name %q", s.Package)
+	}
+
+	keys := make(map[string]bool, len(s.Fields))
+	names := make(map[string]bool, len(s.Fields))
+
+	for i, f := range s.Fields {
+		switch {
+		case f.Key == "":
+			return fmt.Errorf("field %d: empty key", i)
+		case !token.IsIdentifier(f.Name) || !token.IsExported(f.Name):
+			return fmt.Errorf("field %q: name %q is not an exported Go identifier", f.Key, f.Name)
+		case goTypes[f.Type] == "":
+			return fmt.Errorf("field %q: unsupported type %q", f.Key, f.Type)
+		case keys[f.Key]:
+			return fmt.Errorf("field %q: duplicate key", f.Key)
+		case names[f.Name]:
+			return fmt.Errorf("field %q: duplicate name %q", f.Key, f.Name)
+		}
+
+		keys[f.Key] = true
+		names[f.Name] = true
+	}
+
+	return nil
+}
+
+var fileTemplate = template.Must(template.New("keys").Funcs(template.FuncMap{
+	"goType": func(t string) string { return goTypes[t] },
+}).Parse(`// Code generated by logslib-keys; DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/barnowlsnest/go-logslib/pkg/logger"
+
+// Field keys.
+const (
+{{- range .Fields}}
+	{{- if .Doc}}
+	// Key{{.Name}} {{.Doc}}
+	{{- end}}
+	Key{{.Name}} = {{printf "%q" .Key}}
+{{- end}}
+)
+{{range .Fields}}
+// {{.Name}} returns a field with key {{printf "%q" .Key}}.
+{{- if eq .Type "secret"}} The value is never rendered.{{end}}
+func {{.Name}}(v {{goType .Type}}) logger.Field {
+{{- if eq .Type "secret"}}
+	return logger.Secret(Key{{.Name}}, v)
+{{- else}}
+	return logger.Field{Key: Key{{.Name}}, Value: v}
+{{- end}}
+}
+{{end}}`))
+
+// generate renders the Go source for schema.
+func generate(schema *Schema) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, schema); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"package": "logkeys",
+	"fields": [
+		{"key": "user_id", "name": "UserID", "type": "int64", "doc": "identifies the acting user."},
+		{"key": "method", "name": "Method", "type": "string"},
+		{"key": "api_token", "name": "APIToken", "type": "secret"}
+	]
+}`
+
+func TestGenerate(t *testing.T) {
+	schema, err := parseSchema(strings.NewReader(testSchema))
+	require.NoError(t, err)
+	require.NoError(t, schema.validate())
+
+	src, err := generate(schema)
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.True(t, strings.HasPrefix(out, "// Code generated by logslib-keys; DO NOT EDIT."))
+	assert.Contains(t, out, "package logkeys")
+	assert.Regexp(t, `KeyUserID\s+= "user_id"`, out)
+	assert.Contains(t, out, "// KeyUserID identifies the acting user.")
+	assert.Contains(t, out, "func UserID(v int64) logger.Field {")
+	assert.Contains(t, out, "return logger.Field{Key: KeyMethod, Value: v}")
+	assert.Contains(t, out, "return logger.Secret(KeyAPIToken, v)")
+}
+
+func TestSchemaValidation(t *testing.T) {
+	tests := map[string]string{
+		"package":        `{"package": "bad-name", "fields": []}`,
+		"empty key":      `{"package": "k", "fields": [{"key": "", "name": "A", "type": "int"}]}`,
+		"unexported":     `{"package": "k", "fields": [{"key": "a", "name": "a", "type": "int"}]}`,
+		"type":           `{"package": "k", "fields": [{"key": "a", "name": "A", "type": "complex128"}]}`,
+		"duplicate key":  `{"package": "k", "fields": [{"key": "a", "name": "A", "type": "int"}, {"key": "a", "name": "B", "type": "int"}]}`,
+		"duplicate name": `{"package": "k", "fields": [{"key": "a", "name": "A", "type": "int"}, {"key": "b", "name": "A", "type": "int"}]}`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			schema, err := parseSchema(strings.NewReader(input))
+			require.NoError(t, err)
+			assert.Error(t, schema.validate())
+		})
+	}
+
+	_, err := parseSchema(strings.NewReader(`{"package": "k", "unknown": true}`))
+	assert.Error(t, err)
+}
+
+func TestRun_WritesFile(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "keys.json")
+	outPath := filepath.Join(dir, "keys_gen.go")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(testSchema), 0o600))
+
+	require.NoError(t, run(schemaPath, outPath, "override"))
+
+	src, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "package override")
+}
@@ -0,0 +1,75 @@
+// Command logslib-keys generates typed field key constants and field
+// constructors from a JSON schema, so call sites cannot misspell keys or
+// pass values of the wrong type.
+//
+// A schema looks like:
+//
+//	{
+//		"package": "logkeys",
+//		"fields": [
+//			{"key": "user_id", "name": "UserID", "type": "int64", "doc": "identifies the acting user."},
+//			{"key": "method", "name": "Method", "type": "string"},
+//			{"key": "api_token", "name": "APIToken", "type": "secret"}
+//		]
+//	}
+//
+// Supported types are string, int, int64, float64, bool and secret. Secret
+// fields are built with logger.Secret and never render their value.
+//
+// Typical use from a go:generate directive:
+//
+//	//go:generate go run github.com/barnowlsnest/go-logslib/cmd/logslib-keys -schema keys.json -out keys_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "", "path to the JSON schema file (required)")
+	outPath := flag.String("out", "", "output file (default stdout)")
+	pkg := flag.String("package", "", "override the package name from the schema")
+	flag.Parse()
+
+	if err := run(*schemaPath, *outPath, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "logslib-keys:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, outPath, pkg string) error {
+	if schemaPath == "" {
+		return fmt.Errorf("-schema is required")
+	}
+
+	f, err := os.Open(schemaPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	schema, err := parseSchema(f)
+	if err != nil {
+		return err
+	}
+	if pkg != "" {
+		schema.Package = pkg
+	}
+	if err := schema.validate(); err != nil {
+		return err
+	}
+
+	src, err := generate(schema)
+	if err != nil {
+		return err
+	}
+
+	if outPath == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	return os.WriteFile(outPath, src, 0o600)
+}
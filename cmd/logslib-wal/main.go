@@ -0,0 +1,95 @@
+// Command logslib-wal inspects and repairs framed log files written by
+// logger.WALWriter.
+//
+// Usage:
+//
+//	logslib-wal cat FILE...     print every complete entry, one per line
+//	logslib-wal repair FILE...  truncate a torn tail left by a crash
+//
+// cat skips corrupt frames followed by valid ones and stops at a torn or
+// corrupt tail, reporting both on stderr, so its output can be fed to
+// line-oriented tools safely. repair keeps corrupt frames followed by
+// valid ones, so no entry is lost, and reports them.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "logslib-wal:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) < 2 {
+		return errors.New("usage: logslib-wal cat|repair FILE...")
+	}
+
+	var cmd func(path string, stdout, stderr io.Writer) error
+	switch args[0] {
+	case "cat":
+		cmd = catWAL
+	case "repair":
+		cmd = repairWAL
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	for _, path := range args[1:] {
+		if err := cmd(path, stdout, stderr); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func catWAL(path string, stdout, stderr io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	out := bufio.NewWriter(stdout)
+	s := logger.NewWALScanner(f)
+	for s.Scan() {
+		_, _ = out.Write(s.Entry())
+		_ = out.WriteByte('\n')
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+
+	if s.Skipped() > 0 {
+		fmt.Fprintf(stderr, "%s: skipped %d corrupt bytes\n", path, s.Skipped())
+	}
+	if err := s.Err(); err != nil {
+		if !errors.Is(err, logger.ErrWALCorrupt) {
+			return err
+		}
+		fmt.Fprintf(stderr, "%s: torn frame at offset %d\n", path, s.Offset())
+	}
+	return nil
+}
+
+func repairWAL(path string, stdout, _ io.Writer) error {
+	repair, err := logger.RepairWAL(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s: removed %d bytes\n", path, repair.Removed)
+	if repair.Skipped > 0 {
+		fmt.Fprintf(stdout, "%s: kept %d corrupt bytes before valid frames\n", path, repair.Skipped)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+func writeTornWAL(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "app.wal")
+	w, err := logger.OpenWAL(path)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("{\"message\":\"one\"}\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("{\"message\":\"two\"}\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 42, 1})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	return path
+}
+
+func TestRun_Cat(t *testing.T) {
+	path := writeTornWAL(t)
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, run([]string{"cat", path}, &stdout, &stderr))
+
+	assert.Equal(t, "{\"message\":\"one\"}\n{\"message\":\"two\"}\n", stdout.String())
+	assert.Contains(t, stderr.String(), "torn frame at offset 50")
+}
+
+func TestRun_Repair(t *testing.T) {
+	path := writeTornWAL(t)
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, run([]string{"repair", path}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "removed 5 bytes")
+
+	stdout.Reset()
+	stderr.Reset()
+	require.NoError(t, run([]string{"cat", path}, &stdout, &stderr))
+	assert.Empty(t, stderr.String())
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Error(t, run(nil, &stdout, &stderr))
+	assert.Error(t, run([]string{"dump", "x"}, &stdout, &stderr))
+}
@@ -0,0 +1,166 @@
+// Package logcompat is a drop-in replacement for the standard library log
+// package. It exposes Print, Fatal and Panic functions with the same
+// signatures as package log, but routes every message through a configured
+// logger.Logger, so existing code can be migrated by changing a single import:
+//
+//	import log "github.com/barnowlsnest/go-logslib/pkg/logcompat"
+//
+//	func main() {
+//		log.SetLogger(logger.New(logger.ConfigFromEnv()))
+//		log.Printf("listening on %s", addr)
+//	}
+//
+// Print functions log at logger.InfoLevel by default, Fatal functions at
+// logger.FatalLevel and Panic functions at logger.PanicLevel. Fatal
+// functions exit like logger.Logger.Fatal, so Config.OnFatal and
+// Config.ExitFunc apply.
+package logcompat
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+// Logger mirrors the method set of the standard library *log.Logger.
+// It is safe for concurrent use.
+type Logger struct {
+	mu     sync.RWMutex
+	logger *logger.Logger
+	level  logger.Level
+	prefix string
+}
+
+// New creates a Logger that writes through l. Messages are prefixed with
+// prefix, like log.New. If l is nil, a text logger writing to os.Stderr is
+// used, matching the standard library default output.
+func New(l *logger.Logger, prefix string) *Logger {
+	if l == nil {
+		l = logger.New(logger.Config{
+			Level:  logger.DebugLevel,
+			Format: logger.TextFormat,
+			Output: os.Stderr,
+		})
+	}
+
+	return &Logger{
+		logger: l,
+		level:  logger.InfoLevel,
+		prefix: prefix,
+	}
+}
+
+// SetLogger replaces the logger entries are routed through.
+func (cl *Logger) SetLogger(l *logger.Logger) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.logger = l
+}
+
+// SetLevel sets the level used by the Print functions.
+func (cl *Logger) SetLevel(level logger.Level) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.level = level
+}
+
+// Prefix returns the message prefix.
+func (cl *Logger) Prefix() string {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.prefix
+}
+
+// SetPrefix sets the message prefix.
+func (cl *Logger) SetPrefix(prefix string) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.prefix = prefix
+}
+
+// Output logs s at the Print level. calldepth is accepted for signature
+// compatibility with log.Logger.Output and is ignored. It always returns nil.
+func (cl *Logger) Output(calldepth int, s string) error {
+	_ = calldepth
+	cl.output(cl.printLevel(), s)
+	return nil
+}
+
+// Print logs its arguments in the manner of fmt.Print.
+func (cl *Logger) Print(v ...any) {
+	cl.output(cl.printLevel(), fmt.Sprint(v...))
+}
+
+// Printf logs its arguments in the manner of fmt.Printf.
+func (cl *Logger) Printf(format string, v ...any) {
+	cl.output(cl.printLevel(), fmt.Sprintf(format, v...))
+}
+
+// Println logs its arguments in the manner of fmt.Println.
+func (cl *Logger) Println(v ...any) {
+	cl.output(cl.printLevel(), fmt.Sprintln(v...))
+}
+
+// Fatal is equivalent to Print followed by os.Exit(1).
+func (cl *Logger) Fatal(v ...any) {
+	cl.output(logger.FatalLevel, fmt.Sprint(v...))
+}
+
+// Fatalf is equivalent to Printf followed by os.Exit(1).
+func (cl *Logger) Fatalf(format string, v ...any) {
+	cl.output(logger.FatalLevel, fmt.Sprintf(format, v...))
+}
+
+// Fatalln is equivalent to Println followed by os.Exit(1).
+func (cl *Logger) Fatalln(v ...any) {
+	cl.output(logger.FatalLevel, fmt.Sprintln(v...))
+}
+
+// Panic is equivalent to Print followed by a call to panic.
+func (cl *Logger) Panic(v ...any) {
+	cl.output(logger.PanicLevel, fmt.Sprint(v...))
+}
+
+// Panicf is equivalent to Printf followed by a call to panic.
+func (cl *Logger) Panicf(format string, v ...any) {
+	cl.output(logger.PanicLevel, fmt.Sprintf(format, v...))
+}
+
+// Panicln is equivalent to Println followed by a call to panic.
+func (cl *Logger) Panicln(v ...any) {
+	cl.output(logger.PanicLevel, fmt.Sprintln(v...))
+}
+
+func (cl *Logger) printLevel() logger.Level {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.level
+}
+
+// output logs s at level. Like the standard library, a single trailing
+// newline is dropped, since every entry is already newline-terminated.
+func (cl *Logger) output(level logger.Level, s string) {
+	cl.mu.RLock()
+	l, prefix := cl.logger, cl.prefix
+	cl.mu.RUnlock()
+
+	msg := prefix + strings.TrimSuffix(s, "\n")
+
+	switch level {
+	case logger.DebugLevel:
+		l.Debug(msg)
+	case logger.WarnLevel:
+		l.Warn(msg)
+	case logger.ErrorLevel:
+		l.Error(msg)
+	case logger.FatalLevel:
+		l.Fatal(msg)
+	case logger.PanicLevel:
+		l.Panic(msg)
+	default:
+		l.Info(msg)
+	}
+}
@@ -0,0 +1,71 @@
+package logcompat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+func newTestLogger(buf *bytes.Buffer) *logger.Logger {
+	return logger.New(logger.Config{
+		Level:  logger.DebugLevel,
+		Format: logger.JSONFormat,
+		Output: buf,
+	})
+}
+
+func TestLogger_Print(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cl := New(newTestLogger(buf), "svc: ")
+
+	cl.Print("a", 1)
+	cl.Printf("b=%d", 2)
+	cl.Println("c", 3)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"message":"svc: a1"`)
+	assert.Contains(t, lines[1], `"message":"svc: b=2"`)
+	assert.Contains(t, lines[2], `"message":"svc: c 3"`)
+	assert.Contains(t, lines[0], `"level":"INFO"`)
+}
+
+func TestLogger_SetLevelAndPrefix(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cl := New(newTestLogger(buf), "")
+
+	cl.SetLevel(logger.WarnLevel)
+	cl.SetPrefix("[app] ")
+	_ = cl.Output(2, "careful\n")
+
+	assert.Equal(t, "[app] ", cl.Prefix())
+	assert.Contains(t, buf.String(), `"level":"WARN"`)
+	assert.Contains(t, buf.String(), `"message":"[app] careful"`)
+}
+
+func TestLogger_Panic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cl := New(newTestLogger(buf), "")
+
+	assert.PanicsWithValue(t, "boom 42", func() {
+		cl.Panicf("boom %d", 42)
+	})
+	assert.Contains(t, buf.String(), `"level":"PANIC"`)
+}
+
+func TestPackageLevelFunctions(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	previous := Default()
+	defer func() { std = previous }()
+	std = New(nil, "")
+
+	SetLogger(newTestLogger(buf))
+	Println("hello", "world")
+
+	assert.Contains(t, buf.String(), `"message":"hello world"`)
+}
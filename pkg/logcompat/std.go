@@ -0,0 +1,80 @@
+package logcompat
+
+import "github.com/barnowlsnest/go-logslib/pkg/logger"
+
+var std = New(nil, "")
+
+// Default returns the Logger used by the package-level functions.
+func Default() *Logger {
+	return std
+}
+
+// SetLogger sets the logger used by the package-level functions.
+func SetLogger(l *logger.Logger) {
+	std.SetLogger(l)
+}
+
+// SetLevel sets the level used by the package-level Print functions.
+func SetLevel(level logger.Level) {
+	std.SetLevel(level)
+}
+
+// Prefix returns the message prefix of the package-level functions.
+func Prefix() string {
+	return std.Prefix()
+}
+
+// SetPrefix sets the message prefix of the package-level functions.
+func SetPrefix(prefix string) {
+	std.SetPrefix(prefix)
+}
+
+// Output logs s at the Print level. See Logger.Output.
+func Output(calldepth int, s string) error {
+	return std.Output(calldepth+1, s)
+}
+
+// Print logs its arguments in the manner of fmt.Print.
+func Print(v ...any) {
+	std.Print(v...)
+}
+
+// Printf logs its arguments in the manner of fmt.Printf.
+func Printf(format string, v ...any) {
+	std.Printf(format, v...)
+}
+
+// Println logs its arguments in the manner of fmt.Println.
+func Println(v ...any) {
+	std.Println(v...)
+}
+
+// Fatal is equivalent to Print followed by os.Exit(1).
+func Fatal(v ...any) {
+	std.Fatal(v...)
+}
+
+// Fatalf is equivalent to Printf followed by os.Exit(1).
+func Fatalf(format string, v ...any) {
+	std.Fatalf(format, v...)
+}
+
+// Fatalln is equivalent to Println followed by os.Exit(1).
+func Fatalln(v ...any) {
+	std.Fatalln(v...)
+}
+
+// Panic is equivalent to Print followed by a call to panic.
+func Panic(v ...any) {
+	std.Panic(v...)
+}
+
+// Panicf is equivalent to Printf followed by a call to panic.
+func Panicf(format string, v ...any) {
+	std.Panicf(format, v...)
+}
+
+// Panicln is equivalent to Println followed by a call to panic.
+func Panicln(v ...any) {
+	std.Panicln(v...)
+}
@@ -0,0 +1,224 @@
+package logger
+
+import (
+	"hash/maphash"
+	"math"
+	"sync"
+	"time"
+)
+
+// Defaults of an AnomalyHook.
+const (
+	defaultAnomalyFactor          = 5
+	defaultAnomalyInterval        = time.Second
+	defaultAnomalyHalfLife        = 5 * time.Minute
+	defaultAnomalyWarmup          = 10
+	defaultAnomalyMinRate         = 1
+	defaultAnomalyMaxFingerprints = 1024
+	defaultAnomalyField           = "anomaly"
+)
+
+// AnomalyConfig configures an AnomalyHook.
+type AnomalyConfig struct {
+	// Factor is how many times the current rate of a fingerprint must
+	// exceed its baseline to count as a spike. Defaults to 5.
+	Factor float64
+
+	// Interval is the bucket over which the current rate is measured.
+	// Defaults to one second.
+	Interval time.Duration
+
+	// HalfLife controls how fast the EWMA baseline follows the observed
+	// rate. Defaults to five minutes.
+	HalfLife time.Duration
+
+	// Warmup is the number of intervals a fingerprint must be tracked for
+	// before spikes are reported, so new messages do not count as spikes.
+	// Defaults to 10.
+	Warmup int
+
+	// MinRate is the minimum number of entries per second a spike must
+	// reach, which avoids flagging jumps from 0.01/s to 0.1/s.
+	// Defaults to 1.
+	MinRate float64
+
+	// MaxFingerprints caps the number of tracked fingerprints. When the cap
+	// is reached all baselines are reset. Defaults to 1024.
+	MaxFingerprints int
+
+	// Field is the key of the field added to entries logged during a
+	// spike. Its value is the ratio of the current rate to the baseline.
+	// Defaults to "anomaly".
+	Field string
+
+	// OnSpike, if set, is called once when a fingerprint starts spiking,
+	// with the record that crossed the threshold and the ratio. It runs
+	// inside the hook, so it must not log through the same logger
+	// synchronously.
+	OnSpike func(r *Record, ratio float64)
+}
+
+// anomalyState tracks the rate of a single fingerprint.
+type anomalyState struct {
+	bucket   int64 // index of the current interval
+	count    float64
+	baseline float64
+	buckets  int
+	spiking  bool
+}
+
+// AnomalyHook flags entries whose message fingerprint suddenly occurs far
+// more often than usual. The fingerprint is the message with every run of
+// digits replaced, so "user 42 not found" and "user 7 not found" share a
+// rate. Each fingerprint keeps an EWMA baseline of its rate per interval;
+// while the current interval exceeds Factor times the baseline, entries
+// get an extra field with the ratio.
+//
+// Example:
+//
+//	log.AddHook(logger.NewAnomalyHook(logger.AnomalyConfig{
+//		Factor: 10,
+//		OnSpike: func(r *logger.Record, ratio float64) {
+//			alerts.Notify(r.Message, ratio)
+//		},
+//	}))
+type AnomalyHook struct {
+	cfg      AnomalyConfig
+	interval int64
+	alpha    float64
+	seed     maphash.Seed
+
+	mu     sync.Mutex
+	states map[uint64]*anomalyState
+}
+
+// NewAnomalyHook returns a hook detecting rate spikes per fingerprint.
+func NewAnomalyHook(cfg AnomalyConfig) *AnomalyHook {
+	if cfg.Factor <= 1 {
+		cfg.Factor = defaultAnomalyFactor
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultAnomalyInterval
+	}
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = defaultAnomalyHalfLife
+	}
+	if cfg.Warmup <= 0 {
+		cfg.Warmup = defaultAnomalyWarmup
+	}
+	if cfg.MinRate <= 0 {
+		cfg.MinRate = defaultAnomalyMinRate
+	}
+	if cfg.MaxFingerprints <= 0 {
+		cfg.MaxFingerprints = defaultAnomalyMaxFingerprints
+	}
+	if cfg.Field == "" {
+		cfg.Field = defaultAnomalyField
+	}
+
+	return &AnomalyHook{
+		cfg:      cfg,
+		interval: int64(cfg.Interval),
+		alpha:    1 - math.Exp2(-cfg.Interval.Seconds()/cfg.HalfLife.Seconds()),
+		seed:     maphash.MakeSeed(),
+		states:   make(map[uint64]*anomalyState),
+	}
+}
+
+// Run implements Hook.
+func (h *AnomalyHook) Run(r *Record) error {
+	key := h.fingerprint(r.Message)
+	bucket := r.Time.UnixNano() / h.interval
+
+	h.mu.Lock()
+	st := h.states[key]
+	if st == nil {
+		if len(h.states) >= h.cfg.MaxFingerprints {
+			clear(h.states)
+		}
+		st = &anomalyState{bucket: bucket}
+		h.states[key] = st
+	}
+	h.advance(st, bucket)
+	st.count++
+
+	ratio, spike := h.check(st)
+	started := spike && !st.spiking
+	if spike {
+		st.spiking = true
+	}
+	h.mu.Unlock()
+
+	if !spike {
+		return nil
+	}
+
+	ratio = math.Round(ratio*10) / 10
+	r.Fields = append(r.Fields, Field{Key: h.cfg.Field, Value: ratio})
+	if started && h.cfg.OnSpike != nil {
+		h.cfg.OnSpike(r, ratio)
+	}
+	return nil
+}
+
+// advance folds completed intervals into the baseline. Intervals without
+// entries count as a rate of zero. It must be called with h.mu held.
+func (h *AnomalyHook) advance(st *anomalyState, bucket int64) {
+	if bucket <= st.bucket {
+		return
+	}
+
+	if st.buckets == 0 {
+		st.baseline = st.count
+	} else {
+		st.baseline += h.alpha * (st.count - st.baseline)
+	}
+	st.buckets++
+
+	if idle := bucket - st.bucket - 1; idle > 0 {
+		st.baseline *= math.Pow(1-h.alpha, float64(min(idle, 1<<16)))
+		st.buckets += int(min(idle, int64(h.cfg.Warmup)))
+	}
+
+	st.bucket = bucket
+	st.count = 0
+	st.spiking = false
+}
+
+// check reports whether the current interval is a spike and the ratio of
+// the current rate to the baseline. It must be called with h.mu held.
+func (h *AnomalyHook) check(st *anomalyState) (float64, bool) {
+	if st.buckets < h.cfg.Warmup {
+		return 0, false
+	}
+	if st.count < h.cfg.MinRate*h.cfg.Interval.Seconds() {
+		return 0, false
+	}
+
+	// A baseline close to zero would make every entry look like a spike.
+	baseline := max(st.baseline, 1e-3)
+	ratio := st.count / baseline
+	return ratio, ratio >= h.cfg.Factor
+}
+
+// fingerprint hashes msg with every run of digits collapsed.
+func (h *AnomalyHook) fingerprint(msg string) uint64 {
+	var mh maphash.Hash
+	mh.SetSeed(h.seed)
+
+	start := 0
+	for i := 0; i < len(msg); i++ {
+		if msg[i] < '0' || msg[i] > '9' {
+			continue
+		}
+		mh.WriteString(msg[start:i])
+		_ = mh.WriteByte(0)
+		for i < len(msg) && msg[i] >= '0' && msg[i] <= '9' {
+			i++
+		}
+		start = i
+	}
+	mh.WriteString(msg[start:])
+
+	return mh.Sum64()
+}
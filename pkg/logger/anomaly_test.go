@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runAnomaly feeds n records with msg into h during the interval starting
+// at ts and returns the records.
+func runAnomaly(t *testing.T, h *AnomalyHook, msg string, ts time.Time, n int) []*Record {
+	t.Helper()
+
+	records := make([]*Record, n)
+	for i := range records {
+		records[i] = &Record{Time: ts.Add(time.Duration(i) * time.Millisecond), Message: msg}
+		require.NoError(t, h.Run(records[i]))
+	}
+	return records
+}
+
+func TestAnomalyHook_FlagsSpike(t *testing.T) {
+	var spikes []float64
+	h := NewAnomalyHook(AnomalyConfig{
+		Factor: 5,
+		OnSpike: func(r *Record, ratio float64) {
+			spikes = append(spikes, ratio)
+		},
+	})
+
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 20; i++ {
+		for _, r := range runAnomaly(t, h, "user 42 not found", start.Add(time.Duration(i)*time.Second), 2) {
+			assert.Empty(t, r.Fields)
+		}
+	}
+
+	// Different numbers share the fingerprint.
+	records := runAnomaly(t, h, "user 7 not found", start.Add(20*time.Second), 30)
+
+	assert.Empty(t, records[8].Fields)
+	require.Len(t, records[9].Fields, 1)
+	assert.Equal(t, "anomaly", records[9].Fields[0].Key)
+	assert.Equal(t, 5.0, records[9].Fields[0].Value)
+	require.Len(t, records[29].Fields, 1)
+	assert.Equal(t, 15.0, records[29].Fields[0].Value)
+	assert.Equal(t, []float64{5}, spikes)
+
+	// The next interval at the usual rate is quiet again.
+	for _, r := range runAnomaly(t, h, "user 1 not found", start.Add(21*time.Second), 2) {
+		assert.Empty(t, r.Fields)
+	}
+}
+
+func TestAnomalyHook_Warmup(t *testing.T) {
+	h := NewAnomalyHook(AnomalyConfig{Warmup: 3})
+	start := time.Unix(1700000000, 0)
+
+	runAnomaly(t, h, "new message", start, 1)
+	records := runAnomaly(t, h, "new message", start.Add(time.Second), 100)
+	assert.Empty(t, records[99].Fields)
+}
+
+func TestAnomalyHook_MinRate(t *testing.T) {
+	h := NewAnomalyHook(AnomalyConfig{Warmup: 1, MinRate: 10})
+	start := time.Unix(1700000000, 0)
+
+	runAnomaly(t, h, "rare", start, 1)
+	records := runAnomaly(t, h, "rare", start.Add(time.Minute), 9)
+	assert.Empty(t, records[8].Fields)
+}
+
+func TestAnomalyHook_MaxFingerprints(t *testing.T) {
+	h := NewAnomalyHook(AnomalyConfig{MaxFingerprints: 2})
+	start := time.Unix(1700000000, 0)
+
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		runAnomaly(t, h, msg, start, 1)
+		assert.LessOrEqual(t, len(h.states), 2)
+	}
+}
+
+func TestAnomalyHook_Fingerprint(t *testing.T) {
+	h := NewAnomalyHook(AnomalyConfig{})
+
+	assert.Equal(t, h.fingerprint("took 15ms for 3 rows"), h.fingerprint("took 150ms for 12 rows"))
+	assert.NotEqual(t, h.fingerprint("took 15ms"), h.fingerprint("took 15s"))
+	assert.NotEqual(t, h.fingerprint("a1"), h.fingerprint("a"))
+}
+
+func TestAnomalyHook_WithLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+	logger.AddHook(NewAnomalyHook(AnomalyConfig{}))
+
+	logger.Info("steady")
+	assert.NotContains(t, buf.String(), "anomaly=")
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// maxPooledReflectBuffer caps the buffers kept by jsonMarshalers, so one
+// huge value does not pin its buffer for the lifetime of the process.
+const maxPooledReflectBuffer = 64 << 10
+
+// Any returns a field for a value of any type. Values F understands are
+// stored the same way, so Any("status", 200) costs no more than F. Values
+// the encoders do not support natively, such as structs, maps, slices and
+// pointers, are encoded as JSON when the entry is written, in the format of
+// encoding/json; text output renders the same JSON.
+//
+// The encoder of each type is compiled once with reflection, from the json
+// tags of its struct fields, and cached, so logging a domain object costs
+// about as much as writing its fields by hand. Types with MarshalJSON or
+// MarshalText methods, and structs with embedded fields or the string and
+// omitzero tag options, are encoded with encoding/json.
+//
+// With AsyncQueueSize set, the value is encoded when it is logged, since
+// the caller may change it once the call returns. With Config.RedactKeys or
+// RedactValuePatterns set, the value is encoded and masked before it is
+// written: members whose JSON name or map key matches a redacted key are
+// replaced, and strings are matched against the value patterns.
+//
+// The reflection-based encoding lives on its own code path, so call sites
+// logging only primitive fields keep their allocation profile regardless
+// of how often Any is used elsewhere.
+//
+// Example:
+//
+//	log.Info("order placed", logger.Any("items", order.Items))
+func Any(key string, value any) Field {
+	if isNativeValue(value) {
+		return Field{Key: key, Value: value}
+	}
+	if v := fieldValue(value); isNativeValue(v) {
+		return Field{Key: key, Value: v}
+	}
+	return Field{Key: key, Value: reflectValue{v: value}}
+}
+
+// isNativeValue reports whether the encoders handle v without reflection.
+func isNativeValue(v any) bool {
+	switch v.(type) {
+	case string, int, int64, uint64, float64, bool, time.Time, nil,
+		blockValue, bytesValue, secretValue, sourceLocation, httpRequest, valuerValue:
+		return true
+	default:
+		return false
+	}
+}
+
+// reflectValue is a field value created by Any that is encoded through the
+// cached reflection encoders.
+type reflectValue struct {
+	v any
+}
+
+// snapshot returns v encoded as it is now, for entries encoded after the
+// call that logged them returned.
+func (v reflectValue) snapshot() reflectValue {
+	return reflectValue{v: json.RawMessage(v.appendJSON(nil))}
+}
+
+// snapshotReflectValues replaces the Any values of fields, including those
+// in groups, by their encoding. The async queue encodes entries after the
+// logging call returned, when the caller may already be changing them.
+// fields must be owned by the caller; groups are copied before changing.
+func snapshotReflectValues(fields []Field) {
+	for i, field := range fields {
+		switch v := field.Value.(type) {
+		case reflectValue:
+			fields[i].Value = v.snapshot()
+		case groupValue:
+			if hasReflectValues(v) {
+				g := append(groupValue(nil), v...)
+				snapshotReflectValues(g)
+				fields[i].Value = g
+			}
+		}
+	}
+}
+
+// hasReflectValues reports whether fields or their groups hold Any values.
+func hasReflectValues(fields []Field) bool {
+	for _, field := range fields {
+		switch v := field.Value.(type) {
+		case reflectValue:
+			return true
+		case groupValue:
+			if hasReflectValues(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonMarshaler is a JSON encoder writing into its own buffer.
+type jsonMarshaler struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// jsonMarshalers pools the encoding/json encoders used for the values the
+// cached encoders leave to encoding/json, separately from the entry buffers
+// of the loggers.
+var jsonMarshalers = sync.Pool{
+	New: func() any {
+		m := &jsonMarshaler{}
+		m.enc = json.NewEncoder(&m.buf)
+		m.enc.SetEscapeHTML(false)
+		return m
+	},
+}
+
+// appendJSONMarshal appends the encoding/json encoding of v to buf.
+func appendJSONMarshal(buf []byte, v any) ([]byte, error) {
+	m := jsonMarshalers.Get().(*jsonMarshaler)
+	m.buf.Reset()
+
+	err := m.enc.Encode(v)
+	if err == nil {
+		buf = append(buf, bytes.TrimSuffix(m.buf.Bytes(), []byte{'\n'})...)
+	}
+
+	if m.buf.Cap() <= maxPooledReflectBuffer {
+		jsonMarshalers.Put(m)
+	}
+	return buf, err
+}
+
+// appendJSON appends the JSON encoding of v to buf. A value that cannot be
+// encoded, such as a channel or a cyclic structure, is replaced by a string
+// describing the error.
+//
+//go:noinline
+func (v reflectValue) appendJSON(buf []byte) []byte {
+	start := len(buf)
+	buf, err := appendReflectJSON(buf, v.v)
+	if err != nil {
+		buf = append(buf[:start], '"')
+		buf = appendJSONString(buf, "!ERROR: "+err.Error())
+		buf = append(buf, '"')
+	}
+	return buf
+}
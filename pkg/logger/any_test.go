@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type anyItem struct {
+	SKU   string `json:"sku"`
+	Count int    `json:"count"`
+}
+
+func TestAny_NativeValues(t *testing.T) {
+	assert.Equal(t, Field{Key: "status", Value: 200}, Any("status", 200))
+	assert.Equal(t, Field{Key: "n", Value: int64(7)}, Any("n", uint16(7)))
+	assert.Equal(t, Field{Key: "d", Value: "1.5s"}, Any("d", 1500*time.Millisecond))
+	assert.Equal(t, Field{Key: "nil", Value: nil}, Any("nil", nil))
+	assert.Equal(t, Secret("token", "s3cr3t"), Any("token", Secret("token", "s3cr3t").Value))
+}
+
+func TestAny_ReflectedValues(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	log.Info("order placed",
+		Any("items", []anyItem{{SKU: "a<1>", Count: 2}}),
+		Any("meta", map[string]int{"retries": 1}),
+		Any("broken", make(chan int)),
+	)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, []any{map[string]any{"sku": "a<1>", "count": float64(2)}}, entry["items"])
+	assert.Equal(t, map[string]any{"retries": float64(1)}, entry["meta"])
+	assert.True(t, strings.HasPrefix(entry["broken"].(string), "!ERROR: json: unsupported type"))
+
+	buf.Reset()
+	text := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+	text.Info("order placed", Any("item", anyItem{SKU: "a", Count: 2}))
+	assert.Contains(t, buf.String(), `INFO order placed item={"sku":"a","count":2}`)
+}
+
+func TestAny_Replay(t *testing.T) {
+	recording := &bytes.Buffer{}
+	src := New(Config{Level: InfoLevel, Format: JSONFormat, Output: &bytes.Buffer{}})
+	src.AddHook(NewRecorder(recording))
+	src.Info("order placed", Any("item", anyItem{SKU: "a", Count: 2}))
+
+	buf := &bytes.Buffer{}
+	_, err := Replay(recording, New(Config{Level: InfoLevel, Format: TextFormat, Output: buf}))
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `item={"sku":"a","count":2}`)
+}
+
+func TestAny_PrimitiveCallSitesStayAllocationFree(t *testing.T) {
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: discardWriter})
+	fields := []Field{String("action", "login"), Bool("success", true)}
+
+	before := testing.AllocsPerRun(100, func() { log.Info("user action", fields...) })
+	log.Info("order placed", Any("item", anyItem{SKU: "a", Count: 2}))
+	after := testing.AllocsPerRun(100, func() { log.Info("user action", fields...) })
+
+	assert.Zero(t, before)
+	assert.Equal(t, before, after)
+}
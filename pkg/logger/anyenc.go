@@ -0,0 +1,387 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxReflectDepth bounds the nesting of pointers, maps, slices and
+// interfaces the reflection encoders follow, so a cyclic value fails
+// instead of recursing forever.
+const maxReflectDepth = 1000
+
+// reflectEncodeFunc appends the JSON encoding of v to buf. depth counts the
+// references followed so far.
+type reflectEncodeFunc func(buf []byte, v reflect.Value, depth int) ([]byte, error)
+
+// reflectEncoderCache holds the encoder of every type seen by Any, as a
+// reflectEncodeFunc keyed by reflect.Type.
+var reflectEncoderCache sync.Map
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	timeType          = reflect.TypeFor[time.Time]()
+)
+
+// appendReflectJSON appends the JSON encoding of v to buf, in the format
+// of encoding/json with HTML escaping disabled.
+func appendReflectJSON(buf []byte, v any) ([]byte, error) {
+	if v == nil {
+		return append(buf, "null"...), nil
+	}
+	rv := reflect.ValueOf(v)
+	return reflectEncoder(rv.Type())(buf, rv, 0)
+}
+
+// reflectEncoder returns the encoder of t, building it on first use. The
+// encoders of recursive types refer to themselves through a placeholder
+// that waits until the encoder is built.
+func reflectEncoder(t reflect.Type) reflectEncodeFunc {
+	if enc, ok := reflectEncoderCache.Load(t); ok {
+		return enc.(reflectEncodeFunc)
+	}
+
+	var (
+		wg  sync.WaitGroup
+		enc reflectEncodeFunc
+	)
+	wg.Add(1)
+	placeholder, loaded := reflectEncoderCache.LoadOrStore(t, reflectEncodeFunc(func(buf []byte, v reflect.Value, depth int) ([]byte, error) {
+		wg.Wait()
+		return enc(buf, v, depth)
+	}))
+	if loaded {
+		return placeholder.(reflectEncodeFunc)
+	}
+
+	enc = newReflectEncoder(t)
+	wg.Done()
+	reflectEncoderCache.Store(t, enc)
+	return enc
+}
+
+// newReflectEncoder builds the encoder of t. Types with a MarshalJSON or
+// MarshalText method, and the struct features the plan does not cover, are
+// left to encoding/json.
+func newReflectEncoder(t reflect.Type) reflectEncodeFunc {
+	if t == timeType {
+		return encodeReflectTime
+	}
+	if t.Kind() != reflect.Interface {
+		if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+			return encodeReflectMarshaler
+		}
+		if pt := reflect.PointerTo(t); pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType) {
+			return encodeReflectAddrMarshaler(newReflectKindEncoder(t))
+		}
+	}
+	return newReflectKindEncoder(t)
+}
+
+// newReflectKindEncoder builds the encoder of t by its kind.
+func newReflectKindEncoder(t reflect.Type) reflectEncodeFunc {
+	switch t.Kind() {
+	case reflect.Bool:
+		return encodeReflectBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodeReflectInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return encodeReflectUint
+	case reflect.Float32:
+		return encodeReflectFloat(32)
+	case reflect.Float64:
+		return encodeReflectFloat(64)
+	case reflect.String:
+		return encodeReflectString
+	case reflect.Interface:
+		return encodeReflectInterface
+	case reflect.Pointer:
+		return newReflectPointerEncoder(t)
+	case reflect.Struct:
+		return newReflectStructEncoder(t)
+	case reflect.Map:
+		return newReflectMapEncoder(t)
+	case reflect.Slice:
+		elem := t.Elem()
+		if elem.Kind() == reflect.Uint8 && !reflect.PointerTo(elem).Implements(jsonMarshalerType) && !reflect.PointerTo(elem).Implements(textMarshalerType) {
+			return encodeReflectBytes
+		}
+		return newReflectArrayEncoder(t, true)
+	case reflect.Array:
+		return newReflectArrayEncoder(t, false)
+	default:
+		return func(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+			return buf, &json.UnsupportedTypeError{Type: v.Type()}
+		}
+	}
+}
+
+func encodeReflectBool(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+	return strconv.AppendBool(buf, v.Bool()), nil
+}
+
+func encodeReflectInt(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+	return strconv.AppendInt(buf, v.Int(), 10), nil
+}
+
+func encodeReflectUint(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+	return strconv.AppendUint(buf, v.Uint(), 10), nil
+}
+
+// encodeReflectFloat returns the encoder of floats of bits. Like
+// encoding/json, it fails on NaN and infinities.
+func encodeReflectFloat(bits int) reflectEncodeFunc {
+	return func(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return buf, &json.UnsupportedValueError{Value: v, Str: strconv.FormatFloat(f, 'g', -1, bits)}
+		}
+		return appendFloatNumber(buf, f, bits), nil
+	}
+}
+
+func encodeReflectString(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+	buf = append(buf, '"')
+	buf = appendJSONString(buf, v.String())
+	return append(buf, '"'), nil
+}
+
+func encodeReflectBytes(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+	if v.IsNil() {
+		return append(buf, "null"...), nil
+	}
+	buf = append(buf, '"')
+	buf = base64.StdEncoding.AppendEncode(buf, v.Bytes())
+	return append(buf, '"'), nil
+}
+
+func encodeReflectTime(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+	buf = append(buf, '"')
+	buf = v.Interface().(time.Time).AppendFormat(buf, time.RFC3339Nano)
+	return append(buf, '"'), nil
+}
+
+func encodeReflectInterface(buf []byte, v reflect.Value, depth int) ([]byte, error) {
+	if v.IsNil() {
+		return append(buf, "null"...), nil
+	}
+	if depth++; depth > maxReflectDepth {
+		return buf, reflectCycleError(v)
+	}
+	elem := v.Elem()
+	return reflectEncoder(elem.Type())(buf, elem, depth)
+}
+
+// encodeReflectMarshaler encodes v with encoding/json, which calls its
+// MarshalJSON or MarshalText method.
+func encodeReflectMarshaler(buf []byte, v reflect.Value, _ int) ([]byte, error) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return append(buf, "null"...), nil
+	}
+	return appendJSONMarshal(buf, v.Interface())
+}
+
+// encodeReflectAddrMarshaler returns the encoder of a type whose pointer
+// has a MarshalJSON or MarshalText method. Like encoding/json, it calls the
+// method when the value is addressable and uses enc otherwise.
+func encodeReflectAddrMarshaler(enc reflectEncodeFunc) reflectEncodeFunc {
+	return func(buf []byte, v reflect.Value, depth int) ([]byte, error) {
+		if v.CanAddr() {
+			return appendJSONMarshal(buf, v.Addr().Interface())
+		}
+		return enc(buf, v, depth)
+	}
+}
+
+func newReflectPointerEncoder(t reflect.Type) reflectEncodeFunc {
+	elem := reflectEncoder(t.Elem())
+	return func(buf []byte, v reflect.Value, depth int) ([]byte, error) {
+		if v.IsNil() {
+			return append(buf, "null"...), nil
+		}
+		if depth++; depth > maxReflectDepth {
+			return buf, reflectCycleError(v)
+		}
+		return elem(buf, v.Elem(), depth)
+	}
+}
+
+func newReflectArrayEncoder(t reflect.Type, nullable bool) reflectEncodeFunc {
+	enc := reflectEncoder(t.Elem())
+	return func(buf []byte, v reflect.Value, depth int) ([]byte, error) {
+		if nullable && v.IsNil() {
+			return append(buf, "null"...), nil
+		}
+		if depth++; depth > maxReflectDepth {
+			return buf, reflectCycleError(v)
+		}
+
+		buf = append(buf, '[')
+		var err error
+		for i := range v.Len() {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = enc(buf, v.Index(i), depth); err != nil {
+				return buf, err
+			}
+		}
+		return append(buf, ']'), nil
+	}
+}
+
+// reflectMapEntry is a map entry with its key resolved to a string.
+type reflectMapEntry struct {
+	key   string
+	value reflect.Value
+}
+
+// newReflectMapEncoder builds the encoder of maps of t, which write their
+// entries sorted by key like encoding/json. Maps with keys other than
+// strings and integers are left to encoding/json.
+func newReflectMapEncoder(t reflect.Type) reflectEncodeFunc {
+	key := t.Key()
+	var keyString func(reflect.Value) string
+	switch key.Kind() {
+	case reflect.String:
+		keyString = reflect.Value.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		keyString = func(k reflect.Value) string { return strconv.FormatInt(k.Int(), 10) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		keyString = func(k reflect.Value) string { return strconv.FormatUint(k.Uint(), 10) }
+	}
+	if keyString == nil || key.Implements(textMarshalerType) {
+		return encodeReflectMarshaler
+	}
+
+	enc := reflectEncoder(t.Elem())
+	return func(buf []byte, v reflect.Value, depth int) ([]byte, error) {
+		if v.IsNil() {
+			return append(buf, "null"...), nil
+		}
+		if depth++; depth > maxReflectDepth {
+			return buf, reflectCycleError(v)
+		}
+
+		entries := make([]reflectMapEntry, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			entries = append(entries, reflectMapEntry{key: keyString(iter.Key()), value: iter.Value()})
+		}
+		slices.SortFunc(entries, func(a, b reflectMapEntry) int { return strings.Compare(a.key, b.key) })
+
+		buf = append(buf, '{')
+		var err error
+		for i, e := range entries {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, '"')
+			buf = appendJSONString(buf, e.key)
+			buf = append(buf, '"', ':')
+			if buf, err = enc(buf, e.value, depth); err != nil {
+				return buf, err
+			}
+		}
+		return append(buf, '}'), nil
+	}
+}
+
+// reflectStructField is a field in the plan of a struct encoder.
+type reflectStructField struct {
+	index     int
+	name      []byte // "name":, escaped
+	omitEmpty bool
+	enc       reflectEncodeFunc
+}
+
+// newReflectStructEncoder builds the plan of the exported fields of t,
+// honoring the name and omitempty options of their json tags. Structs with
+// embedded fields or the string and omitzero options are left to
+// encoding/json.
+func newReflectStructEncoder(t reflect.Type) reflectEncodeFunc {
+	var fields []reflectStructField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if sf.Anonymous {
+			return encodeReflectMarshaler
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		field := reflectStructField{index: i}
+		for opts != "" {
+			var opt string
+			opt, opts, _ = strings.Cut(opts, ",")
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "string", "omitzero":
+				return encodeReflectMarshaler
+			}
+		}
+		field.name = append(appendJSONString([]byte{'"'}, name), '"', ':')
+		fields = append(fields, field)
+	}
+	// Resolve the field encoders only once the plan is known to be used, so
+	// types left to encoding/json do not build encoders of their own.
+	for i, sf := range fields {
+		fields[i].enc = reflectEncoder(t.Field(sf.index).Type)
+	}
+
+	return func(buf []byte, v reflect.Value, depth int) ([]byte, error) {
+		buf = append(buf, '{')
+		first := true
+		var err error
+		for _, f := range fields {
+			fv := v.Field(f.index)
+			if f.omitEmpty && isEmptyReflectValue(fv) {
+				continue
+			}
+			if !first {
+				buf = append(buf, ',')
+			}
+			first = false
+			buf = append(buf, f.name...)
+			if buf, err = f.enc(buf, fv, depth); err != nil {
+				return buf, err
+			}
+		}
+		return append(buf, '}'), nil
+	}
+}
+
+// isEmptyReflectValue reports whether omitempty drops v.
+func isEmptyReflectValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// reflectCycleError returns the error of a value nested too deeply, which
+// is almost always a cycle.
+func reflectCycleError(v reflect.Value) error {
+	return &json.UnsupportedValueError{Value: v, Str: "encountered a cycle via " + v.Type().String()}
+}
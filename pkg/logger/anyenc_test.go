@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type anyUser struct {
+	ID       int64           `json:"id"`
+	Name     string          `json:"name"`
+	Email    string          `json:"email,omitempty"`
+	Password string          `json:"-"`
+	Dash     string          `json:"-,"`
+	Tags     []string        `json:"tags"`
+	Attrs    map[string]any  `json:"attrs,omitempty"`
+	Scores   map[int]float64 `json:"scores"`
+	Manager  *anyUser        `json:"manager,omitempty"`
+	Avatar   []byte          `json:"avatar"`
+	Created  time.Time       `json:"created"`
+	Addr     netip.Addr      `json:"addr"`
+	Raw      json.RawMessage `json:"raw,omitempty"`
+	Grid     [2][2]int8      `json:"grid"`
+	Ratio    float32         `json:"ratio"`
+	Untagged uint16
+	Labels   map[string]string `json:"labels"`
+	internal int
+}
+
+type anyEmbedded struct {
+	anyItem
+	Extra string `json:"extra"`
+}
+
+type anyNode struct {
+	Value int      `json:"value"`
+	Next  *anyNode `json:"next"`
+}
+
+func TestAppendReflectJSON_MatchesEncodingJSON(t *testing.T) {
+	manager := &anyUser{ID: 1, Name: "boss"}
+	values := []any{
+		anyUser{
+			ID:       2,
+			Name:     `Ann "<x>" \ é`,
+			Password: "secret",
+			Dash:     "d",
+			Tags:     []string{"a", "b"},
+			Attrs:    map[string]any{"z": 1, "a": []any{true, nil, 1.5}},
+			Scores:   map[int]float64{10: 1e-7, -2: 1e21, 3: 0.1},
+			Manager:  manager,
+			Avatar:   []byte{0, 1, 2, 255},
+			Created:  time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC),
+			Addr:     netip.MustParseAddr("10.0.0.1"),
+			Raw:      json.RawMessage(`{"k": 1}`),
+			Grid:     [2][2]int8{{1, -2}, {3, 4}},
+			Ratio:    0.1,
+			Untagged: 7,
+			internal: 3,
+		},
+		&anyUser{},
+		anyEmbedded{anyItem: anyItem{SKU: "a", Count: 1}, Extra: "x"},
+		&anyNode{Value: 1, Next: &anyNode{Value: 2}},
+		[]anyItem{{SKU: "a"}, {SKU: "b", Count: 2}},
+		map[string][]int{"b": {1}, "a": nil},
+		[]any{uint8(1), int8(-1), float32(2.5), "s"},
+		[3]bool{true},
+		(*anyUser)(nil),
+		map[uint]string{2: "b", 10: "a"},
+	}
+
+	for _, v := range values {
+		want := &bytes.Buffer{}
+		enc := json.NewEncoder(want)
+		enc.SetEscapeHTML(false)
+		require.NoError(t, enc.Encode(v))
+
+		got, err := appendReflectJSON(nil, v)
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimSuffix(want.String(), "\n"), string(got), "%T", v)
+	}
+}
+
+func TestAppendReflectJSON_Errors(t *testing.T) {
+	_, err := appendReflectJSON(nil, struct{ C chan int }{})
+	assert.EqualError(t, err, "json: unsupported type: chan int")
+
+	_, err = appendReflectJSON(nil, []float64{math.NaN()})
+	assert.EqualError(t, err, "json: unsupported value: NaN")
+
+	cycle := &anyNode{Value: 1}
+	cycle.Next = cycle
+	_, err = appendReflectJSON(nil, cycle)
+	assert.ErrorContains(t, err, "encountered a cycle via *logger.anyNode")
+}
+
+func TestAny_CyclicValue(t *testing.T) {
+	cycle := &anyNode{Value: 1}
+	cycle.Next = cycle
+
+	assert.Equal(t, `"!ERROR: json: unsupported value: encountered a cycle via *logger.anyNode"`,
+		string(Any("node", cycle).Value.(reflectValue).appendJSON(nil)))
+}
+
+func TestAppendReflectJSON_CachesEncoders(t *testing.T) {
+	item := anyItem{SKU: "a", Count: 2}
+	_, err := appendReflectJSON(nil, item)
+	require.NoError(t, err)
+
+	_, ok := reflectEncoderCache.Load(reflect.TypeOf(item))
+	assert.True(t, ok)
+
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = appendReflectJSON(buf[:0], &item)
+	})
+	assert.Zero(t, allocs)
+}
@@ -0,0 +1,262 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+// asyncEntry is a queued record together with the context of the request
+// it belongs to.
+type asyncEntry struct {
+	r    *Record
+	ctx  context.Context
+	size int64
+}
+
+// canceled reports whether the request the entry belongs to is gone.
+func (e asyncEntry) canceled() bool {
+	return e.ctx.Err() != nil
+}
+
+// asyncQueue is a bounded ring of records processed by a single background
+// goroutine. When the ring is full, low-priority entries are shed so that
+// WARN and more severe entries are never dropped.
+type asyncQueue struct {
+	logger *Logger
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	ring   []asyncEntry
+	head   int
+	n      int
+	busy   bool
+	closed bool
+	done   chan struct{}
+
+	// bytes is the estimated size of the queued records, kept under
+	// maxBytes when it is set.
+	bytes    int64
+	maxBytes int64
+
+	dropped       atomic.Uint64
+	memoryDropped atomic.Uint64
+}
+
+func newAsyncQueue(l *Logger, size int, maxBytes int) *asyncQueue {
+	q := &asyncQueue{
+		logger:   l,
+		ring:     make([]asyncEntry, size),
+		done:     make(chan struct{}),
+		maxBytes: int64(maxBytes),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	go q.run()
+
+	return q
+}
+
+// isLowPriority reports whether an entry may be shed under saturation.
+func isLowPriority(level Level) bool {
+	return level < WarnLevel
+}
+
+// push enqueues r, shedding entries when the queue is saturated in this
+// order of preference:
+//
+//  1. the new entry, if it is low priority and its request is canceled;
+//  2. the lowest-level queued low-priority entry of a canceled request;
+//  3. the new entry, if it is low priority;
+//  4. the lowest-level queued low-priority entry.
+//
+// The queue is saturated when it is full or when r does not fit into the
+// MemoryBudget. A high-priority entry waits for room when nothing can be
+// shed. An entry larger than the whole MemoryBudget is always dropped.
+// After the queue is closed, r is processed synchronously.
+func (q *asyncQueue) push(ctx context.Context, r *Record) {
+	e := asyncEntry{r: r, ctx: ctx}
+	if q.maxBytes > 0 {
+		e.size = recordSize(r)
+		if e.size > q.maxBytes {
+			q.memoryDropped.Add(1)
+			q.logger.countDropped(logmetrics.DropMemory)
+			q.logger.putRecord(r)
+			return
+		}
+	}
+
+	q.mu.Lock()
+	for !q.closed && q.saturated(e) {
+		if q.shed(e) {
+			q.mu.Unlock()
+			return
+		}
+		if !q.saturated(e) {
+			break
+		}
+		q.cond.Wait()
+	}
+
+	if q.closed {
+		q.mu.Unlock()
+		q.logger.process(r)
+		q.logger.putRecord(r)
+		return
+	}
+
+	q.ring[(q.head+q.n)%len(q.ring)] = e
+	q.n++
+	q.bytes += e.size
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
+
+// saturated reports whether there is no room for e. It must be called with
+// q.mu held.
+func (q *asyncQueue) saturated(e asyncEntry) bool {
+	return q.n == len(q.ring) || (q.maxBytes > 0 && q.bytes+e.size > q.maxBytes)
+}
+
+// shed frees room for e on a full queue. It reports true when e itself was
+// dropped. It must be called with q.mu held.
+func (q *asyncQueue) shed(e asyncEntry) bool {
+	low := isLowPriority(e.r.Level)
+
+	if low && e.canceled() {
+		q.drop(e.r)
+		return true
+	}
+	if i := q.victim(true); i >= 0 {
+		q.removeAt(i)
+		return false
+	}
+	if low {
+		q.drop(e.r)
+		return true
+	}
+	if i := q.victim(false); i >= 0 {
+		q.removeAt(i)
+	}
+	return false
+}
+
+// victim returns the position of the oldest queued low-priority entry with
+// the lowest level, restricted to canceled requests when canceledOnly is
+// set, or -1 when there is none. It must be called with q.mu held.
+func (q *asyncQueue) victim(canceledOnly bool) int {
+	best := -1
+	for i := 0; i < q.n; i++ {
+		e := q.ring[(q.head+i)%len(q.ring)]
+		if !isLowPriority(e.r.Level) || (canceledOnly && !e.canceled()) {
+			continue
+		}
+		if best < 0 || e.r.Level < q.ring[(q.head+best)%len(q.ring)].r.Level {
+			best = i
+		}
+	}
+	return best
+}
+
+// removeAt drops the entry at position i, shifting later entries forward.
+// It must be called with q.mu held.
+func (q *asyncQueue) removeAt(i int) {
+	size := len(q.ring)
+	q.bytes -= q.ring[(q.head+i)%size].size
+	q.drop(q.ring[(q.head+i)%size].r)
+
+	for j := i; j < q.n-1; j++ {
+		q.ring[(q.head+j)%size] = q.ring[(q.head+j+1)%size]
+	}
+	q.n--
+	q.ring[(q.head+q.n)%size] = asyncEntry{}
+}
+
+// drop releases a shed record and counts it.
+func (q *asyncQueue) drop(r *Record) {
+	q.dropped.Add(1)
+	q.logger.countDropped(logmetrics.DropAsyncQueue)
+	q.logger.putRecord(r)
+}
+
+// run processes queued entries until the queue is closed and empty.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+		for q.n == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.n == 0 {
+			q.mu.Unlock()
+			return
+		}
+
+		e := q.ring[q.head]
+		q.ring[q.head] = asyncEntry{}
+		q.head = (q.head + 1) % len(q.ring)
+		q.n--
+		q.bytes -= e.size
+		q.busy = true
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		q.logger.process(e.r)
+		q.logger.putRecord(e.r)
+
+		q.mu.Lock()
+		q.busy = false
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// drain blocks until every entry queued so far has been processed.
+func (q *asyncQueue) drain() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.n > 0 || q.busy {
+		q.cond.Wait()
+	}
+}
+
+// close stops accepting entries and waits for the queue to drain.
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	<-q.done
+}
+
+// Close stops delivery tracing, writes the entries of AsyncSignalSafe,
+// drains the async queue, stops its goroutine and the timestamp cache and
+// flushes all buffered output. Entries logged after Close are written synchronously.
+// It is safe to call Close more than once. Close of a logger created by Tee
+// closes every logger it fans out to.
+func (l *Logger) Close() error {
+	var err error
+	for _, t := range l.tee {
+		err = errors.Join(err, t.Close())
+	}
+	if l.tracer != nil {
+		l.tracer.close()
+	}
+	if l.signalRing != nil {
+		l.signalRing.close()
+	}
+	if l.async != nil {
+		l.async.close()
+	}
+	if l.timeCache != nil {
+		l.timeCache.close()
+	}
+	l.Flush()
+	return err
+}
@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter blocks every Write until release is closed.
+type gatedWriter struct {
+	release chan struct{}
+	entered chan struct{}
+	once    sync.Once
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{
+		release: make(chan struct{}),
+		entered: make(chan struct{}),
+	}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.release
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// saturate logs one entry and waits until the background goroutine is
+// blocked writing it, so the queue itself is empty.
+func saturate(t *testing.T, logger *Logger, w *gatedWriter) {
+	t.Helper()
+
+	logger.Warn("blocker")
+	select {
+	case <-w.entered:
+	case <-time.After(time.Second):
+		t.Fatal("background goroutine did not start writing")
+	}
+}
+
+func TestAsync_WritesAfterFlush(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:          DebugLevel,
+		Format:         TextFormat,
+		Output:         buf,
+		AsyncQueueSize: 16,
+	})
+	defer logger.Close()
+
+	fields := []Field{{Key: "n", Value: 1}}
+	logger.Info("first", fields...)
+	fields[0].Value = 2
+	logger.Info("second", fields...)
+	logger.Flush()
+
+	output := buf.String()
+	assert.Contains(t, output, "first n=1")
+	assert.Contains(t, output, "second n=2")
+}
+
+func TestAsync_SnapshotsAnyValues(t *testing.T) {
+	w := newGatedWriter()
+	logger := New(Config{Level: InfoLevel, Output: w, TimestampFormat: TimestampDisabled, AsyncQueueSize: 16})
+	defer logger.Close()
+	saturate(t, logger, w)
+
+	// The entry is queued while the map changes; it is written as logged.
+	counts := map[string]int{"a": 1}
+	logger.Info("counts", Any("counts", counts), Group("g", Any("counts", counts)))
+	counts["a"] = 2
+	close(w.release)
+	logger.Flush()
+
+	assert.Contains(t, w.String(), `INFO counts counts={"a":1} g.counts={"a":1}`)
+}
+
+func TestAsync_ShedsCanceledLowPriorityFirst(t *testing.T) {
+	w := newGatedWriter()
+
+	logger := New(Config{
+		Level:          DebugLevel,
+		Format:         TextFormat,
+		Output:         w,
+		AsyncQueueSize: 2,
+	})
+
+	saturate(t, logger, w)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	logger.WithContext(func() context.Context { return canceled }).Info("canceled-info")
+	logger.Debug("live-debug")
+
+	// The queue is full: the canceled entry is shed before the live one,
+	// even though the live entry has a lower level.
+	logger.Info("live-info")
+	assert.Equal(t, uint64(1), logger.Stats().AsyncDropped)
+
+	// A canceled low-priority entry is dropped on arrival.
+	logger.WithContext(func() context.Context { return canceled }).Debug("canceled-debug")
+	assert.Equal(t, uint64(2), logger.Stats().AsyncDropped)
+
+	// Without canceled entries, the lowest queued level goes first.
+	logger.Error("error")
+	assert.Equal(t, uint64(3), logger.Stats().AsyncDropped)
+	assert.Equal(t, 2, logger.Stats().AsyncQueued)
+
+	close(w.release)
+	require.NoError(t, logger.Close())
+
+	output := w.String()
+	assert.Contains(t, output, "blocker")
+	assert.Contains(t, output, "live-info")
+	assert.Contains(t, output, "error")
+	assert.NotContains(t, output, "canceled-info")
+	assert.NotContains(t, output, "canceled-debug")
+	assert.NotContains(t, output, "live-debug")
+	assert.Zero(t, logger.Stats().AsyncQueued)
+}
+
+func TestAsync_NeverDropsWarnAndAbove(t *testing.T) {
+	w := newGatedWriter()
+
+	logger := New(Config{
+		Level:          DebugLevel,
+		Format:         TextFormat,
+		Output:         w,
+		AsyncQueueSize: 1,
+	})
+
+	saturate(t, logger, w)
+
+	logger.Info("info")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Warn("warn-1")
+		logger.Error("error-1")
+	}()
+
+	// warn-1 replaces the queued INFO entry; error-1 has to wait.
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("ERROR entry was not blocked by a full queue")
+	default:
+	}
+
+	close(w.release)
+	<-done
+	require.NoError(t, logger.Close())
+
+	output := w.String()
+	assert.NotContains(t, output, "info")
+	assert.Contains(t, output, "warn-1")
+	assert.Contains(t, output, "error-1")
+	assert.Equal(t, uint64(1), logger.Stats().AsyncDropped)
+}
+
+func TestAsync_LogAfterCloseIsSynchronous(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:          InfoLevel,
+		Format:         TextFormat,
+		Output:         buf,
+		AsyncQueueSize: 4,
+	})
+
+	logger.Info("before")
+	require.NoError(t, logger.Close())
+	require.NoError(t, logger.Close())
+
+	logger.Info("after")
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), "after")
+}
+
+func TestStats_SyncLogger(t *testing.T) {
+	logger := New(Config{Output: &bytes.Buffer{}})
+
+	assert.Equal(t, Stats{}, logger.Stats())
+	assert.NoError(t, logger.Close())
+}
@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+)
+
+// Keys of the fields added to audit entries.
+const (
+	// AuditSequenceKey holds the number of the entry among those written
+	// by an AuditLogger, starting at 1, so gaps reveal deleted entries.
+	AuditSequenceKey = "audit_seq"
+
+	// AuditMACKey holds the HMAC-SHA256 of the entry, in hex, when
+	// AuditConfig.HMACKey is set. It is always the last field.
+	AuditMACKey = "audit_mac"
+)
+
+// Errors returned by VerifyAuditEntry.
+var (
+	ErrAuditMACMissing  = errors.New("logger: audit entry has no " + AuditMACKey)
+	ErrAuditMACMismatch = errors.New("logger: audit entry was altered")
+)
+
+// AuditConfig configures an AuditLogger.
+type AuditConfig struct {
+	// Output receives the audit entries. It is required. Outputs such as
+	// *os.File are synced after every entry.
+	Output io.Writer
+
+	// Format is the output format of the entries. The MAC is supported
+	// for every format writing one entry per line.
+	Format Format
+
+	// TimestampFormat controls the timestamp of the entries.
+	TimestampFormat TimestampFormat
+
+	// HMACKey, when set, adds an AuditMACKey field holding the
+	// HMAC-SHA256 of every entry under the key, so holders of the key can
+	// tell with VerifyAuditEntry whether an entry was altered. To detect
+	// removed entries as well, write to an IntegrityWriter with a hash
+	// chain.
+	HMACKey []byte
+}
+
+// AuditLogger writes audit entries for compliance logging. Unlike a
+// Logger, it never drops entries: there are no levels, sampling, rate
+// limits or buffering, every entry is written and synced before Audit
+// returns, and Audit reports the write error. Entries are numbered under
+// AuditSequenceKey and written one at a time, in that order.
+//
+// An AuditLogger is safe for concurrent use. Loggers returned by With
+// share the sequence and output of their parent.
+type AuditLogger struct {
+	log   *Logger
+	state *auditState
+}
+
+// auditState is shared by an AuditLogger and the loggers derived from it.
+type auditState struct {
+	mu  sync.Mutex
+	seq uint64
+	out *auditOutput
+}
+
+// NewAuditLogger creates an AuditLogger.
+//
+// Example:
+//
+//	audit, err := logger.NewAuditLogger(logger.AuditConfig{
+//		Output:  file,
+//		Format:  logger.JSONFormat,
+//		HMACKey: key,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer audit.Close()
+//
+//	if err := audit.Audit("role granted", logger.String("user", id), logger.String("role", "admin")); err != nil {
+//		return fmt.Errorf("audit: %w", err)
+//	}
+func NewAuditLogger(config AuditConfig) (*AuditLogger, error) {
+	if config.Output == nil || isNilWriter(config.Output) {
+		return nil, errors.New("logger: AuditConfig.Output is required")
+	}
+	if !validFormat(config.Format) {
+		return nil, fmt.Errorf("logger: unknown AuditConfig.Format %d", config.Format)
+	}
+
+	out := &auditOutput{w: config.Output}
+	out.syncer, _ = config.Output.(syncer)
+	if len(config.HMACKey) > 0 {
+		out.mac = hmac.New(sha256.New, config.HMACKey)
+	}
+
+	log := New(Config{
+		Level:           DebugLevel,
+		Format:          config.Format,
+		TimestampFormat: config.TimestampFormat,
+		Output:          out,
+	})
+	return &AuditLogger{log: log, state: &auditState{out: out}}, nil
+}
+
+// Audit writes an entry with msg and fields and returns once it was
+// written and synced, or the error that prevented it.
+func (a *AuditLogger) Audit(msg string, fields ...Field) error {
+	a.state.mu.Lock()
+	defer a.state.mu.Unlock()
+
+	a.state.seq++
+	fields = append(fields[:len(fields):len(fields)], F(AuditSequenceKey, a.state.seq))
+	a.state.out.err = nil
+	a.log.logAt(context.Background(), a.log.now(), InfoLevel, msg, fields)
+	return a.state.out.err
+}
+
+// With returns an AuditLogger that adds fields to every entry, sharing the
+// sequence and output of a.
+func (a *AuditLogger) With(fields ...Field) *AuditLogger {
+	return &AuditLogger{log: a.log.With(fields...), state: a.state}
+}
+
+// Close releases the resources of the logger. It does not close the
+// output.
+func (a *AuditLogger) Close() error {
+	return a.log.Close()
+}
+
+// auditOutput writes the entries of an AuditLogger, adding the MAC and
+// syncing. It is only used with auditState.mu held.
+type auditOutput struct {
+	w      io.Writer
+	syncer syncer
+	mac    hash.Hash
+	buf    []byte
+
+	// err is the error of the last write.
+	err error
+}
+
+// Write writes the entry p.
+func (o *auditOutput) Write(p []byte) (int, error) {
+	entry := p
+	if o.mac != nil {
+		line := bytes.TrimSuffix(p, []byte{'\n'})
+		o.mac.Reset()
+		o.mac.Write(line)
+		o.buf = appendHexField(o.buf[:0], line, AuditMACKey, o.mac.Sum(nil))
+		o.buf = append(o.buf, '\n')
+		entry = o.buf
+	}
+
+	_, err := o.w.Write(entry)
+	if err == nil && o.syncer != nil {
+		err = o.syncer.Sync()
+	}
+	o.err = err
+	return len(p), err
+}
+
+// lineOutput implements lineOutput.
+func (o *auditOutput) lineOutput() {}
+
+// VerifyAuditEntry checks the AuditMACKey field of an entry written by an
+// AuditLogger with key. It returns
+// ErrAuditMACMissing for an entry without the field and ErrAuditMACMismatch
+// when the entry differs from the one written.
+func VerifyAuditEntry(key, entry []byte) error {
+	line := bytes.TrimSuffix(entry, []byte{'\n'})
+	if !bytes.Contains(line, []byte(AuditMACKey)) {
+		return ErrAuditMACMissing
+	}
+	signed, want, ok := cutHexField(line, AuditMACKey)
+	if !ok {
+		return ErrAuditMACMismatch
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(signed)
+	if !hmac.Equal(h.Sum(nil), want) {
+		return ErrAuditMACMismatch
+	}
+	return nil
+}
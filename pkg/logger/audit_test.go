@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogger(t *testing.T) {
+	out := &syncBuffer{}
+	audit, err := NewAuditLogger(AuditConfig{Output: out, Format: JSONFormat, TimestampFormat: TimestampDisabled})
+	require.NoError(t, err)
+	defer audit.Close()
+
+	require.NoError(t, audit.Audit("role granted", String("user", "42")))
+	require.NoError(t, audit.With(String("actor", "admin")).Audit("user deleted"))
+
+	assert.Equal(t, `{"level":"INFO","message":"role granted","user":"42","audit_seq":1}`+"\n"+
+		`{"level":"INFO","message":"user deleted","actor":"admin","audit_seq":2}`+"\n", out.String())
+	assert.Equal(t, 2, out.syncs, "every entry is synced")
+}
+
+func TestAuditLogger_WriteError(t *testing.T) {
+	audit, err := NewAuditLogger(AuditConfig{Output: failingWriter{}})
+	require.NoError(t, err)
+
+	assert.EqualError(t, audit.Audit("lost"), "disk full")
+}
+
+func TestAuditLogger_Concurrent(t *testing.T) {
+	out := &syncBuffer{}
+	audit, err := NewAuditLogger(AuditConfig{Output: out, Format: JSONFormat})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				assert.NoError(t, audit.Audit("event"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 400)
+	for i, line := range lines {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, float64(i+1), entry[AuditSequenceKey], "entries are written in sequence order")
+	}
+}
+
+func TestAuditLogger_HMAC(t *testing.T) {
+	key := []byte("secret key")
+	for _, format := range []Format{JSONFormat, TextFormat, ECSFormat} {
+		out := &bytes.Buffer{}
+		audit, err := NewAuditLogger(AuditConfig{Output: out, Format: format, HMACKey: key})
+		require.NoError(t, err)
+
+		require.NoError(t, audit.Audit("payment approved", Int("amount", 100)))
+		entry := out.String()
+		assert.Contains(t, entry, AuditMACKey, format)
+		assert.NoError(t, VerifyAuditEntry(key, []byte(entry)), format)
+
+		tampered := strings.Replace(entry, "100", "900", 1)
+		assert.ErrorIs(t, VerifyAuditEntry(key, []byte(tampered)), ErrAuditMACMismatch, format)
+		assert.ErrorIs(t, VerifyAuditEntry([]byte("other key"), []byte(entry)), ErrAuditMACMismatch, format)
+	}
+
+	var entry map[string]any
+	out := &bytes.Buffer{}
+	audit, err := NewAuditLogger(AuditConfig{Output: out, Format: JSONFormat, HMACKey: key})
+	require.NoError(t, err)
+	require.NoError(t, audit.Audit("valid json"))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Len(t, entry[AuditMACKey], 64)
+}
+
+func TestVerifyAuditEntry_Missing(t *testing.T) {
+	assert.ErrorIs(t, VerifyAuditEntry([]byte("k"), []byte(`{"message":"plain"}`)), ErrAuditMACMissing)
+	assert.ErrorIs(t, VerifyAuditEntry([]byte("k"), []byte(`INFO plain`)), ErrAuditMACMissing)
+}
+
+func TestNewAuditLogger_Invalid(t *testing.T) {
+	_, err := NewAuditLogger(AuditConfig{})
+	assert.EqualError(t, err, "logger: AuditConfig.Output is required")
+
+	_, err = NewAuditLogger(AuditConfig{Output: &bytes.Buffer{}, Format: 42})
+	assert.EqualError(t, err, "logger: unknown AuditConfig.Format 42")
+}
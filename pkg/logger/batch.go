@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"io"
+	"time"
+)
+
+// BatchLimits describes how log entries may be grouped into a single Write
+// call on an output. A zero value for any limit means no constraint.
+//
+// Entries are never split across writes: an entry that exceeds MaxBytes on
+// its own is written as a batch of one.
+type BatchLimits struct {
+	// MaxBytes is the maximum size of a single Write, including the
+	// newline terminating each entry.
+	MaxBytes int
+
+	// MaxEvents is the maximum number of entries in a single Write.
+	MaxEvents int
+
+	// MaxAge is the maximum time an entry may stay buffered before it is
+	// written, regardless of the other limits.
+	MaxAge time.Duration
+}
+
+// BatchLimiter is implemented by outputs that constrain batching, such as
+// collectors with payload size or event count limits. When an output
+// implements BatchLimiter, the Logger buffers its entries and flushes them
+// so that every Write respects the declared limits.
+type BatchLimiter interface {
+	BatchLimits() BatchLimits
+}
+
+// resolveBatchLimits combines the configured buffer size and flush interval
+// with the limits declared by the output, keeping the strictest value of
+// each limit. A flush interval alone does not enable buffering.
+func resolveBatchLimits(bufferSize int, flushInterval time.Duration, output io.Writer) BatchLimits {
+	limits := BatchLimits{}
+	if bufferSize > 0 {
+		limits.MaxBytes = bufferSize
+		limits.MaxAge = max(flushInterval, 0)
+	}
+
+	bl, ok := output.(BatchLimiter)
+	if !ok {
+		return limits
+	}
+
+	declared := bl.BatchLimits()
+	limits.MaxBytes = minLimit(limits.MaxBytes, declared.MaxBytes)
+	limits.MaxEvents = minLimit(limits.MaxEvents, declared.MaxEvents)
+	limits.MaxAge = time.Duration(minLimit(int(limits.MaxAge), int(declared.MaxAge)))
+
+	return limits
+}
+
+// minLimit returns the smaller of two limits, treating zero as unlimited.
+func minLimit(a, b int) int {
+	switch {
+	case a <= 0:
+		return b
+	case b <= 0:
+		return a
+	default:
+		return min(a, b)
+	}
+}
+
+// startBatch records the start of a new batch and arms the MaxAge timer.
+// It must be called with s.mu held.
+func (s *sink) startBatch() {
+	if s.batch.MaxAge <= 0 {
+		return
+	}
+
+	s.batchStart = time.Now()
+	if s.batchTimer == nil {
+		s.batchTimer = time.AfterFunc(s.batch.MaxAge, s.flushAged)
+	} else {
+		s.batchTimer.Reset(s.batch.MaxAge)
+	}
+}
+
+// flushAged flushes the buffer once its oldest entry reached MaxAge. A timer
+// that fires for a batch that was already flushed is re-armed for the
+// current batch instead.
+func (s *sink) flushAged() {
+	if s.shards != nil {
+		// The timer was armed by the first entry of the shards; entries
+		// added from now on arm it again.
+		s.timerArmed.Store(false)
+		s.Flush()
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.batchCount == 0 {
+		return
+	}
+
+	if age := time.Since(s.batchStart); age < s.batch.MaxAge {
+		s.batchTimer.Reset(s.batch.MaxAge - age)
+		return
+	}
+
+	s.flush()
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// limitedWriter records every Write call and declares fixed batch limits.
+type limitedWriter struct {
+	mu     sync.Mutex
+	limits BatchLimits
+	writes []string
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *limitedWriter) BatchLimits() BatchLimits {
+	return w.limits
+}
+
+func (w *limitedWriter) Writes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.writes...)
+}
+
+func TestBatchLimits_MaxEvents(t *testing.T) {
+	w := &limitedWriter{limits: BatchLimits{MaxEvents: 2}}
+
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: w})
+
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+
+	writes := w.Writes()
+	require.Len(t, writes, 1)
+	assert.Equal(t, 2, strings.Count(writes[0], "\n"))
+
+	logger.Flush()
+	writes = w.Writes()
+	require.Len(t, writes, 2)
+	assert.Contains(t, writes[1], "three")
+}
+
+func TestBatchLimits_MaxBytesOverridesBufferSize(t *testing.T) {
+	w := &limitedWriter{limits: BatchLimits{MaxBytes: 120}}
+
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: w, BufferSize: 4096})
+
+	for i := 0; i < 10; i++ {
+		logger.Info("batched message")
+	}
+	logger.Flush()
+
+	writes := w.Writes()
+	require.Greater(t, len(writes), 1)
+	for _, batch := range writes {
+		assert.LessOrEqual(t, len(batch), 120)
+		assert.True(t, strings.HasSuffix(batch, "\n"), "entries must not be split")
+	}
+	assert.Equal(t, 10, strings.Count(strings.Join(writes, ""), "batched message"))
+}
+
+func TestBatchLimits_MaxAge(t *testing.T) {
+	w := &limitedWriter{limits: BatchLimits{MaxAge: 10 * time.Millisecond}}
+
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: w})
+	logger.Info("aged")
+
+	assert.Empty(t, w.Writes())
+	assert.Eventually(t, func() bool {
+		return len(w.Writes()) == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestResolveBatchLimits(t *testing.T) {
+	assert.Equal(t, BatchLimits{}, resolveBatchLimits(0, 0, &bytes.Buffer{}))
+	assert.Equal(t, BatchLimits{MaxBytes: 512}, resolveBatchLimits(512, 0, &bytes.Buffer{}))
+
+	w := &limitedWriter{limits: BatchLimits{MaxBytes: 1024, MaxEvents: 10}}
+	assert.Equal(t, BatchLimits{MaxBytes: 512, MaxEvents: 10}, resolveBatchLimits(512, 0, w))
+	assert.Equal(t, BatchLimits{MaxBytes: 1024, MaxEvents: 10}, resolveBatchLimits(0, 0, w))
+}
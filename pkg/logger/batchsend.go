@@ -0,0 +1,369 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of a BatchSender.
+const (
+	defaultSendMaxEvents       = 500
+	defaultSendMaxBytes        = 1 << 20
+	defaultSendInterval        = time.Second
+	defaultSendMaxRetries      = 5
+	defaultSendMinBackoff      = 100 * time.Millisecond
+	defaultSendMaxBackoff      = 10 * time.Second
+	defaultSendTimeout         = 10 * time.Second
+	defaultSendMaxPendingBytes = 16 << 20
+	defaultSendShutdownTimeout = 10 * time.Second
+)
+
+// SendFunc delivers a batch of encoded entries, each without its trailing
+// newline. The entries are only valid until SendFunc returns.
+type SendFunc func(ctx context.Context, batch [][]byte) error
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that a BatchSender does not retry the batch, e.g.
+// when a collector rejects the payload with a 400 status.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	var pe permanentError
+	return errors.As(err, &pe)
+}
+
+// BatchSenderConfig configures a BatchSender. Zero values select the
+// defaults noted on each field.
+type BatchSenderConfig struct {
+	// Send delivers a batch. Required.
+	Send SendFunc
+
+	// MaxEvents is the maximum number of entries per batch. Defaults to 500.
+	MaxEvents int
+
+	// MaxBytes is the maximum size of a batch. Defaults to 1 MiB.
+	MaxBytes int
+
+	// Interval is the maximum time an entry waits for its batch to fill up.
+	// Defaults to one second.
+	Interval time.Duration
+
+	// MaxRetries is the number of retries of a failed batch before it is
+	// dropped. Defaults to 5; a negative value disables retries.
+	MaxRetries int
+
+	// MinBackoff and MaxBackoff bound the exponential delay between
+	// retries. They default to 100ms and 10s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// SendTimeout bounds every call to Send. Defaults to 10s.
+	SendTimeout time.Duration
+
+	// MaxPendingBytes caps the memory held by batches waiting to be sent.
+	// Batches that do not fit are dropped. Defaults to 16 MiB.
+	MaxPendingBytes int
+
+	// ShutdownTimeout bounds how long Close waits for pending batches.
+	// Defaults to 10s.
+	ShutdownTimeout time.Duration
+
+	// OnError, if set, is called when a batch is dropped after its last
+	// attempt failed.
+	OnError func(err error, entries int)
+}
+
+// BatchSender is a generic output that ships entries in batches to a
+// SendFunc, retrying failures with exponential backoff. It is the building
+// block for HTTP collectors such as Loki or Elasticsearch.
+//
+// The Logger groups entries into batches according to the limits declared
+// through BatchLimits, and the BatchSender delivers them on a background
+// goroutine, so logging never waits for the network. Memory is bounded by
+// MaxPendingBytes, and by the MemoryBudget of the Logger, if any. Call
+// Close after the Logger is flushed to deliver the remaining batches.
+type BatchSender struct {
+	cfg    BatchSenderConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []sendBatch
+	pending int
+	closed  bool
+	done    chan struct{}
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+
+	budget atomic.Pointer[bufferBudget]
+}
+
+// sendBatch is a queued batch. The entries point into buf, whose bytes are
+// reserved in budget, if any.
+type sendBatch struct {
+	buf     []byte
+	entries [][]byte
+	budget  *bufferBudget
+}
+
+// NewBatchSender starts a sender delivering batches to cfg.Send.
+//
+// Example:
+//
+//	sender, err := logger.NewBatchSender(logger.BatchSenderConfig{
+//		Send: func(ctx context.Context, batch [][]byte) error {
+//			return collector.Push(ctx, batch)
+//		},
+//		MaxEvents: 1000,
+//		Interval:  2 * time.Second,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	log := logger.New(logger.Config{Format: logger.JSONFormat, Output: sender})
+//	defer sender.Close()
+//	defer log.Close()
+func NewBatchSender(cfg BatchSenderConfig) (*BatchSender, error) {
+	if cfg.Send == nil {
+		return nil, errors.New("logger: BatchSenderConfig.Send is required")
+	}
+	if cfg.MaxEvents <= 0 {
+		cfg.MaxEvents = defaultSendMaxEvents
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultSendMaxBytes
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultSendInterval
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultSendMaxRetries
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultSendMinBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultSendMaxBackoff
+	}
+	cfg.MaxBackoff = max(cfg.MaxBackoff, cfg.MinBackoff)
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = defaultSendTimeout
+	}
+	if cfg.MaxPendingBytes <= 0 {
+		cfg.MaxPendingBytes = defaultSendMaxPendingBytes
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = defaultSendShutdownTimeout
+	}
+
+	s := &BatchSender{
+		cfg:  cfg,
+		done: make(chan struct{}),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.cond = sync.NewCond(&s.mu)
+
+	go s.run()
+
+	return s, nil
+}
+
+// BatchLimits implements BatchLimiter.
+func (s *BatchSender) BatchLimits() BatchLimits {
+	return BatchLimits{
+		MaxBytes:  s.cfg.MaxBytes,
+		MaxEvents: s.cfg.MaxEvents,
+		MaxAge:    s.cfg.Interval,
+	}
+}
+
+// Write queues the newline-terminated entries in p as one batch. It never
+// blocks on delivery and always reports len(p) bytes written; batches that
+// exceed the memory cap are dropped and counted.
+func (s *BatchSender) Write(p []byte) (int, error) {
+	batch := sendBatch{buf: bytes.Clone(p)}
+	rest := batch.buf
+	for len(rest) > 0 {
+		var entry []byte
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			entry, rest = rest[:i], rest[i+1:]
+		} else {
+			entry, rest = rest, nil
+		}
+		if len(entry) > 0 {
+			batch.entries = append(batch.entries, entry)
+		}
+	}
+	if len(batch.entries) == 0 {
+		return len(p), nil
+	}
+
+	if b := s.budget.Load(); b != nil {
+		if !b.reserve(int64(len(batch.buf))) {
+			s.dropped.Add(uint64(len(batch.entries)))
+			b.dropped.Add(uint64(len(batch.entries)))
+			return len(p), nil
+		}
+		batch.budget = b
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.pending+len(batch.buf) > s.cfg.MaxPendingBytes {
+		s.dropped.Add(uint64(len(batch.entries)))
+		batch.release()
+		return len(p), nil
+	}
+
+	s.queue = append(s.queue, batch)
+	s.pending += len(batch.buf)
+	s.cond.Broadcast()
+
+	return len(p), nil
+}
+
+// lineOutput implements lineOutput.
+func (s *BatchSender) lineOutput() {}
+
+// Sent returns the number of entries delivered successfully.
+func (s *BatchSender) Sent() uint64 {
+	return s.sent.Load()
+}
+
+// Dropped returns the number of entries discarded because the memory cap
+// or the MemoryBudget of the Logger was reached, or the sender was closed.
+func (s *BatchSender) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Failed returns the number of entries discarded after delivery failed.
+func (s *BatchSender) Failed() uint64 {
+	return s.failed.Load()
+}
+
+// Close stops accepting entries and waits up to ShutdownTimeout for the
+// pending batches to be delivered. Batches still pending after the timeout
+// are abandoned. It is safe to call Close more than once.
+func (s *BatchSender) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.cfg.ShutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-s.done:
+	case <-timer.C:
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+	return nil
+}
+
+// run delivers queued batches until the sender is closed and drained.
+func (s *BatchSender) run() {
+	defer close(s.done)
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+
+		batch := s.queue[0]
+		s.queue[0] = sendBatch{}
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		s.deliver(batch.entries)
+
+		s.mu.Lock()
+		s.pending -= len(batch.buf)
+		s.mu.Unlock()
+		batch.release()
+	}
+}
+
+// release returns the bytes of the batch to the budget.
+func (b *sendBatch) release() {
+	if b.budget != nil {
+		b.budget.release(int64(len(b.buf)))
+	}
+}
+
+// setBufferBudget implements budgetedOutput.
+func (s *BatchSender) setBufferBudget(b *bufferBudget) {
+	s.budget.Store(b)
+}
+
+// deliver sends one batch, retrying with exponential backoff.
+func (s *BatchSender) deliver(entries [][]byte) {
+	delay := s.cfg.MinBackoff
+
+	for attempt := 0; ; attempt++ {
+		if s.ctx.Err() != nil {
+			s.fail(s.ctx.Err(), len(entries))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(s.ctx, s.cfg.SendTimeout)
+		err := s.cfg.Send(ctx, entries)
+		cancel()
+
+		if err == nil {
+			s.sent.Add(uint64(len(entries)))
+			return
+		}
+		if IsPermanent(err) || attempt >= s.cfg.MaxRetries {
+			s.fail(err, len(entries))
+			return
+		}
+
+		// Up to 20% jitter spreads retries of many senders.
+		jitter := time.Duration(rand.Int64N(int64(delay)/5 + 1))
+		timer := time.NewTimer(delay + jitter)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		delay = min(delay*2, s.cfg.MaxBackoff)
+	}
+}
+
+// fail counts a dropped batch and reports it.
+func (s *BatchSender) fail(err error, entries int) {
+	s.failed.Add(uint64(entries))
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(err, entries)
+	}
+}
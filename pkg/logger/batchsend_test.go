@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSend collects delivered batches and fails the first failures
+// attempts.
+type recordingSend struct {
+	mu       sync.Mutex
+	batches  [][]string
+	attempts int
+	failures int
+	err      error
+}
+
+func (rs *recordingSend) send(_ context.Context, batch [][]byte) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.attempts++
+	if rs.attempts <= rs.failures {
+		return rs.err
+	}
+
+	entries := make([]string, len(batch))
+	for i, entry := range batch {
+		entries[i] = string(entry)
+	}
+	rs.batches = append(rs.batches, entries)
+	return nil
+}
+
+func (rs *recordingSend) snapshot() ([][]string, int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([][]string(nil), rs.batches...), rs.attempts
+}
+
+func TestBatchSender_BatchesByCount(t *testing.T) {
+	rs := &recordingSend{}
+	sender, err := NewBatchSender(BatchSenderConfig{
+		Send:      rs.send,
+		MaxEvents: 2,
+		Interval:  time.Hour,
+	})
+	require.NoError(t, err)
+
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, TextTemplate: "{msg}", Output: sender})
+	for i := 0; i < 5; i++ {
+		logger.Info("entry " + strconv.Itoa(i))
+	}
+	require.NoError(t, logger.Close())
+	require.NoError(t, sender.Close())
+
+	batches, _ := rs.snapshot()
+	assert.Equal(t, [][]string{
+		{"entry 0", "entry 1"},
+		{"entry 2", "entry 3"},
+		{"entry 4"},
+	}, batches)
+	assert.Equal(t, uint64(5), sender.Sent())
+}
+
+func TestBatchSender_BatchesByInterval(t *testing.T) {
+	rs := &recordingSend{}
+	sender, err := NewBatchSender(BatchSenderConfig{
+		Send:     rs.send,
+		Interval: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	logger := New(Config{Level: InfoLevel, Output: sender})
+	logger.Info("lonely")
+
+	assert.Eventually(t, func() bool {
+		return sender.Sent() == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBatchSender_RetriesWithBackoff(t *testing.T) {
+	rs := &recordingSend{failures: 2, err: errors.New("503")}
+	sender, err := NewBatchSender(BatchSenderConfig{
+		Send:       rs.send,
+		MinBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, _ = sender.Write([]byte("a\nb\n"))
+	require.NoError(t, sender.Close())
+
+	batches, attempts := rs.snapshot()
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, [][]string{{"a", "b"}}, batches)
+	assert.Zero(t, sender.Failed())
+}
+
+func TestBatchSender_PermanentErrorIsNotRetried(t *testing.T) {
+	var reported error
+	rs := &recordingSend{failures: 10, err: Permanent(errors.New("400 bad request"))}
+	sender, err := NewBatchSender(BatchSenderConfig{
+		Send:       rs.send,
+		MinBackoff: time.Millisecond,
+		OnError: func(err error, entries int) {
+			reported = err
+		},
+	})
+	require.NoError(t, err)
+
+	_, _ = sender.Write([]byte("a\n"))
+	require.NoError(t, sender.Close())
+
+	_, attempts := rs.snapshot()
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, uint64(1), sender.Failed())
+	assert.True(t, IsPermanent(reported))
+	assert.EqualError(t, reported, "400 bad request")
+}
+
+func TestBatchSender_GivesUpAfterMaxRetries(t *testing.T) {
+	rs := &recordingSend{failures: 10, err: errors.New("timeout")}
+	sender, err := NewBatchSender(BatchSenderConfig{
+		Send:       rs.send,
+		MaxRetries: 2,
+		MinBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, _ = sender.Write([]byte("a\n"))
+	require.NoError(t, sender.Close())
+
+	_, attempts := rs.snapshot()
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, uint64(1), sender.Failed())
+}
+
+func TestBatchSender_BoundedMemory(t *testing.T) {
+	block := make(chan struct{})
+	sender, err := NewBatchSender(BatchSenderConfig{
+		Send: func(ctx context.Context, batch [][]byte) error {
+			<-block
+			return nil
+		},
+		MaxPendingBytes: 10,
+	})
+	require.NoError(t, err)
+
+	_, _ = sender.Write([]byte("12345678\n"))
+	_, _ = sender.Write([]byte("x\ny\n"))
+	assert.Equal(t, uint64(2), sender.Dropped())
+
+	close(block)
+	require.NoError(t, sender.Close())
+	assert.Equal(t, uint64(1), sender.Sent())
+
+	_, _ = sender.Write([]byte("late\n"))
+	assert.Equal(t, uint64(3), sender.Dropped())
+}
+
+func TestBatchSender_CloseTimeoutAbandonsPending(t *testing.T) {
+	sender, err := NewBatchSender(BatchSenderConfig{
+		Send: func(ctx context.Context, batch [][]byte) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		ShutdownTimeout: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, _ = sender.Write([]byte("a\n"))
+	_, _ = sender.Write([]byte("b\n"))
+
+	start := time.Now()
+	require.NoError(t, sender.Close())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, uint64(2), sender.Failed())
+}
+
+func TestNewBatchSender_RequiresSend(t *testing.T) {
+	_, err := NewBatchSender(BatchSenderConfig{})
+	assert.Error(t, err)
+}
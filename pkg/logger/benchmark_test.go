@@ -1,9 +1,12 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"testing"
+	"time"
 )
 
 var discardWriter = io.Discard
@@ -80,6 +83,73 @@ func BenchmarkLogger_JSONWithFields(b *testing.B) {
 	}
 }
 
+func BenchmarkLogger_JSONUnixMilli(b *testing.B) {
+	logger := New(Config{
+		Level:           InfoLevel,
+		Format:          JSONFormat,
+		Output:          discardWriter,
+		TimestampFormat: TimestampUnixMilli,
+	})
+
+	fields := []Field{
+		{Key: "user_id", Value: 12345},
+		{Key: "action", Value: "login"},
+		{Key: "success", Value: true},
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("user action", fields...)
+	}
+}
+
+func BenchmarkLogger_JSONTimestampCache(b *testing.B) {
+	logger := New(Config{
+		Level:          InfoLevel,
+		Format:         JSONFormat,
+		Output:         discardWriter,
+		TimestampCache: time.Millisecond,
+	})
+	defer logger.Close()
+
+	fields := []Field{
+		{Key: "user_id", Value: 12345},
+		{Key: "action", Value: "login"},
+		{Key: "success", Value: true},
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("user action", fields...)
+	}
+}
+
+func BenchmarkLogger_TextTemplate(b *testing.B) {
+	logger := New(Config{
+		Level:        InfoLevel,
+		Format:       TextFormat,
+		Output:       discardWriter,
+		TextTemplate: "[{ts}] {level} {service} {msg} {fields}",
+	})
+
+	fields := []Field{
+		{Key: "service", Value: "auth"},
+		{Key: "user_id", Value: 12345},
+		{Key: "success", Value: true},
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("user action", fields...)
+	}
+}
+
 func BenchmarkLogger_ManyFields(b *testing.B) {
 	logger := New(Config{
 		Level:  InfoLevel,
@@ -125,6 +195,25 @@ func BenchmarkLogger_WithContext(b *testing.B) {
 	}
 }
 
+func BenchmarkLogger_WithHook(b *testing.B) {
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: discardWriter,
+	})
+	logger.AddHook(HookFunc(func(r *Record) error {
+		r.Fields = append(r.Fields, Field{Key: "region", Value: "eu-west-1"})
+		return nil
+	}))
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("hooked message", Field{Key: "status", Value: 200})
+	}
+}
+
 func BenchmarkLogger_Buffered(b *testing.B) {
 	logger := New(Config{
 		Level:      InfoLevel,
@@ -141,6 +230,42 @@ func BenchmarkLogger_Buffered(b *testing.B) {
 	}
 }
 
+func BenchmarkLogger_MultiSink(b *testing.B) {
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: discardWriter,
+		Outputs: []SinkConfig{
+			{Output: discardWriter, Level: DebugLevel, Format: JSONFormat},
+			{Output: discardWriter, Level: InfoLevel, Format: JSONFormat},
+		},
+	})
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("fan out", Field{Key: "status", Value: 200})
+	}
+}
+
+func BenchmarkLogger_Async(b *testing.B) {
+	logger := New(Config{
+		Level:          InfoLevel,
+		Format:         JSONFormat,
+		Output:         discardWriter,
+		AsyncQueueSize: 1024,
+	})
+	defer logger.Close()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("async message", Field{Key: "status", Value: 200})
+	}
+}
+
 func BenchmarkLogger_LevelFiltering(b *testing.B) {
 	logger := New(Config{
 		Level:  WarnLevel,
@@ -157,6 +282,24 @@ func BenchmarkLogger_LevelFiltering(b *testing.B) {
 	}
 }
 
+func BenchmarkLogger_LevelFilteringDeepChain(b *testing.B) {
+	logger := New(Config{
+		Level:  WarnLevel,
+		Format: JSONFormat,
+		Output: discardWriter,
+	})
+	for i := 0; i < 10; i++ {
+		logger = logger.Named("child").With(Field{Key: "depth", Value: i})
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("debug message that should be filtered")
+	}
+}
+
 func BenchmarkLogger_ConcurrentAccess(b *testing.B) {
 	logger := New(Config{
 		Level:  InfoLevel,
@@ -174,15 +317,26 @@ func BenchmarkLogger_ConcurrentAccess(b *testing.B) {
 	})
 }
 
-func BenchmarkAppendInt(b *testing.B) {
-	buf := make([]byte, 0, 64)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		buf = buf[:0]
-		buf = appendInt(buf, int64(i))
+func BenchmarkLogger_ConcurrentBuffered(b *testing.B) {
+	for _, shards := range []int{1, 8} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			logger := New(Config{
+				Level:        InfoLevel,
+				Format:       JSONFormat,
+				Output:       discardWriter,
+				BufferSize:   64 << 10,
+				BufferShards: shards,
+			})
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info("concurrent message", Field{Key: "worker", Value: "test"})
+				}
+			})
+		})
 	}
 }
 
@@ -194,9 +348,14 @@ func BenchmarkAppendJSON(b *testing.B) {
 	})
 
 	buf := make([]byte, 0, 256)
-	fields := []Field{
-		{Key: "key1", Value: "value1"},
-		{Key: "key2", Value: 42},
+	record := &Record{
+		Time:    time.Now(),
+		Level:   InfoLevel,
+		Message: "test message",
+		Fields: []Field{
+			{Key: "key1", Value: "value1"},
+			{Key: "key2", Value: 42},
+		},
 	}
 
 	b.ResetTimer()
@@ -204,7 +363,7 @@ func BenchmarkAppendJSON(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		buf = buf[:0]
-		buf = logger.appendJSON(buf, InfoLevel, "test message", fields...)
+		buf = logger.appendJSON(buf, &defaultJSONKeys, record)
 	}
 }
 
@@ -222,3 +381,233 @@ func BenchmarkFieldAppend(b *testing.B) {
 		buf = appendValue(buf, 3.14159)
 	}
 }
+
+func BenchmarkLogger_AnyPrimitive(b *testing.B) {
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: discardWriter,
+	})
+
+	fields := []Field{
+		Any("action", "login"),
+		Any("success", true),
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("user action", fields...)
+	}
+}
+
+func BenchmarkLogger_AnyReflected(b *testing.B) {
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: discardWriter,
+	})
+
+	fields := []Field{
+		Any("items", []struct {
+			SKU   string
+			Count int
+		}{{"a", 2}, {"b", 1}}),
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("order placed", fields...)
+	}
+}
+
+// BenchmarkLogger_PrimitiveAfterReflected logs primitive-only entries
+// through a logger that has been exercising the reflection path; it should
+// match the allocation profile of BenchmarkLogger_AnyPrimitive.
+func BenchmarkLogger_PrimitiveAfterReflected(b *testing.B) {
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: discardWriter,
+	})
+
+	primitive := []Field{
+		String("action", "login"),
+		Bool("success", true),
+	}
+	reflected := Any("meta", map[string]int{"retries": 1})
+	for i := 0; i < 1000; i++ {
+		logger.Info("order placed", reflected)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("user action", primitive...)
+	}
+}
+
+func BenchmarkLogger_Infof(b *testing.B) {
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: discardWriter,
+	})
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Infof("user %s logged in %d times", "ann", 3)
+	}
+}
+
+func BenchmarkLogger_Infow(b *testing.B) {
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: discardWriter,
+	})
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Infow("user action", "action", "login", "success", true)
+	}
+}
+
+func BenchmarkLogger_CheckDisabled(b *testing.B) {
+	logger := New(Config{Level: InfoLevel, Output: discardWriter})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if ce := logger.Check(DebugLevel, "dump"); ce != nil {
+			ce.Write(Int("i", i))
+		}
+	}
+}
+
+func BenchmarkLogger_CheckEnabled(b *testing.B) {
+	logger := New(Config{Level: InfoLevel, Output: discardWriter})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if ce := logger.Check(InfoLevel, "dump"); ce != nil {
+			ce.Write(Int("i", i))
+		}
+	}
+}
+
+func BenchmarkLogger_NamedDisabled(b *testing.B) {
+	logger := New(Config{Level: DebugLevel, Output: discardWriter})
+	logger.SetNamedLevel("", InfoLevel)
+	logger.SetNamedLevel("http", WarnLevel)
+	named := logger.Named("http").Named("client")
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		named.Info("dropped")
+	}
+}
+
+func BenchmarkNop(b *testing.B) {
+	logger := Nop()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("discarded")
+	}
+}
+
+func BenchmarkNop_Fields(b *testing.B) {
+	logger := Nop()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("discarded", String("user", "ada"), Int("attempt", i))
+	}
+}
+
+func BenchmarkLogger_DuplicateKeysLastWins(b *testing.B) {
+	logger := New(Config{
+		Level:         InfoLevel,
+		Format:        JSONFormat,
+		Output:        discardWriter,
+		DuplicateKeys: DuplicateKeysLastWins,
+	}).With(String("user", "ctx"), Int("attempt", 1))
+	fields := []Field{String("user", "call"), Int("attempt", 2), Bool("ok", true)}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("retry", fields...)
+	}
+}
+
+func BenchmarkLogger_Binary(b *testing.B) {
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: discardWriter,
+	})
+	payload := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, 16)
+	fields := []Field{Binary("frame", payload), Hex("sum", payload[:32]), ByteString("body", []byte(`{"ok":true}`))}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("frame received", fields...)
+	}
+}
+
+func BenchmarkRecordTemplate(b *testing.B) {
+	for name, format := range map[string]Format{"json": JSONFormat, "text": TextFormat} {
+		b.Run(name, func(b *testing.B) {
+			logger := New(Config{Level: InfoLevel, Format: format, Output: discardWriter})
+			served := logger.Template("request served", "method", "status", "latency_ms", "cached")
+			methods := []string{"GET", "POST", "PUT", "DELETE"}
+			statuses := []int{200, 201, 404, 500}
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				served.Log(InfoLevel, methods[i%len(methods)], statuses[i%len(statuses)], float64(i%1000)/8, i%2 == 0)
+			}
+		})
+	}
+}
+
+func BenchmarkLogger_Filtered(b *testing.B) {
+	logger := New(Config{
+		Level:   InfoLevel,
+		Format:  JSONFormat,
+		Output:  discardWriter,
+		Filters: []Filter{{Fields: map[string]string{"path": "/healthz"}}},
+	})
+
+	fields := []Field{
+		{Key: "path", Value: "/healthz"},
+		{Key: "status", Value: 200},
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("request served", fields...)
+	}
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"unicode/utf8"
+)
+
+// bytesEncoding selects how a bytesValue is rendered.
+type bytesEncoding uint8
+
+const (
+	bytesBase64 bytesEncoding = iota
+	bytesHex
+	bytesText
+)
+
+// bytesValue is a field value created by Binary, Hex or ByteString. The
+// bytes are encoded straight into the entry buffer when it is written.
+type bytesValue struct {
+	b   []byte
+	enc bytesEncoding
+}
+
+// Binary returns a field for binary data, such as a protocol frame,
+// encoded as standard base64 in every format.
+//
+// The slice is not copied, so it must not be modified until the entry was
+// written; with Config.AsyncQueueSize that is after the call returns.
+//
+// Example:
+//
+//	log.Debug("frame received", logger.Binary("frame", frame))
+func Binary(key string, value []byte) Field {
+	return Field{Key: key, Value: bytesValue{b: value, enc: bytesBase64}}
+}
+
+// Hex returns a field for binary data encoded as lowercase hex, the usual
+// form of checksums and digests. The slice is not copied, see Binary.
+//
+// Example:
+//
+//	log.Info("upload verified", logger.Hex("sha256", sum[:]))
+func Hex(key string, value []byte) Field {
+	return Field{Key: key, Value: bytesValue{b: value, enc: bytesHex}}
+}
+
+// ByteString returns a field for a UTF-8 payload held in a byte slice,
+// rendered like a String field without converting it to a string first.
+// Quotes, control characters and invalid UTF-8 are escaped. The slice is
+// not copied, see Binary.
+//
+// Example:
+//
+//	log.Warn("rejected request", logger.ByteString("body", body))
+func ByteString(key string, value []byte) Field {
+	return Field{Key: key, Value: bytesValue{b: value, enc: bytesText}}
+}
+
+// appendEncoded appends the base64 or hex encoding of v.
+func (v bytesValue) appendEncoded(buf []byte) []byte {
+	if v.enc == bytesHex {
+		return hex.AppendEncode(buf, v.b)
+	}
+	return base64.StdEncoding.AppendEncode(buf, v.b)
+}
+
+// appendJSON appends v as a JSON string.
+func (v bytesValue) appendJSON(buf []byte) []byte {
+	buf = append(buf, '"')
+	if v.enc == bytesText {
+		buf = appendJSONString(buf, v.b)
+	} else {
+		buf = v.appendEncoded(buf)
+	}
+	return append(buf, '"')
+}
+
+// appendText appends v for the text formats. A ByteString payload that
+// would not read back as a single value is quoted and escaped.
+func (v bytesValue) appendText(buf []byte) []byte {
+	if v.enc != bytesText {
+		return v.appendEncoded(buf)
+	}
+	if !bytesNeedEscaping(v.b) {
+		return append(buf, v.b...)
+	}
+	buf = append(buf, '"')
+	buf = appendJSONString(buf, v.b)
+	return append(buf, '"')
+}
+
+// bytesNeedEscaping reports whether a ByteString payload must be quoted in
+// text output.
+func bytesNeedEscaping(b []byte) bool {
+	ascii := true
+	for _, c := range b {
+		if c < 0x20 || c == ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return true
+		}
+		ascii = ascii && c < utf8.RuneSelf
+	}
+	return !ascii && !utf8.Valid(b)
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryFields(t *testing.T) {
+	fields := []Field{
+		Binary("frame", []byte{0, 1, 2, 0xff}),
+		Hex("sum", []byte{0xde, 0xad, 0xbe, 0xef}),
+		ByteString("plain", []byte("ok")),
+		ByteString("body", []byte("a \"b\"\n\xff")),
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONFormat, `{"level":"INFO","message":"m","frame":"AAEC/w==","sum":"deadbeef","plain":"ok","body":"a \"b\"\n\ufffd"}`},
+		{TextFormat, `INFO m frame=AAEC/w== sum=deadbeef plain=ok body="a \"b\"\n\ufffd"`},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		log := New(Config{Level: InfoLevel, Format: tt.format, Output: buf, TimestampFormat: TimestampDisabled})
+
+		log.Info("m", fields...)
+		assert.Equal(t, tt.want+"\n", buf.String())
+	}
+}
+
+func TestBytesNeedEscaping(t *testing.T) {
+	assert.False(t, bytesNeedEscaping([]byte("héllo")))
+	assert.True(t, bytesNeedEscaping([]byte("hé llo")))
+	assert.True(t, bytesNeedEscaping([]byte("h\xffllo")))
+	assert.True(t, bytesNeedEscaping([]byte("a=b")))
+}
+
+func TestByteString_Redaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:               InfoLevel,
+		Output:              buf,
+		TimestampFormat:     TimestampDisabled,
+		RedactValuePatterns: []*regexp.Regexp{regexp.MustCompile(`\d{4}-\d{4}`)},
+	})
+	body := []byte("card=1234-5678")
+
+	log.Info("m", ByteString("body", body), Hex("raw", []byte("1234-5678")))
+	assert.Equal(t, `INFO m body="card=`+RedactedValue+`" raw=313233342d35363738`+"\n", buf.String())
+	assert.Equal(t, "card=1234-5678", string(body), "the caller's slice is not modified")
+}
+
+func TestBinary_Replay(t *testing.T) {
+	recording := &bytes.Buffer{}
+	src := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}})
+	src.AddHook(NewRecorder(recording))
+	src.Info("m", Binary("frame", []byte{1, 2}), Hex("sum", []byte{0xab}), ByteString("body", []byte("x y")))
+
+	buf := &bytes.Buffer{}
+	_, err := Replay(recording, New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled}))
+	require.NoError(t, err)
+	assert.Equal(t, "INFO m frame=AQI= sum=ab body=\"x y\"\n", buf.String())
+}
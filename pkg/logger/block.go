@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// blockIndent is the indentation of the lines of a block field.
+const blockIndent = "    "
+
+// blockValue is a multi-line field value, see Block.
+type blockValue string
+
+// Block returns a field for multi-line text such as a stack trace, an SQL
+// statement or a JSON payload. TextFormat renders it below the entry as an
+// indented block, which is far easier to read than an escaped one-liner:
+//
+//	2024-01-20T15:04:05.000Z ERROR query failed table=orders
+//	  sql:
+//	    SELECT id, total
+//	    FROM orders
+//	    WHERE status = 'open'
+//
+// Entries with block fields span several lines, so use Block for output
+// read by people rather than line-oriented collectors. Outputs that frame
+// entries by newlines, such as BatchSender, WALWriter, IntegrityWriter and
+// StreamHandler, get the value as a quoted one-liner instead, as does a
+// {key} placeholder of a text template. Other formats encode the value as
+// a plain string.
+func Block(key, value string) Field {
+	return Field{Key: key, Value: blockValue(value)}
+}
+
+// appendBlocks appends the block fields of fields below the entry line.
+func appendBlocks(buf []byte, fields []Field) []byte {
+	for _, field := range fields {
+		if block, ok := field.Value.(blockValue); ok {
+			buf = appendBlock(buf, field.Key, block)
+		}
+	}
+	return buf
+}
+
+// appendBlock appends key and the indented lines of block on lines of
+// their own.
+func appendBlock(buf []byte, key string, block blockValue) []byte {
+	buf = append(buf, "\n  "...)
+	buf = append(buf, key...)
+	buf = append(buf, ':')
+
+	text := strings.TrimRight(strings.ReplaceAll(string(block), "\r\n", "\n"), "\n")
+	for line := range strings.SplitSeq(text, "\n") {
+		buf = append(buf, '\n')
+		if line != "" {
+			buf = append(buf, blockIndent...)
+			buf = append(buf, line...)
+		}
+	}
+	return buf
+}
+
+// lineOutput is implemented by outputs that frame entries by newlines,
+// such as BatchSender and WALWriter. Sinks writing to them keep every
+// entry on one line: block fields are encoded as quoted strings and
+// Config.PrettyJSON is not applied.
+type lineOutput interface {
+	lineOutput()
+}
+
+// isLineOutput reports whether w frames entries by newlines.
+func isLineOutput(w io.Writer) bool {
+	_, ok := w.(lineOutput)
+	return ok
+}
+
+// isBlock reports whether value was created by Block.
+func isBlock(value any) bool {
+	_, ok := value.(blockValue)
+	return ok
+}
+
+// appendQuotedBlock appends a block as an escaped one-line string.
+func appendQuotedBlock(buf []byte, block blockValue) []byte {
+	return strconv.AppendQuote(buf, string(block))
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const blockSQL = "SELECT id, total\r\nFROM orders\n\nWHERE status = 'open'\n"
+
+func TestBlock_Text(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	logger.Error("query failed", F("table", "orders"), Block("sql", blockSQL), Block("stack", "main.main()\n\tmain.go:12"))
+
+	line, blocks, ok := strings.Cut(buf.String(), "\n")
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(line, "ERROR query failed table=orders"), line)
+	assert.Equal(t, ""+
+		"  sql:\n"+
+		"    SELECT id, total\n"+
+		"    FROM orders\n"+
+		"\n"+
+		"    WHERE status = 'open'\n"+
+		"  stack:\n"+
+		"    main.main()\n"+
+		"    \tmain.go:12\n", blocks)
+}
+
+func TestBlock_Template(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf, TextTemplate: "{level} {msg} {query} {fields}"})
+
+	logger.Info("slow", Block("query", "SELECT 1\nFROM t"), Block("plan", "Seq Scan"), F("ms", 120))
+	assert.Equal(t, "INFO slow \"SELECT 1\\nFROM t\" ms=120\n  plan:\n    Seq Scan\n", buf.String())
+}
+
+func TestBlock_OtherFormats(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	logger.Info("payload", Block("body", "{\n  \"a\": 1\n}"))
+	assert.Contains(t, buf.String(), `"body":"{\n  \"a\": 1\n}"}`)
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestBlock_LineOutputs(t *testing.T) {
+	buf, lines := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := New(Config{
+		Level:           InfoLevel,
+		TimestampFormat: TimestampDisabled,
+		Outputs: []SinkConfig{
+			{Output: buf, Format: TextFormat},
+			{Output: NewIntegrityWriter(lines), Format: TextFormat},
+			{Output: NewIntegrityWriter(lines), Format: ConsoleFormat},
+		},
+	})
+
+	logger.Info("slow", Block("query", "SELECT 1\nFROM t"))
+	assert.Equal(t, "INFO slow\n  query:\n    SELECT 1\n    FROM t\n", buf.String())
+	assert.Equal(t, 2, strings.Count(lines.String(), "\n"), "line outputs get one line per entry")
+	assert.Contains(t, lines.String(), "INFO slow query=\"SELECT 1\\nFROM t\"\n")
+}
+
+func TestBlock_Redacted(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{
+		Level:               InfoLevel,
+		Format:              TextFormat,
+		Output:              buf,
+		RedactValuePatterns: []*regexp.Regexp{regexp.MustCompile(`password='[^']*'`)},
+	})
+
+	logger.Info("query", Block("sql", "UPDATE users\nSET password='hunter2'"))
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Contains(t, buf.String(), "    SET "+RedactedValue)
+}
+
+func TestBlock_Replay(t *testing.T) {
+	recording := &bytes.Buffer{}
+	original := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: original})
+	logger.AddHook(NewRecorder(recording))
+	logger.Info("query", Block("sql", blockSQL))
+
+	replayed := &bytes.Buffer{}
+	_, err := Replay(recording, New(Config{Level: InfoLevel, Format: TextFormat, Output: replayed}))
+	require.NoError(t, err)
+	assert.Equal(t, original.String(), replayed.String())
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BreadcrumbKey is the key of the field marking the entries written from
+// the trail of Config.Breadcrumbs.
+const BreadcrumbKey = "breadcrumb"
+
+// BreadcrumbConfig keeps a trail of the last entries below Config.Level,
+// which are not written, in memory and writes it right before the next
+// ERROR, FATAL or PANIC entry. Errors then come with the debug entries that
+// led to them, without running at debug level all the time. Entries that
+// are written are not kept, so they are never written twice.
+//
+// Example:
+//
+//	log := logger.New(logger.Config{
+//		Level:       logger.InfoLevel,
+//		Breadcrumbs: &logger.BreadcrumbConfig{Size: 50, Level: logger.DebugLevel},
+//	})
+//
+// The trail is kept per logger, shared by the loggers derived from it,
+// unless the entry's context was prepared with BeginBreadcrumbs, e.g. per
+// request. Trail entries are written with their own time, level, message
+// and fields, plus a BreadcrumbKey field, to the sinks the error goes to,
+// and the trail starts over. Only entries logged through the methods of
+// Logger and ContextLogger are kept; Check and slog handlers only see
+// entries that are written.
+type BreadcrumbConfig struct {
+	// Size is the number of entries kept. Older entries are dropped.
+	Size int
+
+	// Level is the lowest level kept, e.g. DebugLevel.
+	Level Level
+}
+
+// breadcrumb is an entry of a trail.
+type breadcrumb struct {
+	time   time.Time
+	level  Level
+	msg    string
+	fields []Field
+}
+
+// breadcrumbRing is a trail of entries. The slots and their field slices
+// are reused, so keeping an entry does not allocate once the ring is full.
+type breadcrumbRing struct {
+	mu      sync.Mutex
+	entries []breadcrumb
+	next    int
+	n       int
+}
+
+// keep stores an entry in the ring of size entries, overwriting the
+// oldest one when it is full.
+func (r *breadcrumbRing) keep(size int, t time.Time, level Level, msg string, context, fields []Field) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.entries == nil {
+		r.entries = make([]breadcrumb, size)
+	}
+	e := &r.entries[r.next]
+	e.time, e.level, e.msg = t, level, msg
+	e.fields = append(append(e.fields[:0], context...), fields...)
+	r.next = (r.next + 1) % len(r.entries)
+	r.n = min(r.n+1, len(r.entries))
+}
+
+// take returns the entries, oldest first, and empties the ring. The
+// entries are written without the lock held, so they hand their field
+// slices over to the caller.
+func (r *breadcrumbRing) take() []breadcrumb {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.n == 0 {
+		return nil
+	}
+	trail := make([]breadcrumb, 0, r.n)
+	for i := range r.n {
+		e := &r.entries[(r.next-r.n+i+len(r.entries))%len(r.entries)]
+		trail = append(trail, *e)
+		*e = breadcrumb{}
+	}
+	r.n = 0
+	return trail
+}
+
+// breadcrumbContextKey is the context key of the ring of BeginBreadcrumbs.
+type breadcrumbContextKey struct{}
+
+// BeginBreadcrumbs returns a copy of ctx with its own trail, e.g. at the
+// start of a request, so an error only comes with the entries of the same
+// request. It has no effect unless Config.Breadcrumbs is set.
+func BeginBreadcrumbs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, breadcrumbContextKey{}, &breadcrumbRing{})
+}
+
+// breadcrumbTrail returns the trail for entries of ctx.
+func (l *Logger) breadcrumbTrail(ctx context.Context) *breadcrumbRing {
+	if ctx != nil {
+		if r, ok := ctx.Value(breadcrumbContextKey{}).(*breadcrumbRing); ok {
+			return r
+		}
+	}
+	return l.breadcrumbs
+}
+
+// keepDisabled keeps an entry below the level of l in the trail of ctx,
+// when it passes the filters. fields must already be nested in the groups
+// of l.
+func (l *Logger) keepDisabled(ctx context.Context, level Level, msg string, fields []Field) {
+	if level < l.config.Breadcrumbs.Level || level >= ErrorLevel ||
+		(l.filters != nil && !l.filters.allow(l.name, msg, l.context, fields)) {
+		return
+	}
+	l.breadcrumbTrail(ctx).keep(l.config.Breadcrumbs.Size, l.now(), level, msg, l.context, fields)
+}
+
+// writeBreadcrumbs writes the trail of ctx before an entry at trigger, to
+// the sinks accepting trigger.
+func (l *Logger) writeBreadcrumbs(ctx context.Context, trigger Level) {
+	for _, e := range l.breadcrumbTrail(ctx).take() {
+		r := l.records.Get().(*Record)
+		r.Time = e.time
+		r.Level = e.level
+		r.Message = e.msg
+		r.fields = append(append(r.fields[:0], e.fields...), Field{Key: BreadcrumbKey, Value: true})
+		r.Fields = r.fields
+		r.breadcrumb = true
+		r.trigger = trigger
+		l.resolveValuers(r)
+		l.process(r)
+		l.putRecord(r)
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Breadcrumbs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		Breadcrumbs:     &BreadcrumbConfig{Size: 3, Level: DebugLevel},
+	})
+
+	log.Debug("connecting", String("host", "db"))
+	log.Info("connected")
+	log.Error("query failed")
+	assert.Equal(t, "INFO connected\n"+
+		"DEBUG connecting host=db breadcrumb=true\n"+
+		"ERROR query failed\n", buf.String(), "written entries are not kept")
+
+	// The trail starts over after an error and keeps the last entries.
+	buf.Reset()
+	log.Error("query failed")
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		log.Debug(msg)
+	}
+	log.Warn("slow")
+	log.Error("query failed")
+	assert.Equal(t, "ERROR query failed\n"+
+		"WARN slow\n"+
+		"DEBUG b breadcrumb=true\n"+
+		"DEBUG c breadcrumb=true\n"+
+		"DEBUG d breadcrumb=true\n"+
+		"ERROR query failed\n", buf.String())
+}
+
+func TestConfig_BreadcrumbsSinks(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errs := &bytes.Buffer{}
+	exited := 0
+	log := New(Config{
+		Level:           WarnLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		Breadcrumbs:     &BreadcrumbConfig{Size: 10, Level: InfoLevel},
+		Filters:         []Filter{{Message: "healthz"}},
+		ExitFunc:        func(int) { exited++ },
+	})
+	log.AddSink(SinkConfig{Output: errs, Level: FatalLevel, Format: TextFormat})
+
+	req := log.With(String("id", "7")).WithGroup("req")
+	req.Debug("parsed")
+	req.Info("routed", String("path", "/users"))
+	req.Info("healthz")
+	log.Error("failed")
+	assert.Equal(t, "INFO routed id=7 req.path=/users breadcrumb=true\nERROR failed\n", buf.String())
+	assert.Empty(t, errs.String())
+
+	// Breadcrumbs go to the sinks accepting the entry that writes them.
+	log.Info("restarting")
+	log.Fatal("crashed")
+	assert.Equal(t, 1, exited)
+	assert.Contains(t, errs.String(), "INFO restarting breadcrumb=true\n")
+	assert.Contains(t, errs.String(), "FATAL crashed\n")
+}
+
+func TestBeginBreadcrumbs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		Breadcrumbs:     &BreadcrumbConfig{Size: 10, Level: DebugLevel},
+	})
+
+	a := log.WithStaticContext(BeginBreadcrumbs(context.Background()))
+	b := log.WithStaticContext(BeginBreadcrumbs(context.Background()))
+	a.Debug("a started")
+	b.Debug("b started")
+	log.Debug("shared")
+	a.Error("a failed")
+	assert.Equal(t, "DEBUG a started breadcrumb=true\nERROR a failed\n", buf.String())
+
+	buf.Reset()
+	log.Error("failed")
+	assert.Equal(t, "DEBUG shared breadcrumb=true\nERROR failed\n", buf.String())
+}
+
+func TestConfig_BreadcrumbsValidation(t *testing.T) {
+	_, err := NewE(Config{Breadcrumbs: &BreadcrumbConfig{}})
+	assert.ErrorContains(t, err, "Breadcrumbs.Size must be positive, got 0")
+
+	_, err = NewE(Config{Breadcrumbs: &BreadcrumbConfig{Size: 1, Level: 3}})
+	assert.ErrorContains(t, err, "unknown Breadcrumbs.Level 3")
+}
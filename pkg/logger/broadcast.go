@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// streamEntry is an encoded entry delivered to live subscribers.
+type streamEntry struct {
+	level  Level
+	fields []Field
+	data   []byte // without the trailing newline
+}
+
+// subscriber receives entries from a broadcaster through a bounded
+// channel. Entries that do not fit are dropped for this subscriber only.
+type subscriber struct {
+	ch      chan streamEntry
+	dropped atomic.Uint64
+}
+
+// broadcaster fans entries out to live subscribers and keeps a short
+// history for subscribers that connect later. A slow subscriber never
+// blocks logging or other subscribers.
+type broadcaster struct {
+	mu      sync.Mutex
+	subs    map[*subscriber]struct{}
+	history []streamEntry
+	next    int
+	full    bool
+}
+
+func newBroadcaster(history int) *broadcaster {
+	return &broadcaster{
+		subs:    make(map[*subscriber]struct{}),
+		history: make([]streamEntry, history),
+	}
+}
+
+// publish copies fields and entry and delivers them to every subscriber.
+func (b *broadcaster) publish(level Level, fields []Field, entry []byte) {
+	for len(entry) > 0 && entry[len(entry)-1] == '\n' {
+		entry = entry[:len(entry)-1]
+	}
+	e := streamEntry{
+		level:  level,
+		fields: append([]Field(nil), fields...),
+		data:   append([]byte(nil), entry...),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.history) > 0 {
+		b.history[b.next] = e
+		b.next = (b.next + 1) % len(b.history)
+		b.full = b.full || b.next == 0
+	}
+
+	for s := range b.subs {
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// subscribe registers a subscriber with a buffer of size entries and
+// returns it together with the current history, oldest first.
+func (b *broadcaster) subscribe(size int) (*subscriber, []streamEntry) {
+	s := &subscriber{ch: make(chan streamEntry, size)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var history []streamEntry
+	if b.full {
+		history = append(history, b.history[b.next:]...)
+	}
+	history = append(history, b.history[:b.next]...)
+
+	b.subs[s] = struct{}{}
+	return s, history
+}
+
+// unsubscribe removes s. Its channel is left open for the caller to drain.
+func (b *broadcaster) unsubscribe(s *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subs, s)
+}
+
+// subscribers returns the number of connected subscribers.
+func (b *broadcaster) subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subs)
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcaster_HistoryOldestFirst(t *testing.T) {
+	b := newBroadcaster(3)
+	for _, msg := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		b.publish(InfoLevel, nil, []byte(msg))
+	}
+
+	s, history := b.subscribe(1)
+	defer b.unsubscribe(s)
+
+	require.Len(t, history, 3)
+	assert.Equal(t, "b", string(history[0].data))
+	assert.Equal(t, "c", string(history[1].data))
+	assert.Equal(t, "d", string(history[2].data))
+}
+
+func TestBroadcaster_DropsForSlowSubscriber(t *testing.T) {
+	b := newBroadcaster(0)
+	slow, _ := b.subscribe(1)
+	fast, _ := b.subscribe(4)
+
+	entry := []byte("entry\n")
+	b.publish(WarnLevel, nil, entry)
+	b.publish(WarnLevel, nil, entry)
+	entry[0] = 'X'
+
+	assert.Equal(t, uint64(1), slow.dropped.Load())
+	assert.Equal(t, uint64(0), fast.dropped.Load())
+	require.Len(t, fast.ch, 2)
+
+	e := <-fast.ch
+	assert.Equal(t, WarnLevel, e.level)
+	assert.Equal(t, "entry", string(e.data), "published entries are copied")
+}
+
+func TestBroadcaster_Unsubscribe(t *testing.T) {
+	b := newBroadcaster(0)
+	s, _ := b.subscribe(1)
+	require.Equal(t, 1, b.subscribers())
+
+	b.unsubscribe(s)
+	b.publish(InfoLevel, nil, []byte("ignored"))
+
+	assert.Equal(t, 0, b.subscribers())
+	assert.Empty(t, s.ch)
+}
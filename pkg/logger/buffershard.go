@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// shardPadding keeps the locks of neighbouring shards on separate cache
+// lines, so writers on different CPUs do not contend on the same line.
+const shardPadding = 64
+
+// bufferShard is one of the buffers of a sink with SinkConfig.BufferShards
+// set. Writers append to the shard of their P under the shard lock only;
+// flushing merges all shards under the sink lock.
+type bufferShard struct {
+	mu         sync.Mutex
+	buffer     []byte
+	entries    []shardEntry
+	traceStart time.Time
+
+	// maxBytes and maxEvents are the share of the sink BatchLimits of
+	// this shard, so the merged shards fit into a single Write. maxAge is
+	// the MaxAge of the sink. They are set by configure with mu held.
+	maxBytes  int
+	maxEvents int
+	maxAge    time.Duration
+
+	_ [shardPadding]byte
+}
+
+// shardEntry is an entry in a shard buffer: its sequence number among the
+// entries of all shards, and its end in the buffer.
+type shardEntry struct {
+	seq uint64
+	end int
+}
+
+// newBufferShards creates n shards and the timer flushing them once their oldest
+// entry reached MaxAge. The timer is created stopped; it never changes, so
+// writers can arm it without holding s.mu.
+func (s *sink) newBufferShards(n int) {
+	s.shards = make([]bufferShard, n)
+	s.mergePos = make([]int, n)
+	s.shardHints.New = func() any {
+		i := int(s.nextShard.Add(1)-1) % len(s.shards)
+		return &i
+	}
+	s.batchTimer = time.AfterFunc(time.Hour, s.flushAged)
+	s.batchTimer.Stop()
+}
+
+// configureBufferShards splits the batch limits of the sink between the shards.
+// It must be called with s.mu held and the shards flushed.
+func (s *sink) configureBufferShards() {
+	n := len(s.shards)
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		sh.maxBytes = 0
+		if s.batch.MaxBytes > 0 {
+			sh.maxBytes = max(s.batch.MaxBytes/n, 1)
+		}
+		sh.maxEvents = 0
+		if s.batch.MaxEvents > 0 {
+			sh.maxEvents = max(s.batch.MaxEvents/n, 1)
+		}
+		sh.maxAge = s.batch.MaxAge
+		if sh.maxBytes > 0 && cap(sh.buffer) < sh.maxBytes {
+			s.allocBuffer(&sh.buffer, sh.maxBytes)
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// writeBufferShard buffers entry in the shard of the calling goroutine's
+// P. It reports false when the sink stopped batching or the buffer budget
+// leaves no room, in which case the caller writes entry through the sink
+// buffer. The sequence number is taken under the shard lock, so the
+// entries of a shard are in sequence order, and an entry logged after
+// another, by the same goroutine or not, gets a higher one.
+func (s *sink) writeBufferShard(r *Record, entry []byte) bool {
+	hint := s.shardHints.Get().(*int)
+	defer s.shardHints.Put(hint)
+	return s.writeShard(&s.shards[*hint], r, entry)
+}
+
+// writeShard buffers entry in sh, like writeBufferShard.
+func (s *sink) writeShard(sh *bufferShard, r *Record, entry []byte) bool {
+	sh.mu.Lock()
+	for len(sh.entries) > 0 && sh.maxBytes > 0 && len(sh.buffer)+len(entry) > sh.maxBytes {
+		sh.mu.Unlock()
+		s.Flush()
+		sh.mu.Lock()
+	}
+	// batching is checked under the shard lock: configure clears it before
+	// merging the shards, so an entry is either merged or written directly.
+	if !s.batching.Load() {
+		sh.mu.Unlock()
+		return false
+	}
+	if !s.growBuffer(&sh.buffer, len(entry)) {
+		// The buffer budget leaves no room: write the buffered entries
+		// early, and leave the entry to the caller if it still does not
+		// fit.
+		sh.mu.Unlock()
+		s.Flush()
+		sh.mu.Lock()
+		if !s.batching.Load() || !s.growBuffer(&sh.buffer, len(entry)) {
+			sh.mu.Unlock()
+			return false
+		}
+	}
+
+	sh.buffer = append(sh.buffer, entry...)
+	sh.entries = append(sh.entries, shardEntry{seq: s.shardSeq.Add(1), end: len(sh.buffer)})
+	if r != nil && !r.traced.IsZero() && sh.traceStart.IsZero() {
+		sh.traceStart = r.traced
+	}
+	if len(sh.entries) == 1 && sh.maxAge > 0 && s.timerArmed.CompareAndSwap(false, true) {
+		s.batchTimer.Reset(sh.maxAge)
+	}
+	full := sh.maxEvents > 0 && len(sh.entries) >= sh.maxEvents
+	sh.mu.Unlock()
+
+	if full {
+		s.Flush()
+	}
+	return true
+}
+
+// mergeBufferShards moves the entries of all shards into s.buffer in
+// sequence order, so entries keep the order they were logged in, writing
+// the buffer whenever the next entry would exceed the batch limits. It must
+// be called with s.mu held.
+func (s *sink) mergeBufferShards() {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		if !sh.traceStart.IsZero() && (s.traceStart.IsZero() || sh.traceStart.Before(s.traceStart)) {
+			s.traceStart = sh.traceStart
+		}
+		s.mergePos[i] = 0
+	}
+
+	for {
+		next := -1
+		for i := range s.shards {
+			if s.mergePos[i] < len(s.shards[i].entries) &&
+				(next < 0 || s.shards[i].entries[s.mergePos[i]].seq < s.shards[next].entries[s.mergePos[next]].seq) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+
+		sh, pos := &s.shards[next], s.mergePos[next]
+		start := 0
+		if pos > 0 {
+			start = sh.entries[pos-1].end
+		}
+		entry := sh.buffer[start:sh.entries[pos].end]
+		s.mergePos[next]++
+
+		if s.batchCount > 0 && s.exceedsBatch(len(entry), 1) {
+			s.writeBuffer()
+		}
+		if !s.growBuffer(&s.buffer, len(entry)) {
+			s.writeBuffer()
+			if !s.growBuffer(&s.buffer, len(entry)) {
+				s.writeOutput(entry)
+				continue
+			}
+		}
+		s.buffer = append(s.buffer, entry...)
+		s.batchCount++
+	}
+
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.buffer = sh.buffer[:0]
+		sh.entries = sh.entries[:0]
+		sh.traceStart = time.Time{}
+		sh.mu.Unlock()
+	}
+}
+
+// exceedsBatch reports whether adding n bytes in events entries to the
+// buffer would exceed the batch limits.
+func (s *sink) exceedsBatch(n, events int) bool {
+	return (s.batch.MaxBytes > 0 && len(s.buffer)+n > s.batch.MaxBytes) ||
+		(s.batch.MaxEvents > 0 && s.batchCount+events > s.batch.MaxEvents)
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_BufferShards(t *testing.T) {
+	w := &limitedWriter{limits: BatchLimits{MaxEvents: 40}}
+	logger := New(Config{
+		Level:           InfoLevel,
+		Output:          w,
+		TimestampFormat: TimestampDisabled,
+		BufferSize:      2048,
+		BufferShards:    4,
+	})
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				logger.Info("entry", Int("g", g), Int("i", i))
+			}
+		}()
+	}
+	wg.Wait()
+	logger.Flush()
+
+	var lines []string
+	for _, batch := range w.Writes() {
+		assert.LessOrEqual(t, len(batch), 2048)
+		assert.LessOrEqual(t, strings.Count(batch, "\n"), 40)
+		require.True(t, strings.HasSuffix(batch, "\n"), "entries must not be split")
+		lines = append(lines, strings.Split(strings.TrimSuffix(batch, "\n"), "\n")...)
+	}
+	require.Len(t, lines, 800)
+
+	// The entries of every goroutine are written in order.
+	next := make(map[int]int)
+	for _, line := range lines {
+		var g, i int
+		_, err := fmt.Sscanf(line, "INFO entry g=%d i=%d", &g, &i)
+		require.NoError(t, err)
+		assert.Equal(t, next[g], i)
+		next[g] = i + 1
+	}
+
+	// Every entry is written exactly once.
+	sort.Strings(lines)
+	var want []string
+	for g := range 8 {
+		for i := range 100 {
+			want = append(want, fmt.Sprintf("INFO entry g=%d i=%d", g, i))
+		}
+	}
+	sort.Strings(want)
+	assert.Equal(t, want, lines)
+}
+
+func TestConfig_BufferShardsMaxAge(t *testing.T) {
+	w := &limitedWriter{limits: BatchLimits{MaxAge: 10 * time.Millisecond}}
+	logger := New(Config{Level: InfoLevel, Output: w, TimestampFormat: TimestampDisabled, BufferShards: 2})
+
+	logger.Info("aged")
+	assert.Empty(t, w.Writes())
+	assert.Eventually(t, func() bool { return len(w.Writes()) == 1 }, time.Second, time.Millisecond)
+
+	logger.Info("again")
+	assert.Eventually(t, func() bool { return len(w.Writes()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"INFO aged\n", "INFO again\n"}, w.Writes())
+}
+
+func TestSink_BufferShardsStopBatching(t *testing.T) {
+	w := &limitedWriter{}
+	logger := New(Config{Level: InfoLevel, Output: w, TimestampFormat: TimestampDisabled, BufferSize: 4096, BufferShards: 4})
+
+	logger.Info("buffered")
+	assert.Empty(t, w.Writes())
+
+	// Turning buffering off, as SetProfile does, writes the shards and
+	// every later entry directly.
+	(*logger.sinks.Load())[0].configure(0, 0, false)
+	assert.Equal(t, []string{"INFO buffered\n"}, w.Writes())
+	logger.Info("direct")
+	assert.Equal(t, []string{"INFO buffered\n", "INFO direct\n"}, w.Writes())
+}
+
+func TestSink_BufferShardsKeepOrder(t *testing.T) {
+	w := &limitedWriter{}
+	logger := New(Config{Level: InfoLevel, Output: w, TimestampFormat: TimestampDisabled, BufferSize: 4096, BufferShards: 2})
+	s := (*logger.sinks.Load())[0]
+
+	// A goroutine moving between CPUs appends to different shards; the
+	// entries are still written in the order they were logged.
+	for i, shard := range []int{1, 0, 1, 1, 0} {
+		require.True(t, s.writeShard(&s.shards[shard], nil, fmt.Appendf(nil, "entry %d\n", i)))
+	}
+	logger.Flush()
+	assert.Equal(t, []string{"entry 0\nentry 1\nentry 2\nentry 3\nentry 4\n"}, w.Writes())
+}
+
+func TestConfig_BufferShardsValidation(t *testing.T) {
+	_, err := NewE(Config{BufferShards: -1, Outputs: []SinkConfig{{Output: &limitedWriter{}, BufferShards: -2}}})
+	assert.ErrorContains(t, err, "negative BufferShards -1")
+	assert.ErrorContains(t, err, "Outputs[0]: negative BufferShards -2")
+}
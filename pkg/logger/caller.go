@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CallerKey is the key of the field added by Config.AddCaller.
+const CallerKey = "caller"
+
+// StacktraceKey is the key of the field added by Config.AddStacktrace.
+const StacktraceKey = "stacktrace"
+
+// maxCallerDepth bounds the frames inspected to find the caller.
+const maxCallerDepth = 32
+
+// maxStacktraceDepth bounds the frames of a stack trace.
+const maxStacktraceDepth = 64
+
+// callerSkipPrefixes lists the function name prefixes of the packages
+// between application code and logAt.
+var callerSkipPrefixes = func() []string {
+	pkg := reflect.TypeOf(Logger{}).PkgPath()
+	return []string{pkg + ".", path.Dir(pkg) + "/logcompat.", "log/slog.", "log."}
+}()
+
+// appendCaller appends a CallerKey field locating the first frame outside
+// the logging packages, without modifying the backing array of fields.
+func appendCaller(fields []Field) []Field {
+	var pcs [maxCallerDepth]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isLoggingFrame(frame) {
+			return append(fields[:len(fields):len(fields)], Field{Key: CallerKey, Value: shortCaller(frame)})
+		}
+		if !more {
+			return fields
+		}
+	}
+}
+
+// appendStacktrace appends a StacktraceKey block holding the frames from
+// the first frame outside the logging packages down, rendered like
+// runtime/debug.Stack, without modifying the backing array of fields.
+func appendStacktrace(fields []Field) []Field {
+	var pcs [maxStacktraceDepth]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	skipping := true
+	for {
+		frame, more := frames.Next()
+		if skipping && isLoggingFrame(frame) {
+			if !more {
+				return fields
+			}
+			continue
+		}
+		skipping = false
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		b.WriteByte('\n')
+		if !more {
+			break
+		}
+	}
+	return append(fields[:len(fields):len(fields)], Block(StacktraceKey, b.String()))
+}
+
+// isLoggingFrame reports whether frame belongs to a logging package. Tests
+// of this package count as application code.
+func isLoggingFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	for _, prefix := range callerSkipPrefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortCaller renders frame as the last directory, file name and line,
+// e.g. "billing/charge.go:42".
+func shortCaller(frame runtime.Frame) string {
+	file := frame.File
+	if i := strings.LastIndexByte(file, '/'); i > 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return file + ":" + strconv.Itoa(frame.Line)
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_AddCaller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: DebugLevel, Output: buf, AddCaller: true})
+
+	fields := make([]Field, 1, 2)
+	fields[0] = String("k", "v")
+	line := callerLine() + 1
+	log.Info("direct", fields...)
+	assert.Contains(t, buf.String(), "caller=logger/caller_test.go:"+strconv.Itoa(line))
+	assert.Len(t, fields, 1)
+	assert.Equal(t, "", fields[:2][1].Key, "the caller's slice is not modified")
+
+	buf.Reset()
+	line = callerLine() + 1
+	log.Infof("sugared %d", 1)
+	assert.Contains(t, buf.String(), "caller=logger/caller_test.go:"+strconv.Itoa(line))
+
+	buf.Reset()
+	line = callerLine() + 1
+	slog.New(NewSlogHandler(log)).Info("via slog")
+	assert.Contains(t, buf.String(), "caller=logger/caller_test.go:"+strconv.Itoa(line))
+
+	buf.Reset()
+	New(Config{Output: buf}).Info("off")
+	assert.NotContains(t, buf.String(), CallerKey)
+}
+
+// callerLine returns the line it is called from.
+func callerLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
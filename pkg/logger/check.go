@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CheckedEntry is an entry that passed the level check of Check and waits
+// for its fields. It is returned to a pool by Write and must not be used
+// afterwards.
+type CheckedEntry struct {
+	l     *Logger
+	time  time.Time
+	level Level
+	msg   string
+}
+
+var checkedEntries = sync.Pool{
+	New: func() any { return new(CheckedEntry) },
+}
+
+// Enabled reports whether l writes entries at level to any of its sinks.
+// Entries of an enabled level may still be dropped by sampling or rate
+// limiting.
+func (l *Logger) Enabled(level Level) bool {
+	return l.enabled(level) && (l.tee == nil || l.teeEnabled(level))
+}
+
+// Check returns an entry at level with msg if l writes entries at level,
+// and nil otherwise, so that fields expensive to build are only built for
+// entries that are written:
+//
+//	if ce := log.Check(logger.DebugLevel, "request dump"); ce != nil {
+//		ce.Write(logger.String("body", dump(req)))
+//	}
+//
+// The entry's time is taken by Check. Write is safe on a nil entry.
+func (l *Logger) Check(level Level, msg string) *CheckedEntry {
+	if !l.Enabled(level) {
+		return nil
+	}
+	ce := checkedEntries.Get().(*CheckedEntry)
+	*ce = CheckedEntry{l: l, time: l.now(), level: level, msg: msg}
+	return ce
+}
+
+// Write logs the entry with fields, then returns it to the pool. Like
+// Fatal and Panic, an entry at FatalLevel exits the program and an entry
+// at PanicLevel panics with the message. Write on a nil entry does
+// nothing.
+func (ce *CheckedEntry) Write(fields ...Field) {
+	if ce == nil {
+		return
+	}
+	l, level, msg := ce.l, ce.level, ce.msg
+	l.logAt(context.Background(), ce.time, level, msg, fields)
+	*ce = CheckedEntry{}
+	checkedEntries.Put(ce)
+
+	switch level {
+	case FatalLevel:
+		l.exit()
+	case PanicLevel:
+		l.panic(msg)
+	}
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Check(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled})
+
+	assert.False(t, log.Enabled(DebugLevel))
+	assert.True(t, log.Enabled(InfoLevel))
+
+	built := false
+	if ce := log.Check(DebugLevel, "dump"); ce != nil {
+		built = true
+		ce.Write(String("body", "expensive"))
+	}
+	assert.False(t, built)
+	log.Check(DebugLevel, "dump").Write(String("body", "ignored"))
+	assert.Empty(t, buf.String())
+
+	ce := log.Check(WarnLevel, "slow")
+	require.NotNil(t, ce)
+	ce.Write(Int("ms", 900))
+	assert.Equal(t, "WARN slow ms=900\n", buf.String())
+}
+
+func TestLogger_CheckFatalAndPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exited := 0
+	log := New(Config{Level: InfoLevel, Output: buf, ExitFunc: func(int) { exited++ }})
+
+	log.Check(FatalLevel, "fatal").Write()
+	assert.Equal(t, 1, exited)
+	assert.PanicsWithValue(t, "panic", func() { log.Check(PanicLevel, "panic").Write() })
+	assert.Contains(t, buf.String(), "FATAL fatal")
+	assert.Contains(t, buf.String(), "PANIC panic")
+}
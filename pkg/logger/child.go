@@ -0,0 +1,57 @@
+package logger
+
+// LoggerKey is the field key under which entries of a named logger carry
+// the name given by Named.
+const LoggerKey = "logger"
+
+// With returns a logger that adds fields to every entry, before the fields
+// of the call. The returned logger shares everything else with l, including
+// its sinks, hooks and level; l is not modified.
+//
+// Example:
+//
+//	reqLog := log.With(logger.F("requestID", id), logger.F("userID", user))
+//	reqLog.Info("Order placed") // carries requestID and userID
+func (l *Logger) With(fields ...Field) *Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	if len(l.groups) > 0 {
+		return l.withGroupFields(fields)
+	}
+
+	context := make([]Field, 0, len(l.context)+len(fields))
+	context = append(context, l.context...)
+	context = append(context, fields...)
+
+	return &Logger{core: l.core, name: l.name, context: context, levelCache: l.levelCache}
+}
+
+// Named returns a logger whose entries carry name under LoggerKey. Names of
+// nested loggers are joined with dots, so log.Named("http").Named("client")
+// logs logger=http.client. The returned logger shares everything else with
+// l, like With.
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+
+	context := make([]Field, 0, len(l.context)+1)
+	context = append(context, Field{Key: LoggerKey, Value: name})
+	if l.name != "" {
+		// Replace the previous name, which always comes first.
+		context = append(context, l.context[1:]...)
+	} else {
+		context = append(context, l.context...)
+	}
+
+	return &Logger{core: l.core, name: name, context: context, groups: l.groups, levelCache: l.namedLevels.cache(name)}
+}
+
+// Name returns the name given by Named, or "" for an unnamed logger.
+func (l *Logger) Name() string {
+	return l.name
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_With(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	child := logger.With(F("requestID", "r-1")).With(F("userID", 42))
+	child.Info("order placed", F("amount", 10))
+	assert.Contains(t, buf.String(), `"requestID":"r-1","userID":42,"amount":10`)
+
+	buf.Reset()
+	logger.Info("parent")
+	assert.NotContains(t, buf.String(), "requestID", "the parent is not modified")
+
+	assert.Same(t, logger, logger.With())
+}
+
+func TestLogger_Named(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	client := logger.Named("http").With(F("host", "example.com")).Named("client")
+	assert.Equal(t, "http.client", client.Name())
+
+	client.Info("request sent")
+	assert.Contains(t, buf.String(), `"logger":"http.client","host":"example.com"`)
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte(`"logger"`)))
+	assert.Empty(t, logger.Name())
+}
+
+func TestLogger_ChildSharesCore(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: WarnLevel, Format: TextFormat, Output: buf})
+	child := logger.Named("worker").With(F("id", 1))
+
+	child.Debug("hidden")
+	assert.Empty(t, buf.String())
+
+	// A sink added to the parent lowers the level of every child.
+	debug := &bytes.Buffer{}
+	logger.AddSink(SinkConfig{Output: debug, Level: DebugLevel, Format: TextFormat})
+	child.Debug("visible")
+	assert.Contains(t, debug.String(), "DEBUG visible logger=worker id=1")
+
+	var seen []Field
+	logger.AddHook(HookFunc(func(r *Record) error {
+		seen = append(seen[:0], r.Fields...)
+		return nil
+	}))
+	child.Warn("hooked")
+	assert.Equal(t, []Field{{Key: LoggerKey, Value: "worker"}, {Key: "id", Value: 1}}, seen)
+}
+
+func TestLogger_WithAsync(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf, AsyncQueueSize: 16})
+
+	logger.With(F("id", 7)).WithStaticContext(context.Background()).Info("queued", F("n", 1))
+	require.NoError(t, logger.Close())
+	assert.Contains(t, buf.String(), "INFO queued id=7 n=1")
+}
+
+func TestLogger_WithSamplingKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{
+		Level:    InfoLevel,
+		Format:   TextFormat,
+		Output:   buf,
+		Sampling: &SamplingConfig{Keys: []string{"endpoint"}, First: 1},
+	})
+
+	logger.With(F("endpoint", "/a")).Info("slow")
+	logger.With(F("endpoint", "/b")).Info("slow")
+	logger.With(F("endpoint", "/a")).Info("slow")
+
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("slow")))
+	assert.Equal(t, uint64(1), logger.Stats().Sampled)
+}
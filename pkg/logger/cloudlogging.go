@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cloudFieldNames maps trace field keys to the special fields Cloud Logging
+// uses to correlate entries with Cloud Trace.
+var cloudFieldNames = map[string]string{
+	"traceID":  "logging.googleapis.com/trace",
+	"trace_id": "logging.googleapis.com/trace",
+	"spanID":   "logging.googleapis.com/spanId",
+	"span_id":  "logging.googleapis.com/spanId",
+}
+
+// appendCloudLogging formats a log entry as a structured Google Cloud
+// Logging payload. Trace IDs are expanded to
+// "projects/<projectID>/traces/<traceID>" when projectID is set, and
+// SourceLocation and HTTPRequest fields become the sourceLocation and
+// httpRequest of the entry.
+func appendCloudLogging(buf []byte, r *Record, projectID string) []byte {
+	buf = append(buf, `{"severity":"`...)
+	buf = append(buf, cloudSeverity(r.Level)...)
+	buf = append(buf, `","time":"`...)
+	buf = r.Time.UTC().AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, `","message":"`...)
+	buf = appendJSONString(buf, r.Message)
+	buf = append(buf, '"')
+
+	for _, field := range r.Fields {
+		switch v := field.Value.(type) {
+		case sourceLocation:
+			buf = append(buf, `,"logging.googleapis.com/sourceLocation":`...)
+			buf = v.appendJSON(buf)
+			continue
+		case httpRequest:
+			buf = append(buf, `,"httpRequest":`...)
+			buf = v.appendJSON(buf)
+			continue
+		}
+
+		name, ok := cloudFieldNames[field.Key]
+		if !ok {
+			buf = append(buf, ',', '"')
+			buf = appendJSONString(buf, field.Key)
+			buf = append(buf, '"', ':')
+			buf = appendJSONValue(buf, field.Value)
+			continue
+		}
+
+		buf = append(buf, `,"`+name+`":`...)
+		id, isString := field.Value.(string)
+		if !isString || projectID == "" || !strings.HasSuffix(name, "/trace") ||
+			strings.HasPrefix(id, "projects/") {
+			buf = appendJSONValue(buf, field.Value)
+			continue
+		}
+		buf = append(buf, `"projects/`...)
+		buf = appendJSONString(buf, projectID)
+		buf = append(buf, "/traces/"...)
+		buf = appendJSONString(buf, id)
+		buf = append(buf, '"')
+	}
+
+	return append(buf, '}')
+}
+
+// cloudSeverity returns the Cloud Logging LogSeverity name of level, using
+// the same mapping as syslogSeverity.
+func cloudSeverity(level Level) string {
+	switch {
+	case level < InfoLevel:
+		return "DEBUG"
+	case level < WarnLevel:
+		return "INFO"
+	case level < ErrorLevel:
+		return "WARNING"
+	case level < FatalLevel:
+		return "ERROR"
+	case level < PanicLevel:
+		return "CRITICAL"
+	default:
+		return "ALERT"
+	}
+}
+
+// sourceLocation is the value of a SourceLocation field.
+type sourceLocation struct {
+	file     string
+	line     int
+	function string
+}
+
+// SourceLocation returns a field holding the file, line and function of
+// the caller, skip frames above the function calling SourceLocation.
+// CloudLoggingFormat reports it as the entry's sourceLocation; other formats
+// render it as "file:line".
+//
+// Example:
+//
+//	logger.Error("payment failed", logger.SourceLocation(0))
+func SourceLocation(skip int) Field {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return Field{Key: "sourceLocation", Value: nil}
+	}
+
+	loc := sourceLocation{file: file, line: line}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		loc.function = fn.Name()
+	}
+	return Field{Key: "sourceLocation", Value: loc}
+}
+
+// appendText appends the "file:line" form.
+func (s sourceLocation) appendText(buf []byte) []byte {
+	buf = append(buf, s.file...)
+	buf = append(buf, ':')
+	return strconv.AppendInt(buf, int64(s.line), 10)
+}
+
+// appendJSON appends the LogEntrySourceLocation form. The line is a string,
+// as in the JSON mapping of the int64 proto field.
+func (s sourceLocation) appendJSON(buf []byte) []byte {
+	buf = append(buf, `{"file":"`...)
+	buf = appendJSONString(buf, s.file)
+	buf = append(buf, `","line":"`...)
+	buf = strconv.AppendInt(buf, int64(s.line), 10)
+	buf = append(buf, `","function":"`...)
+	buf = appendJSONString(buf, s.function)
+	return append(buf, `"}`...)
+}
+
+// httpRequest is the value of an HTTPRequest field.
+type httpRequest struct {
+	method       string
+	url          string
+	userAgent    string
+	remoteIP     string
+	referer      string
+	protocol     string
+	status       int
+	requestSize  int64
+	responseSize int64
+	latency      time.Duration
+}
+
+// HTTPRequest returns a field describing a served request.
+// CloudLoggingFormat reports it as the entry's httpRequest, which Cloud
+// Logging shows in its request log views; other formats render a short
+// summary such as "GET /orders 200 12ms".
+//
+// Example:
+//
+//	logger.Info("request served", logger.HTTPRequest(r, status, written, time.Since(start)))
+func HTTPRequest(req *http.Request, status int, responseSize int64, latency time.Duration) Field {
+	v := httpRequest{
+		method:       req.Method,
+		url:          req.URL.String(),
+		userAgent:    req.UserAgent(),
+		referer:      req.Referer(),
+		protocol:     req.Proto,
+		status:       status,
+		requestSize:  max(req.ContentLength, 0),
+		responseSize: responseSize,
+		latency:      latency,
+	}
+	v.remoteIP = req.RemoteAddr
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		v.remoteIP = host
+	}
+	return Field{Key: "httpRequest", Value: v}
+}
+
+// appendText appends a quoted summary of the request.
+func (h httpRequest) appendText(buf []byte) []byte {
+	buf = append(buf, '"')
+	buf = append(buf, h.method...)
+	buf = append(buf, ' ')
+	buf = append(buf, h.url...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(h.status), 10)
+	buf = append(buf, ' ')
+	buf = append(buf, h.latency.String()...)
+	return append(buf, '"')
+}
+
+// appendJSON appends the HttpRequest form used by Cloud Logging. Sizes are
+// strings and the latency is a duration in seconds, e.g. "0.012s".
+func (h httpRequest) appendJSON(buf []byte) []byte {
+	buf = append(buf, `{"requestMethod":"`...)
+	buf = appendJSONString(buf, h.method)
+	buf = append(buf, `","requestUrl":"`...)
+	buf = appendJSONString(buf, h.url)
+	buf = append(buf, `","requestSize":"`...)
+	buf = strconv.AppendInt(buf, h.requestSize, 10)
+	buf = append(buf, `","status":`...)
+	buf = strconv.AppendInt(buf, int64(h.status), 10)
+	buf = append(buf, `,"responseSize":"`...)
+	buf = strconv.AppendInt(buf, h.responseSize, 10)
+	buf = append(buf, `","userAgent":"`...)
+	buf = appendJSONString(buf, h.userAgent)
+	buf = append(buf, `","remoteIp":"`...)
+	buf = appendJSONString(buf, h.remoteIP)
+	buf = append(buf, `","referer":"`...)
+	buf = appendJSONString(buf, h.referer)
+	buf = append(buf, `","latency":"`...)
+	buf = strconv.AppendFloat(buf, h.latency.Seconds(), 'f', -1, 64)
+	buf = append(buf, `s","protocol":"`...)
+	buf = appendJSONString(buf, h.protocol)
+	return append(buf, `"}`...)
+}
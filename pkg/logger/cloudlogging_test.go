@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudLoggingFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:          DebugLevel,
+		Format:         CloudLoggingFormat,
+		Output:         buf,
+		CloudProjectID: "my-project",
+	})
+
+	ctx := context.WithValue(context.Background(), contextKey("traceID"), "4bf92f3577b34da6")
+	ctx = context.WithValue(ctx, contextKey("spanID"), "00f067aa0ba902b7")
+	log.WithContext(func() context.Context { return ctx }).Warn("slow \"query\"",
+		SourceLocation(0),
+		Int("rows", 12),
+	)
+
+	line := buf.String()
+	require.True(t, strings.HasSuffix(line, "}\n"))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &entry))
+
+	assert.Equal(t, "WARNING", entry["severity"])
+	assert.Equal(t, `slow "query"`, entry["message"])
+	ts, err := time.Parse(time.RFC3339Nano, entry["time"].(string))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), ts, 5*time.Second)
+	assert.Equal(t, "projects/my-project/traces/4bf92f3577b34da6", entry["logging.googleapis.com/trace"])
+	assert.Equal(t, "00f067aa0ba902b7", entry["logging.googleapis.com/spanId"])
+	assert.Equal(t, float64(12), entry["rows"])
+
+	loc := entry["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	assert.True(t, strings.HasSuffix(loc["file"].(string), "cloudlogging_test.go"))
+	assert.NotEmpty(t, loc["line"])
+	assert.Contains(t, loc["function"], "TestCloudLoggingFormat")
+}
+
+func TestCloudLoggingFormat_TraceWithoutProject(t *testing.T) {
+	r := &Record{
+		Time:   time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC),
+		Level:  InfoLevel,
+		Fields: []Field{String("trace_id", "abc")},
+	}
+	assert.Equal(t,
+		`{"severity":"INFO","time":"2024-01-20T15:04:05Z","message":"","logging.googleapis.com/trace":"abc"}`,
+		string(appendCloudLogging(nil, r, "")))
+
+	r.Fields = []Field{String("traceID", "projects/other/traces/abc")}
+	assert.Contains(t, string(appendCloudLogging(nil, r, "my-project")),
+		`"logging.googleapis.com/trace":"projects/other/traces/abc"`)
+}
+
+func TestCloudLoggingFormat_ProjectFromEnv(t *testing.T) {
+	t.Setenv(EnvCloudProject, "env-project")
+	buf := &bytes.Buffer{}
+	New(Config{Format: CloudLoggingFormat, Output: buf}).Info("hello", String("traceID", "abc"))
+	assert.Contains(t, buf.String(), `"logging.googleapis.com/trace":"abc"`, "New does not read the environment")
+
+	buf.Reset()
+	config := ConfigFromEnv()
+	config.Format, config.Output = CloudLoggingFormat, buf
+	New(config).Info("hello", String("traceID", "abc"))
+	assert.Contains(t, buf.String(), `"logging.googleapis.com/trace":"projects/env-project/traces/abc"`)
+}
+
+func TestCloudLoggingFormat_HTTPRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/orders?id=7", strings.NewReader("body"))
+	req.RemoteAddr = "203.0.113.9:51234"
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("Referer", "https://example.com/")
+
+	r := &Record{
+		Time:    time.Now(),
+		Level:   InfoLevel,
+		Message: "served",
+		Fields:  []Field{HTTPRequest(req, 201, 512, 12*time.Millisecond)},
+	}
+
+	var entry struct {
+		HTTPRequest map[string]interface{} `json:"httpRequest"`
+	}
+	require.NoError(t, json.Unmarshal(appendCloudLogging(nil, r, ""), &entry))
+	assert.Equal(t, map[string]interface{}{
+		"requestMethod": "POST",
+		"requestUrl":    "/orders?id=7",
+		"requestSize":   "4",
+		"status":        float64(201),
+		"responseSize":  "512",
+		"userAgent":     "curl/8.0",
+		"remoteIp":      "203.0.113.9",
+		"referer":       "https://example.com/",
+		"latency":       "0.012s",
+		"protocol":      "HTTP/1.1",
+	}, entry.HTTPRequest)
+
+	text := &bytes.Buffer{}
+	New(Config{Format: TextFormat, Output: text}).Info("served", r.Fields...)
+	assert.Contains(t, text.String(), `httpRequest="POST /orders?id=7 201 12ms"`)
+}
+
+func TestSourceLocation_OtherFormats(t *testing.T) {
+	text := &bytes.Buffer{}
+	New(Config{Format: TextFormat, Output: text}).Info("here", SourceLocation(0))
+	assert.Regexp(t, `sourceLocation=\S+cloudlogging_test\.go:\d+`, text.String())
+
+	js := &bytes.Buffer{}
+	New(Config{Format: JSONFormat, Output: js}).Info("here", SourceLocation(0))
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(js.Bytes(), &entry))
+	assert.Contains(t, entry["sourceLocation"].(map[string]interface{})["file"], "cloudlogging_test.go")
+}
+
+func TestCloudSeverity(t *testing.T) {
+	for level, want := range map[Level]string{
+		DebugLevel: "DEBUG",
+		InfoLevel:  "INFO",
+		WarnLevel:  "WARNING",
+		ErrorLevel: "ERROR",
+		FatalLevel: "CRITICAL",
+		PanicLevel: "ALERT",
+	} {
+		assert.Equal(t, want, cloudSeverity(level))
+	}
+}
@@ -0,0 +1,311 @@
+package logger
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDiffExamples caps ConfigDiff.Examples.
+const maxDiffExamples = 5
+
+// ConfigDiff reports how the output of two configurations differs for the
+// same recorded calls, as returned by DiffConfigs.
+type ConfigDiff struct {
+	// Calls is the number of recorded calls replayed.
+	Calls int
+
+	// Differing is the number of compared entries whose keys or values
+	// differ.
+	Differing int
+
+	// OnlyA and OnlyB count the entries written by one configuration
+	// only, e.g. because of its level, sampling or rate limits.
+	OnlyA, OnlyB int
+
+	// MissingKeys counts, per key, the entries of A whose key is absent
+	// from the matching entry of B.
+	MissingKeys map[string]int
+
+	// AddedKeys counts, per key, the entries of B with a key absent from
+	// the matching entry of A.
+	AddedKeys map[string]int
+
+	// ChangedValues counts, per key, the entries where both outputs have
+	// the key but render its value differently, e.g. another timestamp
+	// layout or level spelling.
+	ChangedValues map[string]int
+
+	// BytesA and BytesB are the total sizes of the outputs.
+	BytesA, BytesB int64
+
+	// Examples holds the first differing entries.
+	Examples []DiffExample
+}
+
+// DiffExample is a pair of differing output lines.
+type DiffExample struct {
+	// Call is the 1-based index of the recorded call.
+	Call int
+	A, B string
+}
+
+// DiffConfigs replays the calls recorded by a Recorder through loggers
+// created from a and b and compares their output entry by entry, to
+// de-risk a format migration such as moving from TextFormat to ECSFormat:
+//
+//	diff, err := logger.DiffConfigs(recording, current, proposed)
+//	fmt.Println(diff)
+//
+// Entries are compared by key. JSON entries contribute their keys, with
+// nested objects flattened into dotted keys such as "log.level"; text
+// entries contribute their key=value pairs and the timestamp, level and
+// message under TimestampKey, LevelKey and MessageKey. Values are compared
+// as rendered, after unquoting strings, so the same value written by a text
+// and a JSON output compares equal.
+//
+// Only the primary output of each configuration is compared: Output, or
+// the first of Outputs when Output is nil. Buffering, async logging,
+// profiles and delivery tracing are disabled so every call is written
+// before the next one is replayed.
+func DiffConfigs(recording io.Reader, a, b Config) (*ConfigDiff, error) {
+	var outA, outB bytes.Buffer
+	la := New(captureConfig(a, &outA))
+	lb := New(captureConfig(b, &outB))
+	defer func() { _ = la.Close() }()
+	defer func() { _ = lb.Close() }()
+
+	diff := &ConfigDiff{
+		MissingKeys:   make(map[string]int),
+		AddedKeys:     make(map[string]int),
+		ChangedValues: make(map[string]int),
+	}
+	_, err := readRecordedCalls(recording, func(call replayedCall) {
+		outA.Reset()
+		outB.Reset()
+		for _, l := range []*Logger{la, lb} {
+			if l.enabled(call.level) {
+				l.logAt(context.Background(), call.time, call.level, call.message, call.fields)
+			}
+		}
+		diff.Calls++
+		diff.BytesA += int64(outA.Len())
+		diff.BytesB += int64(outB.Len())
+		diff.compare(diff.Calls, outA.String(), outB.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// captureConfig reduces config to a single synchronous sink writing one
+// line per entry to w: the primary output, or else the first of Outputs
+// with its level, format, encoder and key map.
+func captureConfig(config Config, w io.Writer) Config {
+	if config.Output == nil && len(config.Outputs) > 0 {
+		first := config.Outputs[0]
+		config.Outputs = []SinkConfig{{
+			Output:  w,
+			Level:   first.Level,
+			Format:  first.Format,
+			Encoder: first.Encoder,
+			KeyMap:  first.KeyMap,
+		}}
+	} else {
+		config.Output = w
+		config.Outputs = nil
+	}
+	// compare splits the output on newlines.
+	config.PrettyJSON = false
+	config.ErrorOutput = nil
+	config.BufferSize = 0
+	config.FlushInterval = 0
+	config.AsyncQueueSize = 0
+	config.Profile = ""
+	config.DeliveryTraceInterval = 0
+	return config
+}
+
+// compare adds the differences between the output lines of one call.
+func (d *ConfigDiff) compare(call int, outA, outB string) {
+	linesA := strings.Split(strings.TrimSuffix(outA, "\n"), "\n")
+	linesB := strings.Split(strings.TrimSuffix(outB, "\n"), "\n")
+	if outA == "" {
+		linesA = nil
+	}
+	if outB == "" {
+		linesB = nil
+	}
+
+	for i := 0; i < min(len(linesA), len(linesB)); i++ {
+		entryA, entryB := parseDiffEntry(linesA[i]), parseDiffEntry(linesB[i])
+		differs := false
+		for key, va := range entryA {
+			vb, ok := entryB[key]
+			switch {
+			case !ok:
+				d.MissingKeys[key]++
+				differs = true
+			case va != vb:
+				d.ChangedValues[key]++
+				differs = true
+			}
+		}
+		for key := range entryB {
+			if _, ok := entryA[key]; !ok {
+				d.AddedKeys[key]++
+				differs = true
+			}
+		}
+		if differs {
+			d.Differing++
+			if len(d.Examples) < maxDiffExamples {
+				d.Examples = append(d.Examples, DiffExample{Call: call, A: linesA[i], B: linesB[i]})
+			}
+		}
+	}
+	if len(linesA) > len(linesB) {
+		d.OnlyA += len(linesA) - len(linesB)
+	} else {
+		d.OnlyB += len(linesB) - len(linesA)
+	}
+}
+
+// parseDiffEntry returns the rendered values of an output line by key.
+func parseDiffEntry(line string) map[string]string {
+	entry := make(map[string]string)
+	if strings.HasPrefix(line, "{") {
+		var object map[string]json.RawMessage
+		if json.Unmarshal([]byte(line), &object) == nil {
+			flattenDiffObject(entry, "", object)
+			return entry
+		}
+	}
+
+	tokens := splitTextTokens(line)
+	i := 0
+	if len(tokens) > i {
+		if _, err := time.Parse(textTimestampLayout, tokens[i]); err == nil {
+			entry[TimestampKey] = tokens[i]
+			i++
+		}
+	}
+	if len(tokens) > i {
+		if _, ok := levelFromName(tokens[i]); ok {
+			entry[LevelKey] = tokens[i]
+			i++
+		}
+	}
+	var message []string
+	for fields := false; i < len(tokens); i++ {
+		key, value, ok := strings.Cut(tokens[i], "=")
+		if !ok || key == "" || strings.Contains(key, `"`) {
+			// Words before the first field belong to the message.
+			if !fields {
+				message = append(message, tokens[i])
+			}
+			continue
+		}
+		fields = true
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		entry[key] = value
+	}
+	if len(message) > 0 {
+		entry[MessageKey] = strings.Join(message, " ")
+	}
+	return entry
+}
+
+// flattenDiffObject adds the values of object to entry, joining the keys of
+// nested objects with dots.
+func flattenDiffObject(entry map[string]string, prefix string, object map[string]json.RawMessage) {
+	for key, raw := range object {
+		key = prefix + key
+		var nested map[string]json.RawMessage
+		if len(raw) > 0 && raw[0] == '{' && json.Unmarshal(raw, &nested) == nil {
+			flattenDiffObject(entry, key+".", nested)
+			continue
+		}
+		value := string(raw)
+		var s string
+		if len(raw) > 0 && raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+			value = s
+		}
+		entry[key] = value
+	}
+}
+
+// splitTextTokens splits a text line at spaces outside double quotes.
+func splitTextTokens(line string) []string {
+	var tokens []string
+	start, quoted := 0, false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ' ':
+			if !quoted {
+				if i > start {
+					tokens = append(tokens, line[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+	if start < len(line) {
+		tokens = append(tokens, line[start:])
+	}
+	return tokens
+}
+
+// String summarizes the differences.
+func (d *ConfigDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d calls, %d differing entries, %d only in A, %d only in B\n", d.Calls, d.Differing, d.OnlyA, d.OnlyB)
+	fmt.Fprintf(&b, "size: A %d bytes, B %d bytes", d.BytesA, d.BytesB)
+	if d.BytesA > 0 {
+		fmt.Fprintf(&b, " (%+.1f%%)", float64(d.BytesB-d.BytesA)*100/float64(d.BytesA))
+	}
+	b.WriteByte('\n')
+	writeDiffKeys(&b, "missing in B", d.MissingKeys)
+	writeDiffKeys(&b, "added in B", d.AddedKeys)
+	writeDiffKeys(&b, "changed values", d.ChangedValues)
+	for _, ex := range d.Examples {
+		fmt.Fprintf(&b, "call %d:\n  A: %s\n  B: %s\n", ex.Call, ex.A, ex.B)
+	}
+	return b.String()
+}
+
+// writeDiffKeys writes the keys of counts, most frequent first.
+func writeDiffKeys(b *strings.Builder, title string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(x, y string) int {
+		return cmp.Or(cmp.Compare(counts[y], counts[x]), cmp.Compare(x, y))
+	})
+
+	b.WriteString(title + ":")
+	for _, key := range keys {
+		fmt.Fprintf(b, " %s (%d)", key, counts[key])
+	}
+	b.WriteByte('\n')
+}
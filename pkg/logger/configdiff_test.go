@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordCalls(t *testing.T, calls func(log *Logger)) *bytes.Buffer {
+	t.Helper()
+	recording := &bytes.Buffer{}
+	rec := NewRecorder(recording)
+	log := New(Config{Level: DebugLevel, Output: &bytes.Buffer{}})
+	log.AddHook(rec)
+	calls(log)
+	require.NoError(t, rec.Err())
+	return recording
+}
+
+func TestDiffConfigs_TextToECS(t *testing.T) {
+	recording := recordCalls(t, func(log *Logger) {
+		log.Debug("cache miss", String("key", "user:1"))
+		log.Info("user logged in", String("user", "ada"), Int("attempt", 2))
+		log.Error("payment failed", String("order", "A-1"))
+	})
+
+	diff, err := DiffConfigs(recording,
+		Config{Level: DebugLevel, Format: TextFormat},
+		Config{Outputs: []SinkConfig{{Level: InfoLevel, Format: ECSFormat}}},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, diff.Calls)
+	assert.Equal(t, 2, diff.Differing)
+	assert.Equal(t, 1, diff.OnlyA, "the debug entry is filtered by the sink level of B")
+	assert.Zero(t, diff.OnlyB)
+	assert.Equal(t, 2, diff.MissingKeys[TimestampKey])
+	assert.Equal(t, 2, diff.MissingKeys[LevelKey])
+	assert.Equal(t, 2, diff.AddedKeys["@timestamp"])
+	assert.Equal(t, 2, diff.AddedKeys["log.level"])
+	assert.NotContains(t, diff.MissingKeys, MessageKey)
+	assert.NotContains(t, diff.MissingKeys, "user")
+	assert.NotContains(t, diff.ChangedValues, "attempt")
+	assert.Greater(t, diff.BytesB, diff.BytesA)
+	require.Len(t, diff.Examples, 2)
+	assert.Equal(t, 2, diff.Examples[0].Call)
+
+	report := diff.String()
+	assert.Contains(t, report, "3 calls, 2 differing entries, 1 only in A, 0 only in B\n")
+	assert.Contains(t, report, "missing in B: level (2) timestamp (2)\n")
+	assert.Contains(t, report, "call 2:\n  A: ")
+}
+
+func TestDiffConfigs_ChangedValues(t *testing.T) {
+	recording := recordCalls(t, func(log *Logger) {
+		log.Info("tick", F("at", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+	})
+
+	diff, err := DiffConfigs(recording,
+		Config{Format: JSONFormat},
+		Config{Format: JSONFormat, TimestampFormat: TimestampUnixMilli, KeyMap: map[string]string{MessageKey: "msg"}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{TimestampKey: 1}, diff.ChangedValues)
+	assert.Equal(t, map[string]int{MessageKey: 1}, diff.MissingKeys)
+	assert.Equal(t, map[string]int{"msg": 1}, diff.AddedKeys)
+
+	same, err := DiffConfigs(strings.NewReader(""), Config{}, Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "0 calls, 0 differing entries, 0 only in A, 0 only in B\nsize: A 0 bytes, B 0 bytes\n", same.String())
+
+	_, err = DiffConfigs(strings.NewReader("not json\n"), Config{}, Config{})
+	assert.ErrorContains(t, err, "replay line 1")
+}
+
+func TestDiffConfigs_FirstSink(t *testing.T) {
+	recording := recordCalls(t, func(log *Logger) {
+		log.Info("started")
+		log.Warn("slow", F("ms", 900))
+	})
+
+	upper := EncoderFunc(func(buf []byte, r *Record) []byte {
+		return append(buf, `{"msg":"`+strings.ToUpper(r.Message)+`"}`...)
+	})
+	diff, err := DiffConfigs(recording,
+		Config{Outputs: []SinkConfig{{Level: WarnLevel, Format: JSONFormat, KeyMap: map[string]string{MessageKey: "msg"}}}, PrettyJSON: true},
+		Config{Outputs: []SinkConfig{{Level: WarnLevel, Encoder: upper}}},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, diff.Calls)
+	assert.Equal(t, 1, diff.Differing, "the info entry is filtered by the sink level of both")
+	assert.Zero(t, diff.OnlyA)
+	assert.Zero(t, diff.OnlyB)
+	assert.Equal(t, map[string]int{"msg": 1}, diff.ChangedValues)
+	assert.NotContains(t, diff.MissingKeys, MessageKey)
+	assert.Contains(t, diff.MissingKeys, "ms")
+	assert.Empty(t, diff.AddedKeys)
+}
+
+func TestParseDiffEntry_Text(t *testing.T) {
+	entry := parseDiffEntry(`2026-01-02T03:04:05.000Z WARN disk almost full path="/var/log x" used=93`)
+	assert.Equal(t, map[string]string{
+		TimestampKey: "2026-01-02T03:04:05.000Z",
+		LevelKey:     "WARN",
+		MessageKey:   "disk almost full",
+		"path":       "/var/log x",
+		"used":       "93",
+	}, entry)
+}
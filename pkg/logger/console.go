@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// ColorMode selects whether ConsoleFormat colors its output.
+type ColorMode int8
+
+const (
+	// ColorAuto colors the output when it is a terminal and the NO_COLOR
+	// environment variable is not set (see https://no-color.org).
+	ColorAuto ColorMode = iota
+
+	// ColorAlways colors the output regardless of the terminal and
+	// NO_COLOR.
+	ColorAlways
+
+	// ColorNever writes the console layout without colors.
+	ColorNever
+)
+
+const (
+	// consoleTimestampLayout is the timestamp layout of ConsoleFormat
+	// with TimestampDefault. Console output is read while it is written,
+	// so the date and time zone are left out.
+	consoleTimestampLayout = "15:04:05.000"
+
+	// consoleLevelWidth is the width the level is padded to.
+	consoleLevelWidth = 5
+
+	// consoleMessageWidth is the width the message is padded to when
+	// fields follow it, so the fields of consecutive entries line up.
+	consoleMessageWidth = 40
+)
+
+// ANSI escape sequences used by ConsoleFormat.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// useColor resolves mode for the output w.
+func useColor(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a character device such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// levelColor returns the color of level. Custom levels take the color of
+// the closest predefined level below.
+func levelColor(level Level) string {
+	switch {
+	case level < InfoLevel:
+		return ansiBlue
+	case level < WarnLevel:
+		return ansiGreen
+	case level < ErrorLevel:
+		return ansiYellow
+	case level < FatalLevel:
+		return ansiRed
+	case level < PanicLevel:
+		return ansiBold + ansiRed
+	default:
+		return ansiBold + ansiMagenta
+	}
+}
+
+// appendConsole appends r in the ConsoleFormat layout, colored when color
+// is set, with block fields below the entry line when blocks is set:
+//
+//	15:04:05.000 INFO  server started                           addr=:8080
+func (l *Logger) appendConsole(buf []byte, r *Record, color, blocks bool) []byte {
+	if !omitsTimestamp(r.Time, l.config.TimestampFormat) {
+		buf = appendColor(buf, ansiDim, color)
+		if l.config.TimestampFormat == TimestampDefault {
+			buf = r.Time.AppendFormat(buf, consoleTimestampLayout)
+		} else {
+			buf = l.timeCache.appendText(buf, r.Time, l.config.TimestampFormat)
+		}
+		buf = appendColor(buf, ansiReset, color)
+		buf = append(buf, ' ')
+	}
+
+	level := r.Level.String()
+	buf = appendColor(buf, levelColor(r.Level), color)
+	buf = append(buf, level...)
+	buf = appendColor(buf, ansiReset, color)
+	buf = appendPadding(buf, consoleLevelWidth-len(level))
+	buf = append(buf, ' ')
+
+	hasFields, hasBlocks := false, false
+	for _, field := range r.Fields {
+		if blocks && isBlock(field.Value) {
+			hasBlocks = true
+		} else {
+			hasFields = true
+		}
+	}
+
+	buf = appendColor(buf, ansiBold, color)
+	buf = append(buf, r.Message...)
+	buf = appendColor(buf, ansiReset, color)
+	if hasFields {
+		buf = appendPadding(buf, consoleMessageWidth-utf8.RuneCountInString(r.Message))
+	}
+
+	for _, field := range r.Fields {
+		if blocks && isBlock(field.Value) {
+			continue
+		}
+		buf = append(buf, ' ')
+		if g, ok := field.Value.(groupValue); ok {
+			buf = appendTextGroup(buf, field.Key, g, color)
+			continue
+		}
+		buf = appendColor(buf, ansiCyan, color)
+		buf = append(buf, field.Key...)
+		buf = appendColor(buf, ansiDim, color)
+		buf = append(buf, '=')
+		buf = appendColor(buf, ansiReset, color)
+		buf = appendValue(buf, field.Value)
+	}
+
+	if hasBlocks {
+		buf = appendBlocks(buf, r.Fields)
+	}
+	return buf
+}
+
+// appendColor appends the escape sequence code when color is set.
+func appendColor(buf []byte, code string, color bool) []byte {
+	if !color {
+		return buf
+	}
+	return append(buf, code...)
+}
+
+// appendPadding appends n spaces, if n > 0.
+func appendPadding(buf []byte, n int) []byte {
+	for ; n > 0; n-- {
+		buf = append(buf, ' ')
+	}
+	return buf
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleFormat(t *testing.T) {
+	ts := time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC)
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:  DebugLevel,
+		Format: ConsoleFormat,
+		Output: buf,
+		Clock:  func() time.Time { return ts },
+	})
+
+	log.Info("server started", String("addr", ":8080"), Int("workers", 4))
+	log.Warn("no fields")
+	log.Error("query failed", Block("sql", "SELECT 1"))
+
+	assert.Equal(t,
+		"15:04:05.000 INFO  server started                           addr=:8080 workers=4\n"+
+			"15:04:05.000 WARN  no fields\n"+
+			"15:04:05.000 ERROR query failed\n  sql:\n    SELECT 1\n",
+		buf.String())
+}
+
+func TestConsoleFormat_Color(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           DebugLevel,
+		Format:          ConsoleFormat,
+		Color:           ColorAlways,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+	})
+
+	log.Error("boom", Int("code", 7))
+	assert.Equal(t,
+		ansiRed+"ERROR"+ansiReset+" "+ansiBold+"boom"+ansiReset+strings.Repeat(" ", 36)+
+			" "+ansiCyan+"code"+ansiDim+"="+ansiReset+"7\n",
+		buf.String())
+}
+
+func TestLevelColor(t *testing.T) {
+	assert.Equal(t, ansiBlue, levelColor(DebugLevel))
+	assert.Equal(t, ansiGreen, levelColor(InfoLevel))
+	assert.Equal(t, ansiGreen, levelColor(Level(2)), "custom levels take the color of the level below")
+	assert.Equal(t, ansiYellow, levelColor(WarnLevel))
+	assert.Equal(t, ansiRed, levelColor(ErrorLevel))
+	assert.Equal(t, ansiBold+ansiRed, levelColor(FatalLevel))
+	assert.Equal(t, ansiBold+ansiMagenta, levelColor(PanicLevel))
+}
+
+func TestUseColor(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	t.Setenv("NO_COLOR", "")
+	assert.True(t, useColor(ColorAlways, f))
+	assert.False(t, useColor(ColorNever, f))
+	assert.False(t, useColor(ColorAuto, f), "regular files are not terminals")
+	assert.False(t, useColor(ColorAuto, &bytes.Buffer{}))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, useColor(ColorAuto, os.Stdout))
+	assert.True(t, useColor(ColorAlways, os.Stdout), "ColorAlways overrides NO_COLOR")
+}
+
+func TestConsoleFormat_SharedEncoding(t *testing.T) {
+	plain, colored := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Config{
+		Level: InfoLevel,
+		Outputs: []SinkConfig{
+			{Output: plain, Format: ConsoleFormat, Color: ColorNever},
+			{Output: colored, Format: ConsoleFormat, Color: ColorAlways},
+		},
+		TimestampFormat: TimestampDisabled,
+	})
+
+	log.Info("hi")
+	assert.Equal(t, "INFO  hi\n", plain.String())
+	assert.Contains(t, colored.String(), ansiGreen)
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextExtractor derives fields from a context.Context, passing each one
+// to appendField. It is called for every entry logged through a
+// ContextLogger, so it should be cheap when the context carries nothing of
+// interest. A panic in an extractor is recovered, keeping the fields
+// appended before it, unless Config.StrictPanics is set.
+//
+// Example:
+//
+//	type tenantKey struct{}
+//
+//	log := logger.New(logger.Config{
+//		Output: os.Stdout,
+//		ContextExtractor: func(ctx context.Context, appendField func(logger.Field)) {
+//			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+//				appendField(logger.F("tenant", tenant))
+//			}
+//		},
+//	})
+type ContextExtractor func(ctx context.Context, appendField func(Field))
+
+var (
+	registryMu  sync.Mutex
+	extractors  atomic.Pointer[[]ContextExtractor]
+	contextKeys atomic.Pointer[[]registeredKey]
+)
+
+// registeredKey is a context key registered with RegisterContextKey.
+type registeredKey struct {
+	key       any
+	fieldName string
+}
+
+// RegisterContextKey makes every ContextLogger emit the value stored under
+// key in its context as a field named fieldName. Use it with unexported
+// key types, as recommended for context.WithValue, instead of string keys:
+//
+//	type requestIDKey struct{}
+//
+//	func init() {
+//		logger.RegisterContextKey(requestIDKey{}, "requestID")
+//	}
+//
+//	ctx = context.WithValue(ctx, requestIDKey{}, id)
+//
+// Registering a key again changes its field name. Registered keys are
+// looked up after Config.ContextExtractor and before the values of MDC and
+// extractors registered with RegisterContextExtractor. Like
+// context.WithValue, it panics if key is nil or not comparable.
+func RegisterContextKey(key any, fieldName string) {
+	if key == nil {
+		panic("logger: nil context key")
+	}
+	if !reflect.TypeOf(key).Comparable() {
+		panic("logger: context key is not comparable")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var list []registeredKey
+	if current := contextKeys.Load(); current != nil {
+		list = append(list, *current...)
+	}
+	for i := range list {
+		if list[i].key == key {
+			list[i].fieldName = fieldName
+			contextKeys.Store(&list)
+			return
+		}
+	}
+	list = append(list, registeredKey{key: key, fieldName: fieldName})
+	contextKeys.Store(&list)
+}
+
+// RegisterContextExtractor adds an extractor that runs for every
+// ContextLogger of every Logger, after Config.ContextExtractor. It is meant
+// to be called from init functions of integration packages, such as
+// logotel for OpenTelemetry spans, so that importing the package is enough
+// to enable it.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var list []ContextExtractor
+	if current := extractors.Load(); current != nil {
+		list = append(list, *current...)
+	}
+	list = append(list, extractor)
+	extractors.Store(&list)
+}
+
+// extractContextFields returns the fields carried by ctx followed by fields.
+func (l *Logger) extractContextFields(ctx context.Context, fields []Field) []Field {
+	contextFields := make([]Field, 0, 4)
+
+	extract := l.config.ContextExtractor
+	list := extractors.Load()
+	if extract == nil {
+		contextFields = appendTraceFields(contextFields, ctx)
+		if list == nil {
+			// Avoid allocating appendField when no extractor runs.
+			contextFields = appendRegisteredKeys(contextFields, ctx)
+			contextFields = appendMDC(contextFields, ctx)
+			return append(contextFields, fields...)
+		}
+	}
+
+	appendField := func(field Field) {
+		contextFields = append(contextFields, field)
+	}
+	if extract != nil {
+		l.runExtractor(extract, ctx, appendField)
+	}
+	contextFields = appendRegisteredKeys(contextFields, ctx)
+	contextFields = appendMDC(contextFields, ctx)
+	if list != nil {
+		for _, extract := range *list {
+			l.runExtractor(extract, ctx, appendField)
+		}
+	}
+
+	return append(contextFields, fields...)
+}
+
+// appendRegisteredKeys appends the values of the keys registered with
+// RegisterContextKey that ctx carries.
+func appendRegisteredKeys(fields []Field, ctx context.Context) []Field {
+	keys := contextKeys.Load()
+	if keys == nil {
+		return fields
+	}
+	for _, k := range *keys {
+		if value := ctx.Value(k.key); value != nil {
+			fields = append(fields, Field{Key: k.fieldName, Value: fieldValue(value)})
+		}
+	}
+	return fields
+}
+
+// appendTraceFields is the extraction used without Config.ContextExtractor.
+// It looks up the traceID and spanID keys.
+func appendTraceFields(fields []Field, ctx context.Context) []Field {
+	if traceID := ctx.Value(contextKey("traceID")); traceID != nil {
+		fields = append(fields, Field{Key: "traceID", Value: fieldValue(traceID)})
+	}
+	if spanID := ctx.Value(contextKey("spanID")); spanID != nil {
+		fields = append(fields, Field{Key: "spanID", Value: fieldValue(spanID)})
+	}
+	return fields
+}
+
+// loggerContextKey is the context key of the logger stored by NewContext.
+type loggerContextKey struct{}
+
+// NewContext returns a copy of ctx carrying l, typically a request-scoped
+// logger created with With, so that code deep in the call stack can get it
+// with FromContext instead of having it passed through every signature.
+//
+// Example:
+//
+//	func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//		reqLog := s.log.With(logger.F("requestID", r.Header.Get("X-Request-ID")))
+//		s.mux.ServeHTTP(w, r.WithContext(logger.NewContext(r.Context(), reqLog)))
+//	}
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by NewContext, or Default
+// when there is none.
+//
+// Example:
+//
+//	func loadOrder(ctx context.Context, id string) {
+//		logger.FromContext(ctx).Debug("loading order", logger.F("orderID", id))
+//	}
+func FromContext(ctx context.Context) *Logger {
+	return FromContextOr(ctx, nil)
+}
+
+// FromContextOr returns the logger stored in ctx by NewContext, or fallback
+// when there is none. A nil fallback means Default.
+func FromContextOr(ctx context.Context, fallback *Logger) *Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return Default()
+}
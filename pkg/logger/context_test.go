@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	RegisterContextExtractor(func(ctx context.Context, appendField func(Field)) {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			appendField(F("tenant", tenant))
+		}
+	})
+
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	ctx := context.WithValue(context.Background(), contextKey("traceID"), "abc")
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+	logger.WithStaticContext(ctx).Info("request", F("status", 200))
+
+	assert.Contains(t, buf.String(), `"traceID":"abc","tenant":"acme","status":200`)
+
+	buf.Reset()
+	logger.WithStaticContext(context.Background()).Info("plain")
+	assert.NotContains(t, buf.String(), "tenant")
+}
+
+type requestIDKey struct{}
+
+func TestConfigContextExtractor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: buf,
+		ContextExtractor: func(ctx context.Context, appendField func(Field)) {
+			if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+				appendField(F("requestID", id))
+			}
+		},
+	})
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	ctx = context.WithValue(ctx, contextKey("traceID"), "abc")
+	logger.WithStaticContext(ctx).Info("request")
+
+	assert.Contains(t, buf.String(), `"requestID":"req-1"`)
+	assert.NotContains(t, buf.String(), "traceID", "the extractor replaces the built-in lookup")
+}
+
+type userIDKey struct{}
+
+func TestRegisterContextKey(t *testing.T) {
+	RegisterContextKey(userIDKey{}, "user")
+	RegisterContextKey(userIDKey{}, "userID")
+
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	ctx := context.WithValue(context.Background(), userIDKey{}, 42)
+	logger.WithStaticContext(ctx).Info("login")
+
+	assert.Contains(t, buf.String(), `"userID":42`)
+	assert.NotContains(t, buf.String(), `"user":`)
+}
+
+func TestRegisterContextKey_Invalid(t *testing.T) {
+	assert.PanicsWithValue(t, "logger: nil context key", func() {
+		RegisterContextKey(nil, "x")
+	})
+	assert.PanicsWithValue(t, "logger: context key is not comparable", func() {
+		RegisterContextKey([]string{"x"}, "x")
+	})
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"strconv"
+	"time"
+)
+
+// datadogFieldNames maps common field keys to the reserved and standard
+// attributes of Datadog. Keys not listed are written unchanged.
+var datadogFieldNames = map[string]string{
+	"traceID":    "dd.trace_id",
+	"trace_id":   "dd.trace_id",
+	"spanID":     "dd.span_id",
+	"span_id":    "dd.span_id",
+	"error":      "error.message",
+	"err":        "error.message",
+	"stack":      "error.stack",
+	"stacktrace": "error.stack",
+}
+
+// appendDatadog formats a log entry with the attributes the Datadog agent
+// recognizes without a custom pipeline: "date", "status", "message", and
+// dd.trace_id and dd.span_id for trace correlation. Trace and span IDs are
+// sent as decimal strings; OpenTelemetry hex IDs are converted from their
+// lower 64 bits, which is how Datadog stores them. Fields such as
+// "service", "env" and "version" are reserved attributes as they are.
+func appendDatadog(buf []byte, r *Record) []byte {
+	buf = append(buf, `{"date":"`...)
+	buf = r.Time.UTC().AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, `","status":"`...)
+	buf = append(buf, datadogStatus(r.Level)...)
+	buf = append(buf, `","message":"`...)
+	buf = appendJSONString(buf, r.Message)
+	buf = append(buf, '"')
+
+	for _, field := range r.Fields {
+		key, ok := datadogFieldNames[field.Key]
+		if !ok {
+			key = field.Key
+		}
+
+		buf = append(buf, ',', '"')
+		buf = appendJSONString(buf, key)
+		buf = append(buf, '"', ':')
+
+		if key != "dd.trace_id" && key != "dd.span_id" {
+			buf = appendJSONValue(buf, field.Value)
+			continue
+		}
+		if id, ok := datadogID(field.Value); ok {
+			buf = append(buf, '"')
+			buf = strconv.AppendUint(buf, id, 10)
+			buf = append(buf, '"')
+			continue
+		}
+		buf = appendJSONValue(buf, field.Value)
+	}
+
+	return append(buf, '}')
+}
+
+// datadogStatus returns the Datadog status of level, using the same
+// mapping as syslogSeverity.
+func datadogStatus(level Level) string {
+	switch {
+	case level < InfoLevel:
+		return "debug"
+	case level < WarnLevel:
+		return "info"
+	case level < ErrorLevel:
+		return "warning"
+	case level < FatalLevel:
+		return "error"
+	case level < PanicLevel:
+		return "critical"
+	default:
+		return "alert"
+	}
+}
+
+// datadogID converts a trace or span ID to Datadog's unsigned 64-bit form.
+// Integers and decimal strings are used as they are; 16 or 32 digit hex
+// strings, as produced by OpenTelemetry, are reduced to their lower 64 bits.
+func datadogID(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case int:
+		return uint64(v), v >= 0
+	case int64:
+		return uint64(v), v >= 0
+	case uint64:
+		return v, true
+	case string:
+		if len(v) == 16 || len(v) == 32 {
+			if id, err := strconv.ParseUint(v[len(v)-16:], 16, 64); err == nil {
+				return id, true
+			}
+		}
+		id, err := strconv.ParseUint(v, 10, 64)
+		return id, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatadogFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: DebugLevel, Format: DatadogFormat, Output: buf})
+
+	ctx := context.WithValue(context.Background(), contextKey("traceID"), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = context.WithValue(ctx, contextKey("spanID"), "00f067aa0ba902b7")
+	log.WithStaticContext(ctx).Warn("checkout \"slow\"",
+		String("service", "billing"),
+		Err(errors.New("timeout")),
+		Int("items", 3),
+	)
+
+	line := buf.String()
+	require.True(t, strings.HasSuffix(line, "}\n"))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &entry))
+
+	ts, err := time.Parse(time.RFC3339Nano, entry["date"].(string))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), ts, 5*time.Second)
+	assert.Equal(t, "warning", entry["status"])
+	assert.Equal(t, `checkout "slow"`, entry["message"])
+	assert.Equal(t, "11803532876627986230", entry["dd.trace_id"])
+	assert.Equal(t, "67667974448284343", entry["dd.span_id"])
+	assert.Equal(t, "billing", entry["service"])
+	assert.Equal(t, "timeout", entry["error.message"])
+	assert.Equal(t, float64(3), entry["items"])
+	assert.NotContains(t, entry, "traceID")
+}
+
+func TestDatadogID(t *testing.T) {
+	tests := []struct {
+		value any
+		want  uint64
+		ok    bool
+	}{
+		{"4bf92f3577b34da6a3ce929d0e0e4736", 11803532876627986230, true},
+		{"00f067aa0ba902b7", 67667974448284343, true},
+		{"5208512171318403364", 5208512171318403364, true},
+		{int64(42), 42, true},
+		{42, 42, true},
+		{-1, 0, false},
+		{"not-an-id", 0, false},
+		{true, 0, false},
+	}
+	for _, tt := range tests {
+		id, ok := datadogID(tt.value)
+		assert.Equal(t, tt.ok, ok, "%v", tt.value)
+		if tt.ok {
+			assert.Equal(t, tt.want, id, "%v", tt.value)
+		}
+	}
+}
+
+func TestDatadogFormat_UnconvertibleIDKeepsValue(t *testing.T) {
+	r := &Record{
+		Time:   time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC),
+		Level:  FatalLevel,
+		Fields: []Field{String("trace_id", "custom")},
+	}
+	assert.Equal(t,
+		`{"date":"2024-01-20T15:04:05Z","status":"critical","message":"","dd.trace_id":"custom"}`,
+		string(appendDatadog(nil, r)))
+}
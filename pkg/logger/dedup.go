@@ -0,0 +1,84 @@
+package logger
+
+// DuplicateKeyPolicy decides what happens to fields sharing a key, e.g. a
+// key bound with With and passed again at the call site.
+type DuplicateKeyPolicy int8
+
+const (
+	// DuplicateKeysAllow writes every field, so an entry may repeat a
+	// key. It costs nothing and is the default.
+	DuplicateKeysAllow DuplicateKeyPolicy = iota
+
+	// DuplicateKeysLastWins keeps the last field of every key, so fields
+	// of the call override those of With, which override those of the
+	// context.
+	DuplicateKeysLastWins
+
+	// DuplicateKeysFirstWins keeps the first field of every key, so
+	// fields bound with With cannot be overridden by a call.
+	DuplicateKeysFirstWins
+)
+
+// dedupSmallSet is the number of fields up to which duplicates are found
+// by comparing every pair of keys, which beats hashing and does not
+// allocate.
+const dedupSmallSet = 8
+
+// dedupFields removes the fields of r that policy discards, keeping the
+// order of the others. r.Fields must be owned by the record.
+func dedupFields(policy DuplicateKeyPolicy, r *Record) {
+	if len(r.Fields) < 2 {
+		return
+	}
+	if len(r.Fields) <= dedupSmallSet {
+		r.Fields = dedupSmall(policy, r.Fields)
+	} else {
+		r.Fields = dedupLarge(policy, r.Fields)
+	}
+}
+
+// dedupSmall compacts fields in place, comparing keys pairwise.
+func dedupSmall(policy DuplicateKeyPolicy, fields []Field) []Field {
+	out := fields[:0]
+	for i, field := range fields {
+		// out holds the first field of every key seen so far, and the
+		// fields after i are not overwritten yet.
+		if policy == DuplicateKeysFirstWins && hasKey(out, field.Key) ||
+			policy == DuplicateKeysLastWins && hasKey(fields[i+1:], field.Key) {
+			continue
+		}
+		out = append(out, field)
+	}
+	return out
+}
+
+// dedupLarge compacts fields in place, indexing keys in a map.
+func dedupLarge(policy DuplicateKeyPolicy, fields []Field) []Field {
+	index := make(map[string]int, len(fields))
+	for i, field := range fields {
+		if _, seen := index[field.Key]; !seen || policy == DuplicateKeysLastWins {
+			index[field.Key] = i
+		}
+	}
+	if len(index) == len(fields) {
+		return fields
+	}
+
+	out := fields[:0]
+	for i, field := range fields {
+		if index[field.Key] == i {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// hasKey reports whether fields holds a field with key.
+func hasKey(fields []Field, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
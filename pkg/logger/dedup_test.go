@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   string
+	}{
+		{DuplicateKeysAllow, `{"level":"INFO","message":"m","user":"ctx","tenant":"acme","user":"with","user":"call"}`},
+		{DuplicateKeysLastWins, `{"level":"INFO","message":"m","tenant":"acme","user":"call"}`},
+		{DuplicateKeysFirstWins, `{"level":"INFO","message":"m","user":"ctx","tenant":"acme"}`},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		log := New(Config{
+			Level:           InfoLevel,
+			Format:          JSONFormat,
+			Output:          buf,
+			TimestampFormat: TimestampDisabled,
+			DuplicateKeys:   tt.policy,
+		})
+
+		log.With(String("user", "ctx"), String("tenant", "acme")).With(String("user", "with")).Info("m", String("user", "call"))
+		assert.Equal(t, tt.want+"\n", buf.String(), "policy %d", tt.policy)
+	}
+}
+
+func TestDuplicateKeys_Large(t *testing.T) {
+	var fields []Field
+	for i := range 12 {
+		fields = append(fields, Int(fmt.Sprintf("k%d", i%6), i))
+	}
+
+	r := &Record{Fields: append([]Field(nil), fields...)}
+	dedupFields(DuplicateKeysLastWins, r)
+	assert.Equal(t, fields[6:], r.Fields)
+
+	r = &Record{Fields: append([]Field(nil), fields...)}
+	dedupFields(DuplicateKeysFirstWins, r)
+	assert.Equal(t, fields[:6], r.Fields)
+
+	r = &Record{Fields: append([]Field(nil), fields[:6]...)}
+	dedupFields(DuplicateKeysLastWins, r)
+	assert.Equal(t, fields[:6], r.Fields, "distinct keys are kept")
+}
+
+func TestDuplicateKeys_CallerFieldsUntouched(t *testing.T) {
+	log := New(Config{Level: InfoLevel, Output: io.Discard, DuplicateKeys: DuplicateKeysLastWins})
+	fields := []Field{String("a", "1"), String("a", "2")}
+
+	log.Info("m", fields...)
+	assert.Equal(t, []Field{String("a", "1"), String("a", "2")}, fields)
+}
+
+func TestDuplicateKeys_NoAllocs(t *testing.T) {
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: io.Discard, DuplicateKeys: DuplicateKeysLastWins})
+	child := log.With(String("user", "ctx"), Int("attempt", 1))
+	fields := []Field{String("user", "call"), Int("attempt", 2), Bool("ok", true)}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		child.Info("retry", fields...)
+	})
+	assert.Zero(t, allocs)
+}
@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+)
+
+// DeliveryTraceKey is the key of the field holding the sequence number of
+// the marker entries injected by Config.DeliveryTraceInterval, so they can
+// be recognized and filtered downstream.
+const DeliveryTraceKey = "delivery_trace"
+
+// deliveryTraceMessage is the message of delivery trace markers.
+const deliveryTraceMessage = "logger delivery trace"
+
+// DeliveryLatency summarizes the latencies measured for one stage of the
+// pipeline by delivery tracing.
+type DeliveryLatency struct {
+	// Count is the number of markers that completed the stage.
+	Count uint64
+
+	// Last is the latency of the most recent marker.
+	Last time.Duration
+
+	// Max is the highest latency seen.
+	Max time.Duration
+}
+
+// DeliveryStats reports the delivery latencies measured with
+// Config.DeliveryTraceInterval. All latencies are measured from the moment
+// a marker is injected.
+type DeliveryStats struct {
+	// Traces is the number of markers injected. Markers shed by a
+	// saturated async queue are injected but never delivered.
+	Traces uint64
+
+	// Queue is the time until the pipeline starts processing a marker,
+	// which is spent in the async queue when AsyncQueueSize is set.
+	Queue DeliveryLatency
+
+	// Sinks holds, in the order the sinks were added, the time until a
+	// marker has been written to the output of each sink, including any
+	// time spent in its buffer. Sinks that do not accept InfoLevel
+	// entries receive no markers. Nil when tracing is disabled.
+	Sinks []DeliveryLatency
+}
+
+// latencyRecorder accumulates a DeliveryLatency without locking.
+type latencyRecorder struct {
+	count atomic.Uint64
+	last  atomic.Int64
+	max   atomic.Int64
+}
+
+func (lr *latencyRecorder) observe(d time.Duration) {
+	lr.last.Store(int64(d))
+	for {
+		current := lr.max.Load()
+		if int64(d) <= current || lr.max.CompareAndSwap(current, int64(d)) {
+			break
+		}
+	}
+	lr.count.Add(1)
+}
+
+func (lr *latencyRecorder) snapshot() DeliveryLatency {
+	return DeliveryLatency{
+		Count: lr.count.Load(),
+		Last:  time.Duration(lr.last.Load()),
+		Max:   time.Duration(lr.max.Load()),
+	}
+}
+
+// deliveryTracer injects a marker entry every interval until the logger
+// is closed. It refers to the logger weakly, so that a logger dropped
+// without Close does not keep its goroutine running.
+type deliveryTracer struct {
+	traces atomic.Uint64
+	queue  latencyRecorder
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newDeliveryTracer(l *Logger, interval time.Duration) *deliveryTracer {
+	t := &deliveryTracer{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go t.run(weak.Make(l.core), interval)
+	return t
+}
+
+func (t *deliveryTracer) run(core weak.Pointer[core], interval time.Duration) {
+	defer close(t.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			c := core.Value()
+			if c == nil {
+				return
+			}
+			(&Logger{core: c}).injectTrace()
+		}
+	}
+}
+
+// close stops injecting markers.
+func (t *deliveryTracer) close() {
+	t.stopOnce.Do(func() { close(t.stop) })
+	<-t.done
+}
+
+// injectTrace sends a marker entry through the pipeline. Markers bypass
+// sampling, rate limiting and hooks, and are written to every sink that
+// accepts InfoLevel, so that each of them is measured.
+func (l *Logger) injectTrace() {
+	now := time.Now()
+	seq := l.tracer.traces.Add(1)
+
+	r := l.records.Get().(*Record)
+	r.Time = now
+	r.Level = InfoLevel
+	r.Message = deliveryTraceMessage
+	r.fields = append(r.fields[:0], Field{Key: DeliveryTraceKey, Value: int64(seq)})
+	r.Fields = r.fields
+	r.traced = now
+
+	if l.async != nil && !l.bypassAsync.Load() {
+		l.async.push(context.Background(), r)
+		return
+	}
+
+	l.process(r)
+	l.putRecord(r)
+}
+
+// processTrace writes a marker record to the sinks that accept it.
+func (l *Logger) processTrace(r *Record) {
+	l.tracer.queue.observe(time.Since(r.traced))
+	l.writeSinks(r)
+}
+
+// deliveryStats returns the delivery latencies of l.
+func (l *Logger) deliveryStats() DeliveryStats {
+	stats := DeliveryStats{
+		Traces: l.tracer.traces.Load(),
+		Queue:  l.tracer.queue.snapshot(),
+	}
+	sinks := *l.sinks.Load()
+	stats.Sinks = make([]DeliveryLatency, len(sinks))
+	for i, s := range sinks {
+		stats.Sinks[i] = s.delivery.snapshot()
+	}
+	return stats
+}
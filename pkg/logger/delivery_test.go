@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryTracing(t *testing.T) {
+	primary := &syncBuffer{}
+	errorsOnly := &syncBuffer{}
+	log := New(Config{
+		Level:                 InfoLevel,
+		Format:                JSONFormat,
+		Output:                primary,
+		Outputs:               []SinkConfig{{Output: errorsOnly, Level: ErrorLevel, Format: TextFormat}},
+		DeliveryTraceInterval: time.Millisecond,
+	})
+	defer log.Close()
+
+	require.Eventually(t, func() bool {
+		d := log.Stats().Delivery
+		return d.Traces >= 3 && d.Sinks[0].Count >= 3
+	}, time.Second, time.Millisecond)
+
+	stats := log.Stats().Delivery
+	assert.Len(t, stats.Sinks, 2)
+	assert.GreaterOrEqual(t, stats.Queue.Count, uint64(3))
+	assert.Positive(t, stats.Sinks[0].Last)
+	assert.GreaterOrEqual(t, stats.Sinks[0].Max, stats.Sinks[0].Last)
+	assert.Zero(t, stats.Sinks[1].Count)
+
+	assert.Contains(t, primary.String(), `"level":"INFO","message":"logger delivery trace","delivery_trace":1`)
+	assert.Empty(t, errorsOnly.String(), "markers respect the sink level")
+}
+
+func TestDeliveryTracing_StopsWithLogger(t *testing.T) {
+	log := New(Config{Level: InfoLevel, Output: &syncBuffer{}, DeliveryTraceInterval: time.Millisecond})
+	tracer := log.tracer
+	log = nil
+
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		select {
+		case <-tracer.done:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond, "an unreachable logger stops tracing")
+}
+
+func TestDeliveryTracing_BufferedAndAsync(t *testing.T) {
+	buf := &syncBuffer{}
+	log := New(Config{
+		Level:                 InfoLevel,
+		Format:                TextFormat,
+		Output:                buf,
+		BufferSize:            1 << 20,
+		AsyncQueueSize:        64,
+		DeliveryTraceInterval: time.Millisecond,
+	})
+
+	require.Eventually(t, func() bool { return log.Stats().Delivery.Queue.Count >= 2 }, time.Second, time.Millisecond)
+	assert.Zero(t, log.Stats().Delivery.Sinks[0].Count, "markers still sit in the buffer")
+
+	require.NoError(t, log.Close())
+	stats := log.Stats().Delivery
+	assert.Equal(t, uint64(1), stats.Sinks[0].Count, "one flush delivers the buffered markers")
+	assert.Positive(t, stats.Sinks[0].Last)
+
+	traces := stats.Traces
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, traces, log.Stats().Delivery.Traces, "Close stops tracing")
+}
+
+func TestDeliveryTracing_Disabled(t *testing.T) {
+	log := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}})
+	assert.Equal(t, DeliveryStats{}, log.Stats().Delivery)
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	var lr latencyRecorder
+	for _, d := range []time.Duration{3, 9, 4} {
+		lr.observe(d)
+	}
+	assert.Equal(t, DeliveryLatency{Count: 3, Last: 4, Max: 9}, lr.snapshot())
+}
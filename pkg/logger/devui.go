@@ -0,0 +1,97 @@
+package logger
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"path"
+)
+
+const (
+	// devUIHistory is the number of recent entries shown when the page is
+	// opened.
+	devUIHistory = 500
+
+	// devUIBuffer is the number of entries buffered per connected page.
+	devUIBuffer = 256
+)
+
+//go:embed devui.html
+var devUIPage []byte
+
+// DevUI is an output that shows entries live in a browser, replacing
+// terminal tailing during local development. It serves a single page with
+// level filters and a field search, fed by a StreamHandler.
+//
+// The page expects entries encoded with JSONFormat. Most programs use it
+// through Logger.DebugHandler rather than directly.
+type DevUI struct {
+	stream *StreamHandler
+}
+
+// NewDevUI creates a UI that keeps the last history entries for pages
+// opened later.
+func NewDevUI(history int) *DevUI {
+	return &DevUI{stream: NewStreamHandler(StreamConfig{
+		BufferSize: devUIBuffer,
+		History:    history,
+	})}
+}
+
+// Write publishes p as an informational entry.
+func (u *DevUI) Write(p []byte) (int, error) {
+	return u.stream.Write(p)
+}
+
+// WriteRecord implements RecordWriter.
+func (u *DevUI) WriteRecord(r *Record, entry []byte) error {
+	return u.stream.WriteRecord(r, entry)
+}
+
+// ServeHTTP serves the page at the handler root and the event stream at
+// "events", relative to it.
+func (u *DevUI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if path.Base(r.URL.Path) == "events" {
+		u.stream.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(devUIPage)
+}
+
+// DebugHandler returns an HTTP handler for inspecting the logger during
+// development. It serves:
+//
+//	/        a live log viewer with level filters and field search
+//	/events  the entries as a StreamHandler stream, with its filters
+//	/stats   Stats as JSON
+//
+// The first call adds a JSONFormat sink at Config.Level feeding the viewer.
+// Mount the handler on a trailing-slash path:
+//
+//	mux.Handle("/debug/logs/", http.StripPrefix("/debug/logs", log.DebugHandler()))
+//
+// The handler exposes every entry to whoever can reach it, so never serve
+// it on a public address.
+func (l *Logger) DebugHandler() http.Handler {
+	l.debugOnce.Do(func() {
+		ui := NewDevUI(devUIHistory)
+		l.AddSink(SinkConfig{
+			Output: ui,
+			Level:  l.config.Level,
+			Format: JSONFormat,
+		})
+
+		mux := http.NewServeMux()
+		mux.Handle("/events", ui)
+		mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(l.Stats())
+		})
+		mux.Handle("/", ui)
+		l.debugHandler = mux
+	})
+
+	return l.debugHandler
+}
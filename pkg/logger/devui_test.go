@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvents reads n server-sent events from r and returns their data.
+func readEvents(t *testing.T, r io.Reader, n int) []string {
+	t.Helper()
+
+	var events []string
+	sc := bufio.NewScanner(r)
+	for len(events) < n && sc.Scan() {
+		if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+			events = append(events, data)
+		}
+	}
+	require.NoError(t, sc.Err())
+	require.Len(t, events, n)
+	return events
+}
+
+func TestDevUI_ServesPage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewDevUI(0).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, rec.Body.String(), `new EventSource("events")`)
+}
+
+func TestDevUI_StreamsHistoryAndLiveEntries(t *testing.T) {
+	ui := NewDevUI(10)
+	log := New(Config{Level: DebugLevel, Format: JSONFormat, Output: ui})
+	log.Info("before connect", String("user", "ann"))
+
+	srv := httptest.NewServer(ui)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool { return ui.stream.Subscribers() == 1 }, time.Second, 5*time.Millisecond)
+	log.Error("after connect")
+
+	events := readEvents(t, resp.Body, 2)
+
+	var first map[string]any
+	require.NoError(t, json.Unmarshal([]byte(events[0]), &first))
+	assert.Equal(t, "before connect", first["message"])
+	assert.Equal(t, "ann", first["user"])
+	assert.Contains(t, events[1], `"level":"ERROR"`)
+
+	cancel()
+	require.Eventually(t, func() bool { return ui.stream.Subscribers() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestLogger_DebugHandler(t *testing.T) {
+	out := &strings.Builder{}
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: out})
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/logs/", http.StripPrefix("/debug/logs", log.DebugHandler()))
+	assert.Same(t, log.DebugHandler(), log.DebugHandler())
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	log.Debug("filtered")
+	log.Info("visible")
+	assert.Contains(t, out.String(), "INFO visible", "existing outputs keep their format")
+
+	resp, err := http.Get(srv.URL + "/debug/logs/")
+	require.NoError(t, err)
+	page, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "EventSource")
+
+	resp, err = http.Get(srv.URL + "/debug/logs/stats")
+	require.NoError(t, err)
+	var stats Stats
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	resp.Body.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/debug/logs/events", nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	events := readEvents(t, resp.Body, 1)
+	assert.Contains(t, events[0], `"message":"visible"`)
+}
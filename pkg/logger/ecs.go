@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ecsVersion is the ECS version declared by ECSFormat entries.
+const ecsVersion = "1.6.0"
+
+// ecsFieldNames maps common field keys to their ECS names. Keys not listed
+// are written unchanged.
+var ecsFieldNames = map[string]string{
+	"traceID":    "trace.id",
+	"trace_id":   "trace.id",
+	"spanID":     "span.id",
+	"span_id":    "span.id",
+	"error":      "error.message",
+	"err":        "error.message",
+	"stack":      "error.stack_trace",
+	"stacktrace": "error.stack_trace",
+}
+
+// appendECS formats a log entry as an Elastic Common Schema document that
+// Elasticsearch ingests without an ingest pipeline. The timestamp, level and
+// message use their ECS names regardless of Config.KeyMap, and trace, span
+// and error fields are renamed per ecsFieldNames. A field holding an error
+// value also gets its Go type as "error.type".
+//
+// Every key is written once: fields set explicitly, such as an "error.type"
+// field, take precedence over the keys derived from an error value, and
+// otherwise the first field with a key wins.
+func appendECS(buf []byte, r *Record) []byte {
+	buf = append(buf, `{"@timestamp":"`...)
+	buf = r.Time.UTC().AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, `","log.level":"`...)
+	buf = append(buf, ecsLevel(r.Level)...)
+	buf = append(buf, `","message":"`...)
+	buf = appendJSONString(buf, r.Message)
+	buf = append(buf, `","ecs.version":"`+ecsVersion+`"`...)
+
+	var explicitStack, writtenStack [16]string
+	explicit := explicitStack[:0]
+	for _, field := range r.Fields {
+		if _, ok := field.Value.(error); !ok {
+			explicit = append(explicit, ecsFieldName(field.Key))
+		}
+	}
+	written := writtenStack[:0]
+
+	for _, field := range r.Fields {
+		key := ecsFieldName(field.Key)
+
+		if err, ok := field.Value.(error); ok {
+			if key == field.Key {
+				key = "error.message"
+			}
+			if !slices.Contains(explicit, key) && !slices.Contains(written, key) {
+				written = append(written, key)
+				buf = append(buf, ',', '"')
+				buf = appendJSONString(buf, key)
+				buf = append(buf, `":"`...)
+				buf = appendJSONString(buf, err.Error())
+				buf = append(buf, '"')
+			}
+			if !slices.Contains(explicit, "error.type") && !slices.Contains(written, "error.type") {
+				written = append(written, "error.type")
+				buf = append(buf, `,"error.type":"`...)
+				buf = appendJSONString(buf, fmt.Sprintf("%T", err))
+				buf = append(buf, '"')
+			}
+			continue
+		}
+
+		if slices.Contains(written, key) {
+			continue
+		}
+		written = append(written, key)
+		buf = append(buf, ',', '"')
+		buf = appendJSONString(buf, key)
+		buf = append(buf, '"', ':')
+		buf = appendJSONValue(buf, field.Value)
+	}
+
+	return append(buf, '}')
+}
+
+// ecsFieldName returns the ECS name of a field key.
+func ecsFieldName(key string) string {
+	if name, ok := ecsFieldNames[key]; ok {
+		return name
+	}
+	return key
+}
+
+// ecsLevel returns the lowercase level name used for "log.level".
+func ecsLevel(level Level) string {
+	switch level {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	case PanicLevel:
+		return "panic"
+	default:
+		if c, ok := lookupCustomLevel(level); ok {
+			return c.lower
+		}
+		return "unknown"
+	}
+}
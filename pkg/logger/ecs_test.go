@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECSFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: DebugLevel, Format: ECSFormat, Output: buf})
+
+	ctx := context.WithValue(context.Background(), contextKey("traceID"), "4bf92f3577b34da6")
+	ctx = context.WithValue(ctx, contextKey("spanID"), "00f067aa0ba902b7")
+	log.WithContext(func() context.Context { return ctx }).Error("payment \"declined\"",
+		Err(errors.New("card expired")),
+		Field{Key: "stack", Value: "main.go:12"},
+		Field{Key: "orderID", Value: 42},
+	)
+
+	line := buf.String()
+	require.True(t, strings.HasPrefix(line, `{"@timestamp":"`))
+	require.True(t, strings.HasSuffix(line, "}\n"))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &doc))
+
+	ts, err := time.Parse(time.RFC3339Nano, doc["@timestamp"].(string))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), ts, 5*time.Second)
+	assert.Equal(t, "error", doc["log.level"])
+	assert.Equal(t, `payment "declined"`, doc["message"])
+	assert.Equal(t, ecsVersion, doc["ecs.version"])
+	assert.Equal(t, "4bf92f3577b34da6", doc["trace.id"])
+	assert.Equal(t, "00f067aa0ba902b7", doc["span.id"])
+	assert.Equal(t, "card expired", doc["error.message"])
+	assert.Equal(t, "main.go:12", doc["error.stack_trace"])
+	assert.Equal(t, float64(42), doc["orderID"])
+	assert.NotContains(t, doc, "traceID")
+	assert.NotContains(t, doc, "error")
+}
+
+func TestECSFormat_ErrorValue(t *testing.T) {
+	r := &Record{
+		Time:    time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC),
+		Level:   WarnLevel,
+		Message: "open failed",
+		Fields:  []Field{{Key: "cause", Value: fs.ErrNotExist}},
+	}
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(appendECS(nil, r), &doc))
+
+	assert.Equal(t, "2024-01-20T15:04:05Z", doc["@timestamp"])
+	assert.Equal(t, "warn", doc["log.level"])
+	assert.Equal(t, "file does not exist", doc["error.message"])
+	assert.Equal(t, "*errors.errorString", doc["error.type"])
+	assert.NotContains(t, doc, "cause")
+}
+
+func TestECSFormat_FromEnv(t *testing.T) {
+	t.Setenv(EnvLogFormat, "ECS")
+	assert.Equal(t, ECSFormat, ConfigFromEnv().Format)
+}
+
+func TestECSLevel(t *testing.T) {
+	for level, want := range map[Level]string{
+		DebugLevel: "debug",
+		InfoLevel:  "info",
+		WarnLevel:  "warn",
+		ErrorLevel: "error",
+		FatalLevel: "fatal",
+		PanicLevel: "panic",
+		Level(42):  "unknown",
+	} {
+		assert.Equal(t, want, ecsLevel(level))
+	}
+}
+
+func TestECSFormat_DeduplicatesErrorKeys(t *testing.T) {
+	r := &Record{
+		Time:    time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC),
+		Level:   ErrorLevel,
+		Message: "open failed",
+		Fields: []Field{
+			Err(fs.ErrNotExist),
+			{Key: "cause", Value: fs.ErrPermission},
+			{Key: "error.type", Value: "NotFound"},
+			{Key: "orderID", Value: 1},
+			{Key: "orderID", Value: 2},
+		},
+	}
+
+	line := string(appendECS(nil, r))
+	assert.Equal(t, 1, strings.Count(line, `"error.message":`), line)
+	assert.Equal(t, 1, strings.Count(line, `"error.type":`), line)
+	assert.Equal(t, 1, strings.Count(line, `"orderID":`), line)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &doc))
+	assert.Equal(t, "file does not exist", doc["error.message"])
+	assert.Equal(t, "NotFound", doc["error.type"])
+	assert.Equal(t, float64(1), doc["orderID"])
+}
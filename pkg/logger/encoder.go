@@ -0,0 +1,53 @@
+package logger
+
+import "reflect"
+
+// Encoder encodes records for a sink whose SinkConfig.Encoder is set,
+// replacing the built-in formats. This allows per-sink layouts such as a
+// binary encoding for a socket next to text on stdout.
+//
+// Encode appends the complete entry to buf, including any delimiter such
+// as a trailing newline, and returns the extended buffer. The record is
+// only valid for the duration of the call and must not be retained.
+// Encoders must be safe for concurrent use.
+//
+// Every entry is encoded once per encoder, not once per sink: sinks sharing
+// an encoder share its output. Encoders are told apart with ==, so use
+// pointer types to share state between sinks.
+type Encoder interface {
+	Encode(buf []byte, r *Record) []byte
+}
+
+// EncoderFunc adapts an ordinary function to the Encoder interface. An
+// EncoderFunc cannot be compared, so its output is not shared between
+// sinks.
+type EncoderFunc func(buf []byte, r *Record) []byte
+
+// Encode calls f(buf, r).
+func (f EncoderFunc) Encode(buf []byte, r *Record) []byte {
+	return f(buf, r)
+}
+
+// encodingKey identifies the encoding of a sink in the per-record
+// encodings cache.
+type encodingKey struct {
+	format     Format
+	color      bool
+	singleLine bool
+	keyMap     *keyMap
+
+	// encoder is the sink's Encoder, or the sink itself when the encoder
+	// cannot be compared. It is nil for built-in formats.
+	encoder any
+}
+
+// newEncodingKey returns the cache key of the sink s.
+func newEncodingKey(s *sink) encodingKey {
+	if s.encoder == nil {
+		return encodingKey{format: s.format, color: s.color, singleLine: isLineOutput(s.output), keyMap: s.keyMap}
+	}
+	if reflect.TypeOf(s.encoder).Comparable() {
+		return encodingKey{encoder: s.encoder, keyMap: s.keyMap}
+	}
+	return encodingKey{encoder: s}
+}
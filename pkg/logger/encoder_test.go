@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lengthPrefixEncoder frames the message with a big-endian uint16 length,
+// standing in for a binary encoding such as msgpack.
+type lengthPrefixEncoder struct {
+	calls atomic.Int32
+}
+
+func (e *lengthPrefixEncoder) Encode(buf []byte, r *Record) []byte {
+	e.calls.Add(1)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(r.Message)))
+	return append(buf, r.Message...)
+}
+
+func TestSinkEncoder_PerSinkEncoders(t *testing.T) {
+	console := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	socket := &bytes.Buffer{}
+	packed := &lengthPrefixEncoder{}
+
+	log := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: console,
+		Outputs: []SinkConfig{
+			{Output: file, Level: InfoLevel, Format: ECSFormat},
+			{Output: socket, Level: InfoLevel, Format: JSONFormat, Encoder: packed},
+		},
+	})
+	log.Info("hi", Int("n", 1))
+
+	assert.Contains(t, console.String(), "INFO hi n=1\n")
+	assert.Contains(t, file.String(), `"log.level":"info"`)
+	assert.Equal(t, []byte{0, 2, 'h', 'i'}, socket.Bytes(), "the encoder replaces the format")
+}
+
+func TestSinkEncoder_SharedEncoderEncodedOnce(t *testing.T) {
+	first := &bytes.Buffer{}
+	second := &bytes.Buffer{}
+	enc := &lengthPrefixEncoder{}
+
+	log := New(Config{
+		Level: InfoLevel,
+		Outputs: []SinkConfig{
+			{Output: first, Level: InfoLevel, Encoder: enc},
+			{Output: second, Level: InfoLevel, Encoder: enc},
+			{Output: &bytes.Buffer{}, Level: InfoLevel, Encoder: &lengthPrefixEncoder{}},
+		},
+	})
+	log.Info("once")
+
+	assert.Equal(t, int32(1), enc.calls.Load())
+	assert.Equal(t, first.String(), second.String())
+}
+
+func TestSinkEncoder_EncoderFunc(t *testing.T) {
+	var calls int
+	upper := EncoderFunc(func(buf []byte, r *Record) []byte {
+		calls++
+		buf = append(buf, bytes.ToUpper([]byte(r.Message))...)
+		return append(buf, '\n')
+	})
+
+	first := &bytes.Buffer{}
+	second := &bytes.Buffer{}
+	log := New(Config{
+		Level: InfoLevel,
+		Outputs: []SinkConfig{
+			{Output: first, Level: InfoLevel, Encoder: upper},
+			{Output: second, Level: InfoLevel, Encoder: upper},
+		},
+	})
+
+	require.NotPanics(t, func() { log.Info("shout") })
+	assert.Equal(t, "SHOUT\n", first.String())
+	assert.Equal(t, "SHOUT\n", second.String())
+	assert.Equal(t, 2, calls, "functions are not comparable, so nothing is shared")
+}
+
+func TestSinkEncoder_RawBypassesEncoder(t *testing.T) {
+	out := &bytes.Buffer{}
+	enc := &lengthPrefixEncoder{}
+	log := New(Config{
+		Level:   InfoLevel,
+		Outputs: []SinkConfig{{Output: out, Level: InfoLevel, Encoder: enc}},
+	})
+
+	require.NoError(t, log.WriteRaw(InfoLevel, []byte(`{"pre":"encoded"}`)))
+	assert.Equal(t, "{\"pre\":\"encoded\"}\n", out.String())
+	assert.Zero(t, enc.calls.Load())
+}
@@ -0,0 +1,18 @@
+package logger
+
+// addErrorFields appends the fields returned by onError to r. The record
+// may still reference the caller's slice, so r.Fields is copied into the
+// record first unless it already is.
+func addErrorFields(onError func(r *Record) []Field, r *Record) {
+	extra := onError(r)
+	if len(extra) == 0 {
+		return
+	}
+
+	owned := len(r.Fields) > 0 && len(r.fields) > 0 && &r.fields[0] == &r.Fields[0]
+	if !owned {
+		r.fields = append(r.fields[:0], r.Fields...)
+	}
+	r.fields = append(r.fields[:len(r.Fields)], extra...)
+	r.Fields = r.fields
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigOnError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	calls := 0
+	log := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: buf,
+		OnError: func(r *Record) []Field {
+			calls++
+			return []Field{{Key: "pool_in_use", Value: 7}, {Key: "breaker", Value: "open"}}
+		},
+	})
+
+	fields := []Field{{Key: "attempt", Value: 3}}
+	log.Info("retrying", fields...)
+	log.Warn("slow query")
+	assert.Zero(t, calls, "not called below ErrorLevel")
+
+	log.Error("query failed", fields...)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []Field{{Key: "attempt", Value: 3}}, fields, "the caller's slice is not modified")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.NotContains(t, lines[0], "pool_in_use")
+	assert.Contains(t, lines[2], "ERROR query failed attempt=3 pool_in_use=7 breaker=open")
+}
+
+func TestConfigOnError_WithContextAndHooks(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: buf,
+		OnError: func(r *Record) []Field {
+			if r.Message == "quiet" {
+				return nil
+			}
+			return []Field{{Key: "goroutines", Value: 12}}
+		},
+		RedactKeys: []string{"goroutines"},
+	})
+	var seen []Field
+	log.AddHook(HookFunc(func(r *Record) error {
+		seen = append(seen[:0], r.Fields...)
+		return nil
+	}))
+
+	log.With(Field{Key: "service", Value: "db"}).Error("down")
+	assert.Equal(t, []Field{{Key: "service", Value: "db"}, {Key: "goroutines", Value: 12}}, seen, "hooks see the fields")
+	assert.Contains(t, buf.String(), "ERROR down service=db goroutines="+RedactedValue)
+
+	log.Error("quiet")
+	assert.Empty(t, seen)
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// EntryHashKey is the key of the field added by Config.EntryHash.
+const EntryHashKey = "entry_hash"
+
+// entryHashBytes is the number of SHA-256 bytes in an entry hash. 128 bits
+// keep accidental collisions out of reach for any realistic log volume.
+const entryHashBytes = 16
+
+// addEntryHash appends the EntryHashKey field to r. The hash covers the
+// level, the message and the fields sorted by key, each value encoded as in
+// JSON so that 1 and "1" differ. The timestamp is excluded, so an entry
+// retried by an at-least-once pipeline hashes the same. r.Fields must be
+// owned by the record.
+func addEntryHash(r *Record) {
+	var stack [512]byte
+	buf := append(stack[:0], r.Level.String()...)
+	buf = append(buf, 0)
+	buf = appendJSONString(buf, r.Message)
+
+	var scratch [16]int
+	order := scratch[:0]
+	for i := range r.Fields {
+		order = append(order, i)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return strings.Compare(r.Fields[a].Key, r.Fields[b].Key)
+	})
+
+	for _, i := range order {
+		buf = append(buf, 0)
+		buf = appendJSONString(buf, r.Fields[i].Key)
+		buf = append(buf, 0)
+		buf = appendJSONValue(buf, r.Fields[i].Value)
+	}
+	sum := sha256.Sum256(buf)
+	r.Fields = append(r.Fields, Field{Key: EntryHashKey, Value: hex.EncodeToString(sum[:entryHashBytes])})
+}
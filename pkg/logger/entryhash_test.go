@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entryHash(t *testing.T, config Config, log func(l *Logger)) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	config.Output = buf
+	config.Format = JSONFormat
+	config.EntryHash = true
+	log(New(config))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	hash, _ := entry[EntryHashKey].(string)
+	require.Len(t, hash, 32)
+	return hash
+}
+
+func TestEntryHash_Stable(t *testing.T) {
+	base := entryHash(t, Config{}, func(l *Logger) {
+		l.Info("paid", F("order", 1), F("amount", 9.5))
+	})
+
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, base, entryHash(t, Config{}, func(l *Logger) {
+		l.Info("paid", F("amount", 9.5), F("order", 1))
+	}), "timestamp and field order do not matter")
+
+	assert.Equal(t, base, entryHash(t, Config{KeyMap: map[string]string{"order": "orderID"}}, func(l *Logger) {
+		l.Info("paid", F("order", 1), F("amount", 9.5))
+	}), "renamed keys do not matter")
+
+	for name, log := range map[string]func(l *Logger){
+		"level":   func(l *Logger) { l.Warn("paid", F("order", 1), F("amount", 9.5)) },
+		"message": func(l *Logger) { l.Info("Paid", F("order", 1), F("amount", 9.5)) },
+		"value":   func(l *Logger) { l.Info("paid", F("order", 2), F("amount", 9.5)) },
+		"type":    func(l *Logger) { l.Info("paid", F("order", "1"), F("amount", 9.5)) },
+		"extra":   func(l *Logger) { l.Info("paid", F("order", 1), F("amount", 9.5), F("retry", true)) },
+	} {
+		assert.NotEqual(t, base, entryHash(t, Config{}, log), name)
+	}
+}
+
+func TestEntryHash_ManyFields(t *testing.T) {
+	fields := make([]Field, 40)
+	for i := range fields {
+		fields[i] = F(strings.Repeat("k", i+1), strings.Repeat("v", 100))
+	}
+	reversed := make([]Field, len(fields))
+	for i := range fields {
+		reversed[i] = fields[len(fields)-1-i]
+	}
+
+	assert.Equal(t,
+		entryHash(t, Config{}, func(l *Logger) { l.Info("large", fields...) }),
+		entryHash(t, Config{}, func(l *Logger) { l.Info("large", reversed...) }))
+}
+
+func TestEntryHash_KeyMap(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Format: TextFormat, Output: buf, EntryHash: true, KeyMap: map[string]string{EntryHashKey: "dedup_id"}})
+
+	logger.Info("renamed")
+	assert.Regexp(t, `INFO renamed dedup_id=[0-9a-f]{32}\n$`, buf.String())
+}
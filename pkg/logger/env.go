@@ -1,9 +1,13 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -18,8 +22,27 @@ const (
 	EnvPanicLevel    = "panic"
 	EnvLogFormatJSON = "json"
 	EnvLogFormatText = "text"
+	EnvLogFormatECS  = "ecs"
+	EnvLogFormatGCP  = "gcp"
+	EnvLogFormatDD   = "datadog"
 )
 
+// Variables read by NewFromEnv in addition to the ones above.
+const (
+	EnvLogOutput        = "LOG_OUTPUT"
+	EnvLogFlushInterval = "LOG_FLUSH_INTERVAL"
+	EnvLogCaller        = "LOG_CALLER"
+	EnvLogSampling      = "LOG_SAMPLING"
+	EnvLogProfile       = "LOG_PROFILE"
+
+	// EnvCloudProject is set to the project ID by Google Cloud runtimes.
+	// ConfigFromEnv and NewFromEnv read it into Config.CloudProjectID.
+	EnvCloudProject = "GOOGLE_CLOUD_PROJECT"
+)
+
+// envFilePrefix prefixes the path of a file given as LOG_OUTPUT.
+const envFilePrefix = "file:"
+
 func fromEnvLogLevel() Level {
 	var envLevel string
 	envLevel = os.Getenv(EnvLogLevel)
@@ -38,6 +61,9 @@ func fromEnvLogLevel() Level {
 	case EnvPanicLevel:
 		return PanicLevel
 	default:
+		if level, ok := levelFromName(envLevel); ok {
+			return level
+		}
 		return DebugLevel
 	}
 }
@@ -66,6 +92,12 @@ func fromEnvLogFormat() Format {
 		return JSONFormat
 	case EnvLogFormatText:
 		return TextFormat
+	case EnvLogFormatECS:
+		return ECSFormat
+	case EnvLogFormatGCP:
+		return CloudLoggingFormat
+	case EnvLogFormatDD:
+		return DatadogFormat
 	default:
 		return TextFormat
 	}
@@ -73,9 +105,148 @@ func fromEnvLogFormat() Format {
 
 func ConfigFromEnv() Config {
 	return Config{
-		Level:      fromEnvLogLevel(),
-		Format:     fromEnvLogFormat(),
-		BufferSize: fromEnvBufferSize(),
-		Output:     os.Stdout,
+		Level:          fromEnvLogLevel(),
+		Format:         fromEnvLogFormat(),
+		BufferSize:     fromEnvBufferSize(),
+		Output:         os.Stdout,
+		CloudProjectID: os.Getenv(EnvCloudProject),
+	}
+}
+
+// NewFromEnv creates a logger configured by environment variables, so
+// containers can be reconfigured without code changes. Unlike
+// ConfigFromEnv it rejects malformed values instead of ignoring them:
+//
+//	LOG_LEVEL             debug, info, warn, error, fatal, panic or a
+//	                      registered level; defaults to info
+//	LOG_FORMAT            text, json, gelf, ecs, gcp, datadog or console;
+//	                      defaults to text
+//	LOG_OUTPUT            stdout, stderr or file:/path/to/file, opened for
+//	                      appending; defaults to stdout
+//	LOG_BUFFER_SIZE       Config.BufferSize in bytes
+//	LOG_FLUSH_INTERVAL    Config.FlushInterval, e.g. 500ms
+//	LOG_CALLER            true to set Config.AddCaller
+//	LOG_SAMPLING          Config.Sampling as comma-separated settings, e.g.
+//	                      first=100,thereafter=10,window=1m
+//	LOG_PROFILE           Config.Profile, e.g. throughput
+//	GOOGLE_CLOUD_PROJECT  Config.CloudProjectID
+//
+// All problems are reported at once. A file output stays open for the
+// lifetime of the process.
+func NewFromEnv() (*Logger, error) {
+	config, err := configFromEnv(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	l, err := NewE(config)
+	if err != nil {
+		if c, ok := config.Output.(io.Closer); ok && config.Output != os.Stdout && config.Output != os.Stderr {
+			_ = c.Close()
+		}
+		return nil, err
+	}
+	return l, nil
+}
+
+// configFromEnv builds the configuration of NewFromEnv from the variables
+// returned by getenv.
+func configFromEnv(getenv func(string) string) (Config, error) {
+	config := Config{Level: InfoLevel, Output: os.Stdout}
+	var errs []error
+	invalid := func(name, value string, reason any) {
+		errs = append(errs, fmt.Errorf("logger: invalid %s %q: %v", name, value, reason))
+	}
+
+	if v := getenv(EnvLogLevel); v != "" {
+		level, err := ParseLevel(v)
+		if err != nil {
+			invalid(EnvLogLevel, v, "unknown level")
+		}
+		config.Level = level
+	}
+	if v := getenv(EnvLogFormat); v != "" {
+		format, ok := formatNames[strings.ToLower(v)]
+		if !ok {
+			invalid(EnvLogFormat, v, "unknown format")
+		}
+		config.Format = format
+	}
+	if v := getenv(EnvLogBufferSize); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 0 {
+			invalid(EnvLogBufferSize, v, "not a size in bytes")
+		}
+		config.BufferSize = size
+	}
+	if v := getenv(EnvLogFlushInterval); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			invalid(EnvLogFlushInterval, v, "not a duration")
+		}
+		config.FlushInterval = interval
+	}
+	if v := getenv(EnvLogCaller); v != "" {
+		caller, err := strconv.ParseBool(v)
+		if err != nil {
+			invalid(EnvLogCaller, v, "not a boolean")
+		}
+		config.AddCaller = caller
+	}
+	if v := getenv(EnvLogSampling); v != "" {
+		sampling, err := parseEnvSampling(v)
+		if err != nil {
+			invalid(EnvLogSampling, v, err)
+		}
+		config.Sampling = sampling
+	}
+	config.Profile = Profile(getenv(EnvLogProfile))
+	config.CloudProjectID = getenv(EnvCloudProject)
+
+	// The output is opened last, so that a file is not left open when
+	// another variable is invalid.
+	if len(errs) > 0 {
+		return Config{}, errors.Join(errs...)
+	}
+	switch v := getenv(EnvLogOutput); {
+	case v == "" || strings.EqualFold(v, "stdout"):
+	case strings.EqualFold(v, "stderr"):
+		config.Output = os.Stderr
+	case strings.HasPrefix(v, envFilePrefix) && len(v) > len(envFilePrefix):
+		f, err := os.OpenFile(v[len(envFilePrefix):], os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return Config{}, fmt.Errorf("logger: %s: %w", EnvLogOutput, err)
+		}
+		config.Output = f
+	default:
+		return Config{}, fmt.Errorf("logger: invalid %s %q: want stdout, stderr or file:<path>", EnvLogOutput, v)
+	}
+
+	return config, nil
+}
+
+// parseEnvSampling parses LOG_SAMPLING settings such as
+// "first=100,thereafter=10,window=1m".
+func parseEnvSampling(s string) (*SamplingConfig, error) {
+	sampling := &SamplingConfig{}
+	for _, setting := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(setting), "=")
+		if !ok {
+			return nil, fmt.Errorf("setting %q is not key=value", setting)
+		}
+		var err error
+		switch key {
+		case "first":
+			sampling.First, err = strconv.Atoi(value)
+		case "thereafter":
+			sampling.Thereafter, err = strconv.Atoi(value)
+		case "window":
+			sampling.Window, err = time.ParseDuration(value)
+		default:
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("setting %q: %w", key, err)
+		}
 	}
+	return sampling, nil
 }
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv(EnvLogLevel, "warn")
+	t.Setenv(EnvLogFormat, "JSON")
+	t.Setenv(EnvLogOutput, "file:"+path)
+	t.Setenv(EnvLogBufferSize, "4096")
+	t.Setenv(EnvLogFlushInterval, "250ms")
+	t.Setenv(EnvLogCaller, "true")
+	t.Setenv(EnvLogSampling, "first=2, thereafter=0, window=1m")
+	t.Setenv(EnvLogProfile, "")
+	t.Setenv(EnvCloudProject, "my-project")
+
+	log, err := NewFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, log.config.Level)
+	assert.Equal(t, 4096, log.config.BufferSize)
+	assert.Equal(t, 250*time.Millisecond, log.config.FlushInterval)
+	assert.Equal(t, &SamplingConfig{First: 2, Window: time.Minute}, log.config.Sampling)
+	assert.Equal(t, "my-project", log.config.CloudProjectID)
+
+	log.Info("dropped")
+	for range 3 {
+		log.Warn("disk low")
+	}
+	require.NoError(t, log.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3, "warnings are not sampled")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "disk low", entry["message"])
+	assert.Contains(t, entry[CallerKey], "logger/env_test.go:")
+}
+
+func TestNewFromEnv_Defaults(t *testing.T) {
+	for _, name := range []string{EnvLogLevel, EnvLogFormat, EnvLogOutput, EnvLogBufferSize,
+		EnvLogFlushInterval, EnvLogCaller, EnvLogSampling, EnvLogProfile, EnvCloudProject} {
+		t.Setenv(name, "")
+	}
+
+	config, err := configFromEnv(os.Getenv)
+	require.NoError(t, err)
+	assert.Equal(t, Config{Level: InfoLevel, Format: TextFormat, Output: os.Stdout}, config)
+
+	t.Setenv(EnvLogOutput, "stderr")
+	config, err = configFromEnv(os.Getenv)
+	require.NoError(t, err)
+	assert.Equal(t, os.Stderr, config.Output)
+}
+
+func TestNewFromEnv_Errors(t *testing.T) {
+	env := map[string]string{
+		EnvLogLevel:         "loud",
+		EnvLogFormat:        "xml",
+		EnvLogBufferSize:    "-1",
+		EnvLogFlushInterval: "soon",
+		EnvLogCaller:        "maybe",
+		EnvLogSampling:      "first=1,every=2",
+	}
+	_, err := configFromEnv(func(name string) string { return env[name] })
+	require.Error(t, err)
+	for name := range env {
+		assert.ErrorContains(t, err, name)
+	}
+
+	for _, output := range []string{"kafka", "file:", "file:" + filepath.Join(t.TempDir(), "missing", "app.log")} {
+		_, err := configFromEnv(func(name string) string {
+			if name == EnvLogOutput {
+				return output
+			}
+			return ""
+		})
+		assert.ErrorContains(t, err, EnvLogOutput, output)
+	}
+
+	t.Setenv(EnvLogProfile, "turbo")
+	_, err = NewFromEnv()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
@@ -0,0 +1,39 @@
+package logger
+
+import "errors"
+
+// ErrEventLogUnsupported is returned by NewEventLogWriter on platforms
+// other than Windows.
+var ErrEventLogUnsupported = errors.New("logger: the Windows Event Log is only supported on windows")
+
+// Windows event types as used by ReportEvent.
+const (
+	eventLogError       = 0x0001
+	eventLogWarning     = 0x0002
+	eventLogInformation = 0x0004
+)
+
+// EventLogConfig configures an EventLogWriter.
+type EventLogConfig struct {
+	// Source is the event source name entries are reported under, usually
+	// the service name. Required. Register it once at install time, e.g.
+	// with New-EventLog, so Event Viewer can render the entries.
+	Source string
+
+	// EventID is reported with every entry. Defaults to 1.
+	EventID uint32
+}
+
+// eventLogType maps a level to a Windows event type. DEBUG and INFO are
+// informational, WARN is a warning and more severe levels are errors.
+// Custom levels take the type of the closest predefined level below.
+func eventLogType(level Level) uint16 {
+	switch {
+	case level < WarnLevel:
+		return eventLogInformation
+	case level < ErrorLevel:
+		return eventLogWarning
+	default:
+		return eventLogError
+	}
+}
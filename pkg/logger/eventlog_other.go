@@ -0,0 +1,26 @@
+//go:build !windows
+
+package logger
+
+// EventLogWriter is not available on this platform.
+type EventLogWriter struct{}
+
+// NewEventLogWriter returns ErrEventLogUnsupported on this platform.
+func NewEventLogWriter(EventLogConfig) (*EventLogWriter, error) {
+	return nil, ErrEventLogUnsupported
+}
+
+// Write returns ErrEventLogUnsupported.
+func (w *EventLogWriter) Write([]byte) (int, error) {
+	return 0, ErrEventLogUnsupported
+}
+
+// WriteRecord returns ErrEventLogUnsupported.
+func (w *EventLogWriter) WriteRecord(*Record, []byte) error {
+	return ErrEventLogUnsupported
+}
+
+// Close does nothing.
+func (w *EventLogWriter) Close() error {
+	return nil
+}
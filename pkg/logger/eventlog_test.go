@@ -0,0 +1,16 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventLogType(t *testing.T) {
+	assert.Equal(t, uint16(eventLogInformation), eventLogType(DebugLevel))
+	assert.Equal(t, uint16(eventLogInformation), eventLogType(InfoLevel))
+	assert.Equal(t, uint16(eventLogWarning), eventLogType(WarnLevel))
+	assert.Equal(t, uint16(eventLogError), eventLogType(ErrorLevel))
+	assert.Equal(t, uint16(eventLogError), eventLogType(FatalLevel))
+	assert.Equal(t, uint16(eventLogError), eventLogType(PanicLevel))
+}
@@ -0,0 +1,123 @@
+//go:build windows
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSourceW  = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEventW          = advapi32.NewProc("ReportEventW")
+)
+
+// EventLogWriter reports log entries to the Windows Event Log. The entry
+// level selects the event type: DEBUG and INFO are reported as
+// information, WARN as a warning and ERROR, FATAL and PANIC as errors. The
+// event text is the entry encoded in the sink format.
+type EventLogWriter struct {
+	eventID uint32
+
+	mu     sync.Mutex
+	handle syscall.Handle
+}
+
+// NewEventLogWriter opens the event source named by cfg.Source on the
+// local machine.
+//
+// Example:
+//
+//	w, err := logger.NewEventLogWriter(logger.EventLogConfig{Source: "BillingService"})
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Close()
+//
+//	log := logger.New(logger.Config{Level: logger.InfoLevel, Output: w})
+func NewEventLogWriter(cfg EventLogConfig) (*EventLogWriter, error) {
+	if cfg.Source == "" {
+		return nil, errors.New("logger: EventLogConfig.Source is required")
+	}
+	if cfg.EventID == 0 {
+		cfg.EventID = 1
+	}
+
+	source, err := syscall.UTF16PtrFromString(cfg.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, _, err := procRegisterEventSourceW.Call(0, uintptr(unsafe.Pointer(source)))
+	if handle == 0 {
+		return nil, err
+	}
+
+	return &EventLogWriter{eventID: cfg.EventID, handle: syscall.Handle(handle)}, nil
+}
+
+// Write reports p as an informational event. It allows an EventLogWriter
+// to be used as a plain io.Writer.
+func (w *EventLogWriter) Write(p []byte) (int, error) {
+	if err := w.report(eventLogInformation, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteRecord implements RecordWriter.
+func (w *EventLogWriter) WriteRecord(r *Record, entry []byte) error {
+	return w.report(eventLogType(r.Level), entry)
+}
+
+// Close deregisters the event source.
+func (w *EventLogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.handle == 0 {
+		return nil
+	}
+	ok, _, err := procDeregisterEventSource.Call(uintptr(w.handle))
+	w.handle = 0
+	if ok == 0 {
+		return err
+	}
+	return nil
+}
+
+func (w *EventLogWriter) report(eventType uint16, entry []byte) error {
+	msg, err := syscall.UTF16PtrFromString(string(bytes.TrimRight(entry, "\r\n")))
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.handle == 0 {
+		return syscall.EINVAL
+	}
+
+	inserts := [1]*uint16{msg}
+	ok, _, err := procReportEventW.Call(
+		uintptr(w.handle),
+		uintptr(eventType),
+		0, // category
+		uintptr(w.eventID),
+		0, // user SID
+		1, // number of strings
+		0, // raw data size
+		uintptr(unsafe.Pointer(&inserts[0])),
+		0, // raw data
+	)
+	if ok == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLogWriter(t *testing.T) {
+	w, err := NewEventLogWriter(EventLogConfig{Source: "go-logslib-test"})
+	require.NoError(t, err)
+
+	logger := New(Config{Level: InfoLevel, Output: w})
+	logger.Warn("event log test entry", Field{Key: "n", Value: 1})
+
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Close())
+}
+
+func TestNewEventLogWriter_RequiresSource(t *testing.T) {
+	_, err := NewEventLogWriter(EventLogConfig{})
+	assert.Error(t, err)
+}
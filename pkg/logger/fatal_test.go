@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_FatalExit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var calls []string
+	log := New(Config{
+		Level:          InfoLevel,
+		Output:         buf,
+		BufferSize:     4096,
+		AsyncQueueSize: 16,
+		OnFatal: []func(){
+			func() { calls = append(calls, "first") },
+			func() { panic("broken hook") },
+			func() { calls = append(calls, "third") },
+		},
+		ExitFunc: func(code int) {
+			calls = append(calls, "exit")
+			assert.Equal(t, 1, code)
+		},
+	})
+	defer log.Close()
+
+	log.Fatal("shutting down")
+	assert.Equal(t, []string{"first", "third", "exit"}, calls)
+	assert.Contains(t, buf.String(), "FATAL shutting down", "buffered and queued entries are flushed before exiting")
+
+	calls = nil
+	log.Fatalf("code %d", 3)
+	log.Fatalw("kv", "k", "v")
+	log.WithContext(context.Background).Fatal("ctx")
+	assert.Equal(t, []string{"first", "third", "exit", "first", "third", "exit", "first", "third", "exit"}, calls)
+}
+
+func TestLogger_PanicFlushes(t *testing.T) {
+	buf := &syncBuffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, BufferSize: 4096, AsyncQueueSize: 16})
+	defer log.Close()
+
+	panics := map[string]func(){
+		"Panic":        func() { log.Panic("Panic") },
+		"Panicf":       func() { log.Panicf("%s", "Panicf") },
+		"Panicw":       func() { log.Panicw("Panicw") },
+		"ContextPanic": func() { log.WithStaticContext(context.Background()).Panic("ContextPanic") },
+		"Check":        func() { log.Check(PanicLevel, "Check").Write() },
+		"Template":     func() { log.Template("Template").Log(PanicLevel) },
+	}
+	for msg, fn := range panics {
+		assert.PanicsWithValue(t, msg, fn)
+		assert.Contains(t, buf.String(), "PANIC "+msg, "buffered and queued entries are flushed before panicking")
+	}
+}
+
+func TestWithoutExit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ran := false
+	log, err := NewWithOptions(WithOutput(buf), WithoutExit(), WithOnFatal(func() { ran = true }))
+	require.NoError(t, err)
+
+	log.Fatal("not exiting")
+	assert.True(t, ran)
+	assert.Contains(t, buf.String(), "FATAL not exiting")
+}
@@ -0,0 +1,244 @@
+package logger
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// String returns a field with a string value.
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int returns a field with an int value.
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 returns a field with an int64 value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int8 returns a field with an int8 value, stored as int64.
+func Int8(key string, value int8) Field {
+	return Field{Key: key, Value: int64(value)}
+}
+
+// Int16 returns a field with an int16 value, stored as int64.
+func Int16(key string, value int16) Field {
+	return Field{Key: key, Value: int64(value)}
+}
+
+// Int32 returns a field with an int32 value, stored as int64.
+func Int32(key string, value int32) Field {
+	return Field{Key: key, Value: int64(value)}
+}
+
+// Uint returns a field with a uint value, e.g. a size.
+func Uint(key string, value uint) Field {
+	return Field{Key: key, Value: unsignedValue(uint64(value))}
+}
+
+// Uint8 returns a field with a uint8 value, stored as int64.
+func Uint8(key string, value uint8) Field {
+	return Field{Key: key, Value: int64(value)}
+}
+
+// Uint16 returns a field with a uint16 value, e.g. a port, stored as int64.
+func Uint16(key string, value uint16) Field {
+	return Field{Key: key, Value: int64(value)}
+}
+
+// Uint32 returns a field with a uint32 value, stored as int64.
+func Uint32(key string, value uint32) Field {
+	return Field{Key: key, Value: int64(value)}
+}
+
+// Uint64 returns a field with a uint64 value, e.g. a counter. Values up to
+// math.MaxInt64 are stored as int64, larger ones as uint64, so every value
+// is written exactly.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: unsignedValue(value)}
+}
+
+// Uintptr returns a field with a uintptr value, stored like Uint64.
+func Uintptr(key string, value uintptr) Field {
+	return Field{Key: key, Value: unsignedValue(uint64(value))}
+}
+
+// Float32 returns a field with a float32 value, stored as the float64 with
+// the same shortest decimal representation, so 0.1 is written as 0.1.
+func Float32(key string, value float32) Field {
+	return Field{Key: key, Value: float32Value(value)}
+}
+
+// Float64 returns a field with a float64 value.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool returns a field with a bool value.
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err returns an "error" field holding err.Error(), or nil for a nil error.
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error", Value: nil}
+	}
+	return Field{Key: "error", Value: err.Error()}
+}
+
+// F returns a field for any value, keeping call sites terse:
+//
+//	logger.Info("request", logger.F("status", 200), logger.F("path", r.URL.Path))
+//
+// Values of the natively encoded types (string, int, int64, float64, bool,
+// time.Time) are stored as-is. Other numeric types, including named types
+// such as `type TenantID int64`, are converted like the typed constructors
+// such as Uint64 and Float32 do, and named strings and bools to string or
+// bool, and errors, fmt.Stringers and encoding.TextMarshalers are rendered
+// to strings, so they do not encode as "unknown"; a String or MarshalText
+// method that panics renders as "!PANIC: <value>", and long renderings are
+// truncated. A LogValuer is resolved when the entry is emitted.
+//
+// For the predeclared types F costs the same as the typed constructor, e.g.
+// F("n", uint64(n)) is Uint64("n", n): the value is stored in the field
+// without first being boxed into an interface. Named types and the other
+// conversions go through an interface and cost one more allocation.
+func F[T any](key string, value T) Field {
+	switch v := any(value).(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case int32:
+		return Int32(key, v)
+	case int16:
+		return Int16(key, v)
+	case int8:
+		return Int8(key, v)
+	case uint:
+		return Uint(key, v)
+	case uint64:
+		return Uint64(key, v)
+	case uint32:
+		return Uint32(key, v)
+	case uint16:
+		return Uint16(key, v)
+	case uint8:
+		return Uint8(key, v)
+	case uintptr:
+		return Uintptr(key, v)
+	case float64:
+		return Float64(key, v)
+	case float32:
+		return Float32(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Time:
+		return Field{Key: key, Value: v}
+	}
+	return Field{Key: key, Value: fieldValue(value)}
+}
+
+// fieldValue converts v into a value the encoders understand.
+func fieldValue(v any) any {
+	switch x := v.(type) {
+	case string, int, int64, float64, bool, time.Time, blockValue, nil:
+		return x
+	case LogValuer:
+		return valuerValue{v: x}
+	case int8:
+		return int64(x)
+	case int16:
+		return int64(x)
+	case int32:
+		return int64(x)
+	case uint8:
+		return int64(x)
+	case uint16:
+		return int64(x)
+	case uint32:
+		return int64(x)
+	case uint:
+		return unsignedValue(uint64(x))
+	case uint64:
+		return unsignedValue(x)
+	case float32:
+		return float32Value(x)
+	case uintptr:
+		return unsignedValue(uint64(x))
+	case error:
+		return x.Error()
+	case fmt.Stringer, encoding.TextMarshaler:
+		s, _ := textValue(x)
+		return s
+	default:
+		return kindValue(x)
+	}
+}
+
+// kindValue converts values of named basic types to their underlying type,
+// keeping numbers numeric. Other values are returned unchanged.
+func kindValue(v any) any {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return unsignedValue(rv.Uint())
+	case reflect.Float32:
+		return float32Value(float32(rv.Float()))
+	case reflect.Float64:
+		return rv.Float()
+	default:
+		return v
+	}
+}
+
+// unsignedValue stores values that fit into int64 as int64, like the other
+// integers, and larger ones as uint64.
+func unsignedValue(u uint64) any {
+	if u <= 1<<63-1 {
+		return int64(u)
+	}
+	return u
+}
+
+// appendInteger appends v if it is one of the integer types the encoders do
+// not store natively, and reports whether it is. It covers fields built
+// from a Field literal, such as Field{Key: "port", Value: uint16(8080)}.
+func appendInteger(buf []byte, v any) ([]byte, bool) {
+	switch n := v.(type) {
+	case int8:
+		return strconv.AppendInt(buf, int64(n), 10), true
+	case int16:
+		return strconv.AppendInt(buf, int64(n), 10), true
+	case int32:
+		return strconv.AppendInt(buf, int64(n), 10), true
+	case uint:
+		return strconv.AppendUint(buf, uint64(n), 10), true
+	case uint8:
+		return strconv.AppendUint(buf, uint64(n), 10), true
+	case uint16:
+		return strconv.AppendUint(buf, uint64(n), 10), true
+	case uint32:
+		return strconv.AppendUint(buf, uint64(n), 10), true
+	case uintptr:
+		return strconv.AppendUint(buf, uint64(n), 10), true
+	default:
+		return buf, false
+	}
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedConstructors(t *testing.T) {
+	assert.Equal(t, Field{Key: "s", Value: "v"}, String("s", "v"))
+	assert.Equal(t, Field{Key: "i", Value: 1}, Int("i", 1))
+	assert.Equal(t, Field{Key: "i64", Value: int64(2)}, Int64("i64", 2))
+	assert.Equal(t, Field{Key: "f", Value: 1.5}, Float64("f", 1.5))
+	assert.Equal(t, Field{Key: "b", Value: true}, Bool("b", true))
+	assert.Equal(t, Field{Key: "error", Value: "boom"}, Err(errors.New("boom")))
+	assert.Equal(t, Field{Key: "error", Value: nil}, Err(nil))
+}
+
+func TestF_ConvertsToEncodableValues(t *testing.T) {
+	ts := time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		field Field
+		want  any
+	}{
+		{F("string", "v"), "v"},
+		{F("int", 42), 42},
+		{F("int64", int64(42)), int64(42)},
+		{F("int8", int8(-8)), int64(-8)},
+		{F("int32", int32(32)), int64(32)},
+		{F("uint16", uint16(16)), int64(16)},
+		{F("uint", uint(7)), int64(7)},
+		{F("uint64", uint64(math.MaxUint64)), uint64(math.MaxUint64)},
+		{F("float32", float32(0.5)), 0.5},
+		{F("bool", true), true},
+		{F("duration", 1500*time.Millisecond), "1.5s"},
+		{F("time", ts), ts},
+		{F("error", errors.New("boom")), "boom"},
+		{F("named int", tenantID(7)), int64(7)},
+		{F("named uint", shardID(3)), int64(3)},
+		{F("named string", region("eu")), "eu"},
+		{F("named bool", flag(true)), true},
+		{F("named float", score(0.5)), 0.5},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.field.Value, tt.field.Key)
+	}
+}
+
+type (
+	tenantID int64
+	shardID  uint8
+	region   string
+	flag     bool
+	score    float32
+)
+
+func TestF_AllocatesLikeTypedConstructors(t *testing.T) {
+	var (
+		u   uint64 = 1 << 40
+		f   float32
+		out Field
+	)
+	typed := testing.AllocsPerRun(100, func() {
+		u, f = u+1, f+1
+		out = Uint64("u", u)
+		out = Float32("f", f)
+	})
+	generic := testing.AllocsPerRun(100, func() {
+		u, f = u+1, f+1
+		out = F("u", u)
+		out = F("f", f)
+	})
+	assert.Equal(t, typed, generic)
+	assert.Equal(t, float32Value(f), out.Value)
+}
+
+func TestF_Encoding(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+	logger.Info("typed", F("port", uint16(8080)), F("ratio", float32(0.25)), String("user", "alice"))
+
+	output := buf.String()
+	assert.Contains(t, output, `"port":8080`)
+	assert.Contains(t, output, `"ratio":0.25`)
+	assert.Contains(t, output, `"user":"alice"`)
+}
+
+func TestTimeValue_Encoding(t *testing.T) {
+	ts := time.Date(2024, 1, 20, 15, 4, 5, 123000000, time.UTC)
+
+	js := &bytes.Buffer{}
+	New(Config{Level: InfoLevel, Format: JSONFormat, Output: js}).Info("at", F("deadline", ts))
+	assert.Contains(t, js.String(), `"deadline":"2024-01-20T15:04:05.123Z"`)
+
+	text := &bytes.Buffer{}
+	New(Config{Level: InfoLevel, Format: TextFormat, Output: text}).Info("at", F("deadline", ts))
+	assert.Contains(t, text.String(), "deadline=2024-01-20T15:04:05.123Z")
+}
+
+func TestErr_NilEncodesAsNull(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+	logger.Info("done", Err(nil))
+
+	assert.Contains(t, buf.String(), `"error":null`)
+}
+
+func TestNumericFields(t *testing.T) {
+	fields := []Field{
+		Int8("i8", -8),
+		Int16("i16", -16),
+		Int32("i32", -32),
+		Uint("u", 7),
+		Uint8("u8", 255),
+		Uint16("port", 8080),
+		Uint32("u32", math.MaxUint32),
+		Uint64("u64", math.MaxUint64),
+		Uintptr("ptr", 0x10),
+		Float32("f32", 0.1),
+		{Key: "raw_u16", Value: uint16(443)},
+		{Key: "raw_u64", Value: uint64(math.MaxUint64)},
+		{Key: "raw_i8", Value: int8(-1)},
+		{Key: "raw_f32", Value: float32(2.5)},
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONFormat, `{"level":"INFO","message":"m","i8":-8,"i16":-16,"i32":-32,"u":7,"u8":255,"port":8080,"u32":4294967295,` +
+			`"u64":18446744073709551615,"ptr":16,"f32":0.1,"raw_u16":443,"raw_u64":18446744073709551615,"raw_i8":-1,"raw_f32":2.5}`},
+		{TextFormat, `INFO m i8=-8 i16=-16 i32=-32 u=7 u8=255 port=8080 u32=4294967295 u64=18446744073709551615 ptr=16 f32=0.1 ` +
+			`raw_u16=443 raw_u64=18446744073709551615 raw_i8=-1 raw_f32=2.5`},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		log := New(Config{Level: InfoLevel, Format: tt.format, Output: buf, TimestampFormat: TimestampDisabled})
+
+		log.Info("m", fields...)
+		assert.Equal(t, tt.want+"\n", buf.String())
+	}
+}
+
+func TestNumericFields_Boundaries(t *testing.T) {
+	fields := []Field{
+		Int64("min", math.MinInt64),
+		Int64("max", math.MaxInt64),
+		Int("int_min", math.MinInt),
+		Uint64("umax", math.MaxUint64),
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONFormat, `{"level":"INFO","message":"m","min":-9223372036854775808,"max":9223372036854775807,` +
+			`"int_min":-9223372036854775808,"umax":18446744073709551615}`},
+		{TextFormat, `INFO m min=-9223372036854775808 max=9223372036854775807 int_min=-9223372036854775808 umax=18446744073709551615`},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		log := New(Config{Level: InfoLevel, Format: tt.format, Output: buf, TimestampFormat: TimestampDisabled})
+
+		log.Info("m", fields...)
+		assert.Equal(t, tt.want+"\n", buf.String())
+	}
+}
+
+func TestNumericFields_Replay(t *testing.T) {
+	recording := &bytes.Buffer{}
+	src := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}})
+	src.AddHook(NewRecorder(recording))
+	src.Info("m", Uint64("u64", math.MaxUint64))
+
+	buf := &bytes.Buffer{}
+	_, err := Replay(recording, New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled}))
+	require.NoError(t, err)
+	assert.Equal(t, "INFO m u64=18446744073709551615\n", buf.String())
+}
@@ -0,0 +1,214 @@
+package logger
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// FilterAction decides what a Filter does with the entries it matches.
+type FilterAction uint8
+
+const (
+	// FilterExclude drops the entries the filter matches.
+	FilterExclude FilterAction = iota
+
+	// FilterInclude writes only entries matched by at least one FilterInclude
+	// filter, when there is one.
+	FilterInclude
+)
+
+// Filter is a rule of Config.Filters matching entries by their message,
+// fields and logger name, e.g. to drop health check noise:
+//
+//	Filters: []logger.Filter{
+//		{Fields: map[string]string{"path": "/healthz"}},
+//		{Logger: "db", MessagePattern: regexp.MustCompile(`^ping`)},
+//	}
+//
+// A filter matches an entry when all its criteria do; a filter needs at
+// least one. Validate reports filters without criteria and New ignores
+// them. Filters are evaluated right after the level check, before
+// sampling, hooks and encoding, so a filtered entry costs little more than
+// a disabled one. Dropped entries are counted as logmetrics.DropFiltered.
+type Filter struct {
+	// Action is FilterExclude by default.
+	Action FilterAction
+
+	// Message matches entries whose message contains it.
+	Message string
+
+	// MessagePattern matches entries whose message it matches.
+	MessagePattern *regexp.Regexp
+
+	// Logger matches entries of the logger with that name, see Named, and
+	// of its descendants: "db" matches "db" and "db.pool".
+	Logger string
+
+	// Fields matches entries with a field of each key whose value is
+	// written as the given text, as in TextFormat without quotes: "200"
+	// matches Int("status", 200). Context fields are matched too, and
+	// "http.path" matches the field path in the group http. LogValuer
+	// values are resolved after filtering and never match.
+	Fields map[string]string
+
+	// FieldPatterns matches entries with a field of each key whose value,
+	// written as for Fields, the pattern matches.
+	FieldPatterns map[string]*regexp.Regexp
+}
+
+// isZero reports whether f has no criteria.
+func (f *Filter) isZero() bool {
+	return f.Message == "" && f.MessagePattern == nil && f.Logger == "" && len(f.Fields) == 0 && len(f.FieldPatterns) == 0
+}
+
+// filterSet holds the filters of Config.Filters split by action.
+type filterSet struct {
+	include []filterRule
+	exclude []filterRule
+}
+
+// filterRule is a Filter with its field criteria in a slice, sorted by
+// key, which is faster to range over than the maps.
+type filterRule struct {
+	Filter
+	fields []filterField
+}
+
+// filterField is a criterion of Filter.Fields or Filter.FieldPatterns.
+type filterField struct {
+	key     string
+	want    string
+	pattern *regexp.Regexp
+}
+
+// newFilterSet compiles filters, ignoring those without criteria, and
+// returns nil when none remain.
+func newFilterSet(filters []Filter) *filterSet {
+	f := &filterSet{}
+	for _, filter := range filters {
+		if filter.isZero() {
+			continue
+		}
+		rule := filterRule{Filter: filter}
+		for key, want := range filter.Fields {
+			rule.fields = append(rule.fields, filterField{key: key, want: want})
+		}
+		for key, pattern := range filter.FieldPatterns {
+			rule.fields = append(rule.fields, filterField{key: key, pattern: pattern})
+		}
+		slices.SortFunc(rule.fields, func(a, b filterField) int { return strings.Compare(a.key, b.key) })
+
+		if filter.Action == FilterInclude {
+			f.include = append(f.include, rule)
+		} else {
+			f.exclude = append(f.exclude, rule)
+		}
+	}
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return nil
+	}
+	return f
+}
+
+// allow reports whether an entry of the logger name with msg, the context
+// fields and fields passes the filters.
+func (f *filterSet) allow(name, msg string, context, fields []Field) bool {
+	for i := range f.exclude {
+		if f.exclude[i].matches(name, msg, context, fields) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for i := range f.include {
+		if f.include[i].matches(name, msg, context, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the entry meets all criteria of f.
+func (f *filterRule) matches(name, msg string, context, fields []Field) bool {
+	if f.Message != "" && !strings.Contains(msg, f.Message) {
+		return false
+	}
+	if f.Logger != "" && name != f.Logger && !(strings.HasPrefix(name, f.Logger) && name[len(f.Logger)] == '.') {
+		return false
+	}
+	if f.MessagePattern != nil && !f.MessagePattern.MatchString(msg) {
+		return false
+	}
+
+	for _, field := range f.fields {
+		value, ok := lookupFilterField(field.key, context, fields)
+		if !ok {
+			return false
+		}
+		if field.pattern != nil {
+			if !filterValueMatches(value, field.pattern) {
+				return false
+			}
+		} else if !filterValueEquals(value, field.want) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterValueEquals reports whether value is written as want.
+func filterValueEquals(value any, want string) bool {
+	if s, ok := value.(string); ok {
+		return s == want
+	}
+	var buf [64]byte
+	return string(filterValueText(buf[:0], value)) == want
+}
+
+// filterValueMatches reports whether pattern matches the text of value.
+func filterValueMatches(value any, pattern *regexp.Regexp) bool {
+	if s, ok := value.(string); ok {
+		return pattern.MatchString(s)
+	}
+	return pattern.Match(filterValueText(nil, value))
+}
+
+// lookupFilterField returns the value of the last field with key in
+// fields, or else in context, descending into groups for dotted keys.
+func lookupFilterField(key string, context, fields []Field) (any, bool) {
+	if v, ok := lookupField(key, fields); ok {
+		return v, true
+	}
+	return lookupField(key, context)
+}
+
+func lookupField(key string, fields []Field) (any, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		if f.Key == key {
+			_, lazy := f.Value.(valuerValue)
+			return f.Value, !lazy
+		}
+		if g, ok := f.Value.(groupValue); ok && len(key) > len(f.Key) && key[len(f.Key)] == '.' && strings.HasPrefix(key, f.Key) {
+			if v, ok := lookupField(key[len(f.Key)+1:], g); ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// filterValueText appends the text a filter matches for value: strings as
+// they are, other values as TextFormat writes them.
+func filterValueText(buf []byte, value any) []byte {
+	switch v := value.(type) {
+	case string:
+		return append(buf, v...)
+	case blockValue:
+		return append(buf, v...)
+	default:
+		return appendValue(buf, value)
+	}
+}
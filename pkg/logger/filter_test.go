@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+func TestConfig_Filters(t *testing.T) {
+	var counters logmetrics.Counters
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		Metrics:         &counters,
+		Filters: []Filter{
+			{Fields: map[string]string{"path": "/healthz"}},
+			{Fields: map[string]string{"status": "200"}, Message: "served"},
+			{Logger: "db", MessagePattern: regexp.MustCompile(`^ping`)},
+			{FieldPatterns: map[string]*regexp.Regexp{"http.agent": regexp.MustCompile(`(?i)bot`)}},
+		},
+	})
+
+	log.Info("request served", String("path", "/healthz"))
+	log.With(String("path", "/healthz")).Info("from context")
+	log.Info("request served", Int("status", 200))
+	log.Info("request served", Int("status", 500))
+	log.Named("db").Named("pool").Info("ping ok")
+	log.Named("dbx").Info("ping ok")
+	log.Named("db").Info("query ok")
+	log.Info("crawl", Group("http", String("agent", "GoogleBot/2.1")))
+	log.Info("visit", Group("http", String("agent", "Firefox")))
+
+	assert.Equal(t, "INFO request served status=500\n"+
+		"INFO ping ok logger=dbx\n"+
+		"INFO query ok logger=db\n"+
+		"INFO visit http.agent=Firefox\n", buf.String())
+	assert.Equal(t, uint64(5), counters.Snapshot().Dropped[logmetrics.DropFiltered])
+}
+
+func TestConfig_FiltersInclude(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           DebugLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		Filters: []Filter{
+			{Action: FilterInclude, Logger: "payments"},
+			{Action: FilterInclude, Message: "audit"},
+			{Message: "noisy"},
+		},
+	})
+
+	log.Info("startup")
+	log.Named("payments").Info("charged")
+	log.Named("payments").Info("noisy retry")
+	log.Info("audit: login")
+
+	assert.Equal(t, "INFO charged logger=payments\nINFO audit: login\n", buf.String())
+}
+
+func TestConfig_FiltersRecordTemplate(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		Filters:         []Filter{{Fields: map[string]string{"path": "/healthz"}}},
+	})
+
+	served := log.Template("served", "path")
+	served.Log(InfoLevel, "/healthz")
+	served.Log(InfoLevel, "/orders")
+	assert.Equal(t, "INFO served path=/orders\n", buf.String())
+}
+
+func TestConfig_FiltersValidation(t *testing.T) {
+	_, err := NewE(Config{Filters: []Filter{
+		{},
+		{Action: 9, Message: "x"},
+		{FieldPatterns: map[string]*regexp.Regexp{"path": nil}},
+	}})
+	assert.ErrorContains(t, err, "Filters[0] has no criteria")
+	assert.ErrorContains(t, err, "Filters[1]: unknown Action 9")
+	assert.ErrorContains(t, err, `Filters[2].FieldPatterns["path"] is nil`)
+}
+
+func TestConfig_FiltersIgnoreEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled, Filters: []Filter{{}, {Message: "ping"}}})
+	log.Info("ping")
+	log.Info("served")
+	assert.Equal(t, "INFO served\n", buf.String(), "a filter without criteria matches nothing")
+
+	assert.Nil(t, New(Config{Output: buf, Filters: []Filter{{}}}).filters)
+}
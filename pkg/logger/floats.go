@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"math"
+	"strconv"
+)
+
+// NonFiniteFloatPolicy decides how NaN and infinite float values, which
+// JSON numbers cannot represent, are written.
+type NonFiniteFloatPolicy uint8
+
+const (
+	// NonFiniteAsString writes NaN and infinities as the strings "NaN",
+	// "+Inf" and "-Inf" in JSON formats, and unquoted in text formats.
+	NonFiniteAsString NonFiniteFloatPolicy = iota
+
+	// NonFiniteAsNull writes NaN and infinities as null, for consumers
+	// that expect every value of a field to be a number.
+	NonFiniteAsNull
+)
+
+// maxFloatPrecision is the largest Config.FloatPrecision. Beyond 17
+// digits a float64 carries no more information.
+const maxFloatPrecision = 17
+
+// appendFloatNumber appends f, which must be finite, in the format of
+// encoding/json: the shortest representation that parses back to the same
+// value of bits, with an exponent only for magnitudes below 1e-6 or from
+// 1e21 on.
+func appendFloatNumber(buf []byte, f float64, bits int) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// Shorten e-09 to e-9, like encoding/json.
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
+
+// appendNonFinite appends the name of a NaN or infinite f.
+func appendNonFinite(buf []byte, f float64) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(buf, "NaN"...)
+	case f > 0:
+		return append(buf, "+Inf"...)
+	default:
+		return append(buf, "-Inf"...)
+	}
+}
+
+// float32Value converts f to the float64 with the same shortest decimal
+// representation, so 0.1 stays 0.1 instead of becoming
+// 0.10000000149011612.
+func float32Value(f float32) float64 {
+	var buf [32]byte
+	// The shortest representation always parses, NaN and infinities too.
+	v, _ := strconv.ParseFloat(string(strconv.AppendFloat(buf[:0], float64(f), 'g', -1, 32)), 64)
+	return v
+}
+
+// roundFloat rounds f to precision digits after the decimal point. Values
+// too large to have a fractional part at that precision are returned as is.
+func roundFloat(f float64, precision int) float64 {
+	pow := math.Pow10(precision)
+	scaled := f * pow
+	if math.IsInf(scaled, 0) || math.IsNaN(scaled) || math.Abs(scaled) >= 1<<53 {
+		return f
+	}
+	return math.Round(scaled) / pow
+}
+
+// formatFloats applies Config.FloatPrecision and Config.NonFiniteFloats to
+// the float fields of r, including those nested in groups.
+func formatFloats(precision int, nonFinite NonFiniteFloatPolicy, r *Record) {
+	r.Fields = formatFloatFields(precision, nonFinite, r.Fields, false)
+}
+
+// formatFloatFields rewrites the float values of fields, copying fields
+// first when shared is set, so group members bound with With are not
+// modified.
+func formatFloatFields(precision int, nonFinite NonFiniteFloatPolicy, fields []Field, shared bool) []Field {
+	for i, field := range fields {
+		var value any
+		switch v := field.Value.(type) {
+		case float64:
+			switch {
+			case math.IsNaN(v) || math.IsInf(v, 0):
+				if nonFinite != NonFiniteAsNull {
+					continue
+				}
+				value = nil
+			case precision > 0:
+				rounded := roundFloat(v, precision)
+				if rounded == v {
+					continue
+				}
+				value = rounded
+			default:
+				continue
+			}
+		case groupValue:
+			g := formatFloatFields(precision, nonFinite, v, true)
+			if len(v) == 0 || &g[0] == &v[0] {
+				continue
+			}
+			value = groupValue(g)
+		default:
+			continue
+		}
+
+		if shared {
+			fields = append([]Field(nil), fields...)
+			shared = false
+		}
+		fields[i].Value = value
+	}
+	return fields
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloatEncoding(t *testing.T) {
+	fields := []Field{
+		Float64("a", 3.05),
+		Float64("b", math.Nextafter(0.3, 1)),
+		Float64("c", 1e21),
+		Float64("d", 1e-7),
+		Float64("e", -2.5),
+		Float64("f", 100),
+		F("g", float32(0.1)),
+		Float64("nan", math.NaN()),
+		Float64("inf", math.Inf(-1)),
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONFormat, `{"level":"INFO","message":"m","a":3.05,"b":0.30000000000000004,"c":1e+21,"d":1e-7,"e":-2.5,"f":100,"g":0.1,"nan":"NaN","inf":"-Inf"}`},
+		{TextFormat, `INFO m a=3.05 b=0.30000000000000004 c=1e+21 d=1e-7 e=-2.5 f=100 g=0.1 nan=NaN inf=-Inf`},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		log := New(Config{Level: InfoLevel, Format: tt.format, Output: buf, TimestampFormat: TimestampDisabled})
+
+		log.Info("m", fields...)
+		assert.Equal(t, tt.want+"\n", buf.String())
+	}
+}
+
+func TestAppendJSONFloat_RoundTrips(t *testing.T) {
+	for _, f := range []float64{math.Pi, math.MaxFloat64, math.SmallestNonzeroFloat64, -1234567.891, 1 << 60, 0.000123} {
+		var got float64
+		require.NoError(t, json.Unmarshal(appendJSONFloat(nil, f), &got))
+		assert.Equal(t, f, got)
+	}
+}
+
+func TestConfig_FloatPrecision(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Format:          JSONFormat,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		FloatPrecision:  2,
+	})
+	grouped := log.WithGroup("g").With(Float64("bound", 1.23456))
+
+	grouped.Info("m", Float64("ratio", 0.6666), Float64("big", 1e300), Float64("nan", math.NaN()), Int("n", 3))
+	assert.Equal(t, `{"level":"INFO","message":"m","g":{"bound":1.23,"ratio":0.67,"big":1e+300,"nan":"NaN","n":3}}`+"\n", buf.String())
+	assert.Equal(t, 1.23456, grouped.groups[0].fields[0].Value, "bound fields are not modified")
+}
+
+func TestConfig_NonFiniteAsNull(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Format:          JSONFormat,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		NonFiniteFloats: NonFiniteAsNull,
+	})
+
+	log.Info("m", Float64("nan", math.NaN()), Float64("inf", math.Inf(1)), Float64("ok", 1.5))
+	assert.Equal(t, `{"level":"INFO","message":"m","nan":null,"inf":null,"ok":1.5}`+"\n", buf.String())
+}
+
+func TestConfig_FloatValidation(t *testing.T) {
+	_, err := NewE(Config{FloatPrecision: -1})
+	assert.ErrorContains(t, err, "FloatPrecision -1 out of range")
+
+	_, err = NewE(Config{FloatPrecision: 18})
+	assert.ErrorContains(t, err, "FloatPrecision 18 out of range")
+
+	_, err = NewE(Config{NonFiniteFloats: 9})
+	assert.ErrorContains(t, err, "unknown NonFiniteFloats 9")
+}
+
+func TestFloat32Value(t *testing.T) {
+	for _, f := range []float32{0.1, 3.3, 1e-20, math.MaxFloat32} {
+		assert.Equal(t, strconv.FormatFloat(float64(f), 'g', -1, 32), strconv.FormatFloat(float32Value(f), 'g', -1, 64))
+	}
+	assert.True(t, math.IsNaN(float32Value(float32(math.NaN()))))
+}
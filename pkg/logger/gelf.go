@@ -0,0 +1,367 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// gelfVersion is the GELF specification version produced by GELFFormat.
+	gelfVersion = "1.1"
+
+	// DefaultGELFChunkSize is the UDP chunk size used when GELFConfig.ChunkSize
+	// is not set. It fits into a single datagram on most WAN paths.
+	DefaultGELFChunkSize = 1420
+
+	// gelfChunkHeaderSize is the size of the magic bytes, message ID,
+	// sequence number and sequence count preceding every chunk.
+	gelfChunkHeaderSize = 12
+
+	// maxGELFChunks is the maximum number of chunks per message accepted by
+	// Graylog.
+	maxGELFChunks = 128
+)
+
+// gelfChunkMagic starts every chunk of a chunked GELF message.
+var gelfChunkMagic = [2]byte{0x1e, 0x0f}
+
+// ErrGELFMessageTooLarge is returned by GELFWriter when a UDP message needs
+// more than 128 chunks.
+var ErrGELFMessageTooLarge = errors.New("logger: GELF message exceeds 128 chunks")
+
+// gelfHost is the "host" of every GELF message.
+var gelfHost = sync.OnceValue(func() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "localhost"
+	}
+	return host
+})
+
+// appendGELF formats a log entry as a GELF 1.1 message. Fields become
+// additional fields prefixed with '_'; GELF only allows strings and numbers
+// there, so booleans are sent as strings and nil values are omitted.
+func appendGELF(buf []byte, r *Record) []byte {
+	buf = append(buf, `{"version":"`+gelfVersion+`","host":"`...)
+	buf = appendJSONString(buf, gelfHost())
+	buf = append(buf, `","short_message":"`...)
+	buf = appendJSONString(buf, r.Message)
+	buf = append(buf, `","timestamp":`...)
+	buf = appendGELFTimestamp(buf, r.Time)
+	buf = append(buf, `,"level":`...)
+	buf = strconv.AppendInt(buf, int64(syslogSeverity(r.Level)), 10)
+
+	for _, field := range r.Fields {
+		if field.Value == nil {
+			continue
+		}
+
+		buf = append(buf, ',', '"', '_')
+		buf = appendGELFKey(buf, field.Key)
+		buf = append(buf, '"', ':')
+
+		if b, ok := field.Value.(bool); ok {
+			if b {
+				buf = append(buf, `"true"`...)
+			} else {
+				buf = append(buf, `"false"`...)
+			}
+			continue
+		}
+		buf = appendJSONValue(buf, field.Value)
+	}
+
+	return append(buf, '}')
+}
+
+// appendGELFTimestamp appends t as seconds since the epoch with millisecond
+// precision.
+func appendGELFTimestamp(buf []byte, t time.Time) []byte {
+	ms := t.UnixMilli()
+	buf = strconv.AppendInt(buf, ms/1000, 10)
+
+	frac := ms % 1000
+	if frac < 0 {
+		frac = -frac
+	}
+	return append(buf, '.', byte('0'+frac/100), byte('0'+frac/10%10), byte('0'+frac%10))
+}
+
+// appendGELFKey appends the name of an additional field. Characters outside
+// of [A-Za-z0-9_.-] are replaced with '_', and the reserved name "id" is
+// sent as "_id" so that the field ends up as "__id".
+func appendGELFKey(buf []byte, key string) []byte {
+	if key == "" || key == "id" {
+		buf = append(buf, '_')
+		return append(buf, key...)
+	}
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '_', c == '.', c == '-':
+		default:
+			c = '_'
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+// GELFCompression selects how UDP messages are compressed by a GELFWriter.
+type GELFCompression int8
+
+const (
+	// GELFCompressionNone sends messages uncompressed.
+	GELFCompressionNone GELFCompression = iota
+
+	// GELFCompressionGzip compresses every message with gzip.
+	GELFCompressionGzip
+
+	// GELFCompressionZlib compresses every message with zlib.
+	GELFCompressionZlib
+)
+
+// GELFConfig configures a GELFWriter.
+type GELFConfig struct {
+	// Compression is applied to UDP messages before chunking. GELF over
+	// TCP does not support compression, so it is ignored there.
+	Compression GELFCompression
+
+	// ChunkSize is the maximum UDP datagram size, including the chunk
+	// header. Defaults to DefaultGELFChunkSize; 8154 is common on LANs.
+	ChunkSize int
+}
+
+// GELFWriter delivers GELF messages produced by a sink with GELFFormat to
+// a Graylog input, without a sidecar.
+//
+// Over UDP, every message is optionally compressed and sent in one
+// datagram, or split into chunks when it exceeds the chunk size. Over TCP,
+// messages are uncompressed and terminated by a null byte, and the
+// connection is re-established once when a write fails.
+type GELFWriter struct {
+	network     string
+	addr        string
+	stream      bool
+	compression GELFCompression
+	chunkSize   int
+
+	mu    sync.Mutex
+	conn  net.Conn
+	buf   bytes.Buffer
+	gzip  *gzip.Writer
+	zlib  *zlib.Writer
+	chunk []byte
+}
+
+// NewGELFWriter connects to a Graylog GELF input. network is "udp" or
+// "tcp".
+//
+// Example:
+//
+//	w, err := logger.NewGELFWriter("udp", "graylog.internal:12201", logger.GELFConfig{
+//		Compression: logger.GELFCompressionGzip,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Close()
+//
+//	log := logger.New(logger.Config{Format: logger.GELFFormat, Output: w})
+func NewGELFWriter(network, addr string, cfg GELFConfig) (*GELFWriter, error) {
+	if cfg.ChunkSize <= gelfChunkHeaderSize {
+		cfg.ChunkSize = DefaultGELFChunkSize
+	}
+
+	w := &GELFWriter{
+		network:     network,
+		addr:        addr,
+		stream:      network == "tcp" || network == "tcp4" || network == "tcp6",
+		compression: cfg.Compression,
+		chunkSize:   cfg.ChunkSize,
+	}
+	if w.stream {
+		w.compression = GELFCompressionNone
+	}
+
+	if err := w.reconnect(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write sends every newline-terminated entry in p as its own GELF message,
+// so entries flushed together by a buffered Logger keep their boundaries.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rest := p
+	for len(rest) > 0 {
+		var entry []byte
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			entry, rest = rest[:i], rest[i+1:]
+		} else {
+			entry, rest = rest, nil
+		}
+
+		if len(entry) == 0 {
+			continue
+		}
+
+		if err := w.send(entry); err != nil {
+			return len(p) - len(rest), err
+		}
+	}
+
+	return len(p), nil
+}
+
+// lineOutput implements lineOutput.
+func (w *GELFWriter) lineOutput() {}
+
+// Close closes the connection.
+func (w *GELFWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// send delivers a single message. It must be called with w.mu held.
+func (w *GELFWriter) send(msg []byte) error {
+	if w.stream {
+		return w.sendStream(msg)
+	}
+
+	payload, err := w.compress(msg)
+	if err != nil {
+		return err
+	}
+	if w.conn == nil {
+		if err := w.reconnect(); err != nil {
+			return err
+		}
+	}
+	if len(payload) <= w.chunkSize {
+		_, err := w.conn.Write(payload)
+		return err
+	}
+	return w.sendChunked(payload)
+}
+
+// sendStream writes msg followed by a null byte, reconnecting once when the
+// write fails. It must be called with w.mu held.
+func (w *GELFWriter) sendStream(msg []byte) error {
+	w.buf.Reset()
+	w.buf.Write(msg)
+	w.buf.WriteByte(0)
+
+	if w.conn == nil {
+		if err := w.reconnect(); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.conn.Write(w.buf.Bytes())
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		if err = w.reconnect(); err == nil {
+			_, err = w.conn.Write(w.buf.Bytes())
+		}
+	}
+	return err
+}
+
+// reconnect dials the configured endpoint. It must be called with w.mu held.
+func (w *GELFWriter) reconnect() error {
+	conn, err := net.Dial(w.network, w.addr)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// compress returns msg compressed according to the configuration. The
+// result is only valid until the next call. It must be called with w.mu held.
+func (w *GELFWriter) compress(msg []byte) ([]byte, error) {
+	var zw interface {
+		io.Writer
+		Close() error
+	}
+
+	w.buf.Reset()
+	switch w.compression {
+	case GELFCompressionGzip:
+		if w.gzip == nil {
+			w.gzip = gzip.NewWriter(&w.buf)
+		} else {
+			w.gzip.Reset(&w.buf)
+		}
+		zw = w.gzip
+	case GELFCompressionZlib:
+		if w.zlib == nil {
+			w.zlib = zlib.NewWriter(&w.buf)
+		} else {
+			w.zlib.Reset(&w.buf)
+		}
+		zw = w.zlib
+	default:
+		return msg, nil
+	}
+
+	if _, err := zw.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// sendChunked splits payload into GELF chunks sharing a random message ID.
+// It must be called with w.mu held.
+func (w *GELFWriter) sendChunked(payload []byte) error {
+	size := w.chunkSize - gelfChunkHeaderSize
+	count := (len(payload) + size - 1) / size
+	if count > maxGELFChunks {
+		return ErrGELFMessageTooLarge
+	}
+
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+
+	for seq := 0; seq < count; seq++ {
+		end := min((seq+1)*size, len(payload))
+
+		w.chunk = append(w.chunk[:0], gelfChunkMagic[:]...)
+		w.chunk = append(w.chunk, id[:]...)
+		w.chunk = append(w.chunk, byte(seq), byte(count))
+		w.chunk = append(w.chunk, payload[seq*size:end]...)
+
+		if _, err := w.conn.Write(w.chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGELFFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  DebugLevel,
+		Format: GELFFormat,
+		Output: buf,
+	})
+	logger.Warn("disk \"almost\" full",
+		Field{Key: "free", Value: 42},
+		Field{Key: "ratio", Value: 0.5},
+		Field{Key: "mounted", Value: true},
+		Field{Key: "id", Value: "vol-1"},
+		Field{Key: "mount point", Value: "/data"},
+		Field{Key: "missing", Value: nil},
+	)
+
+	line := buf.String()
+	require.True(t, strings.HasSuffix(line, "}\n"))
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(line), &msg))
+
+	assert.Equal(t, "1.1", msg["version"])
+	assert.Equal(t, gelfHost(), msg["host"])
+	assert.Equal(t, `disk "almost" full`, msg["short_message"])
+	assert.Equal(t, float64(4), msg["level"])
+	assert.InDelta(t, float64(time.Now().Unix()), msg["timestamp"], 5)
+	assert.Equal(t, float64(42), msg["_free"])
+	assert.Equal(t, 0.5, msg["_ratio"])
+	assert.Equal(t, "true", msg["_mounted"])
+	assert.Equal(t, "vol-1", msg["__id"])
+	assert.Equal(t, "/data", msg["_mount_point"])
+	assert.NotContains(t, msg, "_missing")
+}
+
+func TestAppendGELFTimestamp(t *testing.T) {
+	ts := time.Unix(1700000000, 7*int64(time.Millisecond)+999)
+	assert.Equal(t, "1700000000.007", string(appendGELFTimestamp(nil, ts)))
+}
+
+func readGELFChunks(t *testing.T, server net.PacketConn) []byte {
+	t.Helper()
+
+	var chunks [][]byte
+	for {
+		require.NoError(t, server.SetReadDeadline(time.Now().Add(time.Second)))
+		packet := make([]byte, 65536)
+		n, _, err := server.ReadFrom(packet)
+		require.NoError(t, err)
+		packet = packet[:n]
+
+		if !bytes.HasPrefix(packet, gelfChunkMagic[:]) {
+			return packet
+		}
+
+		if chunks == nil {
+			chunks = make([][]byte, packet[11])
+		}
+		chunks[packet[10]] = packet[gelfChunkHeaderSize:]
+
+		complete := true
+		for _, c := range chunks {
+			complete = complete && c != nil
+		}
+		if complete {
+			return bytes.Join(chunks, nil)
+		}
+	}
+}
+
+func TestGELFWriter_UDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	w, err := NewGELFWriter("udp", server.LocalAddr().String(), GELFConfig{})
+	require.NoError(t, err)
+	defer w.Close()
+
+	logger := New(Config{Level: InfoLevel, Format: GELFFormat, Output: w})
+	logger.Info("hello", Field{Key: "user", Value: "alice"})
+
+	payload := readGELFChunks(t, server)
+	assert.Contains(t, string(payload), `"short_message":"hello"`)
+	assert.Contains(t, string(payload), `"_user":"alice"`)
+}
+
+func TestGELFWriter_UDPChunkedGzip(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	w, err := NewGELFWriter("udp", server.LocalAddr().String(), GELFConfig{
+		Compression: GELFCompressionGzip,
+		ChunkSize:   64,
+	})
+	require.NoError(t, err)
+	defer w.Close()
+
+	// Random-looking content does not compress below the chunk size.
+	var sb strings.Builder
+	for i := 0; i < 200; i++ {
+		sb.WriteByte(byte('a' + (i*7919)%26))
+		sb.WriteByte(byte('A' + (i*104729)%26))
+	}
+
+	logger := New(Config{Level: InfoLevel, Format: GELFFormat, Output: w})
+	logger.Info(sb.String())
+
+	zr, err := gzip.NewReader(bytes.NewReader(readGELFChunks(t, server)))
+	require.NoError(t, err)
+	payload, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &msg))
+	assert.Equal(t, sb.String(), msg["short_message"])
+}
+
+func TestGELFWriter_UDPZlib(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	w, err := NewGELFWriter("udp", server.LocalAddr().String(), GELFConfig{
+		Compression: GELFCompressionZlib,
+	})
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte(`{"short_message":"a"}` + "\n" + `{"short_message":"b"}` + "\n"))
+	require.NoError(t, err)
+
+	for _, want := range []string{"a", "b"} {
+		zr, err := zlib.NewReader(bytes.NewReader(readGELFChunks(t, server)))
+		require.NoError(t, err)
+		payload, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, `{"short_message":"`+want+`"}`, string(payload))
+	}
+}
+
+func TestGELFWriter_TooManyChunks(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	w, err := NewGELFWriter("udp", server.LocalAddr().String(), GELFConfig{ChunkSize: 13})
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write(bytes.Repeat([]byte("x"), maxGELFChunks+1))
+	assert.ErrorIs(t, err, ErrGELFMessageTooLarge)
+}
+
+func TestGELFWriter_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for i := 0; i < 2; i++ {
+			msg, err := reader.ReadString(0)
+			if err != nil {
+				return
+			}
+			messages = append(messages, strings.TrimSuffix(msg, "\x00"))
+		}
+		received <- messages
+	}()
+
+	w, err := NewGELFWriter("tcp", listener.Addr().String(), GELFConfig{
+		Compression: GELFCompressionGzip,
+	})
+	require.NoError(t, err)
+	defer w.Close()
+
+	logger := New(Config{Level: InfoLevel, Format: GELFFormat, Output: w, BufferSize: 4096})
+	logger.Info("first")
+	logger.Error("second")
+	logger.Flush()
+
+	select {
+	case messages := <-received:
+		require.Len(t, messages, 2)
+		assert.Contains(t, messages[0], `"short_message":"first"`)
+		assert.Contains(t, messages[1], `"short_message":"second"`)
+		assert.Contains(t, messages[1], `"level":3`)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for GELF messages")
+	}
+}
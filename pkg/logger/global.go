@@ -0,0 +1,56 @@
+package logger
+
+import "sync/atomic"
+
+// defaultLogger is the logger returned by Default.
+var defaultLogger atomic.Pointer[Logger]
+
+// Default returns the package default logger: the logger set with
+// SetDefault, or a text logger writing INFO and more severe entries to
+// os.Stdout. It is used by FromContext when the context carries no logger
+// and by the package-level logging functions such as Info.
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	defaultLogger.CompareAndSwap(nil, New(Config{}))
+	return defaultLogger.Load()
+}
+
+// SetDefault makes l the package default logger. It is safe to call
+// concurrently with Default; a nil l restores the built-in default.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Debug logs a message at DebugLevel with the default logger.
+func Debug(msg string, fields ...Field) {
+	Default().log(DebugLevel, msg, fields...)
+}
+
+// Info logs a message at InfoLevel with the default logger.
+func Info(msg string, fields ...Field) {
+	Default().log(InfoLevel, msg, fields...)
+}
+
+// Warn logs a message at WarnLevel with the default logger.
+func Warn(msg string, fields ...Field) {
+	Default().log(WarnLevel, msg, fields...)
+}
+
+// Error logs a message at ErrorLevel with the default logger.
+func Error(msg string, fields ...Field) {
+	Default().log(ErrorLevel, msg, fields...)
+}
+
+// Fatal logs a message at FatalLevel with the default logger, then exits
+// like Logger.Fatal.
+func Fatal(msg string, fields ...Field) {
+	Default().Fatal(msg, fields...)
+}
+
+// Panic logs a message at PanicLevel with the default logger, then panics
+// with the message.
+func Panic(msg string, fields ...Field) {
+	Default().Panic(msg, fields...)
+}
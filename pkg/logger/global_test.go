@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	builtin := Default()
+	assert.NotNil(t, builtin)
+	assert.Same(t, builtin, Default())
+
+	custom := New(Config{Output: &bytes.Buffer{}})
+	SetDefault(custom)
+	assert.Same(t, custom, Default())
+
+	SetDefault(nil)
+	assert.NotSame(t, custom, Default())
+}
+
+func TestFromContext(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	buf := &bytes.Buffer{}
+	base := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+	reqLog := base.With(F("requestID", "r-1"))
+
+	ctx := NewContext(context.Background(), reqLog)
+	FromContext(ctx).Info("handled")
+	assert.Contains(t, buf.String(), "INFO handled requestID=r-1")
+
+	fallback := New(Config{Output: &bytes.Buffer{}})
+	assert.Same(t, reqLog, FromContextOr(ctx, fallback))
+	assert.Same(t, fallback, FromContextOr(context.Background(), fallback))
+
+	SetDefault(base)
+	assert.Same(t, base, FromContext(context.Background()))
+	assert.Same(t, base, FromContext(NewContext(context.Background(), nil)))
+}
+
+func TestPackageLevelFunctions(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	buf := &bytes.Buffer{}
+	SetDefault(New(Config{Level: DebugLevel, Output: buf, TimestampFormat: TimestampDisabled, AddCaller: true}))
+
+	line := callerLine() + 1
+	Info("started", Int("port", 80))
+	Debug("debug")
+	Warn("warn")
+	Error("error")
+	assert.Equal(t, "INFO started port=80 caller=logger/global_test.go:"+strconv.Itoa(line)+"\n", strings.SplitAfter(buf.String(), "\n")[0])
+	assert.Contains(t, buf.String(), "DEBUG debug")
+	assert.Contains(t, buf.String(), "WARN warn")
+	assert.Contains(t, buf.String(), "ERROR error")
+
+	buf.Reset()
+	assert.PanicsWithValue(t, "boom", func() { Panic("boom") })
+	assert.Contains(t, buf.String(), "PANIC boom")
+}
@@ -0,0 +1,118 @@
+package logger
+
+// groupValue is the value of a field created by Group or WithGroup.
+type groupValue []Field
+
+// openGroup is a group opened with WithGroup, with the fields given to
+// With since.
+type openGroup struct {
+	name   string
+	fields []Field
+}
+
+// Group returns a field nesting fields under key, like slog.Group. JSON
+// formats write it as an object, {"http":{"method":"GET","status":200}},
+// while TextFormat, ConsoleFormat and text templates qualify the keys of
+// its fields, http.method=GET http.status=200. Other formats write the
+// object as the value of key.
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Value: groupValue(append([]Field(nil), fields...))}
+}
+
+// WithGroup returns a logger that nests the fields of later With calls and
+// of every entry under name, as Group does, matching the semantics of
+// slog.Logger.WithGroup:
+//
+//	log.WithGroup("http").With(logger.String("method", "GET")).Info("served", logger.Int("status", 200))
+//	// {"level":"INFO","message":"served","http":{"method":"GET","status":200}}
+//
+// Fields added before WithGroup, the name of Named, the fields extracted
+// from the context of a ContextLogger and the caller stay at the top
+// level. An entry without fields in the group omits it. An empty name
+// returns l.
+func (l *Logger) WithGroup(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	groups := append(l.groups[:len(l.groups):len(l.groups)], openGroup{name: name})
+	return &Logger{core: l.core, name: l.name, context: l.context, groups: groups, levelCache: l.levelCache}
+}
+
+// withGroupFields returns a logger adding fields to the innermost group of
+// l, for With.
+func (l *Logger) withGroupFields(fields []Field) *Logger {
+	groups := append([]openGroup(nil), l.groups...)
+	last := &groups[len(groups)-1]
+	last.fields = append(last.fields[:len(last.fields):len(last.fields)], fields...)
+	return &Logger{core: l.core, name: l.name, context: l.context, groups: groups, levelCache: l.levelCache}
+}
+
+// groupFields nests fields in the groups opened by WithGroup, together with
+// the fields bound to each group.
+func (l *Logger) groupFields(fields []Field) []Field {
+	for i := len(l.groups) - 1; i >= 0; i-- {
+		g := l.groups[i]
+		if len(g.fields)+len(fields) == 0 {
+			continue
+		}
+		members := make(groupValue, 0, len(g.fields)+len(fields))
+		members = append(append(members, g.fields...), fields...)
+		fields = []Field{{Key: g.name, Value: members}}
+	}
+	return fields
+}
+
+// appendJSONGroup appends g as a JSON object.
+func appendJSONGroup(buf []byte, g groupValue) []byte {
+	buf = append(buf, '{')
+	for i, field := range g {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '"')
+		buf = appendJSONString(buf, field.Key)
+		buf = append(buf, '"', ':')
+		buf = appendJSONValue(buf, field.Value)
+	}
+	return append(buf, '}')
+}
+
+// appendFlatGroup appends the fields of g as space-separated key=value
+// pairs, with every key qualified by prefix and a dot, colored for
+// ConsoleFormat when color is set. prefix must have spare capacity for the
+// nested keys, which are built in place. An empty group is written as
+// prefix={}.
+func appendFlatGroup(buf, prefix []byte, g groupValue, color bool) []byte {
+	if len(g) == 0 {
+		return appendFlatField(buf, prefix, g, color)
+	}
+	for i, field := range g {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		key := append(append(prefix, '.'), field.Key...)
+		if sub, ok := field.Value.(groupValue); ok {
+			buf = appendFlatGroup(buf, key, sub, color)
+			continue
+		}
+		buf = appendFlatField(buf, key, field.Value, color)
+	}
+	return buf
+}
+
+// appendFlatField appends a single key=value pair of appendFlatGroup.
+func appendFlatField(buf, key []byte, value any, color bool) []byte {
+	buf = appendColor(buf, ansiCyan, color)
+	buf = append(buf, key...)
+	buf = appendColor(buf, ansiDim, color)
+	buf = append(buf, '=')
+	buf = appendColor(buf, ansiReset, color)
+	return appendValue(buf, value)
+}
+
+// appendTextGroup appends the group field with key and value g for the
+// text formats, see appendFlatGroup.
+func appendTextGroup(buf []byte, key string, g groupValue, color bool) []byte {
+	var stack [64]byte
+	return appendFlatGroup(buf, append(stack[:0], key...), g, color)
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newGroupTestLogger(format Format) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return New(Config{Level: InfoLevel, Format: format, Output: buf, TimestampFormat: TimestampDisabled}), buf
+}
+
+func TestWithGroup(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONFormat, `{"level":"INFO","message":"served","service":"api","http":{"method":"GET","status":200}}`},
+		{TextFormat, `INFO served service=api http.method=GET http.status=200`},
+	}
+	for _, tt := range tests {
+		log, buf := newGroupTestLogger(tt.format)
+
+		log.With(String("service", "api")).WithGroup("http").With(String("method", "GET")).Info("served", Int("status", 200))
+		assert.Equal(t, tt.want+"\n", buf.String())
+	}
+}
+
+func TestWithGroup_Nested(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONFormat, `{"level":"INFO","message":"m","a":{"x":1,"b":{"y":2}}}`},
+		{TextFormat, `INFO m a.x=1 a.b.y=2`},
+	}
+	for _, tt := range tests {
+		log, buf := newGroupTestLogger(tt.format)
+
+		log.WithGroup("a").With(Int("x", 1)).WithGroup("b").Info("m", Int("y", 2))
+		assert.Equal(t, tt.want+"\n", buf.String())
+	}
+}
+
+func TestWithGroup_EmptyGroupsOmitted(t *testing.T) {
+	log, buf := newGroupTestLogger(JSONFormat)
+
+	log.WithGroup("a").WithGroup("b").Info("m")
+	assert.Equal(t, `{"level":"INFO","message":"m"}`+"\n", buf.String())
+	assert.Same(t, log, log.WithGroup(""))
+}
+
+func TestWithGroup_ParentUnchanged(t *testing.T) {
+	log, buf := newGroupTestLogger(TextFormat)
+	grouped := log.WithGroup("g").With(Int("a", 1))
+
+	grouped.With(Int("b", 2)).Info("child")
+	grouped.Info("parent")
+	log.Info("root", Int("c", 3))
+	assert.Equal(t, "INFO child g.a=1 g.b=2\nINFO parent g.a=1\nINFO root c=3\n", buf.String())
+}
+
+func TestWithGroup_NamedAndContextFieldsStayTopLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Format:          JSONFormat,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		ContextExtractor: func(ctx context.Context, appendField func(Field)) {
+			appendField(String("trace_id", "t1"))
+		},
+	})
+
+	log.WithGroup("req").Named("api").WithStaticContext(context.Background()).Info("m", Int("n", 1))
+	assert.Equal(t, `{"level":"INFO","message":"m","logger":"api","trace_id":"t1","req":{"n":1}}`+"\n", buf.String())
+}
+
+func TestGroup(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONFormat, `{"level":"INFO","message":"m","http":{"method":"GET","empty":{}}}`},
+		{TextFormat, `INFO m http.method=GET http.empty={}`},
+	}
+	for _, tt := range tests {
+		log, buf := newGroupTestLogger(tt.format)
+
+		log.Info("m", Group("http", String("method", "GET"), Group("empty")))
+		assert.Equal(t, tt.want+"\n", buf.String())
+	}
+}
+
+func TestGroup_CopiesFields(t *testing.T) {
+	fields := []Field{String("a", "1")}
+	g := Group("g", fields...)
+	fields[0] = String("a", "2")
+
+	assert.Equal(t, groupValue{String("a", "1")}, g.Value)
+}
+
+func TestWithGroup_Redaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Format:          TextFormat,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		RedactKeys:      []string{"password"},
+	})
+	grouped := log.WithGroup("user").With(String("password", "hunter2"))
+
+	grouped.Info("m")
+	assert.Equal(t, "INFO m user.password="+RedactedValue+"\n", buf.String())
+	assert.Equal(t, "hunter2", grouped.groups[0].fields[0].Value, "bound fields are not modified")
+}
+
+func TestWithGroup_Slog(t *testing.T) {
+	log, buf := newGroupTestLogger(JSONFormat)
+
+	slog.New(NewSlogHandler(log.WithGroup("g"))).Info("m", "a", 1)
+	assert.Equal(t, `{"level":"INFO","message":"m","g":{"a":1}}`+"\n", buf.String())
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDropRecord can be returned by a Hook to veto a record. The record is
+// discarded and the remaining hooks are skipped.
+var ErrDropRecord = errors.New("logger: drop record")
+
+// Hook is invoked with every record that passes level filtering, before it
+// is encoded. Hooks may modify the record in place: change the message,
+// rewrite or remove fields, or append new ones. This enables redaction,
+// enrichment and metrics without a custom encoder.
+//
+// Returning ErrDropRecord vetoes the record. Any other error is reported to
+// Config.InternalOutput and the record is still written, so a failing
+// enrichment never loses entries. A panic in Run is recovered and treated
+// the same way, unless Config.StrictPanics is set.
+//
+// The record is only valid for the duration of the call and must not be
+// retained. Hooks run on the logging goroutine, or on the background
+// goroutine in async mode, and must be safe for concurrent use.
+type Hook interface {
+	Run(r *Record) error
+}
+
+// HookFunc adapts an ordinary function to the Hook interface.
+type HookFunc func(r *Record) error
+
+// Run calls f(r).
+func (f HookFunc) Run(r *Record) error {
+	return f(r)
+}
+
+// AddHook appends a hook to the logger. Hooks run in the order they were
+// added. It is safe to call concurrently with logging methods.
+//
+// Example:
+//
+//	logger.AddHook(logger.HookFunc(func(r *logger.Record) error {
+//		r.Fields = append(r.Fields, logger.Field{Key: "region", Value: "eu-west-1"})
+//		return nil
+//	}))
+func (l *Logger) AddHook(hook Hook) {
+	l.hooksMu.Lock()
+	defer l.hooksMu.Unlock()
+
+	var hooks []Hook
+	if current := l.hooks.Load(); current != nil {
+		hooks = append(hooks, *current...)
+	}
+	hooks = append(hooks, hook)
+
+	l.hooks.Store(&hooks)
+}
+
+// runHooks runs all hooks against r and reports whether the record should
+// be written.
+func (l *Logger) runHooks(hooks []Hook, r *Record) bool {
+	for _, hook := range hooks {
+		err := l.runHook(hook, r)
+		if errors.Is(err, ErrDropRecord) {
+			return false
+		}
+		if err != nil {
+			l.internal.report(WarnLevel, "hook failed", String("hook", fmt.Sprintf("%T", hook)), Err(err))
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook_MutatesAndEnrichesRecord(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+	logger.AddHook(HookFunc(func(r *Record) error {
+		for i := range r.Fields {
+			if r.Fields[i].Key == "password" {
+				r.Fields[i].Value = "***"
+			}
+		}
+		r.Fields = append(r.Fields, Field{Key: "region", Value: "eu-west-1"})
+		r.Message += "!"
+		return nil
+	}))
+
+	fields := make([]Field, 1, 4)
+	fields[0] = Field{Key: "password", Value: "hunter2"}
+	logger.Info("login", fields...)
+
+	output := buf.String()
+	assert.Contains(t, output, `"message":"login!"`)
+	assert.Contains(t, output, `"password":"***"`)
+	assert.Contains(t, output, `"region":"eu-west-1"`)
+	assert.Equal(t, "hunter2", fields[0].Value, "caller fields must not be modified")
+	assert.Zero(t, fields[:cap(fields)][1], "caller backing array must not be modified")
+}
+
+func TestHook_VetoAndOrder(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	var calls []string
+	logger.AddHook(HookFunc(func(r *Record) error {
+		calls = append(calls, "first")
+		if r.Message == "secret" {
+			return ErrDropRecord
+		}
+		return errors.New("ignored failure")
+	}))
+	logger.AddHook(HookFunc(func(r *Record) error {
+		calls = append(calls, "second")
+		return nil
+	}))
+
+	logger.Info("secret")
+	logger.Info("public")
+
+	output := buf.String()
+	assert.NotContains(t, output, "secret")
+	assert.Contains(t, output, "public")
+	assert.Equal(t, []string{"first", "first", "second"}, calls)
+}
+
+func TestHook_SeesRecordMetadata(t *testing.T) {
+	logger := New(Config{Level: DebugLevel, Output: &bytes.Buffer{}})
+
+	var got Record
+	logger.AddHook(HookFunc(func(r *Record) error {
+		got = Record{Time: r.Time, Level: r.Level, Message: r.Message}
+		return nil
+	}))
+
+	logger.Warn("careful")
+
+	require.False(t, got.Time.IsZero())
+	assert.Equal(t, WarnLevel, got.Level)
+	assert.Equal(t, "careful", got.Message)
+}
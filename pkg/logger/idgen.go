@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IDGenerator creates unique IDs, such as the request IDs assigned by
+// HTTPMiddleware. Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc adapts an ordinary function to the IDGenerator interface.
+type IDGeneratorFunc func() string
+
+// NewID calls f().
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// NewID returns a new ID from Config.IDGenerator, or a UUIDv4 when none is
+// configured.
+func (l *Logger) NewID() string {
+	if l.config.IDGenerator != nil {
+		return l.config.IDGenerator.NewID()
+	}
+	return defaultIDGenerator.NewID()
+}
+
+var defaultIDGenerator = UUIDv4()
+
+// UUIDv4 returns a generator of random RFC 9562 version 4 UUIDs, e.g.
+// "0b6a4c3e-3f1d-4d7b-9a52-4c1f2e8d9b10".
+func UUIDv4() IDGenerator {
+	return IDGeneratorFunc(func() string {
+		var u [16]byte
+		_, _ = rand.Read(u[:])
+		u[6] = u[6]&0x0f | 0x40
+		u[8] = u[8]&0x3f | 0x80
+		return formatUUID(u)
+	})
+}
+
+// UUIDv7 returns a generator of RFC 9562 version 7 UUIDs, which start with
+// a millisecond timestamp and therefore sort by creation time. IDs from
+// one generator are strictly increasing, even within a millisecond.
+func UUIDv7() IDGenerator {
+	g := &uuidV7Generator{}
+	return IDGeneratorFunc(g.newID)
+}
+
+type uuidV7Generator struct {
+	mu     sync.Mutex
+	lastMS uint64
+	seq    uint16 // 12-bit counter in the rand_a bits
+}
+
+func (g *uuidV7Generator) newID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[6:])
+
+	g.mu.Lock()
+	ms := uint64(time.Now().UnixMilli())
+	if ms > g.lastMS {
+		g.lastMS = ms
+		g.seq = binary.BigEndian.Uint16(u[6:]) & 0x07ff // leave room to count up
+	} else {
+		g.seq++
+		if g.seq > 0x0fff {
+			// Borrow the next millisecond rather than repeat an ID.
+			g.lastMS++
+			g.seq = 0
+		}
+	}
+	ms, seq := g.lastMS, g.seq
+	g.mu.Unlock()
+
+	u[0] = byte(ms >> 40)
+	u[1] = byte(ms >> 32)
+	u[2] = byte(ms >> 24)
+	u[3] = byte(ms >> 16)
+	u[4] = byte(ms >> 8)
+	u[5] = byte(ms)
+	u[6] = 0x70 | byte(seq>>8)
+	u[7] = byte(seq)
+	u[8] = u[8]&0x3f | 0x80
+	return formatUUID(u)
+}
+
+// formatUUID renders u in the canonical 8-4-4-4-12 form.
+func formatUUID(u [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID returns a generator of ULIDs: 26 characters of Crockford base32
+// holding a millisecond timestamp and 80 random bits, e.g.
+// "01ARZ3NDEKTSV4RRFFQ69G5FAV". IDs from one generator are strictly
+// increasing, even within a millisecond.
+func ULID() IDGenerator {
+	g := &ulidGenerator{}
+	return IDGeneratorFunc(g.newID)
+}
+
+type ulidGenerator struct {
+	mu      sync.Mutex
+	lastMS  uint64
+	entropy [10]byte
+}
+
+func (g *ulidGenerator) newID() string {
+	g.mu.Lock()
+	ms := uint64(time.Now().UnixMilli())
+	if ms > g.lastMS {
+		g.lastMS = ms
+		_, _ = rand.Read(g.entropy[:])
+	} else {
+		// Increment the entropy as a big-endian number.
+		for i := len(g.entropy) - 1; i >= 0; i-- {
+			g.entropy[i]++
+			if g.entropy[i] != 0 {
+				break
+			}
+			if i == 0 {
+				g.lastMS++
+			}
+		}
+	}
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(g.lastMS>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(g.lastMS))
+	copy(id[6:], g.entropy[:])
+	g.mu.Unlock()
+
+	// 128 bits as 26 base32 digits, the first holding the top 3 bits.
+	hi := binary.BigEndian.Uint64(id[0:8])
+	lo := binary.BigEndian.Uint64(id[8:16])
+	var buf [26]byte
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+// SnowflakeEpoch is the epoch of Snowflake IDs, 2020-01-01 UTC.
+var SnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// MaxSnowflakeNode is the largest node ID accepted by Snowflake.
+const MaxSnowflakeNode = 1<<10 - 1
+
+// errSnowflakeNode is returned by Snowflake for an out-of-range node ID.
+var errSnowflakeNode = errors.New("logger: snowflake node ID must be between 0 and " + strconv.Itoa(MaxSnowflakeNode))
+
+// Snowflake returns a generator of Snowflake IDs, rendered in decimal: 41
+// bits of milliseconds since SnowflakeEpoch, 10 bits of node ID and a 12 bit
+// sequence. Every process generating IDs concurrently needs its own node
+// ID for the IDs to be unique. IDs from one generator are strictly
+// increasing; when a millisecond's 4096 IDs are used up, the generator
+// waits for the next millisecond.
+func Snowflake(node int64) (IDGenerator, error) {
+	if node < 0 || node > MaxSnowflakeNode {
+		return nil, errSnowflakeNode
+	}
+	g := &snowflakeGenerator{node: node}
+	return IDGeneratorFunc(g.newID), nil
+}
+
+type snowflakeGenerator struct {
+	node int64
+
+	mu     sync.Mutex
+	lastMS int64
+	seq    int64
+}
+
+func (g *snowflakeGenerator) newID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := time.Since(SnowflakeEpoch).Milliseconds()
+	if ms < g.lastMS {
+		// The clock went backwards; keep counting from the last value.
+		ms = g.lastMS
+	}
+	if ms == g.lastMS {
+		g.seq = (g.seq + 1) & 0xfff
+		if g.seq == 0 {
+			for ms <= g.lastMS {
+				time.Sleep(time.Millisecond / 10)
+				ms = time.Since(SnowflakeEpoch).Milliseconds()
+			}
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastMS = ms
+
+	return strconv.FormatInt(ms<<22|g.node<<12|g.seq, 10)
+}
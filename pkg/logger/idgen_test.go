@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-([0-9a-f])[0-9a-f]{3}-([0-9a-f])[0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestUUIDv4(t *testing.T) {
+	gen := UUIDv4()
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := gen.NewID()
+		m := uuidPattern.FindStringSubmatch(id)
+		require.NotNil(t, m, id)
+		assert.Equal(t, "4", m[1], "version")
+		assert.Contains(t, "89ab", m[2], "variant")
+		assert.False(t, seen[id], "duplicate %s", id)
+		seen[id] = true
+	}
+}
+
+func TestUUIDv7(t *testing.T) {
+	gen := UUIDv7()
+	prev := ""
+	for i := 0; i < 10000; i++ {
+		id := gen.NewID()
+		m := uuidPattern.FindStringSubmatch(id)
+		require.NotNil(t, m, id)
+		assert.Equal(t, "7", m[1], "version")
+		assert.Contains(t, "89ab", m[2], "variant")
+		require.Greater(t, id, prev)
+		prev = id
+	}
+}
+
+func TestULID(t *testing.T) {
+	gen := ULID()
+	prev := ""
+	for i := 0; i < 10000; i++ {
+		id := gen.NewID()
+		require.Len(t, id, 26)
+		for _, c := range id {
+			require.True(t, strings.ContainsRune(crockford, c), id)
+		}
+		require.Greater(t, id, prev)
+		prev = id
+	}
+	assert.LessOrEqual(t, prev[:1], "7", "the first digit holds only 3 bits")
+}
+
+func TestSnowflake(t *testing.T) {
+	for _, node := range []int64{-1, MaxSnowflakeNode + 1} {
+		_, err := Snowflake(node)
+		assert.Error(t, err, node)
+	}
+
+	gen, err := Snowflake(7)
+	require.NoError(t, err)
+
+	var prev int64
+	for i := 0; i < 10000; i++ {
+		id, err := strconv.ParseInt(gen.NewID(), 10, 64)
+		require.NoError(t, err)
+		require.Greater(t, id, prev)
+		assert.Equal(t, int64(7), id>>12&MaxSnowflakeNode, "node")
+		prev = id
+	}
+}
+
+func TestLogger_NewID(t *testing.T) {
+	assert.Regexp(t, uuidPattern, New(Config{}).NewID())
+
+	log := New(Config{IDGenerator: IDGeneratorFunc(func() string { return "fixed" })})
+	assert.Equal(t, "fixed", log.NewID())
+	assert.Equal(t, "fixed", log.Named("child").NewID())
+}
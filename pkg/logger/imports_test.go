@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImports_NoExpvar guards against importing expvar, whose init
+// registers /debug/vars on http.DefaultServeMux in every program that
+// imports the logger.
+func TestImports_NoExpvar(t *testing.T) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	out, err := exec.Command(gobin, "list", "-deps", ".").Output()
+	require.NoError(t, err)
+
+	deps := strings.Fields(string(out))
+	assert.Contains(t, deps, "github.com/barnowlsnest/go-logslib/pkg/logmetrics")
+	assert.False(t, slices.Contains(deps, "expvar"), "pkg/logger must not import expvar")
+}
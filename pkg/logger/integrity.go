@@ -0,0 +1,257 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Keys of the fields added by an IntegrityWriter.
+const (
+	// PrevHashKey holds the SHA-256 of the previous line written by an
+	// IntegrityWriter with WithIntegrityHashChain, in hex. The first line
+	// of a chain holds zeros.
+	PrevHashKey = "prev_hash"
+
+	// SignatureKey holds the Ed25519 signature of the line, in hex, when
+	// the IntegrityWriter was given WithIntegritySigningKey. It covers
+	// everything before it, including PrevHashKey, and is always the last
+	// field.
+	SignatureKey = "sig"
+)
+
+// Errors returned by VerifyIntegrity, wrapped with the line number.
+var (
+	ErrHashChainBroken  = errors.New("logger: hash chain broken")
+	ErrSignatureInvalid = errors.New("logger: invalid signature")
+)
+
+// integrityOptions holds the settings of an IntegrityWriter.
+type integrityOptions struct {
+	chain bool
+	key   ed25519.PrivateKey
+}
+
+// IntegrityOption configures an IntegrityWriter.
+type IntegrityOption func(*integrityOptions)
+
+// WithIntegrityHashChain adds a PrevHashKey field to every line, chaining
+// each line to the one before, so removing, reordering or altering lines
+// breaks the chain. Lines cut off at the end of the output leave no trace
+// in the chain; sign the lines or record the last hash elsewhere to detect
+// that.
+func WithIntegrityHashChain() IntegrityOption {
+	return func(o *integrityOptions) {
+		o.chain = true
+	}
+}
+
+// WithIntegritySigningKey adds a SignatureKey field holding the Ed25519
+// signature of every line under key, so lines cannot be forged without
+// the key.
+func WithIntegritySigningKey(key ed25519.PrivateKey) IntegrityOption {
+	return func(o *integrityOptions) {
+		o.key = key
+	}
+}
+
+// IntegrityWriter is an output that makes tampering with the lines it
+// writes evident downstream: with WithIntegrityHashChain every line
+// carries the hash of the previous one, and with WithIntegritySigningKey
+// an Ed25519 signature. The fields are added to each line as written, so
+// they work with any format producing one entry per line: JSON lines get
+// them before the closing brace, other lines at the end as key=value.
+// VerifyIntegrity checks an output written this way.
+//
+// Every IntegrityWriter starts its own chain, so use one per output and
+// restart the chain only when the output starts over, e.g. with a new
+// file. It is safe for concurrent use.
+type IntegrityWriter struct {
+	w    io.Writer
+	opts integrityOptions
+
+	mu    sync.Mutex
+	prev  [sha256.Size]byte
+	buf   []byte
+	lines []integrityLine
+}
+
+// integrityLine is a line of a Write: its end in the output and in the
+// input, and its hash.
+type integrityLine struct {
+	end, consumed int
+	hash          [sha256.Size]byte
+}
+
+// NewIntegrityWriter returns an IntegrityWriter writing to w. Without
+// options it passes lines through unchanged.
+//
+// Example:
+//
+//	w := logger.NewIntegrityWriter(file,
+//		logger.WithIntegrityHashChain(),
+//		logger.WithIntegritySigningKey(privateKey),
+//	)
+//	log := logger.New(logger.Config{Format: logger.JSONFormat, Output: w})
+func NewIntegrityWriter(w io.Writer, opts ...IntegrityOption) *IntegrityWriter {
+	iw := &IntegrityWriter{w: w}
+	for _, opt := range opts {
+		opt(&iw.opts)
+	}
+	return iw
+}
+
+// Write adds the integrity fields to every line of p and writes the lines
+// to the underlying writer at once. The chain only advances past the lines
+// the underlying writer accepted, so that a retry of the rest continues
+// it.
+func (w *IntegrityWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prev := w.prev
+	buf := w.buf[:0]
+	lines := w.lines[:0]
+	for rest := p; len(rest) > 0; {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = nil
+		}
+
+		start := len(buf)
+		buf = append(buf, line...)
+		if w.opts.chain {
+			buf = appendHexField(buf[:start], buf[start:], PrevHashKey, prev[:])
+		}
+		if w.opts.key != nil {
+			buf = appendHexField(buf[:start], buf[start:], SignatureKey, ed25519.Sign(w.opts.key, buf[start:]))
+		}
+		if w.opts.chain {
+			prev = sha256.Sum256(buf[start:])
+		}
+		buf = append(buf, '\n')
+		lines = append(lines, integrityLine{end: len(buf), consumed: len(p) - len(rest), hash: prev})
+	}
+	w.buf, w.lines = buf, lines
+
+	n, err := w.w.Write(buf)
+	if err != nil {
+		written := 0
+		for _, line := range lines {
+			if line.end > n {
+				break
+			}
+			w.prev, written = line.hash, line.consumed
+		}
+		return written, err
+	}
+	w.prev = prev
+	return len(p), nil
+}
+
+// lineOutput implements lineOutput.
+func (w *IntegrityWriter) lineOutput() {}
+
+// Sync commits the underlying writer to stable storage if it supports it.
+func (w *IntegrityWriter) Sync() error {
+	if s, ok := w.w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// VerifyIntegrity reads the lines written by an IntegrityWriter from r and
+// checks them. When publicKey is set, every line must carry a valid
+// SignatureKey field. When the first line carries a PrevHashKey field,
+// every line must, each holding the hash of the line before; a line
+// holding zeros starts a new chain. The first failing line is reported as
+// an error wrapping ErrSignatureInvalid or ErrHashChainBroken.
+func VerifyIntegrity(r io.Reader, publicKey ed25519.PublicKey) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+
+	var prev [sha256.Size]byte
+	chained := false
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+
+		signed := line
+		if publicKey != nil {
+			rest, sig, ok := cutHexField(line, SignatureKey)
+			if !ok || !ed25519.Verify(publicKey, rest, sig) {
+				return fmt.Errorf("line %d: %w", n, ErrSignatureInvalid)
+			}
+			signed = rest
+		}
+
+		_, hash, ok := cutHexField(signed, PrevHashKey)
+		if n == 1 {
+			chained = ok
+		}
+		if chained && (!ok || len(hash) != sha256.Size ||
+			!bytes.Equal(hash, prev[:]) && !bytes.Equal(hash, make([]byte, sha256.Size))) {
+			return fmt.Errorf("line %d: %w", n, ErrHashChainBroken)
+		}
+		prev = sha256.Sum256(line)
+	}
+	return scanner.Err()
+}
+
+// isJSONLine reports whether line holds a JSON object.
+func isJSONLine(line []byte) bool {
+	return len(line) >= 2 && line[0] == '{' && line[len(line)-1] == '}'
+}
+
+// appendHexField appends line to buf with a field key holding value in
+// hex: inside the object for a JSON line, as key=value at the end
+// otherwise. line may be the tail of buf.
+func appendHexField(buf, line []byte, key string, value []byte) []byte {
+	if !isJSONLine(line) {
+		buf = append(buf, line...)
+		buf = append(buf, ' ')
+		buf = append(buf, key...)
+		buf = append(buf, '=')
+		return hex.AppendEncode(buf, value)
+	}
+	buf = append(buf, line[:len(line)-1]...)
+	buf = append(buf, `,"`...)
+	buf = append(buf, key...)
+	buf = append(buf, `":"`...)
+	buf = hex.AppendEncode(buf, value)
+	return append(buf, `"}`...)
+}
+
+// cutHexField reverses appendHexField: it returns line without the field
+// key, which must be the last one, and the decoded value.
+func cutHexField(line []byte, key string) (rest, value []byte, ok bool) {
+	var encoded []byte
+	if isJSONLine(line) {
+		i := bytes.LastIndex(line, []byte(`,"`+key+`":"`))
+		if i < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+			return nil, nil, false
+		}
+		encoded = line[i+len(key)+5 : len(line)-2]
+		rest = append(line[:i:i], '}')
+	} else {
+		i := bytes.LastIndex(line, []byte(" "+key+"="))
+		if i < 0 {
+			return nil, nil, false
+		}
+		encoded = line[i+len(key)+2:]
+		rest = line[:i]
+	}
+
+	value, err := hex.AppendDecode(nil, encoded)
+	if err != nil {
+		return nil, nil, false
+	}
+	return rest, value, true
+}
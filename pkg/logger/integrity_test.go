@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeIntegrityLog(t *testing.T, format Format, bufferSize int, opts ...IntegrityOption) string {
+	t.Helper()
+
+	out := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Format:          format,
+		Output:          NewIntegrityWriter(out, opts...),
+		BufferSize:      bufferSize,
+		TimestampFormat: TimestampDisabled,
+	})
+	for _, msg := range []string{"first", "second", "third"} {
+		log.Info(msg, String("user", "42"))
+	}
+	log.Flush()
+	return out.String()
+}
+
+func TestIntegrityWriter_HashChain(t *testing.T) {
+	for _, format := range []Format{JSONFormat, TextFormat} {
+		for _, bufferSize := range []int{0, 1 << 10} {
+			written := writeIntegrityLog(t, format, bufferSize, WithIntegrityHashChain())
+			lines := strings.Split(strings.TrimSuffix(written, "\n"), "\n")
+			require.Len(t, lines, 3)
+			assert.Contains(t, lines[0], strings.Repeat("0", 64))
+			require.NoError(t, VerifyIntegrity(strings.NewReader(written), nil), format)
+
+			// Removing, reordering or altering a line breaks the chain.
+			for name, tampered := range map[string][]string{
+				"removed":   {lines[0], lines[2]},
+				"reordered": {lines[1], lines[0], lines[2]},
+				"altered":   {lines[0], strings.Replace(lines[1], "42", "43", 1), lines[2]},
+			} {
+				err := VerifyIntegrity(strings.NewReader(strings.Join(tampered, "\n")), nil)
+				assert.ErrorIs(t, err, ErrHashChainBroken, name)
+			}
+		}
+	}
+}
+
+func TestIntegrityWriter_JSONStaysValid(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	written := writeIntegrityLog(t, JSONFormat, 0, WithIntegrityHashChain(), WithIntegritySigningKey(key))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.SplitN(written, "\n", 2)[0]), &entry))
+	assert.Equal(t, "first", entry["message"])
+	assert.Len(t, entry[PrevHashKey], 64)
+	assert.Len(t, entry[SignatureKey], 128)
+}
+
+func TestIntegrityWriter_Signature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherPublic, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	for _, format := range []Format{JSONFormat, TextFormat} {
+		written := writeIntegrityLog(t, format, 0, WithIntegrityHashChain(), WithIntegritySigningKey(private))
+		require.NoError(t, VerifyIntegrity(strings.NewReader(written), public), format)
+		assert.ErrorIs(t, VerifyIntegrity(strings.NewReader(written), otherPublic), ErrSignatureInvalid)
+
+		forged := strings.Replace(written, "second", "SECOND", 1)
+		err := VerifyIntegrity(strings.NewReader(forged), public)
+		assert.ErrorIs(t, err, ErrSignatureInvalid)
+		assert.ErrorContains(t, err, "line 2")
+	}
+
+	unsigned := writeIntegrityLog(t, JSONFormat, 0)
+	assert.ErrorIs(t, VerifyIntegrity(strings.NewReader(unsigned), public), ErrSignatureInvalid)
+	assert.NoError(t, VerifyIntegrity(strings.NewReader(unsigned), nil))
+}
+
+func TestIntegrityWriter_ChainRestart(t *testing.T) {
+	first := writeIntegrityLog(t, JSONFormat, 0, WithIntegrityHashChain())
+	second := writeIntegrityLog(t, JSONFormat, 0, WithIntegrityHashChain())
+	assert.NoError(t, VerifyIntegrity(strings.NewReader(first+second), nil))
+}
+
+func TestIntegrityWriter_AuditLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	audit, err := NewAuditLogger(AuditConfig{
+		Output:  NewIntegrityWriter(out, WithIntegrityHashChain()),
+		Format:  JSONFormat,
+		HMACKey: []byte("secret"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, audit.Audit("first"))
+	require.NoError(t, audit.Audit("second"))
+
+	require.NoError(t, VerifyIntegrity(bytes.NewReader(out.Bytes()), nil))
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		rest, _, ok := cutHexField([]byte(strings.TrimSuffix(line, "\n")), PrevHashKey)
+		require.True(t, ok)
+		assert.NoError(t, VerifyAuditEntry([]byte("secret"), rest))
+	}
+}
+
+func TestIntegrityWriter_FailedWriteKeepsChain(t *testing.T) {
+	clean := &bytes.Buffer{}
+	_, err := NewIntegrityWriter(clean, WithIntegrityHashChain()).Write([]byte("first\nsecond\n"))
+	require.NoError(t, err)
+	lines := strings.SplitAfter(clean.String(), "\n")
+
+	out := &flakyWriter{failures: 1, limit: len(lines[0]) + 10}
+	w := NewIntegrityWriter(out, WithIntegrityHashChain())
+	_, err = w.Write([]byte("first\nsecond\n"))
+	require.Error(t, err)
+
+	// The short write accepts the first line only, so the chain continues
+	// from it and a retry of the rest writes the same second line.
+	n, err := w.Write([]byte("first\nsecond\n"))
+	require.Error(t, err)
+	assert.Equal(t, len("first\n"), n)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	assert.Equal(t, lines[0]+lines[1][:10]+lines[1], out.String())
+}
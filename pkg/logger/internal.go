@@ -0,0 +1,45 @@
+package logger
+
+import "io"
+
+// internalName is the name of the logger writing to Config.InternalOutput.
+const internalName = "logslib"
+
+// internalRateLimit caps the reports of each level written to
+// Config.InternalOutput. A broken output fails every entry, and reporting
+// each failure would drown the reports that matter.
+var internalRateLimit = RateLimit{EventsPerSecond: 1, Burst: 10}
+
+// internalReporter is implemented by outputs that report their own
+// problems, such as lost connections, to Config.InternalOutput. An output
+// shared by several loggers reports to the one it was added to last.
+type internalReporter interface {
+	setInternal(internal *Logger)
+}
+
+// newInternalLogger creates the logger writing to w, or returns nil when w
+// is nil.
+func newInternalLogger(w io.Writer) *Logger {
+	if w == nil {
+		return nil
+	}
+	return New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: w,
+		RateLimit: map[Level]RateLimit{
+			InfoLevel:  internalRateLimit,
+			WarnLevel:  internalRateLimit,
+			ErrorLevel: internalRateLimit,
+		},
+	}).Named(internalName)
+}
+
+// report logs a problem of the library to the internal logger l. It does
+// nothing when l is nil, i.e. without Config.InternalOutput.
+func (l *Logger) report(level Level, msg string, fields ...Field) {
+	if l == nil {
+		return
+	}
+	l.log(level, msg, fields...)
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternalOutput_WriteFailure(t *testing.T) {
+	internal := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: failingWriter{}, InternalOutput: internal})
+
+	log.Info("lost")
+	assert.Contains(t, internal.String(), "ERROR write failed")
+	assert.Contains(t, internal.String(), "logger=logslib")
+	assert.Contains(t, internal.String(), "disk full")
+	assert.NotContains(t, internal.String(), "lost")
+}
+
+func TestInternalOutput_RateLimited(t *testing.T) {
+	internal := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: failingWriter{}, InternalOutput: internal})
+
+	for range 100 {
+		log.Info("lost")
+	}
+	assert.Equal(t, internalRateLimit.Burst, strings.Count(internal.String(), "write failed"))
+}
+
+func TestInternalOutput_HookError(t *testing.T) {
+	internal, out := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: out, InternalOutput: internal})
+	log.AddHook(HookFunc(func(*Record) error { return errors.New("lookup failed") }))
+
+	log.Info("still written")
+	assert.Contains(t, out.String(), "still written")
+	assert.Contains(t, internal.String(), "WARN hook failed")
+	assert.Contains(t, internal.String(), "lookup failed")
+}
+
+func TestInternalOutput_OnFatalPanic(t *testing.T) {
+	internal := &bytes.Buffer{}
+	exited := false
+	log := New(Config{
+		Level:          InfoLevel,
+		Output:         &bytes.Buffer{},
+		InternalOutput: internal,
+		OnFatal:        []func(){func() { panic("cleanup broke") }},
+		ExitFunc:       func(int) { exited = true },
+	})
+
+	log.Fatal("giving up")
+	assert.True(t, exited)
+	assert.Contains(t, internal.String(), "OnFatal function panicked")
+	assert.Contains(t, internal.String(), "cleanup broke")
+}
+
+func TestInternalOutput_AsyncDrop(t *testing.T) {
+	internal := &syncBuffer{}
+	block := make(chan struct{})
+	log := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}, AsyncQueueSize: 1, InternalOutput: internal})
+	log.AddHook(HookFunc(func(*Record) error { <-block; return nil }))
+
+	for range 5 {
+		log.Info("shed")
+	}
+	close(block)
+	require.NoError(t, log.Close())
+	assert.Contains(t, internal.String(), "WARN entry dropped logger=logslib reason=async_queue")
+}
+
+func TestInternalOutput_SamplingNotReported(t *testing.T) {
+	internal := &bytes.Buffer{}
+	log := New(Config{
+		Level:          InfoLevel,
+		Output:         &bytes.Buffer{},
+		InternalOutput: internal,
+		Sampling:       &SamplingConfig{First: 1, Window: time.Hour},
+	})
+
+	log.Info("sampled")
+	log.Info("sampled")
+	assert.Empty(t, internal.String())
+}
+
+func TestInternalOutput_NetWriterReconnect(t *testing.T) {
+	addr := unusedAddr(t)
+	w, err := NewNetWriter("tcp", addr, WithNetBackoff(10*time.Millisecond, 50*time.Millisecond))
+	require.NoError(t, err)
+	defer w.Close()
+
+	internal := &syncBuffer{}
+	log := New(Config{Level: InfoLevel, Output: w, InternalOutput: internal})
+	log.Info("buffered")
+
+	listener, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer listener.Close()
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(internal.String(), "INFO collector reconnected")
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestInternalOutput_PartitionRotationFailure(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewPartitionWriter(filepath.Join(dir, "{tenant}.log"), WithPartitionRotation(10, 1))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	internal := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: w, InternalOutput: internal})
+	log.Info("first", String("tenant", "acme"))
+
+	// A directory in the backup slot makes the rename fail.
+	path := filepath.Join(dir, "acme.log")
+	require.NoError(t, os.Mkdir(path+".1", 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(path+".1", "keep"), nil, 0o644))
+	log.Info("second", String("tenant", "acme"))
+
+	assert.Contains(t, internal.String(), "ERROR partition rotation failed")
+}
+
+func TestWithInternalOutput(t *testing.T) {
+	internal := &bytes.Buffer{}
+	log, err := NewWithOptions(WithOutput(failingWriter{}), WithInternalOutput(internal))
+	require.NoError(t, err)
+
+	log.Info("lost")
+	assert.Contains(t, internal.String(), "write failed")
+}
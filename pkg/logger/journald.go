@@ -0,0 +1,50 @@
+package logger
+
+import "errors"
+
+// defaultJournaldSocket is the native protocol socket of systemd-journald.
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+// ErrJournaldUnsupported is returned by NewJournaldWriter on platforms
+// without systemd-journald.
+var ErrJournaldUnsupported = errors.New("logger: journald is only supported on linux")
+
+// JournaldConfig configures a JournaldWriter.
+type JournaldConfig struct {
+	// SyslogIdentifier is sent as SYSLOG_IDENTIFIER. Defaults to the base
+	// name of the executable.
+	SyslogIdentifier string
+
+	// Socket is the path of the journald socket. Defaults to
+	// "/run/systemd/journal/socket".
+	Socket string
+}
+
+// appendJournaldKey appends key converted to a journal field name: upper
+// case letters, digits and underscores, not starting with an underscore or
+// a digit, and at most 64 characters long.
+func appendJournaldKey(buf []byte, key string) []byte {
+	start := len(buf)
+	for i := 0; i < len(key) && len(buf)-start < 64; i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			c = '_'
+		}
+		if len(buf) == start && (c == '_' || c >= '0' && c <= '9') {
+			// Leading underscores are reserved for trusted fields.
+			if c == '_' {
+				continue
+			}
+			buf = append(buf, 'F', '_')
+		}
+		buf = append(buf, c)
+	}
+	if len(buf) == start {
+		buf = append(buf, "FIELD"...)
+	}
+	return buf[:min(len(buf), start+64)]
+}
@@ -0,0 +1,186 @@
+//go:build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// JournaldWriter delivers log entries to systemd-journald using its native
+// protocol. The entry level is sent as PRIORITY, the message as MESSAGE and
+// every field as a journal field with an upper-cased name, e.g. "userID"
+// becomes USERID, so entries can be filtered with journalctl USERID=42.
+//
+// Entries too large for a single datagram are passed to journald through a
+// deleted temporary file, as the protocol specifies.
+type JournaldWriter struct {
+	addr   *net.UnixAddr
+	header []byte // SYSLOG_IDENTIFIER field sent with every entry
+
+	mu    sync.Mutex
+	conn  *net.UnixConn
+	buf   []byte
+	value []byte
+}
+
+// NewJournaldWriter connects to the local journald socket.
+//
+// Example:
+//
+//	w, err := logger.NewJournaldWriter(logger.JournaldConfig{SyslogIdentifier: "billing-api"})
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Close()
+//
+//	log := logger.New(logger.Config{Output: w})
+func NewJournaldWriter(cfg JournaldConfig) (*JournaldWriter, error) {
+	if cfg.Socket == "" {
+		cfg.Socket = defaultJournaldSocket
+	}
+	if cfg.SyslogIdentifier == "" {
+		cfg.SyslogIdentifier = filepath.Base(os.Args[0])
+	}
+
+	// Fail early when journald is not running.
+	if _, err := os.Stat(cfg.Socket); err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournaldWriter{
+		addr:   &net.UnixAddr{Name: cfg.Socket, Net: "unixgram"},
+		header: appendJournaldField(nil, "SYSLOG_IDENTIFIER", cfg.SyslogIdentifier),
+		conn:   conn,
+	}, nil
+}
+
+// Write sends p as a single entry at informational priority without
+// fields. It allows a JournaldWriter to be used as a plain io.Writer.
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = w.appendHeader(w.buf[:0], InfoLevel)
+	w.buf = appendJournaldField(w.buf, "MESSAGE", bytes.TrimRight(p, "\n"))
+	if err := w.send(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteRecord implements RecordWriter. The sink format is not used:
+// journald stores the message and fields as separate journal fields.
+func (w *JournaldWriter) WriteRecord(r *Record, _ []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf := w.appendHeader(w.buf[:0], r.Level)
+	buf = appendJournaldField(buf, "MESSAGE", r.Message)
+	for _, field := range r.Fields {
+		buf = appendJournaldKey(buf, field.Key)
+		if s, ok := field.Value.(string); ok {
+			buf = appendJournaldValue(buf, s)
+			continue
+		}
+		w.value = appendValue(w.value[:0], field.Value)
+		buf = appendJournaldValue(buf, w.value)
+	}
+	w.buf = buf
+
+	return w.send()
+}
+
+// Close closes the socket.
+func (w *JournaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.conn.Close()
+}
+
+// appendHeader appends the PRIORITY and SYSLOG_IDENTIFIER fields.
+func (w *JournaldWriter) appendHeader(buf []byte, level Level) []byte {
+	buf = append(buf, "PRIORITY="...)
+	buf = append(buf, byte('0'+syslogSeverity(level)), '\n')
+	return append(buf, w.header...)
+}
+
+// send delivers w.buf as one datagram. It must be called with w.mu held.
+func (w *JournaldWriter) send() error {
+	_, _, err := w.conn.WriteMsgUnix(w.buf, nil, w.addr)
+	if err != nil && isMessageTooLarge(err) {
+		err = w.sendFile(w.buf)
+	}
+	return err
+}
+
+// sendFile passes an entry that does not fit into a datagram as a file
+// descriptor. It must be called with w.mu held.
+func (w *JournaldWriter) sendFile(entry []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "logslib-journal-")
+	if err != nil {
+		f, err = os.CreateTemp("", "logslib-journal-")
+		if err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(entry); err != nil {
+		return err
+	}
+
+	_, _, err = w.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), w.addr)
+	return err
+}
+
+// isMessageTooLarge reports whether err means the datagram exceeded the
+// socket limits.
+func isMessageTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// appendJournaldField appends a field in the native protocol format.
+func appendJournaldField[T string | []byte](buf []byte, name string, value T) []byte {
+	buf = append(buf, name...)
+	return appendJournaldValue(buf, value)
+}
+
+// appendJournaldValue appends the value part of a field. Values containing a
+// newline are sent in the binary-safe form: a newline, the little-endian
+// 64-bit length and the raw value.
+func appendJournaldValue[T string | []byte](buf []byte, value T) []byte {
+	multiline := false
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\n' {
+			multiline = true
+			break
+		}
+	}
+
+	if !multiline {
+		buf = append(buf, '=')
+		buf = append(buf, value...)
+		return append(buf, '\n')
+	}
+
+	buf = append(buf, '\n')
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(len(value)))
+	buf = append(buf, value...)
+	return append(buf, '\n')
+}
@@ -0,0 +1,133 @@
+//go:build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenJournal starts a fake journald socket.
+func listenJournal(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, path
+}
+
+// readJournalEntry reads one datagram, following a passed file descriptor,
+// and parses the native protocol fields.
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 1<<20)
+	oob := make([]byte, 128)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+	data := buf[:n]
+
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		require.NoError(t, err)
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		require.NoError(t, err)
+		f := os.NewFile(uintptr(fds[0]), "journal")
+		defer f.Close()
+		_, err = f.Seek(0, io.SeekStart)
+		require.NoError(t, err)
+		data, err = io.ReadAll(f)
+		require.NoError(t, err)
+	}
+
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		require.GreaterOrEqual(t, i, 0)
+		name := string(data[:i])
+
+		if data[i] == '=' {
+			end := bytes.IndexByte(data, '\n')
+			fields[name] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+
+		size := binary.LittleEndian.Uint64(data[i+1 : i+9])
+		fields[name] = string(data[i+9 : i+9+int(size)])
+		data = data[i+9+int(size)+1:]
+	}
+	return fields
+}
+
+func TestJournaldWriter(t *testing.T) {
+	server, path := listenJournal(t)
+
+	w, err := NewJournaldWriter(JournaldConfig{SyslogIdentifier: "billing", Socket: path})
+	require.NoError(t, err)
+	defer w.Close()
+
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: w})
+	logger.Error("payment failed\nretrying",
+		Field{Key: "userID", Value: 42},
+		Field{Key: "order id", Value: "A-1"},
+	)
+
+	fields := readJournalEntry(t, server)
+	assert.Equal(t, "3", fields["PRIORITY"])
+	assert.Equal(t, "billing", fields["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "payment failed\nretrying", fields["MESSAGE"])
+	assert.Equal(t, "42", fields["USERID"])
+	assert.Equal(t, "A-1", fields["ORDER_ID"])
+}
+
+func TestJournaldWriter_PlainWrite(t *testing.T) {
+	server, path := listenJournal(t)
+
+	w, err := NewJournaldWriter(JournaldConfig{Socket: path})
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	fields := readJournalEntry(t, server)
+	assert.Equal(t, "6", fields["PRIORITY"])
+	assert.Equal(t, "hello", fields["MESSAGE"])
+	assert.Equal(t, filepath.Base(os.Args[0]), fields["SYSLOG_IDENTIFIER"])
+}
+
+func TestJournaldWriter_LargeEntryPassesFile(t *testing.T) {
+	server, path := listenJournal(t)
+
+	w, err := NewJournaldWriter(JournaldConfig{Socket: path})
+	require.NoError(t, err)
+	defer w.Close()
+
+	msg := strings.Repeat("x", 512*1024)
+	_, err = w.Write([]byte(msg))
+	require.NoError(t, err)
+
+	fields := readJournalEntry(t, server)
+	assert.Equal(t, msg, fields["MESSAGE"])
+}
+
+func TestNewJournaldWriter_MissingSocket(t *testing.T) {
+	_, err := NewJournaldWriter(JournaldConfig{Socket: filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package logger
+
+// JournaldWriter is not available on this platform.
+type JournaldWriter struct{}
+
+// NewJournaldWriter returns ErrJournaldUnsupported on this platform.
+func NewJournaldWriter(JournaldConfig) (*JournaldWriter, error) {
+	return nil, ErrJournaldUnsupported
+}
+
+// Write returns ErrJournaldUnsupported.
+func (w *JournaldWriter) Write([]byte) (int, error) {
+	return 0, ErrJournaldUnsupported
+}
+
+// WriteRecord returns ErrJournaldUnsupported.
+func (w *JournaldWriter) WriteRecord(*Record, []byte) error {
+	return ErrJournaldUnsupported
+}
+
+// Close does nothing.
+func (w *JournaldWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendJournaldKey(t *testing.T) {
+	tests := map[string]string{
+		"userID":      "USERID",
+		"http.status": "HTTP_STATUS",
+		"_private":    "PRIVATE",
+		"2fa":         "F_2FA",
+		"":            "FIELD",
+		"___":         "FIELD",
+	}
+
+	for key, want := range tests {
+		assert.Equal(t, want, string(appendJournaldKey(nil, key)), key)
+	}
+
+	long := string(appendJournaldKey(nil, "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz"))
+	assert.Len(t, long, 64)
+}
@@ -1,28 +1,42 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
 	"time"
+	"unicode/utf8"
 )
 
+// prettyJSONIndent is the indentation of Config.PrettyJSON.
+const prettyJSONIndent = "  "
+
 // appendJSON formats a log entry in JSON format and appends it to the buffer.
 // It creates a JSON object with timestamp, level, message, and any additional fields.
+// The timestamp is rendered according to Config.TimestampFormat and is
+// omitted when the entry has a zero time.
 // This method is optimized for minimal allocations using buffer operations.
-func (l *Logger) appendJSON(buf []byte, level Level, msg string, fields ...Field) []byte {
+func (l *Logger) appendJSON(buf []byte, keys *jsonKeys, r *Record) []byte {
 	buf = append(buf, '{')
 
-	buf = append(buf, `"timestamp":"`...)
-	buf = append(buf, time.Now().Format(time.RFC3339Nano)...)
-	buf = append(buf, '"')
-
-	buf = append(buf, `,"level":"`...)
-	buf = append(buf, level.String()...)
+	if omitsTimestamp(r.Time, l.config.TimestampFormat) {
+		// Entries without a timestamp, such as slog records with a zero
+		// time, start with the level; its key is preceded by a comma.
+		buf = append(buf, keys.level[1:]...)
+	} else {
+		buf = append(buf, keys.timestamp...)
+		buf = l.timeCache.appendJSON(buf, r.Time, l.config.TimestampFormat)
+		buf = append(buf, keys.level...)
+	}
+	buf = append(buf, r.Level.String()...)
 	buf = append(buf, '"')
 
-	buf = append(buf, `,"message":"`...)
-	buf = appendJSONString(buf, msg)
+	buf = append(buf, keys.message...)
+	buf = appendJSONString(buf, r.Message)
 	buf = append(buf, '"')
 
-	for _, field := range fields {
+	for _, field := range r.Fields {
 		buf = append(buf, ',', '"')
 		buf = appendJSONString(buf, field.Key)
 		buf = append(buf, '"', ':')
@@ -34,15 +48,44 @@ func (l *Logger) appendJSON(buf []byte, level Level, msg string, fields ...Field
 }
 
 // appendJSONString escapes and appends a string value to the JSON buffer.
-// It handles JSON string escaping for quotes, backslashes, and control characters.
-// This function is optimized for performance with minimal allocations.
-func appendJSONString(buf []byte, s string) []byte {
-	for _, r := range []byte(s) {
-		switch r {
-		case '"':
-			buf = append(buf, '\\', '"')
-		case '\\':
-			buf = append(buf, '\\', '\\')
+// Quotes and backslashes are escaped, control characters are written as
+// \n, \r, \t or \u00XX, and invalid UTF-8 is replaced by \ufffd, so the
+// output is always valid JSON. Strings of clean ASCII are appended in one
+// copy. It takes byte slices as well so ByteString payloads need no
+// conversion.
+func appendJSONString[S string | []byte](buf []byte, s S) []byte {
+	i := 0
+	for i < len(s) && jsonSafeASCII(s[i]) {
+		i++
+	}
+	if i == len(s) {
+		return append(buf, s...)
+	}
+
+	start := 0
+	for i < len(s) {
+		c := s[i]
+		if jsonSafeASCII(c) {
+			i++
+			continue
+		}
+		if c >= utf8.RuneSelf {
+			r, size := decodeJSONRune(s[i:])
+			if r != utf8.RuneError || size != 1 {
+				i += size
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i++
+			start = i
+			continue
+		}
+
+		buf = append(buf, s[start:i]...)
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
 		case '\n':
 			buf = append(buf, '\\', 'n')
 		case '\r':
@@ -50,15 +93,82 @@ func appendJSONString(buf []byte, s string) []byte {
 		case '\t':
 			buf = append(buf, '\\', 't')
 		default:
-			buf = append(buf, r)
+			buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+		}
+		i++
+		start = i
+	}
+	return append(buf, s[start:]...)
+}
+
+// hexDigits are the digits of \u escapes.
+const hexDigits = "0123456789abcdef"
+
+// jsonSafeASCII reports whether c is ASCII that JSON strings hold as is.
+func jsonSafeASCII(c byte) bool {
+	return c >= 0x20 && c < utf8.RuneSelf && c != '"' && c != '\\'
+}
+
+// decodeJSONRune decodes the first rune of s, which is a string or a byte
+// slice, without converting it.
+func decodeJSONRune[S string | []byte](s S) (rune, int) {
+	var b [utf8.UTFMax]byte
+	n := copy(b[:], s)
+	return utf8.DecodeRune(b[:n])
+}
+
+// escapeJSONHTML escapes <, >, & and the line and paragraph separators
+// U+2028 and U+2029 in the JSON encoded in buf[start:], for
+// Config.EscapeHTML. They can only occur inside JSON strings, so the
+// escapes are made in place, from the end, growing buf once.
+func escapeJSONHTML(buf []byte, start int) []byte {
+	extra := 0
+	for i := start; i < len(buf); i++ {
+		switch {
+		case buf[i] == '<' || buf[i] == '>' || buf[i] == '&':
+			extra += len(`\u003c`) - 1
+		case isJSONLineSeparator(buf, i):
+			extra += len(`\u2028`) - 3
+		}
+	}
+	if extra == 0 {
+		return buf
+	}
+
+	n := len(buf)
+	buf = append(buf, make([]byte, extra)...)
+	w := len(buf)
+	for r := n - 1; r >= start; r-- {
+		c := buf[r]
+		switch {
+		case c == '<' || c == '>' || c == '&':
+			w -= 6
+			copy(buf[w:], `\u00`)
+			buf[w+4], buf[w+5] = hexDigits[c>>4], hexDigits[c&0xf]
+		case r >= start+2 && isJSONLineSeparator(buf, r-2):
+			w -= 6
+			copy(buf[w:], `\u202`)
+			buf[w+5] = hexDigits[buf[r]&0xf]
+			r -= 2
+		default:
+			w--
+			buf[w] = c
 		}
 	}
 	return buf
 }
 
+// isJSONLineSeparator reports whether buf[i:] starts with the UTF-8
+// encoding of U+2028 or U+2029.
+func isJSONLineSeparator(buf []byte, i int) bool {
+	return i+2 < len(buf) && buf[i] == 0xe2 && buf[i+1] == 0x80 && (buf[i+2] == 0xa8 || buf[i+2] == 0xa9)
+}
+
 // appendJSONValue appends a typed value to the JSON buffer with proper JSON formatting.
-// It supports string, int, int64, float64, and bool types. Unknown types are
-// represented as the string "unknown".
+// It supports string, int, int64, float64, and bool types as well as values
+// created by Secret, HashedSecret and Any. A nil value is encoded as null.
+// Other values are encoded as the string of their String, Error or MarshalText
+// method, see textValue, or as the string "unknown".
 func appendJSONValue(buf []byte, value interface{}) []byte {
 	switch v := value.(type) {
 	case string:
@@ -66,48 +176,90 @@ func appendJSONValue(buf []byte, value interface{}) []byte {
 		buf = appendJSONString(buf, v)
 		buf = append(buf, '"')
 	case int:
-		buf = appendInt(buf, int64(v))
+		buf = strconv.AppendInt(buf, int64(v), 10)
 	case int64:
-		buf = appendInt(buf, v)
+		buf = strconv.AppendInt(buf, v, 10)
 	case float64:
 		buf = appendJSONFloat(buf, v)
+	case uint64:
+		buf = strconv.AppendUint(buf, v, 10)
+	case float32:
+		buf = appendJSONFloat(buf, float32Value(v))
 	case bool:
 		if v {
 			buf = append(buf, "true"...)
 		} else {
 			buf = append(buf, "false"...)
 		}
+	case secretValue:
+		buf = append(buf, '"')
+		buf = v.appendTo(buf)
+		buf = append(buf, '"')
+	case bytesValue:
+		buf = v.appendJSON(buf)
+	case blockValue:
+		buf = append(buf, '"')
+		buf = appendJSONString(buf, string(v))
+		buf = append(buf, '"')
+	case time.Time:
+		buf = append(buf, '"')
+		buf = v.AppendFormat(buf, time.RFC3339Nano)
+		buf = append(buf, '"')
+	case sourceLocation:
+		buf = v.appendJSON(buf)
+	case httpRequest:
+		buf = v.appendJSON(buf)
+	case reflectValue:
+		buf = v.appendJSON(buf)
+	case groupValue:
+		buf = appendJSONGroup(buf, v)
+	case nil:
+		buf = append(buf, "null"...)
 	default:
+		if out, ok := appendInteger(buf, v); ok {
+			return out
+		}
+		s, ok := textValue(v)
+		if !ok {
+			s = "unknown"
+		}
 		buf = append(buf, '"')
-		buf = appendJSONString(buf, "unknown")
+		buf = appendJSONString(buf, s)
 		buf = append(buf, '"')
 	}
 	return buf
 }
 
-// appendJSONFloat appends a float64 value to the JSON buffer.
-// It provides basic float formatting with 3 decimal places precision for the
-// fractional part. This is optimized for performance over full precision.
+// appendJSONFloat appends a float64 value to the JSON buffer, as the
+// shortest representation that parses back to f. NaN and infinities, which
+// JSON numbers cannot represent, are written as the strings "NaN", "+Inf"
+// and "-Inf".
 func appendJSONFloat(buf []byte, f float64) []byte {
-	if f == 0.0 {
-		return append(buf, '0')
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		buf = append(buf, '"')
+		buf = appendNonFinite(buf, f)
+		return append(buf, '"')
 	}
+	return appendFloatNumber(buf, f, 64)
+}
 
-	if f < 0 {
-		buf = append(buf, '-')
-		f = -f
+// isJSONFormat reports whether format encodes entries as JSON objects
+// meant to be read from files, as opposed to GELFFormat messages.
+func isJSONFormat(format Format) bool {
+	switch format {
+	case JSONFormat, ECSFormat, CloudLoggingFormat, DatadogFormat:
+		return true
+	default:
+		return false
 	}
+}
 
-	integer := int64(f)
-	fractional := f - float64(integer)
-
-	buf = appendInt(buf, integer)
-
-	if fractional > 0 {
-		buf = append(buf, '.')
-		fractional *= 1000
-		buf = appendInt(buf, int64(fractional))
+// indentJSON indents the JSON object encoded in buf[start:] for
+// Config.PrettyJSON, leaving buf unchanged if it is not valid JSON.
+func indentJSON(buf []byte, start int) []byte {
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf[start:], "", prettyJSONIndent); err != nil {
+		return buf
 	}
-
-	return buf
+	return append(buf[:start], out.Bytes()...)
 }
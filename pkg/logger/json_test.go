@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain ascii", "plain ascii"},
+		{`q"b\`, `q\"b\\`},
+		{"a\nb\rc\td", `a\nb\rc\td`},
+		{"\x00\x01\x1f\x7f", `\u0000\u0001\u001f` + "\x7f"},
+		{"héllo ✓ 🦉", "héllo ✓ 🦉"},
+		{"bad\xffutf8\xc3", `bad\ufffdutf8\ufffd`},
+		{"\xed\xa0\x80", `\ufffd\ufffd\ufffd`},
+		{"<&>", "<&>"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, string(appendJSONString(nil, tt.in)), "%q", tt.in)
+		assert.Equal(t, tt.want, string(appendJSONString(nil, []byte(tt.in))), "%q as bytes", tt.in)
+	}
+}
+
+func TestAppendJSONString_AlwaysValid(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 1000 {
+		in := make([]byte, rng.Intn(32))
+		rng.Read(in)
+
+		quoted := append(appendJSONString([]byte{'"'}, in), '"')
+		var out string
+		require.NoError(t, json.Unmarshal(quoted, &out), "%q", in)
+		if utf8.Valid(in) {
+			assert.Equal(t, string(in), out)
+		}
+	}
+}
+
+func TestAppendJSONString_ASCIIAllocationFree(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = appendJSONString(buf[:0], "user logged in")
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestConfig_EscapeHTML(t *testing.T) {
+	for _, format := range []Format{JSONFormat, GELFFormat, ECSFormat} {
+		buf := &bytes.Buffer{}
+		log := New(Config{Level: InfoLevel, Format: format, Output: buf, EscapeHTML: true})
+
+		log.Info("<script>", String("q", "a&b"), String("sep", "x\u2028y\u2029"))
+		line := buf.String()
+		assert.True(t, json.Valid([]byte(line)), line)
+		assert.NotContains(t, line, "<")
+		assert.NotContains(t, line, "\u2028")
+		assert.Contains(t, line, `\u003cscript\u003e`)
+		assert.Contains(t, line, `a\u0026b`)
+		assert.Contains(t, line, `x\u2028y\u2029`)
+		assert.True(t, strings.HasSuffix(line, "}\n"))
+	}
+}
+
+func TestConfig_EscapeHTML_TextUnchanged(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled, EscapeHTML: true})
+
+	log.Info("<b>", String("q", "a&b"))
+	assert.Equal(t, "INFO <b> q=a&b\n", buf.String())
+}
+
+func TestEscapeJSONHTML_Prefix(t *testing.T) {
+	buf := []byte(`<kept>{"a":"<&>"}`)
+	assert.Equal(t, `<kept>{"a":"\u003c\u0026\u003e"}`, string(escapeJSONHTML(buf, len("<kept>"))))
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrUnknownLevel is returned when parsing a name that is neither a
+// predefined nor a registered level.
+var ErrUnknownLevel = errors.New("logger: unknown level")
+
+// predefinedLevels lists the built-in levels in ascending order.
+var predefinedLevels = [...]Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel}
+
+// customLevel is a level registered with RegisterLevel.
+type customLevel struct {
+	name  string // upper case, as returned by Level.String
+	lower string
+}
+
+var (
+	levelsMu     sync.Mutex
+	customLevels atomic.Pointer[map[Level]customLevel]
+)
+
+// RegisterLevel registers a custom level with the given severity and name
+// and returns it. Levels are ordered by value, so filtering, rate limits
+// and sink routing treat a custom level like any other; the predefined
+// levels are spaced to leave room for custom ones:
+//
+//	DebugLevel = -4, InfoLevel = 0, WarnLevel = 4, ErrorLevel = 8,
+//	FatalLevel = 12, PanicLevel = 16
+//
+// Names are case-insensitive when parsed and rendered in upper case.
+// Formats with a fixed set of severities, such as syslog or Cloud
+// Logging, use the severity of the closest predefined level below.
+// Register levels during initialization:
+//
+//	var (
+//		NoticeLevel = logger.RegisterLevel(2, "NOTICE")
+//		AuditLevel  = logger.RegisterLevel(10, "AUDIT")
+//	)
+//
+//	log.Log(NoticeLevel, "config reloaded")
+//
+// Registering the same name and value again returns the level. It panics
+// if value is outside the range of Level or is a predefined level, if name
+// is empty or contains whitespace, or if the name or value is already
+// registered otherwise.
+func RegisterLevel(value int, name string) Level {
+	if value < math.MinInt8 || value > math.MaxInt8 {
+		panic("logger: level value out of range")
+	}
+	if name == "" || strings.ContainsFunc(name, isSpace) {
+		panic("logger: invalid level name " + `"` + name + `"`)
+	}
+
+	level := Level(value)
+	upper := strings.ToUpper(name)
+	for _, predefined := range predefinedLevels {
+		if level == predefined {
+			panic("logger: level value is predefined as " + predefined.String())
+		}
+		if upper == predefined.String() {
+			panic("logger: level name " + upper + " is predefined")
+		}
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	current := customLevels.Load()
+	if current != nil {
+		if existing, ok := (*current)[level]; ok {
+			if existing.name == upper {
+				return level
+			}
+			panic("logger: level value already registered as " + existing.name)
+		}
+		for _, existing := range *current {
+			if existing.name == upper {
+				panic("logger: level name " + upper + " already registered")
+			}
+		}
+	}
+
+	next := make(map[Level]customLevel, 1)
+	if current != nil {
+		for l, c := range *current {
+			next[l] = c
+		}
+	}
+	next[level] = customLevel{name: upper, lower: strings.ToLower(name)}
+	customLevels.Store(&next)
+
+	return level
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// lookupCustomLevel returns the registration of level, if any.
+func lookupCustomLevel(level Level) (customLevel, bool) {
+	if levels := customLevels.Load(); levels != nil {
+		c, ok := (*levels)[level]
+		return c, ok
+	}
+	return customLevel{}, false
+}
+
+// levelFromName returns the predefined or registered level named name,
+// ignoring case.
+func levelFromName(name string) (Level, bool) {
+	for _, level := range predefinedLevels {
+		if strings.EqualFold(level.String(), name) {
+			return level, true
+		}
+	}
+	if levels := customLevels.Load(); levels != nil {
+		for level, c := range *levels {
+			if strings.EqualFold(c.name, name) {
+				return level, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// validLevel reports whether level is a predefined or registered level.
+func validLevel(level Level) bool {
+	for _, predefined := range predefinedLevels {
+		if level == predefined {
+			return true
+		}
+	}
+	_, ok := lookupCustomLevel(level)
+	return ok
+}
+
+// ParseLevel returns the predefined or registered level with the given
+// name, ignoring case, so levels can be read from flags, environment
+// variables and configuration files:
+//
+//	level, err := logger.ParseLevel(os.Getenv("LOG_LEVEL"))
+//
+// "WARNING" is accepted as an alias of WARN. It returns an error wrapping
+// ErrUnknownLevel for any other name.
+func ParseLevel(name string) (Level, error) {
+	if level, ok := levelFromName(name); ok {
+		return level, nil
+	}
+	if strings.EqualFold(name, "warning") {
+		return WarnLevel, nil
+	}
+	return 0, fmt.Errorf("%w %q", ErrUnknownLevel, name)
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the name of
+// the level as rendered by String, and an error for a value that is
+// neither predefined nor registered, since it could not be parsed back.
+func (l Level) MarshalText() ([]byte, error) {
+	if !validLevel(l) {
+		return nil, fmt.Errorf("%w %d", ErrUnknownLevel, l)
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so a Level can be
+// used with flag.TextVar and decoded from configuration files. It parses
+// text like ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the level as a string
+// like MarshalText.
+func (l Level) MarshalJSON() ([]byte, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return strconv.AppendQuote(make([]byte, 0, len(text)+2), string(text)), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts a level name as a
+// JSON string; null leaves the level unchanged.
+func (l *Level) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("logger: level must be a JSON string, got %s", data)
+	}
+	return l.UnmarshalText([]byte(name))
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	stdflag "flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testNoticeLevel = RegisterLevel(2, "Notice")
+	testAuditLevel  = RegisterLevel(10, "AUDIT")
+	testTraceLevel  = RegisterLevel(-8, "trace")
+)
+
+func TestRegisterLevel(t *testing.T) {
+	assert.Equal(t, "NOTICE", testNoticeLevel.String())
+	assert.Equal(t, "TRACE", testTraceLevel.String())
+	assert.Equal(t, "UNKNOWN", Level(3).String())
+	assert.Equal(t, testNoticeLevel, RegisterLevel(2, "notice"), "registering again is a no-op")
+
+	assert.True(t, InfoLevel < testNoticeLevel && testNoticeLevel < WarnLevel)
+	assert.True(t, ErrorLevel < testAuditLevel && testAuditLevel < FatalLevel)
+
+	for name, register := range map[string]func(){
+		"out of range":    func() { RegisterLevel(200, "HUGE") },
+		"predefined":      func() { RegisterLevel(int(WarnLevel), "WARNING") },
+		"predefined name": func() { RegisterLevel(3, "warn") },
+		"empty name":      func() { RegisterLevel(3, "") },
+		"spaces":          func() { RegisterLevel(3, "NOT ICE") },
+		"taken value":     func() { RegisterLevel(2, "OTHER") },
+		"taken name":      func() { RegisterLevel(3, "AUDIT") },
+	} {
+		assert.Panics(t, register, name)
+	}
+}
+
+func TestCustomLevelFiltering(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	log := New(Config{Level: testNoticeLevel, Format: TextFormat, Output: buf, ErrorOutput: errBuf})
+
+	log.Log(InfoLevel, "dropped")
+	log.Log(testNoticeLevel, "config reloaded")
+	log.Log(testAuditLevel, "user deleted")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), "NOTICE config reloaded")
+	assert.Contains(t, errBuf.String(), "AUDIT user deleted")
+	require.NoError(t, Config{Level: testNoticeLevel, Output: buf}.Validate())
+	require.Error(t, Config{Level: Level(3), Output: buf}.Validate())
+}
+
+func TestCustomLevelEncodings(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: testTraceLevel, Format: JSONFormat, Output: buf})
+	log.Log(testAuditLevel, "user deleted")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "AUDIT", entry["level"])
+
+	assert.Equal(t, "audit", ecsLevel(testAuditLevel))
+	assert.Equal(t, 3, syslogSeverity(testAuditLevel), "severity of ERROR")
+	assert.Equal(t, 6, syslogSeverity(testNoticeLevel), "severity of INFO")
+	assert.Equal(t, 7, syslogSeverity(testTraceLevel), "severity of DEBUG")
+	assert.Equal(t, "ERROR", cloudSeverity(testAuditLevel))
+	assert.Equal(t, "info", datadogStatus(testNoticeLevel))
+}
+
+func TestCustomLevelParsing(t *testing.T) {
+	level, ok := levelFromName("notice")
+	assert.True(t, ok)
+	assert.Equal(t, testNoticeLevel, level)
+
+	level, ok = levelFromName("Warn")
+	assert.True(t, ok)
+	assert.Equal(t, WarnLevel, level)
+
+	_, ok = levelFromName("verbose")
+	assert.False(t, ok)
+
+	t.Setenv(EnvLogLevel, "AUDIT")
+	assert.Equal(t, testAuditLevel, ConfigFromEnv().Level)
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{
+		"debug": DebugLevel, "INFO": InfoLevel, "Warn": WarnLevel, "warning": WarnLevel,
+		"error": ErrorLevel, "fatal": FatalLevel, "panic": PanicLevel, "notice": testNoticeLevel,
+	} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+
+	_, err := ParseLevel("loud")
+	assert.ErrorIs(t, err, ErrUnknownLevel)
+	assert.ErrorContains(t, err, `"loud"`)
+}
+
+func TestLevel_TextAndJSON(t *testing.T) {
+	text, err := WarnLevel.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "WARN", string(text))
+
+	_, err = Level(3).MarshalText()
+	assert.ErrorIs(t, err, ErrUnknownLevel)
+
+	var level Level
+	require.NoError(t, level.UnmarshalText([]byte("error")))
+	assert.Equal(t, ErrorLevel, level)
+	assert.ErrorIs(t, level.UnmarshalText([]byte("loud")), ErrUnknownLevel)
+
+	var fs stdflag.FlagSet
+	fs.TextVar(&level, "level", InfoLevel, "log level")
+	require.NoError(t, fs.Parse([]string{"-level", "debug"}))
+	assert.Equal(t, DebugLevel, level)
+
+	var cfg struct {
+		Level  Level `json:"level"`
+		Audit  Level `json:"audit"`
+		Absent Level `json:"absent"`
+	}
+	cfg.Absent = ErrorLevel
+	require.NoError(t, json.Unmarshal([]byte(`{"level":"warn","audit":"notice","absent":null}`), &cfg))
+	assert.Equal(t, WarnLevel, cfg.Level)
+	assert.Equal(t, testNoticeLevel, cfg.Audit)
+	assert.Equal(t, ErrorLevel, cfg.Absent)
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"level":"WARN","audit":"NOTICE","absent":"ERROR"}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"level":4}`), &cfg))
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"level":"loud"}`), &cfg), ErrUnknownLevel)
+}
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// TruncatedFieldsKey is the key of the field holding the number of fields
+// dropped by Config.MaxFields.
+const TruncatedFieldsKey = "truncated_fields"
+
+// truncateString cuts s to at most limit bytes, on a rune boundary, and
+// appends a marker with its original size, e.g. "abc...(truncated, 5242880
+// bytes)". The marker comes on top of the limit.
+func truncateString(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	n := limit
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	buf := make([]byte, 0, n+32)
+	buf = append(buf, s[:n]...)
+	buf = append(buf, "...(truncated, "...)
+	buf = strconv.AppendInt(buf, int64(len(s)), 10)
+	buf = append(buf, " bytes)"...)
+	return string(buf)
+}
+
+// limitRecord applies Config.MaxMessageBytes, Config.MaxFieldBytes and
+// Config.MaxFields to r.
+func (l *Logger) limitRecord(r *Record) {
+	if limit := l.config.MaxMessageBytes; limit > 0 {
+		r.Message = truncateString(r.Message, limit)
+	}
+	if limit := l.config.MaxFieldBytes; limit > 0 {
+		r.Fields = truncateFields(r.Fields, limit, false)
+	}
+	if limit := l.config.MaxFields; limit > 0 && len(r.Fields) > limit {
+		dropped := len(r.Fields) - limit
+		r.Fields = append(r.Fields[:limit], Int(TruncatedFieldsKey, dropped))
+	}
+}
+
+// truncateFields cuts the string, Block and ByteString values of fields,
+// including those nested in groups, to limit bytes. It copies fields first
+// when shared is set, so group members bound with With are not modified.
+func truncateFields(fields []Field, limit int, shared bool) []Field {
+	for i, field := range fields {
+		var value any
+		switch v := field.Value.(type) {
+		case string:
+			if len(v) <= limit {
+				continue
+			}
+			value = truncateString(v, limit)
+		case blockValue:
+			if len(v) <= limit {
+				continue
+			}
+			value = blockValue(truncateString(string(v), limit))
+		case bytesValue:
+			if v.enc != bytesText || len(v.b) <= limit {
+				continue
+			}
+			value = truncateString(string(v.b), limit)
+		case groupValue:
+			g := truncateFields(v, limit, true)
+			if len(v) == 0 || &g[0] == &v[0] {
+				continue
+			}
+			value = groupValue(g)
+		default:
+			continue
+		}
+
+		if shared {
+			fields = append([]Field(nil), fields...)
+			shared = false
+		}
+		fields[i].Value = value
+	}
+	return fields
+}
+
+// RecordTruncatedKey is the key of the field holding the original size of
+// an entry shortened for Config.MaxRecordBytes.
+const RecordTruncatedKey = "record_truncated"
+
+// OversizedRecordPolicy decides what happens to an entry whose encoding
+// exceeds Config.MaxRecordBytes.
+type OversizedRecordPolicy uint8
+
+const (
+	// OversizedTruncate writes the entry with its fields replaced by a
+	// RecordTruncatedKey field holding the original size, and its message
+	// truncated as far as needed, so the output stays parseable.
+	OversizedTruncate OversizedRecordPolicy = iota
+
+	// OversizedDrop drops the entry and counts it as
+	// logmetrics.DropOversized.
+	OversizedDrop
+)
+
+// shrinkRecord re-encodes r into bufPtr within Config.MaxRecordBytes for
+// OversizedTruncate, and reports whether it fits.
+func (l *Logger) shrinkRecord(s *sink, r *Record, bufPtr *[]byte) bool {
+	if l.config.OversizedRecords == OversizedDrop {
+		return false
+	}
+
+	limit := l.config.MaxRecordBytes
+	stub := &Record{
+		Time:   r.Time,
+		Level:  r.Level,
+		Fields: []Field{Int(RecordTruncatedKey, len(*bufPtr))},
+	}
+	if !l.encodeSink(s, stub, bufPtr) || len(*bufPtr) > limit {
+		return false
+	}
+
+	// Escaping can make the message longer once encoded, so shrink the
+	// budget until the entry fits.
+	const marker = len("...(truncated, 0000000000 bytes)")
+	for budget := limit - len(*bufPtr) - marker; budget > 0; budget /= 2 {
+		stub.Message = truncateString(r.Message, budget)
+		if l.encodeSink(s, stub, bufPtr) && len(*bufPtr) <= limit {
+			return true
+		}
+	}
+	stub.Message = ""
+	return l.encodeSink(s, stub, bufPtr) && len(*bufPtr) <= limit
+}
@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+func TestConfig_SizeLimits(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Format:          JSONFormat,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		MaxMessageBytes: 5,
+		MaxFieldBytes:   4,
+	})
+
+	log.Info("hello world", String("body", "abcdefgh"), String("short", "abcd"), ByteString("raw", []byte("123456")), Int("n", 123456789))
+	assert.Equal(t, `{"level":"INFO","message":"hello...(truncated, 11 bytes)","body":"abcd...(truncated, 8 bytes)",`+
+		`"short":"abcd","raw":"1234...(truncated, 6 bytes)","n":123456789}`+"\n", buf.String())
+}
+
+func TestConfig_MaxFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		MaxFields:       2,
+	}).With(Int("a", 1))
+
+	log.Info("m", Int("b", 2), Int("c", 3), Int("d", 4))
+	log.Info("m", Int("b", 2))
+	assert.Equal(t, "INFO m a=1 b=2 "+TruncatedFieldsKey+"=2\nINFO m a=1 b=2\n", buf.String())
+}
+
+func TestConfig_MaxFieldBytes_AfterRedaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:               InfoLevel,
+		Output:              buf,
+		TimestampFormat:     TimestampDisabled,
+		MaxFieldBytes:       12,
+		RedactValuePatterns: []*regexp.Regexp{regexp.MustCompile(`secret-\w+`)},
+	})
+
+	log.Info("m", String("v", "see secret-abcdefgh"))
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestConfig_MaxFieldBytes_Groups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled, MaxFieldBytes: 2})
+	grouped := log.WithGroup("g").With(String("bound", "xyz"))
+
+	grouped.Info("m")
+	assert.Equal(t, `INFO m g.bound="xy...(truncated, 3 bytes)"`+"\n", buf.String())
+	assert.Equal(t, "xyz", grouped.groups[0].fields[0].Value)
+}
+
+func TestTruncateString_RuneBoundary(t *testing.T) {
+	s := truncateString(strings.Repeat("é", 4), 3)
+	assert.Equal(t, "é...(truncated, 8 bytes)", s)
+}
+
+func TestConfig_SizeLimitValidation(t *testing.T) {
+	_, err := NewE(Config{MaxMessageBytes: -1, MaxFieldBytes: -1, MaxFields: -1})
+	assert.ErrorContains(t, err, "negative MaxMessageBytes -1")
+	assert.ErrorContains(t, err, "negative MaxFieldBytes -1")
+	assert.ErrorContains(t, err, "negative MaxFields -1")
+}
+
+func TestConfig_MaxRecordBytes(t *testing.T) {
+	var counters logmetrics.Counters
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:          InfoLevel,
+		Format:         JSONFormat,
+		Output:         buf,
+		Clock:          func() time.Time { return time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC) },
+		Metrics:        &counters,
+		MaxRecordBytes: 200,
+	})
+
+	log.Info("small")
+	log.Info(strings.Repeat("m", 300), String("payload", strings.Repeat("x", 500)))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, `{"timestamp":"2024-01-20T15:04:05Z","level":"INFO","message":"small"}`, lines[0])
+	assert.LessOrEqual(t, len(lines[1])+1, 200)
+	assert.True(t, json.Valid([]byte(lines[1])), lines[1])
+	assert.Regexp(t, `"message":"m+\.\.\.\(truncated, 300 bytes\)"`, lines[1])
+	assert.Regexp(t, `"`+RecordTruncatedKey+`":\d{3}}$`, lines[1])
+	assert.NotContains(t, lines[1], "payload")
+	assert.Zero(t, counters.Snapshot().Dropped[logmetrics.DropOversized])
+}
+
+func TestConfig_MaxRecordBytes_Drop(t *testing.T) {
+	var counters logmetrics.Counters
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:            InfoLevel,
+		Output:           buf,
+		TimestampFormat:  TimestampDisabled,
+		Metrics:          &counters,
+		MaxRecordBytes:   16,
+		OversizedRecords: OversizedDrop,
+	})
+
+	log.Info("fits")
+	log.Info("does not fit at all")
+	assert.Equal(t, "INFO fits\n", buf.String())
+	assert.Equal(t, uint64(1), counters.Snapshot().Dropped[logmetrics.DropOversized])
+}
+
+// exclusiveWriter fails the test when Write is called concurrently. It
+// accepts at most 7 bytes per call, so every entry takes several writes.
+type exclusiveWriter struct {
+	t    *testing.T
+	busy atomic.Bool
+	buf  bytes.Buffer
+}
+
+func (w *exclusiveWriter) Write(p []byte) (int, error) {
+	if !w.busy.CompareAndSwap(false, true) {
+		w.t.Error("concurrent Write")
+	}
+	defer w.busy.Store(false)
+	n := min(len(p), 7)
+	w.buf.Write(p[:n])
+	if n < len(p) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+func TestSink_UnbufferedWritesDoNotInterleave(t *testing.T) {
+	w := &exclusiveWriter{t: t}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          w,
+		TimestampFormat: TimestampDisabled,
+		WriteErrors:     WriteErrorPolicy{Retries: 100},
+	})
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				log.Info("entry", Int("g", g), Int("i", i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(w.buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 400)
+	for _, line := range lines {
+		assert.Regexp(t, `^INFO entry g=\d i=\d+$`, line)
+	}
+}
+
+func TestConfig_MaxRecordBytesValidation(t *testing.T) {
+	_, err := NewE(Config{MaxRecordBytes: -1, OversizedRecords: 7})
+	assert.ErrorContains(t, err, "MaxRecordBytes")
+	assert.ErrorContains(t, err, "OversizedRecords")
+}
@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// maxWriterLine is the longest line a Writer buffers before logging it
+// without waiting for the newline, so unterminated output stays bounded.
+const maxWriterLine = 64 << 10
+
+// WriterOption configures a writer returned by Logger.Writer.
+type WriterOption func(*lineWriter)
+
+// WithJSONLines makes the writer parse lines holding a JSON object. The
+// keys of the object become fields of the entry, except "message" or "msg",
+// which becomes its message, and "level", which overrides the level of the
+// writer when it names a known level. Remaining keys that would collide with
+// the timestamp, level or message key of the entry, as renamed by
+// Config.KeyMap, are prefixed with "original_", so the output holds every
+// key once. Lines that are not a JSON object are logged as plain text.
+func WithJSONLines() WriterOption {
+	return func(w *lineWriter) {
+		w.json = true
+	}
+}
+
+// Writer returns an io.WriteCloser that logs every line written to it as
+// an entry at level, with the line as the message. Partial lines are
+// buffered until their newline arrives or the writer is closed; empty
+// lines are skipped. Close logs any pending partial line and does not
+// close l. The writer is safe for concurrent use.
+//
+// Example:
+//
+//	stderr := log.Writer(logger.WarnLevel)
+//	defer stderr.Close()
+//	cmd.Stderr = stderr
+//
+//	srv := &http.Server{ErrorLog: stdlog.New(log.Writer(logger.ErrorLevel), "", 0)}
+func (l *Logger) Writer(level Level, opts ...WriterOption) io.WriteCloser {
+	w := &lineWriter{log: l, level: level}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// lineWriter implements Logger.Writer.
+type lineWriter struct {
+	log   *Logger
+	level Level
+	json  bool
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.pending = append(w.pending, p...)
+			if len(w.pending) >= maxWriterLine {
+				w.flushPending()
+			}
+			break
+		}
+
+		line := p[:i]
+		if len(w.pending) > 0 {
+			w.pending = append(w.pending, line...)
+			line = w.pending
+		}
+		w.logLine(line)
+		w.pending = w.pending[:0]
+		p = p[i+1:]
+	}
+
+	return n, nil
+}
+
+// Close logs the pending partial line, if any.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushPending()
+	return nil
+}
+
+func (w *lineWriter) flushPending() {
+	if len(w.pending) > 0 {
+		w.logLine(w.pending)
+		w.pending = w.pending[:0]
+	}
+}
+
+func (w *lineWriter) logLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) == 0 {
+		return
+	}
+
+	if w.json && line[0] == '{' {
+		if level, msg, fields, ok := parseJSONLine(line, w.level, w.log.config.KeyMap); ok {
+			w.log.log(level, msg, fields...)
+			return
+		}
+	}
+	if w.log.enabled(w.level) {
+		w.log.log(w.level, string(line))
+	}
+}
+
+// parseJSONLine decodes a line holding a JSON object into an entry. Fields
+// are sorted by key, since JSON objects are unordered.
+func parseJSONLine(line []byte, level Level, keyMap map[string]string) (Level, string, []Field, bool) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(line, &object); err != nil {
+		return 0, "", nil, false
+	}
+
+	var msg string
+	for _, key := range [...]string{MessageKey, "msg"} {
+		if s, ok := jsonFieldValue(object[key]).(string); ok {
+			msg = s
+			delete(object, key)
+			break
+		}
+	}
+	if s, ok := jsonFieldValue(object[LevelKey]).(string); ok {
+		if named, ok := levelFromName(s); ok {
+			level = named
+			delete(object, LevelKey)
+		}
+	}
+
+	fields := make([]Field, 0, len(object))
+	for key, raw := range object {
+		if collidesWithEntryKey(keyMap, key) {
+			key = "original_" + key
+		}
+		fields = append(fields, Field{Key: key, Value: jsonFieldValue(raw)})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+
+	return level, msg, fields, true
+}
+
+// collidesWithEntryKey reports whether a field named key would be written
+// under the same name as the timestamp, level or message of the entry.
+func collidesWithEntryKey(keyMap map[string]string, key string) bool {
+	key = remapKey(keyMap, key)
+	for _, entryKey := range [...]string{TimestampKey, LevelKey, MessageKey} {
+		if key == remapKey(keyMap, entryKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFieldValue converts a JSON value into a field value. Integers become
+// int64 and other numbers float64; arrays and objects are kept as their
+// compact JSON text.
+func jsonFieldValue(raw json.RawMessage) interface{} {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+	switch raw[0] {
+	case '"':
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			return s
+		}
+	case 't':
+		return true
+	case 'f':
+		return false
+	case 'n':
+		return nil
+	case '{', '[':
+		var compact bytes.Buffer
+		if json.Compact(&compact, raw) == nil {
+			return compact.String()
+		}
+	default:
+		if i, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(string(raw), 64); err == nil {
+			return f
+		}
+	}
+	return string(raw)
+}
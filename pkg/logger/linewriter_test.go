@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	stdlog "log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	w := log.Writer(WarnLevel)
+	_, err := w.Write([]byte("first line\nsecond "))
+	require.NoError(t, err)
+	_, _ = w.Write([]byte("line\r\n\n"))
+	_, _ = w.Write([]byte("unterminated"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "WARN first line")
+	assert.Contains(t, lines[1], "WARN second line")
+	assert.NotContains(t, lines[1], "\r")
+
+	require.NoError(t, w.Close())
+	assert.Contains(t, buf.String(), "WARN unterminated")
+}
+
+func TestLoggerWriter_Level(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: WarnLevel, Format: TextFormat, Output: buf})
+
+	_, _ = log.Writer(InfoLevel).Write([]byte("filtered\n"))
+	assert.Empty(t, buf.String())
+
+	stdlog.New(log.Writer(ErrorLevel), "", 0).Printf("http: TLS handshake error")
+	assert.Contains(t, buf.String(), "ERROR http: TLS handshake error")
+}
+
+func TestLoggerWriter_LongLine(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	w := log.Writer(InfoLevel)
+	_, _ = w.Write(bytes.Repeat([]byte("x"), maxWriterLine))
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"), "logged without waiting for the newline")
+}
+
+func TestLoggerWriter_JSONLines(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	w := log.Writer(InfoLevel, WithJSONLines())
+	_, _ = w.Write([]byte(`{"msg":"request done","level":"error","status":500,"latency":0.25,"ok":false,"tags":["a", "b"],"user":null}` + "\n"))
+	_, _ = w.Write([]byte("{not json\n"))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	assert.Contains(t, string(lines[0]), `"level":"ERROR","message":"request done","latency":`)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &entry))
+	assert.Equal(t, 0.25, entry["latency"])
+	assert.Equal(t, false, entry["ok"])
+	assert.Equal(t, float64(500), entry["status"])
+	assert.Equal(t, `["a","b"]`, entry["tags"])
+	assert.Nil(t, entry["user"])
+
+	entry = nil
+	require.NoError(t, json.Unmarshal(lines[1], &entry))
+	assert.Equal(t, "{not json", entry["message"])
+	assert.Equal(t, "INFO", entry["level"])
+}
+
+func TestLoggerWriter_Concurrent(t *testing.T) {
+	buf := &syncBuffer{}
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+	w := log.Writer(InfoLevel)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = w.Write([]byte("line\n"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 800, strings.Count(buf.String(), "INFO line"))
+}
+
+func TestLoggerWriter_JSONLinesEntryKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf, KeyMap: map[string]string{TimestampKey: "ts"}})
+
+	w := log.Writer(InfoLevel, WithJSONLines())
+	_, _ = w.Write([]byte(`{"ts":"2026-01-02T03:04:05Z","timestamp":1,"level":"chatty","message":"done"}` + "\n"))
+
+	line := buf.String()
+	assert.Equal(t, 1, strings.Count(line, `"ts":`), line)
+	assert.Equal(t, 1, strings.Count(line, `"level":`), line)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(line), &entry))
+	assert.Equal(t, "done", entry["message"])
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "2026-01-02T03:04:05Z", entry["original_ts"])
+	assert.Equal(t, "chatty", entry["original_level"])
+	assert.Equal(t, float64(1), entry["original_timestamp"])
+}
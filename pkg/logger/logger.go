@@ -20,43 +20,56 @@ package logger
 import (
 	"context"
 	"io"
+	"math"
+	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
 )
 
 // contextKey is a custom type for context keys to avoid collisions.
 type contextKey string
 
 // Level represents the severity level of a log entry.
-// Lower values indicate more verbose logging.
+// Lower values indicate more verbose logging. The predefined levels are
+// four apart, leaving room for levels added with RegisterLevel.
+//
+// The values changed from -1 to 4 to -4 to 16 when custom levels were
+// added. Levels persisted as numbers must be converted; the text form of
+// MarshalText and ParseLevel is stable.
 type Level int8
 
 const (
 	// DebugLevel logs are typically voluminous, and are usually disabled in
 	// production.
-	DebugLevel Level = iota - 1
+	DebugLevel Level = -4
 
 	// InfoLevel is the default logging priority.
-	InfoLevel
+	InfoLevel Level = 0
 
 	// WarnLevel logs are more important than Info, but don't need individual
 	// human review.
-	WarnLevel
+	WarnLevel Level = 4
 
 	// ErrorLevel logs are high-priority. If an application is running smoothly,
 	// it shouldn't generate any error-level logs.
-	ErrorLevel
+	ErrorLevel Level = 8
 
-	// FatalLevel logs a message, then calls os.Exit(1).
-	FatalLevel
+	// FatalLevel logs a message, then exits the program (see
+	// Config.ExitFunc).
+	FatalLevel Level = 12
 
 	// PanicLevel logs a message, then panics.
-	PanicLevel
+	PanicLevel Level = 16
 )
 
-// String returns the string representation of the log level.
+// String returns the string representation of the log level: the upper
+// case name of a predefined or registered level, or "UNKNOWN".
 func (l Level) String() string {
 	switch l {
 	case DebugLevel:
@@ -72,10 +85,16 @@ func (l Level) String() string {
 	case PanicLevel:
 		return "PANIC"
 	default:
+		if c, ok := lookupCustomLevel(l); ok {
+			return c.name
+		}
 		return "UNKNOWN"
 	}
 }
 
+// textTimestampLayout is the timestamp layout used by TextFormat.
+const textTimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
 // Format represents the output format for log entries.
 type Format int8
 
@@ -87,6 +106,34 @@ const (
 	// JSONFormat outputs logs in structured JSON format.
 	// Example: {"timestamp":"2024-01-20T15:04:05.000Z","level":"INFO","message":"User logged in","userID":12345}
 	JSONFormat
+
+	// GELFFormat outputs logs as GELF 1.1 messages for Graylog. Fields are
+	// sent as additional fields, e.g. "userID" becomes "_userID". Use it
+	// with a GELFWriter to ship entries to a Graylog input directly.
+	GELFFormat
+
+	// ECSFormat outputs logs as Elastic Common Schema JSON, ready for
+	// Elasticsearch and Kibana without an ingest pipeline.
+	// Example: {"@timestamp":"2024-01-20T15:04:05Z","log.level":"info","message":"User logged in","ecs.version":"1.6.0","trace.id":"4bf92f"}
+	ECSFormat
+
+	// CloudLoggingFormat outputs logs as structured JSON for Google Cloud
+	// Logging, with Cloud Logging severity names and trace correlation. See
+	// Config.CloudProjectID, SourceLocation and HTTPRequest.
+	// Example: {"severity":"WARNING","time":"2024-01-20T15:04:05Z","message":"Slow query","logging.googleapis.com/trace":"projects/my-project/traces/4bf92f"}
+	CloudLoggingFormat
+
+	// DatadogFormat outputs logs as JSON with the reserved attributes of
+	// Datadog, correlating entries with APM traces through dd.trace_id and
+	// dd.span_id.
+	// Example: {"date":"2024-01-20T15:04:05Z","status":"info","message":"User logged in","dd.trace_id":"5208512171318403364"}
+	DatadogFormat
+
+	// ConsoleFormat outputs logs for reading in a terminal during
+	// development: a short timestamp, a colored level, the message and
+	// aligned key=value fields. Colors are controlled by Config.Color.
+	// Example: "15:04:05.000 INFO  User logged in                           userID=12345"
+	ConsoleFormat
 )
 
 // Field represents a key-value pair that can be attached to a log entry.
@@ -99,39 +146,490 @@ type Field struct {
 	Value interface{}
 }
 
+// Record is the structured form of a log entry. It is built once the entry
+// passes level filtering and is handed to hooks before encoding.
+type Record struct {
+	// Time is the moment the entry was created.
+	Time time.Time
+
+	// Level is the severity of the entry.
+	Level Level
+
+	// Message is the log message.
+	Message string
+
+	// Fields holds the entry fields, including fields extracted from the
+	// context by a ContextLogger.
+	Fields []Field
+
+	// fields is the reusable backing storage for Fields when the record is
+	// modified before encoding, so the caller's slice is never touched.
+	fields []Field
+
+	// remapped is the reusable storage for the fields renamed by the key
+	// map of a sink.
+	remapped []Field
+
+	// raw holds the line passed to WriteRaw. Records with a raw line skip
+	// hooks and encoding.
+	raw []byte
+
+	// traced is the injection time of a delivery trace marker, zero for
+	// regular entries.
+	traced time.Time
+
+	// breadcrumb marks an entry of the trail of Config.Breadcrumbs, which
+	// is written to the sinks accepting the level trigger of the entry
+	// that caused it to be written.
+	breadcrumb bool
+	trigger    Level
+
+	// redactor is the redactor of the logger owning the record, so hooks
+	// that persist entries, like Recorder, can mask them as the sinks will.
+	redactor *redactor
+}
+
 // Config holds the configuration for a Logger instance.
 type Config struct {
 	// Level sets the minimum log level that will be output.
 	// Log entries below this level will be discarded.
 	Level Level
 
-	// Format determines the output format (TextFormat or JSONFormat).
+	// NamedLevels sets the levels of named loggers, keyed by name, like
+	// SetNamedLevel.
+	NamedLevels map[string]Level
+
+	// Format determines the output format (TextFormat, JSONFormat, GELFFormat,
+	// ECSFormat, CloudLoggingFormat, DatadogFormat or ConsoleFormat).
 	Format Format
 
+	// Color selects whether ConsoleFormat colors the entries written to
+	// Output and ErrorOutput. Defaults to ColorAuto.
+	Color ColorMode
+
+	// TimestampFormat selects how TextFormat, JSONFormat and TextTemplate
+	// render timestamps, e.g. TimestampUnixMilli or a time.Format layout.
+	// Defaults to TimestampDefault.
+	TimestampFormat TimestampFormat
+
+	// Clock returns the time of new entries. Tests can inject a fixed
+	// clock to get deterministic output. Defaults to time.Now. Entries of
+	// AsyncSignalSafe always use time.Now, since Clock may not be safe to
+	// call there.
+	Clock func() time.Time
+
+	// TimestampCache reads the clock and renders the timestamp once per
+	// period in a background goroutine, e.g. every millisecond, instead of
+	// on every entry. Entries logged within a period share a timestamp,
+	// which never goes backwards. Zero reads the clock for every entry.
+	// Cannot be combined with Clock.
+	TimestampCache time.Duration
+
+	// TextTemplate overrides the line layout of TextFormat. Placeholders
+	// are {ts}, {level}, {msg}, {fields} and {<key>} for a single field,
+	// e.g. "[{ts}] {level} {service} {msg} {fields}". Fields rendered by
+	// their own placeholder are omitted from {fields}. Use "{{" for a
+	// literal '{'. Ignored for other formats.
+	TextTemplate string
+
+	// PrettyJSON indents the entries of JSONFormat, ECSFormat,
+	// CloudLoggingFormat and DatadogFormat, with every field on a line of
+	// its own, for reading JSON output during local debugging. It applies
+	// to every sink of these formats except those writing to outputs that
+	// frame entries by newlines, such as BatchSender, WALWriter,
+	// IntegrityWriter and StreamHandler. It costs an extra pass per entry,
+	// so leave it off in production.
+	PrettyJSON bool
+
+	// EscapeHTML escapes <, > and & in the strings of JSON, ECS, Cloud
+	// Logging, Datadog and GELF entries as \u003c, \u003e and \u0026, and
+	// U+2028 and U+2029 as \u2028 and \u2029, like encoding/json does by
+	// default, so entries can be embedded in HTML pages or scripts safely.
+	EscapeHTML bool
+
+	// FloatPrecision, when positive, rounds float fields to that many
+	// digits after the decimal point, at most 17, e.g. 2 writes 0.1234 as
+	// 0.12. By default floats are written in full, as the shortest
+	// representation that parses back to the same value.
+	FloatPrecision int
+
+	// NonFiniteFloats decides how NaN and infinite float fields are
+	// written. Defaults to NonFiniteAsString, which keeps JSON output valid.
+	NonFiniteFloats NonFiniteFloatPolicy
+
+	// MaxMessageBytes, when positive, truncates longer messages, appending
+	// "...(truncated, N bytes)" with their original size N, to protect
+	// sinks and parsers from pathological entries.
+	MaxMessageBytes int
+
+	// MaxFieldBytes, when positive, truncates longer string, Block and
+	// ByteString field values like MaxMessageBytes truncates messages.
+	// Values are truncated after redaction, so a secret is never cut
+	// before it is masked.
+	MaxFieldBytes int
+
+	// MaxFields, when positive, caps the number of fields of an entry,
+	// counting those of the context, With and the call. Excess fields are
+	// dropped from the end and counted in a TruncatedFieldsKey field.
+	MaxFields int
+
+	// MaxRecordBytes, when positive, caps the size of an encoded entry,
+	// including its newline, for outputs such as container runtimes that
+	// split longer lines. An oversized entry is handled according to
+	// OversizedRecords. It does not apply to lines passed to WriteRaw.
+	MaxRecordBytes int
+
+	// OversizedRecords decides what happens to entries over
+	// MaxRecordBytes. Defaults to OversizedTruncate.
+	OversizedRecords OversizedRecordPolicy
+
 	// Output specifies where log entries will be written.
-	// If nil, defaults to os.Stdout.
+	// If nil and Outputs is empty, defaults to os.Stdout.
 	Output io.Writer
 
-	// BufferSize enables buffering when > 0. Log entries are buffered
+	// ErrorOutput, when set, receives WARN and more severe entries instead
+	// of Output, which then only receives DEBUG and INFO entries. Setting
+	// Output to os.Stdout and ErrorOutput to os.Stderr separates the streams
+	// the way container log collectors expect. Level, Format and BufferSize
+	// apply to both. Ignored when Output is nil.
+	ErrorOutput io.Writer
+
+	// Outputs adds further sinks, each with its own minimum level and
+	// format, e.g. text to stdout at INFO plus JSON to a file at DEBUG.
+	// Level, Format and BufferSize above apply to Output only. Every entry
+	// is encoded once per distinct format, not once per sink.
+	Outputs []SinkConfig
+
+	// BufferSize enables buffering of Output when > 0. Log entries are buffered
 	// until the buffer is full or Flush() is called. Useful for reducing
 	// I/O operations in cloud environments.
+	//
+	// Outputs implementing BatchLimiter enable buffering on their own and
+	// may lower the effective buffer size.
 	BufferSize int
+
+	// FlushInterval bounds how long an entry stays buffered when BufferSize
+	// is set. Zero keeps entries until the buffer is full or Flush is
+	// called.
+	FlushInterval time.Duration
+
+	// BufferShards splits the buffer of Output into that many shards when
+	// > 1, e.g. runtime.GOMAXPROCS(0), so that goroutines logging
+	// concurrently append under separate locks instead of contending on
+	// one. Goroutines running on the same CPU share a shard, and a flush
+	// merges all shards into as few writes as the buffer limits allow.
+	// Entries are numbered as they are buffered and merged in that order,
+	// so they are written in the order they were logged, even when a
+	// goroutine moves to another CPU. Ignored unless buffering is enabled.
+	BufferShards int
+
+	// SyncWrites commits every write of Output to stable storage, for
+	// outputs that support it such as *os.File. It trades throughput for
+	// durability across crashes.
+	SyncWrites bool
+
+	// Profile applies a bundle of operating settings, see Profile. Options
+	// set explicitly take precedence over the profile. Unknown profiles are
+	// ignored. The profile can be switched later with SetProfile.
+	Profile Profile
+
+	// AsyncQueueSize enables asynchronous logging when > 0. Entries are
+	// queued and processed by a background goroutine, so hooks and writes
+	// no longer run on the logging goroutine. When the queue is full,
+	// DEBUG and INFO entries are shed, starting with entries whose request
+	// context is already canceled (see ContextLogger); WARN and more severe
+	// entries wait for room instead of being dropped. Call Close to drain
+	// the queue before the program exits.
+	AsyncQueueSize int
+
+	// MemoryBudget puts hard caps on the memory held by encoding buffers
+	// and the async queue. Nil leaves memory use unbounded.
+	MemoryBudget *MemoryBudget
+
+	// InitialBufferSize is the capacity of a new encoding buffer. Workloads
+	// whose entries are consistently large can raise it so buffers do not
+	// grow while encoding. Defaults to 256 bytes.
+	InitialBufferSize int
+
+	// MaxPooledBufferSize is the largest capacity of an encoding buffer
+	// kept for reuse. Buffers grown beyond it by a huge entry are released
+	// instead, so one large entry does not keep its memory alive. Defaults
+	// to 64 KiB.
+	MaxPooledBufferSize int
+
+	// Metrics receives the counts of emitted entries, bytes written,
+	// flushes, write errors and dropped entries, so they can be exported
+	// to Prometheus or expvar. See package logmetrics.
+	Metrics logmetrics.Collector
+
+	// WriteErrors decides what happens to entries whose write to an
+	// output fails, e.g. on a full disk or a broken pipe. The zero value
+	// drops them.
+	WriteErrors WriteErrorPolicy
+
+	// OnWriteError, when set, is called with the error of every write
+	// that still failed after the WriteErrors policy ran. It runs on the
+	// writing goroutine, possibly with a sink lock held, so it must not
+	// log through the same logger.
+	OnWriteError func(err error)
+
+	// InternalOutput, when set, receives the library's reports about its
+	// own problems, kept apart from the application's entries: failed
+	// writes, entries dropped from the async queue or the memory budget,
+	// failing hooks, NetWriter disconnects and PartitionWriter rotation
+	// failures. Reports are written in TextFormat under the name
+	// "logslib" and rate limited, so a broken output cannot flood it.
+	// Without InternalOutput these problems are only visible through
+	// Metrics and OnWriteError. It must not be an output of the logger
+	// itself, typically os.Stderr while entries go to os.Stdout or a file.
+	InternalOutput io.Writer
+
+	// StrictPanics lets panics in user code called while logging crash
+	// the program. By default a panic in a hook, a context extractor, a
+	// LogValuer, a json.Marshaler or a custom Encoder is recovered and
+	// reported to InternalOutput with its stack: a panicking hook counts
+	// as a failed hook, a panicking LogValuer is logged as "!PANIC: <value>"
+	// and an entry whose encoding panics is dropped for that sink. Set it
+	// in tests and development to find such bugs early.
+	StrictPanics bool
+
+	// DeliveryTraceInterval enables delivery tracing: every interval a
+	// marker entry carrying DeliveryTraceKey is injected and the time it
+	// takes through the async queue, the sink buffers and each output is
+	// reported in Stats().Delivery. Markers are INFO entries, written only
+	// to the sinks that accept InfoLevel. Zero disables tracing.
+	DeliveryTraceInterval time.Duration
+
+	// RateLimit caps the number of entries per level using token buckets.
+	// Levels without an entry are not limited. Entries over budget are
+	// dropped and summarized by a "N records suppressed" entry.
+	RateLimit map[Level]RateLimit
+
+	// RateLimitReportInterval is the minimum time between two
+	// "N records suppressed" entries for the same level. The summary is
+	// emitted with the next admitted entry of that level, one interval
+	// after the first suppressed entry, or on Flush(), whichever comes
+	// first. Defaults to one second.
+	RateLimitReportInterval time.Duration
+
+	// RepeatWindow, when > 0, drops an entry identical to the previous one,
+	// in level, message and fields, logged less than RepeatWindow after
+	// the last written copy, like syslog does for crash loops. The repeats
+	// are summarized by a "last message repeated N times" entry with a
+	// RepeatedKey field, written before the next different entry, when
+	// the window ends or on Flush. FATAL and PANIC entries are never
+	// dropped.
+	RepeatWindow time.Duration
+
+	// Breadcrumbs keeps the last disabled entries in memory and writes them
+	// before the next ERROR, FATAL or PANIC entry. Nil disables
+	// breadcrumbs. See BreadcrumbConfig.
+	Breadcrumbs *BreadcrumbConfig
+
+	// Sampling enables a sampler that keeps rare entries and samples
+	// entries whose message and key field values repeat often. Sampling
+	// runs before rate limiting. Nil disables sampling.
+	Sampling *SamplingConfig
+
+	// OnError returns fields appended to every ERROR, FATAL and PANIC
+	// entry, for diagnostics too expensive to collect on every call, such
+	// as connection pool statistics or circuit breaker states. It runs
+	// after sampling and rate limiting and before hooks, on the writer
+	// goroutine when AsyncQueueSize is set. Entries below ErrorLevel never
+	// call it. It must not log at ErrorLevel or above through the same
+	// logger.
+	OnError func(r *Record) []Field
+
+	// OnFatal lists functions run in order after a FATAL entry is logged
+	// and before the program exits, e.g. to close connections or report
+	// the crash. A panic in one of them is recovered so the others still
+	// run. The logger is flushed after them, so entries they log are kept.
+	OnFatal []func()
+
+	// ExitFunc is called with status 1 by Fatal and its variants once the
+	// OnFatal functions ran and the logger is flushed. Defaults to
+	// os.Exit. Tests can record the call instead of exiting; Fatal then
+	// returns to its caller.
+	ExitFunc func(code int)
+
+	// Filters drop entries by message, field values and logger name before
+	// they are sampled, processed or encoded, see Filter.
+	Filters []Filter
+
+	// RedactKeys lists field keys whose values are replaced with
+	// RedactedValue. Entries may be glob patterns as understood by
+	// path.Match (e.g. "*password*"). Matching is case-insensitive.
+	RedactKeys []string
+
+	// RedactValuePatterns masks every match in the message and in string
+	// field values, e.g. RedactEmailPattern or RedactCreditCardPattern.
+	// Redaction runs after hooks, right before encoding.
+	RedactValuePatterns []*regexp.Regexp
+
+	// Normalizers converts field values to a canonical form per key, e.g.
+	// {"method": NormalizeUpper, "latency": NormalizeDurationMillis}.
+	// Normalization runs after hooks and before redaction.
+	Normalizers map[string]Normalizer
+
+	// KeyMap renames keys right before encoding, e.g. {"trace_id": "traceID"}.
+	// It applies to field keys and to the built-in JSON keys TimestampKey,
+	// LevelKey and MessageKey, so the output can match the schema expected
+	// downstream. Redaction and hooks see the original keys.
+	//
+	// KeyMap applies to Output, ErrorOutput and the sinks whose
+	// SinkConfig.KeyMap is nil; sinks feeding another schema set their own.
+	KeyMap map[string]string
+
+	// DuplicateKeys decides which fields are written when several share a
+	// key, e.g. a key of the context, of With and of the call. It applies
+	// after hooks, normalization and redaction, before EntryHash and
+	// KeyMap. Defaults to DuplicateKeysAllow.
+	DuplicateKeys DuplicateKeyPolicy
+
+	// EntryHash adds an EntryHashKey field holding a stable hash of the
+	// level, message and fields of every entry, excluding the timestamp,
+	// so pipelines with at-least-once delivery can drop duplicates. The
+	// hash is computed after redaction and before KeyMap, so renaming
+	// keys does not change it.
+	EntryHash bool
+
+	// AddCaller adds a CallerKey field holding the file and line of the
+	// code that logged the entry, e.g. "billing/charge.go:42". Frames of
+	// this package, log/slog and the standard log package are skipped, so
+	// entries logged through them point at the application.
+	AddCaller bool
+
+	// AddStacktrace adds a StacktraceKey field holding the stack of the
+	// goroutine that logged the entry to entries at StacktraceLevel and
+	// above. The stack starts at the code that logged the entry and is a
+	// Block, so TextFormat and ConsoleFormat render it below the entry.
+	AddStacktrace bool
+
+	// StacktraceLevel is the minimum level of the entries AddStacktrace
+	// applies to.
+	StacktraceLevel Level
+
+	// ShardID adds a ShardKey field holding it and a SequenceKey field
+	// numbering the entries of the logger from 1, so that the output of
+	// several processes sharing a sink can be put back into order with
+	// MergeShards. Use an ID unique to the process run, e.g. the host name
+	// and process ID, since the sequence restarts with the process.
+	ShardID string
+
+	// ContextExtractor derives the fields a ContextLogger adds to every
+	// entry from its context, e.g. a tenant or request ID stored under the
+	// application's own context key types. Nil looks up the traceID and
+	// spanID keys.
+	ContextExtractor ContextExtractor
+
+	// IDGenerator creates the IDs returned by Logger.NewID, such as the
+	// request IDs of HTTPMiddleware, e.g. ULID() or Snowflake(node). Nil
+	// generates UUIDv4s.
+	IDGenerator IDGenerator
+
+	// CloudProjectID is the Google Cloud project used by CloudLoggingFormat
+	// to expand trace IDs to "projects/<id>/traces/<trace>", which links
+	// entries to Cloud Trace. ConfigFromEnv and NewFromEnv set it from
+	// EnvCloudProject; New does not read the environment.
+	CloudProjectID string
 }
 
 // Logger is a high-performance logging instance that supports structured
 // logging with minimal memory allocations. It is safe for concurrent use.
+//
+// Loggers derived with With and Named share the sinks, hooks, queue and
+// level of the logger created by New.
 type Logger struct {
-	config Config
-	buffer []byte
-	pool   sync.Pool
-	mu     sync.Mutex
+	*core
+
+	// name is the dot-separated name given by Named.
+	name string
+
+	// context holds the fields added to every entry: the name under
+	// LoggerKey, followed by the fields given to With.
+	context []Field
+
+	// groups holds the groups opened by WithGroup, outermost first.
+	groups []openGroup
+
+	// levelCache caches the level set with SetNamedLevel that applies to
+	// name. Loggers with the same name share it.
+	levelCache *levelCache
+}
+
+// core is the state shared by a Logger and all loggers derived from it.
+// Derived loggers point to the core directly, so checking whether a level
+// is enabled is a single atomic load however deep the chain of With and
+// Named calls is.
+type core struct {
+	config      Config
+	pool        sync.Pool
+	sinks       atomic.Pointer[[]*sink]
+	sinksMu     sync.Mutex
+	minLevel    atomic.Int32
+	namedLevels namedLevels
+	limiter     *rateLimiter
+	sampler     atomic.Pointer[sampler]
+	template    *textTemplate
+	records     sync.Pool
+	hooks       atomic.Pointer[[]Hook]
+	hooksMu     sync.Mutex
+	redactor    *redactor
+	filters     *filterSet
+	repeats     *repeatSuppressor
+	breadcrumbs *breadcrumbRing
+	keyMap      *keyMap
+	async       *asyncQueue
+	buffers     *bufferBudget
+	tracer      *deliveryTracer
+	sequence    atomic.Uint64
+
+	signalOnce sync.Once
+	signalRing *signalRing
+
+	timeCache *timeCache
+
+	// internal is the logger of Config.InternalOutput, or nil.
+	internal *Logger
+
+	// tee holds the loggers a logger created by Tee fans out to.
+	tee []*Logger
+
+	// sampled counts the entries dropped by samplers replaced through
+	// SetProfile.
+	sampled     atomic.Uint64
+	bypassAsync atomic.Bool
+	profileMu   sync.Mutex
+	profile     atomic.Pointer[Profile]
+
+	// explicit holds the profile settings set in the Config passed to
+	// New, which SetProfile keeps.
+	explicit profileSettings
+
+	debugOnce    sync.Once
+	debugHandler http.Handler
+
+	// rewritesFields is set when the configuration modifies record fields
+	// before encoding, which requires a private copy of the fields.
+	rewritesFields bool
+
+	// limitsRecords is set when any of the size limits of the
+	// configuration applies.
+	limitsRecords bool
+
+	// bufferSize and maxPooledBuffer are Config.InitialBufferSize and
+	// Config.MaxPooledBufferSize with their defaults applied.
+	bufferSize      int
+	maxPooledBuffer int
 }
 
 // New creates a new Logger instance with the given configuration.
 //
-// If config.Output is nil, it defaults to os.Stdout.
+// If config.Output is nil and no config.Outputs are given, it defaults to
+// os.Stdout.
 // The logger is safe for concurrent use and optimized for minimal
-// memory allocations using object pooling.
+// memory allocations using object pooling. New does not validate config;
+// use NewE to have misconfigurations reported as an error.
 //
 // Example:
 //
@@ -142,21 +640,113 @@ type Logger struct {
 //		BufferSize: 4096, // Optional buffering
 //	})
 func New(config Config) *Logger {
-	if config.Output == nil {
+	if config.Output == nil && len(config.Outputs) == 0 {
 		config.Output = os.Stdout
 	}
+	_, profiled := profiles[config.Profile]
+	explicit := configProfile(config)
+	config = applyProfile(config)
 
-	l := &Logger{
-		config: config,
-		buffer: make([]byte, 0, config.BufferSize),
+	l := &Logger{core: &core{
+		config:   config,
+		limiter:  newRateLimiter(config.RateLimit, config.RateLimitReportInterval),
+		template: compileTextTemplate(config.TextTemplate, config.TimestampFormat),
+		redactor: newRedactor(config.RedactKeys, config.RedactValuePatterns),
+		filters:  newFilterSet(config.Filters),
+		repeats:  newRepeatSuppressor(config.RepeatWindow),
+		keyMap:   newKeyMap(config.KeyMap),
+		internal: newInternalLogger(config.InternalOutput),
+		explicit: explicit,
+	}}
+	l.levelCache = l.namedLevels.cache("")
+	for name, level := range config.NamedLevels {
+		l.SetNamedLevel(name, level)
+	}
+	l.rewritesFields = l.redactor != nil || len(config.Normalizers) > 0 || config.EntryHash ||
+		config.DuplicateKeys != DuplicateKeysAllow || config.FloatPrecision > 0 || config.NonFiniteFloats != NonFiniteAsString
+	l.limitsRecords = config.MaxMessageBytes > 0 || config.MaxFieldBytes > 0 || config.MaxFields > 0
+	l.rewritesFields = l.rewritesFields || l.limitsRecords
+	l.sampler.Store(newSampler(config.Sampling))
+	if profiled {
+		l.profile.Store(&config.Profile)
 	}
 
+	l.bufferSize = config.InitialBufferSize
+	if l.bufferSize == 0 {
+		l.bufferSize = defaultInitialBufferSize
+	}
+	l.maxPooledBuffer = config.MaxPooledBufferSize
+	if l.maxPooledBuffer == 0 {
+		l.maxPooledBuffer = max(defaultMaxPooledBufferSize, l.bufferSize)
+	}
 	l.pool = sync.Pool{
 		New: func() interface{} {
-			buf := make([]byte, 0, 256)
+			buf := make([]byte, 0, l.bufferSize)
 			return &buf
 		},
 	}
+	l.buffers = newBufferBudget(config.MemoryBudget, l.bufferSize, l.maxPooledBuffer)
+
+	sinks := make([]*sink, 0, len(config.Outputs)+2)
+	if config.Output != nil {
+		primary := l.newSink(SinkConfig{
+			Output:        config.Output,
+			Level:         config.Level,
+			Format:        config.Format,
+			Color:         config.Color,
+			BufferSize:    config.BufferSize,
+			FlushInterval: config.FlushInterval,
+			SyncWrites:    config.SyncWrites,
+			BufferShards:  config.BufferShards,
+		})
+		primary.profiled = true
+		sinks = append(sinks, primary)
+
+		if config.ErrorOutput != nil {
+			primary.maxLevel = WarnLevel - 1
+			errorSink := l.newSink(SinkConfig{
+				Output:        config.ErrorOutput,
+				Level:         max(config.Level, WarnLevel),
+				Format:        config.Format,
+				BufferSize:    config.BufferSize,
+				FlushInterval: config.FlushInterval,
+				SyncWrites:    config.SyncWrites,
+			})
+			errorSink.profiled = true
+			sinks = append(sinks, errorSink)
+		}
+	}
+	for _, cfg := range config.Outputs {
+		sinks = append(sinks, l.newSink(cfg))
+	}
+	l.storeSinks(sinks)
+
+	if config.Breadcrumbs != nil {
+		l.breadcrumbs = &breadcrumbRing{}
+	}
+
+	l.records = sync.Pool{
+		New: func() interface{} {
+			return &Record{redactor: l.redactor}
+		},
+	}
+
+	if config.AsyncQueueSize > 0 {
+		var maxQueueBytes int
+		if config.MemoryBudget != nil {
+			maxQueueBytes = config.MemoryBudget.MaxQueueBytes
+		}
+		l.async = newAsyncQueue(l, config.AsyncQueueSize, maxQueueBytes)
+	}
+	if config.TimestampCache > 0 {
+		l.timeCache = newTimeCache(config.TimestampCache, config.TimestampFormat)
+		if l.template != nil {
+			l.template.timeCache = l.timeCache
+		}
+	}
+	if config.DeliveryTraceInterval > 0 {
+		l.tracer = newDeliveryTracer(l, config.DeliveryTraceInterval)
+	}
 
 	return l
 }
@@ -200,23 +790,278 @@ func (l *Logger) WithStaticContext(ctx context.Context) *ContextLogger {
 }
 
 func (l *Logger) log(level Level, msg string, fields ...Field) {
-	if level < l.config.Level {
+	l.logContext(context.Background(), level, msg, fields...)
+}
+
+// enabled reports whether any sink accepts entries at level and the named
+// level of l lets them through.
+func (l *Logger) enabled(level Level) bool {
+	return int32(level) >= l.minLevel.Load() && l.namedEnabled(level)
+}
+
+// logContext logs an entry that belongs to ctx, the context of the request
+// being served, or context.Background() when it is not tied to a request.
+func (l *Logger) logContext(ctx context.Context, level Level, msg string, fields ...Field) {
+	if !l.enabled(level) {
+		if l.breadcrumbs != nil {
+			if len(l.groups) > 0 {
+				fields = l.groupFields(fields)
+			}
+			l.keepDisabled(ctx, level, msg, fields)
+		}
 		return
 	}
 
-	bufPtr := l.pool.Get().(*[]byte)
-	defer l.pool.Put(bufPtr)
+	l.logAt(ctx, l.now(), level, msg, fields)
+}
 
-	buf := (*bufPtr)[:0]
+// logAt runs sampling and rate limiting for an enabled entry created at t
+// and emits it. A zero t, which slog records may carry, is kept in the
+// record while sampling and rate limiting use the current time.
+func (l *Logger) logAt(ctx context.Context, t time.Time, level Level, msg string, fields []Field) {
+	if len(l.groups) > 0 {
+		fields = l.groupFields(fields)
+	}
+	l.logGrouped(ctx, t, level, msg, fields)
+}
+
+// logGrouped is logAt for fields already nested in the groups of l.
+func (l *Logger) logGrouped(ctx context.Context, t time.Time, level Level, msg string, fields []Field) {
+	if l.tee != nil {
+		l.teeLog(ctx, t, level, msg, fields)
+		return
+	}
+
+	if l.filters != nil && !l.filters.allow(l.name, msg, l.context, fields) {
+		l.countDropped(logmetrics.DropFiltered)
+		return
+	}
 
-	switch l.config.Format {
+	now := t
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	if l.repeats != nil && l.suppressRepeat(level, msg, fields, now) {
+		return
+	}
+
+	if l.breadcrumbs != nil && level >= ErrorLevel {
+		l.writeBreadcrumbs(ctx, level)
+	}
+
+	if s := l.sampler.Load(); s != nil && level < WarnLevel && !s.allow(msg, l.context, fields, now) {
+		l.countDropped(logmetrics.DropSampled)
+		return
+	}
+
+	if l.limiter != nil {
+		allowed, suppressed := l.limiter.allow(l, level, now)
+		if !allowed {
+			l.countDropped(logmetrics.DropRateLimited)
+			return
+		}
+		if suppressed > 0 {
+			l.logSuppressed(level, suppressed)
+		}
+	}
+
+	if l.config.AddCaller {
+		fields = appendCaller(fields)
+	}
+	if l.config.AddStacktrace && level >= l.config.StacktraceLevel {
+		fields = appendStacktrace(fields)
+	}
+	l.emit(ctx, t, level, msg, fields...)
+}
+
+// emit builds the record for an entry that already passed level filtering
+// and either processes it right away or hands it to the async queue.
+func (l *Logger) emit(ctx context.Context, t time.Time, level Level, msg string, fields ...Field) {
+	if l.config.ShardID != "" {
+		fields = l.appendShardFields(fields)
+	}
+
+	r := l.records.Get().(*Record)
+	r.Time = t
+	r.Level = level
+	r.Message = msg
+
+	if l.async != nil && !l.bypassAsync.Load() {
+		// The caller may reuse its slice once we return.
+		r.fields = append(append(r.fields[:0], l.context...), fields...)
+		r.Fields = r.fields
+		l.resolveValuers(r)
+		snapshotReflectValues(r.Fields)
+		l.async.push(ctx, r)
+		return
+	}
+
+	if len(l.context) > 0 || l.hooks.Load() != nil || l.rewritesFields {
+		r.fields = append(append(r.fields[:0], l.context...), fields...)
+		r.Fields = r.fields
+	} else {
+		r.Fields = fields
+	}
+	l.resolveValuers(r)
+
+	l.process(r)
+	l.putRecord(r)
+}
+
+// process runs OnError, the hooks, normalization and redaction on r and
+// writes the encoded entry to every sink that accepts it, renamed by the
+// key map of the sink. r.Fields must
+// be owned by the record whenever the record is modified.
+func (l *Logger) process(r *Record) {
+	if len(r.raw) > 0 {
+		l.processRaw(r)
+		return
+	}
+	if !r.traced.IsZero() {
+		l.processTrace(r)
+		return
+	}
+
+	if l.config.OnError != nil && r.Level >= ErrorLevel {
+		addErrorFields(l.config.OnError, r)
+	}
+
+	if hooks := l.hooks.Load(); hooks != nil && !l.runHooks(*hooks, r) {
+		return
+	}
+
+	if len(l.config.Normalizers) > 0 {
+		normalizeFields(l.config.Normalizers, r)
+	}
+
+	if l.redactor != nil {
+		l.redactor.redact(r)
+	}
+
+	if l.config.FloatPrecision > 0 || l.config.NonFiniteFloats != NonFiniteAsString {
+		formatFloats(l.config.FloatPrecision, l.config.NonFiniteFloats, r)
+	}
+
+	if l.config.DuplicateKeys != DuplicateKeysAllow {
+		dedupFields(l.config.DuplicateKeys, r)
+	}
+
+	if l.limitsRecords {
+		l.limitRecord(r)
+	}
+
+	if l.config.EntryHash {
+		addEntryHash(r)
+	}
+
+	if l.config.Metrics != nil {
+		l.config.Metrics.Emitted(r.Level.String())
+	}
+	l.writeSinks(r)
+}
+
+// writeSinks writes the encoded entry to every sink that accepts r.
+func (l *Logger) writeSinks(r *Record) {
+	var encoded encodings
+	defer encoded.release(l)
+
+	level := r.Level
+	if r.breadcrumb {
+		level = r.trigger
+	}
+	fields := r.Fields
+	defer func() { r.Fields = fields }()
+	for _, s := range *l.sinks.Load() {
+		if !s.accepts(level) {
+			continue
+		}
+		r.Fields = s.keyMap.remapFields(r, fields)
+
+		if buf := encoded.get(s.key); buf != nil {
+			s.write(r, buf)
+			continue
+		}
+
+		bufPtr := l.getBuffer()
+		if l.buffers != nil && !l.buffers.grow(bufPtr, int(recordSize(r))) {
+			l.putBuffer(bufPtr)
+			l.dropMemory()
+			continue
+		}
+		before := cap(*bufPtr)
+		if !l.encodeSink(s, r, bufPtr) {
+			l.putBuffer(bufPtr)
+			continue
+		}
+		if limit := l.config.MaxRecordBytes; limit > 0 && len(*bufPtr) > limit && !l.shrinkRecord(s, r, bufPtr) {
+			l.putBuffer(bufPtr)
+			l.countDropped(logmetrics.DropOversized)
+			continue
+		}
+		if l.buffers != nil && !l.buffers.account(bufPtr, before) {
+			l.dropMemory()
+			continue
+		}
+		s.write(r, *bufPtr)
+
+		if !encoded.put(s.key, bufPtr) {
+			l.putBuffer(bufPtr)
+		}
+	}
+}
+
+// encode appends the newline-terminated encoding of r in format to buf.
+// color applies to ConsoleFormat only and keys to JSONFormat only.
+func (l *Logger) encode(buf []byte, format Format, color, singleLine bool, keys *jsonKeys, r *Record) []byte {
+	start := len(buf)
+	switch format {
 	case JSONFormat:
-		buf = l.appendJSON(buf, level, msg, fields...)
+		buf = l.appendJSON(buf, keys, r)
+	case GELFFormat:
+		buf = appendGELF(buf, r)
+	case ECSFormat:
+		buf = appendECS(buf, r)
+	case CloudLoggingFormat:
+		buf = appendCloudLogging(buf, r, l.config.CloudProjectID)
+	case DatadogFormat:
+		buf = appendDatadog(buf, r)
+	case ConsoleFormat:
+		buf = l.appendConsole(buf, r, color, !singleLine)
 	default:
-		buf = l.appendText(buf, level, msg, fields...)
+		if l.template != nil {
+			buf = l.template.appendTemplate(buf, r, !singleLine)
+		} else {
+			buf = l.appendText(buf, r, !singleLine)
+		}
+	}
+
+	if l.config.EscapeHTML && (isJSONFormat(format) || format == GELFFormat) {
+		buf = escapeJSONHTML(buf, start)
+	}
+	if l.config.PrettyJSON && isJSONFormat(format) && !singleLine {
+		buf = indentJSON(buf, start)
 	}
+	return append(buf, '\n')
+}
+
+// putRecord returns a record to the pool without retaining field values.
+func (l *Logger) putRecord(r *Record) {
+	clear(r.fields[:cap(r.fields)])
+	r.fields = r.fields[:0]
+	r.Fields = nil
+	clear(r.remapped)
+	r.remapped = r.remapped[:0]
+	r.raw = r.raw[:0]
+	r.traced = time.Time{}
+	r.breadcrumb = false
+	l.records.Put(r)
+}
 
-	l.write(buf)
+// Log logs a message at level, which may be a level added with
+// RegisterLevel. Unlike Fatal and Panic, it neither exits nor panics.
+func (l *Logger) Log(level Level, msg string, fields ...Field) {
+	l.log(level, msg, fields...)
 }
 
 // Debug logs a message at DebugLevel. Debug logs are typically voluminous
@@ -243,53 +1088,81 @@ func (l *Logger) Error(msg string, fields ...Field) {
 	l.log(ErrorLevel, msg, fields...)
 }
 
-// Fatal logs a message at FatalLevel, then calls os.Exit(1).
-// This function does not return.
+// Fatal logs a message at FatalLevel, runs Config.OnFatal, flushes the
+// logger and calls Config.ExitFunc, os.Exit by default, with status 1.
+// Unless ExitFunc returns, this function does not return.
 func (l *Logger) Fatal(msg string, fields ...Field) {
 	l.log(FatalLevel, msg, fields...)
-	os.Exit(1)
+	l.exit()
 }
 
-// Panic logs a message at PanicLevel, then panics with the message.
-// This function does not return.
-func (l *Logger) Panic(msg string, fields ...Field) {
-	l.log(PanicLevel, msg, fields...)
-	panic(msg)
+// exit runs Config.OnFatal, flushes the buffered and queued entries so the
+// FATAL entry is not lost, and calls Config.ExitFunc with status 1.
+func (l *Logger) exit() {
+	for _, fn := range l.config.OnFatal {
+		l.runOnFatal(fn)
+	}
+	l.Flush()
+
+	exit := l.config.ExitFunc
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(1)
 }
 
-func (l *Logger) write(buf []byte) {
-	if l.config.BufferSize > 0 {
-		l.mu.Lock()
-		defer l.mu.Unlock()
+// panic flushes the buffered and queued entries so the PANIC entry is not
+// lost, like exit, and panics with msg.
+func (l *Logger) panic(msg string) {
+	l.Flush()
+	panic(msg)
+}
 
-		if len(l.buffer)+len(buf) > l.config.BufferSize {
-			l.flush()
+// runOnFatal calls fn, reporting a panic to the internal logger instead of
+// letting it prevent the exit.
+func (l *Logger) runOnFatal(fn func()) {
+	defer func() {
+		if p := recover(); p != nil {
+			l.internal.report(ErrorLevel, "OnFatal function panicked", F("panic", p))
 		}
-		l.buffer = append(l.buffer, buf...)
-		l.buffer = append(l.buffer, '\n')
-	} else {
-		_, _ = l.config.Output.Write(buf)
-		_, _ = l.config.Output.Write([]byte{'\n'})
-	}
+	}()
+	fn()
 }
 
-// Flush forces all buffered log entries to be written to the output.
-// This method is only effective when BufferSize > 0 in the Config.
-// It is safe to call concurrently with other logger methods.
+// Panic logs a message at PanicLevel, flushes the logger, then panics with
+// the message. This function does not return.
+func (l *Logger) Panic(msg string, fields ...Field) {
+	l.log(PanicLevel, msg, fields...)
+	l.panic(msg)
+}
+
+// Flush forces all buffered log entries to be written to the outputs.
+// This method is only effective for outputs with buffering enabled through
+// BufferSize or declared BatchLimits. It is safe to call concurrently with
+// other logger methods.
+//
+// Pending rate limit and repeat summaries are emitted and the async queue is drained
+// before the buffers are flushed.
 func (l *Logger) Flush() {
-	if l.config.BufferSize > 0 {
-		l.mu.Lock()
-		defer l.mu.Unlock()
-		l.flush()
+	if l.limiter != nil {
+		l.limiter.drain(l.logSuppressed)
+	}
+	if l.repeats != nil {
+		l.flushRepeats()
+	}
+
+	if l.async != nil {
+		l.async.drain()
 	}
-}
 
-// flush is an internal method that writes all buffered content to the output.
-// It must be called with l.mu held.
-func (l *Logger) flush() {
-	if len(l.buffer) > 0 {
-		_, _ = l.config.Output.Write(l.buffer)
-		l.buffer = l.buffer[:0]
+	for _, s := range *l.sinks.Load() {
+		s.Flush()
+	}
+	if l.internal != nil {
+		l.internal.Flush()
+	}
+	for _, t := range l.tee {
+		t.Flush()
 	}
 }
 
@@ -306,73 +1179,98 @@ type ContextLogger struct {
 // Debug logs a message at DebugLevel, automatically including context fields
 // such as traceID and spanID if present in the context.
 func (cl *ContextLogger) Debug(msg string, fields ...Field) {
-	cl.logger.log(DebugLevel, msg, cl.extractContextFields(fields)...)
+	cl.log(DebugLevel, msg, fields)
 }
 
 // Info logs a message at InfoLevel, automatically including context fields
 // such as traceID and spanID if present in the context.
 func (cl *ContextLogger) Info(msg string, fields ...Field) {
-	cl.logger.log(InfoLevel, msg, cl.extractContextFields(fields)...)
+	cl.log(InfoLevel, msg, fields)
 }
 
 // Warn logs a message at WarnLevel, automatically including context fields
 // such as traceID and spanID if present in the context.
 func (cl *ContextLogger) Warn(msg string, fields ...Field) {
-	cl.logger.log(WarnLevel, msg, cl.extractContextFields(fields)...)
+	cl.log(WarnLevel, msg, fields)
 }
 
 // Error logs a message at ErrorLevel, automatically including context fields
 // such as traceID and spanID if present in the context.
 func (cl *ContextLogger) Error(msg string, fields ...Field) {
-	cl.logger.log(ErrorLevel, msg, cl.extractContextFields(fields)...)
+	cl.log(ErrorLevel, msg, fields)
 }
 
-// Fatal logs a message at FatalLevel with context fields, then calls os.Exit(1).
-// This function does not return.
+// Fatal logs a message at FatalLevel with context fields, then exits like
+// Logger.Fatal.
 func (cl *ContextLogger) Fatal(msg string, fields ...Field) {
-	cl.logger.log(FatalLevel, msg, cl.extractContextFields(fields)...)
-	os.Exit(1)
+	cl.log(FatalLevel, msg, fields)
+	cl.logger.exit()
 }
 
-// Panic logs a message at PanicLevel with context fields, then panics with the message.
-// This function does not return.
+// Panic logs a message at PanicLevel with context fields, then flushes and
+// panics like Logger.Panic. This function does not return.
 func (cl *ContextLogger) Panic(msg string, fields ...Field) {
-	cl.logger.log(PanicLevel, msg, cl.extractContextFields(fields)...)
-	panic(msg)
+	cl.log(PanicLevel, msg, fields)
+	cl.logger.panic(msg)
 }
 
-func (cl *ContextLogger) extractContextFields(fields []Field) []Field {
-	contextFields := make([]Field, 0, 4)
+// log resolves the context once and logs the entry with the context fields
+// prepended. The context is attached to the entry so async mode can shed
+// entries of canceled requests first.
+func (cl *ContextLogger) log(level Level, msg string, fields []Field) {
+	l := cl.logger
+	enabled := l.enabled(level)
+	if !enabled && l.breadcrumbs == nil {
+		return
+	}
 
+	ctx := context.Background()
 	if cl.ctxFunc != nil {
-		ctx := cl.ctxFunc()
-		if traceID := ctx.Value(contextKey("traceID")); traceID != nil {
-			contextFields = append(contextFields, Field{Key: "traceID", Value: traceID})
-		}
-		if spanID := ctx.Value(contextKey("spanID")); spanID != nil {
-			contextFields = append(contextFields, Field{Key: "spanID", Value: spanID})
-		}
+		ctx = cl.ctxFunc()
 	}
 
-	return append(contextFields, fields...)
+	// The context fields stay at the top level, outside the groups.
+	if len(l.groups) > 0 {
+		fields = l.groupFields(fields)
+	}
+	fields = l.extractContextFields(ctx, fields)
+	if !enabled {
+		l.keepDisabled(ctx, level, msg, fields)
+		return
+	}
+	l.logGrouped(ctx, l.now(), level, msg, fields)
 }
 
-func (l *Logger) appendText(buf []byte, level Level, msg string, fields ...Field) []byte {
-	now := time.Now().UTC()
-
-	buf = append(buf, now.Format("2006-01-02T15:04:05.000Z07:00")...)
-	buf = append(buf, ' ')
-	buf = append(buf, level.String()...)
+// appendText appends r in the TextFormat layout. Block fields go below the
+// entry line when blocks is set, and are quoted inline otherwise.
+func (l *Logger) appendText(buf []byte, r *Record, blocks bool) []byte {
+	if !omitsTimestamp(r.Time, l.config.TimestampFormat) {
+		buf = l.timeCache.appendText(buf, r.Time, l.config.TimestampFormat)
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, r.Level.String()...)
 	buf = append(buf, ' ')
-	buf = append(buf, msg...)
+	buf = append(buf, r.Message...)
 
-	for _, field := range fields {
+	hasBlocks := false
+	for _, field := range r.Fields {
+		if blocks && isBlock(field.Value) {
+			hasBlocks = true
+			continue
+		}
 		buf = append(buf, ' ')
+		if g, ok := field.Value.(groupValue); ok {
+			buf = appendTextGroup(buf, field.Key, g, false)
+			continue
+		}
 		buf = append(buf, field.Key...)
 		buf = append(buf, '=')
 		buf = appendValue(buf, field.Value)
 	}
 
+	if hasBlocks {
+		buf = appendBlocks(buf, r.Fields)
+	}
 	return buf
 }
 
@@ -387,18 +1285,46 @@ func appendValue(buf []byte, value interface{}) []byte {
 			buf = append(buf, v...)
 		}
 	case int:
-		return appendInt(buf, int64(v))
+		return strconv.AppendInt(buf, int64(v), 10)
 	case int64:
-		return appendInt(buf, v)
+		return strconv.AppendInt(buf, v, 10)
 	case float64:
 		return appendFloat(buf, v)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return appendFloat(buf, float32Value(v))
 	case bool:
 		if v {
 			buf = append(buf, "true"...)
 		} else {
 			buf = append(buf, "false"...)
 		}
+	case secretValue:
+		buf = v.appendTo(buf)
+	case bytesValue:
+		buf = v.appendText(buf)
+	case blockValue:
+		buf = appendQuotedBlock(buf, v)
+	case time.Time:
+		buf = v.AppendFormat(buf, time.RFC3339Nano)
+	case sourceLocation:
+		buf = v.appendText(buf)
+	case httpRequest:
+		buf = v.appendText(buf)
+	case reflectValue:
+		buf = v.appendJSON(buf)
+	case groupValue:
+		buf = appendJSONGroup(buf, v)
+	case nil:
+		buf = append(buf, "null"...)
 	default:
+		if out, ok := appendInteger(buf, v); ok {
+			return out
+		}
+		if s, ok := textValue(v); ok {
+			return appendValue(buf, s)
+		}
 		buf = append(buf, '"')
 		buf = append(buf, "unknown"...)
 		buf = append(buf, '"')
@@ -415,29 +1341,11 @@ func needsQuoting(s string) bool {
 	return false
 }
 
-func appendInt(buf []byte, i int64) []byte {
-	if i == 0 {
-		return append(buf, '0')
-	}
-
-	if i < 0 {
-		buf = append(buf, '-')
-		i = -i
-	}
-
-	var tmp [20]byte
-	idx := 20
-	for i > 0 {
-		idx--
-		tmp[idx] = byte('0' + i%10)
-		i /= 10
-	}
-
-	return append(buf, tmp[idx:]...)
-}
-
-// appendFloat appends the string representation of a float64 to the buffer.
+// appendFloat appends the string representation of a float64 to the buffer,
+// formatted like appendJSONFloat but with NaN and infinities unquoted.
 func appendFloat(buf []byte, f float64) []byte {
-	// Use 'g' format for compact representation, 6 digits precision, -1 for all digits necessary
-	return append(buf, strconv.FormatFloat(f, 'g', -1, 64)...)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendNonFinite(buf, f)
+	}
+	return appendFloatNumber(buf, f, 64)
 }
@@ -229,6 +229,25 @@ func TestContextFieldsExtraction(t *testing.T) {
 	assert.NotContains(t, output, `"spanID"`)
 }
 
+func TestContextFieldsExtraction_PreservesTypes(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: buf,
+	})
+
+	type traceID uint64
+	ctx := context.WithValue(context.Background(), contextKey("traceID"), traceID(1234567890))
+	ctx = context.WithValue(ctx, contextKey("spanID"), int32(42))
+	logger.WithStaticContext(ctx).Info("test")
+
+	output := buf.String()
+	assert.Contains(t, output, `"traceID":1234567890`)
+	assert.Contains(t, output, `"spanID":42`)
+}
+
 func TestLogger_WithStaticContext(t *testing.T) {
 	buf := &bytes.Buffer{}
 
@@ -0,0 +1,378 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lokiPushPath is appended to LokiConfig.URL when it has no path.
+const lokiPushPath = "/loki/api/v1/push"
+
+// errLokiNoURL is returned when LokiConfig.URL is empty.
+var errLokiNoURL = errors.New("logger: LokiConfig.URL is required")
+
+// LokiEncoding selects the payload format of a LokiWriter.
+type LokiEncoding int8
+
+const (
+	// LokiJSON sends JSON push requests.
+	LokiJSON LokiEncoding = iota
+
+	// LokiProtobuf sends snappy-compressed protobuf push requests, which
+	// are smaller and cheaper for Loki to decode.
+	LokiProtobuf
+)
+
+// LokiConfig configures a LokiWriter.
+type LokiConfig struct {
+	// URL is the Loki base URL, e.g. "http://loki:3100", or the full push
+	// endpoint. Required.
+	URL string
+
+	// Labels are static stream labels, e.g. {"service": "billing"}.
+	Labels map[string]string
+
+	// LabelFields lists keys of JSON entries whose values become stream
+	// labels, e.g. []string{"level", "region"}. Keep their cardinality
+	// low; every distinct combination is a separate Loki stream. Only
+	// entries encoded with JSONFormat are inspected.
+	LabelFields []string
+
+	// TimestampKey is the key of the entry timestamp in JSON entries.
+	// Defaults to TimestampKey. Entries without it use the time they are
+	// sent.
+	TimestampKey string
+
+	// Encoding selects the payload format. Defaults to LokiJSON.
+	Encoding LokiEncoding
+
+	// TenantID is sent as the X-Scope-OrgID header in multi-tenant setups.
+	TenantID string
+
+	// Headers are added to every request, e.g. for authentication.
+	Headers map[string]string
+
+	// Client sends the requests. Defaults to a client with a 10s timeout.
+	Client *http.Client
+
+	// Batch configures batching and retries. Batch.Send is ignored.
+	// Requests failing with 429 or a 5xx status are retried; other
+	// failures drop the batch.
+	Batch BatchSenderConfig
+}
+
+// LokiWriter pushes entries to Grafana Loki's push API. It is a
+// BatchSender whose batches are grouped into streams by their labels.
+//
+// Example:
+//
+//	w, err := logger.NewLokiWriter(logger.LokiConfig{
+//		URL:         "http://loki:3100",
+//		Labels:      map[string]string{"service": "billing"},
+//		LabelFields: []string{"level"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	log := logger.New(logger.Config{Format: logger.JSONFormat, Output: w})
+//	defer w.Close()
+//	defer log.Close()
+type LokiWriter struct {
+	*BatchSender
+
+	url         string
+	labels      map[string]string
+	labelFields []string
+	tsKey       string
+	encoding    LokiEncoding
+	tenantID    string
+	headers     map[string]string
+	client      *http.Client
+}
+
+// NewLokiWriter creates a writer pushing to the Loki instance at cfg.URL.
+func NewLokiWriter(cfg LokiConfig) (*LokiWriter, error) {
+	if cfg.URL == "" {
+		return nil, errLokiNoURL
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("logger: invalid Loki URL %q", cfg.URL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = lokiPushPath
+	}
+
+	w := &LokiWriter{
+		url:         u.String(),
+		labels:      make(map[string]string, len(cfg.Labels)),
+		labelFields: cfg.LabelFields,
+		tsKey:       cfg.TimestampKey,
+		encoding:    cfg.Encoding,
+		tenantID:    cfg.TenantID,
+		headers:     cfg.Headers,
+		client:      cfg.Client,
+	}
+	for name, value := range cfg.Labels {
+		w.labels[lokiLabelName(name)] = value
+	}
+	if w.tsKey == "" {
+		w.tsKey = TimestampKey
+	}
+	if w.client == nil {
+		w.client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	batch := cfg.Batch
+	batch.Send = w.send
+	w.BatchSender, err = NewBatchSender(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// lokiStream is a set of entries sharing the same labels.
+type lokiStream struct {
+	labels  map[string]string
+	entries []lokiEntry
+}
+
+type lokiEntry struct {
+	ts   time.Time
+	line []byte
+}
+
+// send groups the batch into streams and pushes it.
+func (w *LokiWriter) send(ctx context.Context, batch [][]byte) error {
+	streams := w.group(batch, time.Now())
+
+	var body []byte
+	contentType := "application/json"
+	if w.encoding == LokiProtobuf {
+		contentType = "application/x-protobuf"
+		body = appendSnappy(nil, appendLokiProtobuf(nil, streams))
+	} else {
+		body = appendLokiJSON(nil, streams)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if w.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", w.tenantID)
+	}
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("logger: loki push failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return Permanent(err)
+}
+
+// group sorts the entries of a batch into streams by label set, keeping
+// the order of entries within each stream.
+func (w *LokiWriter) group(batch [][]byte, now time.Time) []*lokiStream {
+	var streams []*lokiStream
+	index := make(map[string]*lokiStream)
+
+	for _, line := range batch {
+		labels, ts := w.inspect(line)
+		if ts.IsZero() {
+			ts = now
+		}
+
+		key := formatLokiLabels(labels)
+		st := index[key]
+		if st == nil {
+			st = &lokiStream{labels: labels}
+			index[key] = st
+			streams = append(streams, st)
+		}
+		st.entries = append(st.entries, lokiEntry{ts: ts, line: line})
+	}
+
+	return streams
+}
+
+// inspect returns the labels and timestamp of an entry. Entries that are
+// not JSON objects only get the static labels.
+func (w *LokiWriter) inspect(line []byte) (map[string]string, time.Time) {
+	labels := make(map[string]string, len(w.labels)+len(w.labelFields))
+	for name, value := range w.labels {
+		labels[name] = value
+	}
+
+	if len(line) == 0 || line[0] != '{' {
+		return labels, time.Time{}
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(line, &fields) != nil {
+		return labels, time.Time{}
+	}
+
+	for _, key := range w.labelFields {
+		if raw, ok := fields[key]; ok {
+			labels[lokiLabelName(key)] = lokiLabelValue(raw)
+		}
+	}
+
+	var ts time.Time
+	if raw, ok := fields[w.tsKey]; ok {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			ts, _ = time.Parse(time.RFC3339Nano, s)
+		}
+	}
+
+	return labels, ts
+}
+
+// lokiLabelValue converts a JSON value to a label value. Strings are
+// unquoted; other values keep their JSON form.
+func lokiLabelValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// lokiLabelName converts name to a valid Prometheus label name.
+func lokiLabelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		default:
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+	return string(b)
+}
+
+// formatLokiLabels renders labels in the selector form Loki expects, with
+// sorted names: {level="INFO", service="billing"}.
+func formatLokiLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(name)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Quote(labels[name]))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// appendLokiJSON renders a JSON push request.
+func appendLokiJSON(buf []byte, streams []*lokiStream) []byte {
+	buf = append(buf, `{"streams":[`...)
+	for i, st := range streams {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		labels, _ := json.Marshal(st.labels)
+		buf = append(buf, `{"stream":`...)
+		buf = append(buf, labels...)
+		buf = append(buf, `,"values":[`...)
+		for j, e := range st.entries {
+			if j > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, `["`...)
+			buf = strconv.AppendInt(buf, e.ts.UnixNano(), 10)
+			buf = append(buf, `","`...)
+			buf = appendJSONString(buf, string(e.line))
+			buf = append(buf, `"]`...)
+		}
+		buf = append(buf, "]}"...)
+	}
+	return append(buf, "]}"...)
+}
+
+// appendLokiProtobuf renders a logproto.PushRequest:
+//
+//	message PushRequest  { repeated StreamAdapter streams = 1; }
+//	message StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+//	message EntryAdapter { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+func appendLokiProtobuf(buf []byte, streams []*lokiStream) []byte {
+	var stream, entry, ts []byte
+	for _, st := range streams {
+		stream = appendProtoBytes(stream[:0], 1, []byte(formatLokiLabels(st.labels)))
+		for _, e := range st.entries {
+			ts = ts[:0]
+			if sec := e.ts.Unix(); sec != 0 {
+				ts = appendProtoVarint(ts, 1, uint64(sec))
+			}
+			if nsec := e.ts.Nanosecond(); nsec != 0 {
+				ts = appendProtoVarint(ts, 2, uint64(nsec))
+			}
+			entry = appendProtoBytes(entry[:0], 1, ts)
+			entry = appendProtoBytes(entry, 2, e.line)
+			stream = appendProtoBytes(stream, 2, entry)
+		}
+		buf = appendProtoBytes(buf, 1, stream)
+	}
+	return buf
+}
+
+// appendProtoVarint appends a varint field.
+func appendProtoVarint(buf []byte, field int, v uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3)
+	return binary.AppendUvarint(buf, v)
+}
+
+// appendProtoBytes appends a length-delimited field.
+func appendProtoBytes(buf []byte, field int, v []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lokiServer records push requests and answers with the given statuses in
+// turn, then 204.
+type lokiServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+func newLokiServer(t *testing.T, statuses ...int) *lokiServer {
+	ls := &lokiServer{statuses: statuses}
+	ls.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		ls.mu.Lock()
+		ls.requests = append(ls.requests, r)
+		ls.bodies = append(ls.bodies, body)
+		status := http.StatusNoContent
+		if len(ls.statuses) > 0 {
+			status, ls.statuses = ls.statuses[0], ls.statuses[1:]
+		}
+		ls.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(ls.Close)
+	return ls
+}
+
+func (ls *lokiServer) snapshot() ([]*http.Request, [][]byte) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return append([]*http.Request(nil), ls.requests...), append([][]byte(nil), ls.bodies...)
+}
+
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+func TestLokiWriter_JSON(t *testing.T) {
+	server := newLokiServer(t)
+
+	w, err := NewLokiWriter(LokiConfig{
+		URL:         server.URL,
+		Labels:      map[string]string{"service": "billing", "app-name": "api"},
+		LabelFields: []string{"level"},
+		TenantID:    "team-a",
+	})
+	require.NoError(t, err)
+
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: w})
+	logger.Info("first")
+	logger.Error("failed")
+	logger.Info("second")
+	require.NoError(t, logger.Close())
+	require.NoError(t, w.Close())
+
+	requests, bodies := server.snapshot()
+	require.Len(t, requests, 1)
+	assert.Equal(t, lokiPushPath, requests[0].URL.Path)
+	assert.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+	assert.Equal(t, "team-a", requests[0].Header.Get("X-Scope-OrgID"))
+
+	var push lokiPush
+	require.NoError(t, json.Unmarshal(bodies[0], &push))
+	require.Len(t, push.Streams, 2)
+
+	assert.Equal(t, map[string]string{"service": "billing", "app_name": "api", "level": "INFO"}, push.Streams[0].Stream)
+	require.Len(t, push.Streams[0].Values, 2)
+	assert.Contains(t, push.Streams[0].Values[0][1], `"message":"first"`)
+	assert.Contains(t, push.Streams[0].Values[1][1], `"message":"second"`)
+
+	assert.Equal(t, "ERROR", push.Streams[1].Stream["level"])
+	assert.Contains(t, push.Streams[1].Values[0][1], `"message":"failed"`)
+}
+
+func TestLokiWriter_TimestampFromEntry(t *testing.T) {
+	w := &LokiWriter{tsKey: TimestampKey}
+
+	_, ts := w.inspect([]byte(`{"timestamp":"2024-01-20T15:04:05.123456789Z","message":"x"}`))
+	assert.Equal(t, time.Date(2024, 1, 20, 15, 4, 5, 123456789, time.UTC), ts.UTC())
+
+	labels, ts := w.inspect([]byte("plain text entry"))
+	assert.True(t, ts.IsZero())
+	assert.Empty(t, labels)
+}
+
+func TestLokiWriter_Protobuf(t *testing.T) {
+	server := newLokiServer(t)
+
+	w, err := NewLokiWriter(LokiConfig{
+		URL:      server.URL + "/custom/push",
+		Labels:   map[string]string{"service": "billing"},
+		Encoding: LokiProtobuf,
+	})
+	require.NoError(t, err)
+
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: w})
+	logger.Info("proto entry")
+	require.NoError(t, logger.Close())
+	require.NoError(t, w.Close())
+
+	requests, bodies := server.snapshot()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/custom/push", requests[0].URL.Path)
+	assert.Equal(t, "application/x-protobuf", requests[0].Header.Get("Content-Type"))
+
+	payload, err := decodeSnappy(bodies[0])
+	require.NoError(t, err)
+
+	streams := protoFields(t, payload)
+	require.Len(t, streams[1], 1)
+	stream := protoFields(t, streams[1][0])
+	assert.Equal(t, `{service="billing"}`, string(stream[1][0]))
+	require.Len(t, stream[2], 1)
+
+	entry := protoFields(t, stream[2][0])
+	assert.Contains(t, string(entry[2][0]), `"message":"proto entry"`)
+	assert.NotEmpty(t, entry[1][0])
+}
+
+// protoFields splits a message into its length-delimited fields.
+func protoFields(t *testing.T, msg []byte) map[int][][]byte {
+	t.Helper()
+
+	fields := map[int][][]byte{}
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		require.Positive(t, n)
+		msg = msg[n:]
+		require.Equal(t, uint64(2), key&7, "only length-delimited fields expected")
+
+		size, n := binary.Uvarint(msg)
+		require.Positive(t, n)
+		msg = msg[n:]
+		fields[int(key>>3)] = append(fields[int(key>>3)], msg[:size])
+		msg = msg[size:]
+	}
+	return fields
+}
+
+func TestLokiWriter_RetriesOn429And5xx(t *testing.T) {
+	server := newLokiServer(t, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+
+	w, err := NewLokiWriter(LokiConfig{
+		URL:   server.URL,
+		Batch: BatchSenderConfig{MinBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	_, _ = w.Write([]byte(`{"message":"retried"}` + "\n"))
+	require.NoError(t, w.Close())
+
+	requests, _ := server.snapshot()
+	assert.Len(t, requests, 3)
+	assert.Equal(t, uint64(1), w.Sent())
+}
+
+func TestLokiWriter_DropsOnClientError(t *testing.T) {
+	server := newLokiServer(t, http.StatusBadRequest)
+
+	var reported error
+	w, err := NewLokiWriter(LokiConfig{
+		URL: server.URL,
+		Batch: BatchSenderConfig{
+			MinBackoff: time.Millisecond,
+			OnError:    func(err error, entries int) { reported = err },
+		},
+	})
+	require.NoError(t, err)
+
+	_, _ = w.Write([]byte(`{"message":"rejected"}` + "\n"))
+	require.NoError(t, w.Close())
+
+	requests, _ := server.snapshot()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, uint64(1), w.Failed())
+	assert.ErrorContains(t, reported, "400")
+}
+
+func TestNewLokiWriter_InvalidURL(t *testing.T) {
+	_, err := NewLokiWriter(LokiConfig{})
+	assert.Error(t, err)
+
+	_, err = NewLokiWriter(LokiConfig{URL: "loki:3100"})
+	assert.Error(t, err)
+}
+
+func TestFormatLokiLabels(t *testing.T) {
+	assert.Equal(t, `{a="1", b="x\"y"}`, formatLokiLabels(map[string]string{"b": `x"y`, "a": "1"}))
+	assert.Equal(t, "_9lives", lokiLabelName("9lives"))
+}
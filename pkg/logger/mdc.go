@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+// MappedContext is the type of MDC.
+type MappedContext struct{}
+
+// MDC is a mapped diagnostic context in the style of SLF4J and logback: a
+// per-request map of values that every ContextLogger adds to its entries.
+// Since a context.Context is immutable, MDC.Begin attaches an empty map to
+// the context of a request once, and Set and Remove change that map from
+// anywhere the context reaches. Starting a new map per request clears the
+// values of the previous one.
+//
+// Example:
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//		ctx := logger.MDC.Begin(r.Context())
+//		logger.MDC.Set(ctx, "userID", user.ID)
+//		process(ctx) // log.WithStaticContext(ctx).Info(...) includes userID
+//	}
+var MDC MappedContext
+
+// mdcContextKey is the context key of the map attached by MDC.Begin.
+type mdcContextKey struct{}
+
+// mdcMap holds the values of one MDC scope in insertion order.
+type mdcMap struct {
+	mu     sync.Mutex
+	fields []Field
+}
+
+// Begin returns a copy of ctx with an empty map, e.g. at the start of a
+// request. A map begun inside another one starts with a copy of its values,
+// so changes to the inner map do not leak to the outer one.
+func (MappedContext) Begin(ctx context.Context) context.Context {
+	m := &mdcMap{}
+	if outer := mdcFrom(ctx); outer != nil {
+		outer.mu.Lock()
+		m.fields = append([]Field(nil), outer.fields...)
+		outer.mu.Unlock()
+	}
+	return context.WithValue(ctx, mdcContextKey{}, m)
+}
+
+// Set stores value under key in the map of ctx, replacing the previous
+// value of key. It reports false and does nothing when ctx has no map,
+// i.e. MDC.Begin was not called for it.
+func (MappedContext) Set(ctx context.Context, key string, value any) bool {
+	m := mdcFrom(ctx)
+	if m == nil {
+		return false
+	}
+
+	field := Field{Key: key, Value: fieldValue(value)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.fields {
+		if m.fields[i].Key == key {
+			m.fields[i] = field
+			return true
+		}
+	}
+	m.fields = append(m.fields, field)
+	return true
+}
+
+// Get returns the value stored under key in the map of ctx.
+func (MappedContext) Get(ctx context.Context, key string) (any, bool) {
+	m := mdcFrom(ctx)
+	if m == nil {
+		return nil, false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if field, ok := findField(m.fields, key); ok {
+		return field.Value, true
+	}
+	return nil, false
+}
+
+// Remove deletes key from the map of ctx.
+func (MappedContext) Remove(ctx context.Context, key string) {
+	m := mdcFrom(ctx)
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.fields {
+		if m.fields[i].Key == key {
+			m.fields = append(m.fields[:i], m.fields[i+1:]...)
+			return
+		}
+	}
+}
+
+// Clear deletes all values from the map of ctx.
+func (MappedContext) Clear(ctx context.Context) {
+	m := mdcFrom(ctx)
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clear(m.fields)
+	m.fields = m.fields[:0]
+}
+
+// mdcFrom returns the map attached to ctx, or nil.
+func mdcFrom(ctx context.Context) *mdcMap {
+	m, _ := ctx.Value(mdcContextKey{}).(*mdcMap)
+	return m
+}
+
+// appendMDC appends the values of the map attached to ctx.
+func appendMDC(fields []Field, ctx context.Context) []Field {
+	m := mdcFrom(ctx)
+	if m == nil {
+		return fields
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append(fields, m.fields...)
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMDC(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	ctx := MDC.Begin(context.Background())
+	log := logger.WithStaticContext(ctx)
+
+	assert.True(t, MDC.Set(ctx, "userID", 42))
+	MDC.Set(ctx, "tenant", "acme")
+	MDC.Set(ctx, "userID", 43)
+	log.Info("first", F("n", 1))
+	assert.Contains(t, buf.String(), "INFO first userID=43 tenant=acme n=1")
+
+	value, ok := MDC.Get(ctx, "tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "acme", value)
+
+	buf.Reset()
+	MDC.Remove(ctx, "userID")
+	log.Info("second")
+	assert.Contains(t, buf.String(), "INFO second tenant=acme\n")
+
+	buf.Reset()
+	MDC.Clear(ctx)
+	log.Info("third")
+	assert.Contains(t, buf.String(), "INFO third\n")
+}
+
+func TestMDC_NestedScope(t *testing.T) {
+	outer := MDC.Begin(context.Background())
+	MDC.Set(outer, "requestID", "r-1")
+
+	inner := MDC.Begin(outer)
+	MDC.Set(inner, "step", "charge")
+
+	value, _ := MDC.Get(inner, "requestID")
+	assert.Equal(t, "r-1", value)
+	_, ok := MDC.Get(outer, "step")
+	assert.False(t, ok, "inner values do not leak")
+}
+
+func TestMDC_WithoutBegin(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, MDC.Set(ctx, "key", "value"))
+	_, ok := MDC.Get(ctx, "key")
+	assert.False(t, ok)
+	MDC.Remove(ctx, "key")
+	MDC.Clear(ctx)
+}
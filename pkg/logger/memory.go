@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+const (
+	// defaultInitialBufferSize is the capacity of a new encoding buffer
+	// unless Config.InitialBufferSize is set.
+	defaultInitialBufferSize = 256
+
+	// defaultMaxPooledBufferSize is the capacity above which an encoding
+	// buffer is not kept for reuse unless Config.MaxPooledBufferSize is set.
+	defaultMaxPooledBufferSize = 64 << 10
+
+	// recordOverhead and fieldOverhead approximate the fixed memory cost of
+	// a queued record and of each of its fields.
+	recordOverhead = 128
+	fieldOverhead  = 32
+
+	// bufferBudgetSlots is the number of free buffers a bufferBudget keeps
+	// for reuse.
+	bufferBudgetSlots = 32
+)
+
+// MemoryBudget puts hard caps on the memory the logger holds, for programs
+// running in constrained containers. Entries that would exceed a cap are
+// dropped and counted in Stats.MemoryDropped instead of growing memory.
+//
+// Example:
+//
+//	log := logger.New(logger.Config{
+//		Output:         os.Stdout,
+//		AsyncQueueSize: 10000,
+//		MemoryBudget: &logger.MemoryBudget{
+//			MaxBufferBytes: 1 << 20,
+//			MaxQueueBytes:  4 << 20,
+//		},
+//	})
+type MemoryBudget struct {
+	// MaxBufferBytes caps the bytes held by buffers: encoding buffers, both
+	// those in use and those kept for reuse, the batch buffers of the sinks
+	// and the batches queued by BatchSender outputs. Room for an entry is
+	// reserved before it is encoded; an entry that does not fit is dropped
+	// for the sink being written. A batch that cannot grow is written
+	// early instead. Zero leaves buffers unbounded.
+	MaxBufferBytes int
+
+	// MaxQueueBytes caps the estimated size of the records waiting in the
+	// async queue. When it is reached, entries are shed the same way as
+	// when the queue is full (see Config.AsyncQueueSize); an entry larger
+	// than the whole budget is dropped. Zero leaves the queue bounded by
+	// its length only. Ignored unless AsyncQueueSize is set.
+	MaxQueueBytes int
+}
+
+// bufferBudget hands out encoding buffers while keeping the bytes held by
+// the logger under a hard cap: encoding buffers in use and kept for reuse,
+// the batch buffers of the sinks and the batches queued by BatchSender
+// outputs. Bytes are reserved before a buffer grows, on an atomic counter,
+// and free buffers are kept in slots filled and emptied with atomic swaps,
+// so logging does not serialize on a lock. It replaces the logger's
+// sync.Pool, whose retained memory cannot be accounted for.
+type bufferBudget struct {
+	max       int64
+	initial   int
+	maxPooled int
+
+	held    atomic.Int64
+	free    [bufferBudgetSlots]atomic.Pointer[[]byte]
+	next    atomic.Uint32
+	dropped atomic.Uint64
+}
+
+func newBufferBudget(budget *MemoryBudget, initial, maxPooled int) *bufferBudget {
+	if budget == nil || budget.MaxBufferBytes <= 0 {
+		return nil
+	}
+	return &bufferBudget{max: int64(budget.MaxBufferBytes), initial: initial, maxPooled: maxPooled}
+}
+
+// get returns an empty buffer, reusing a free one when possible. A new
+// buffer has no capacity; grow reserves it.
+func (b *bufferBudget) get() *[]byte {
+	start := b.next.Add(1)
+	for i := range uint32(bufferBudgetSlots) {
+		if buf := b.free[(start+i)%bufferBudgetSlots].Swap(nil); buf != nil {
+			return buf
+		}
+	}
+	return new([]byte)
+}
+
+// reserve accounts for n more bytes, discarding free buffers to make room.
+// It reports false, accounting for nothing, when they do not fit.
+func (b *bufferBudget) reserve(n int64) bool {
+	for {
+		held := b.held.Load()
+		if held+n > b.max {
+			if b.evict() {
+				continue
+			}
+			return false
+		}
+		if b.held.CompareAndSwap(held, held+n) {
+			return true
+		}
+	}
+}
+
+// release accounts for n bytes no longer held.
+func (b *bufferBudget) release(n int64) {
+	b.held.Add(-n)
+}
+
+// evict discards a free buffer, reporting false when there is none.
+func (b *bufferBudget) evict() bool {
+	for i := range b.free {
+		if buf := b.free[i].Swap(nil); buf != nil {
+			b.release(int64(cap(*buf)))
+			return true
+		}
+	}
+	return false
+}
+
+// grow makes room for n more bytes in buf, reserving the new capacity
+// before allocating it. When it does not fit, grow reports false and
+// leaves buf as it is.
+func (b *bufferBudget) grow(buf *[]byte, n int) bool {
+	if cap(*buf)-len(*buf) >= n {
+		return true
+	}
+	size := max(len(*buf)+n, 2*cap(*buf), b.initial)
+	if !b.reserve(int64(size - cap(*buf))) {
+		size = len(*buf) + n
+		if !b.reserve(int64(size - cap(*buf))) {
+			return false
+		}
+	}
+	grown := make([]byte, len(*buf), size)
+	copy(grown, *buf)
+	*buf = grown
+	return true
+}
+
+// account reserves the growth of buf beyond the capacity before, for an
+// entry whose encoding outgrew the room made by grow. When the growth does
+// not fit, buf is discarded and account reports false; the caller must not
+// use or put buf then.
+func (b *bufferBudget) account(buf *[]byte, before int) bool {
+	grown := int64(cap(*buf) - before)
+	if grown <= 0 || b.reserve(grown) {
+		return true
+	}
+	b.release(int64(before))
+	return false
+}
+
+// put returns buf for reuse, or discards it when no slot is free or it
+// grew beyond Config.MaxPooledBufferSize.
+func (b *bufferBudget) put(buf *[]byte) {
+	size := cap(*buf)
+	if size == 0 {
+		return
+	}
+	if size <= b.maxPooled {
+		*buf = (*buf)[:0]
+		start := b.next.Add(1)
+		for i := range uint32(bufferBudgetSlots) {
+			if b.free[(start+i)%bufferBudgetSlots].CompareAndSwap(nil, buf) {
+				return
+			}
+		}
+	}
+	b.release(int64(size))
+}
+
+// bytes returns the bytes held by buffers in use and kept for reuse.
+func (b *bufferBudget) bytes() int64 {
+	return b.held.Load()
+}
+
+// budgetedOutput is implemented by outputs holding entries in memory,
+// which account for them in the MemoryBudget of the logger.
+type budgetedOutput interface {
+	setBufferBudget(b *bufferBudget)
+}
+
+// getBuffer returns an empty encoding buffer.
+func (l *Logger) getBuffer() *[]byte {
+	if l.buffers != nil {
+		return l.buffers.get()
+	}
+	return l.pool.Get().(*[]byte)
+}
+
+// putBuffer returns an encoding buffer for reuse. Buffers grown beyond
+// Config.MaxPooledBufferSize by a large entry are left to the garbage
+// collector, so a single huge entry does not pin its memory in the pool.
+func (l *Logger) putBuffer(buf *[]byte) {
+	if l.buffers != nil {
+		l.buffers.put(buf)
+		return
+	}
+	if cap(*buf) > l.maxPooledBuffer {
+		return
+	}
+	l.pool.Put(buf)
+}
+
+// dropMemory counts an entry dropped for a sink by the buffer budget.
+func (l *Logger) dropMemory() {
+	l.buffers.dropped.Add(1)
+	l.countDropped(logmetrics.DropMemory)
+}
+
+// recordSize estimates the memory held by a queued record, which is also
+// the room reserved for its encoding.
+func recordSize(r *Record) int64 {
+	size := recordOverhead + len(r.Message) + len(r.raw)
+	for _, field := range r.Fields {
+		size += fieldOverhead + len(field.Key)
+		if s, ok := field.Value.(string); ok {
+			size += len(s)
+		}
+	}
+	return int64(size)
+}
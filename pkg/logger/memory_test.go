@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBudget_DropsEntriesOverBufferBudget(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{
+		Level:        InfoLevel,
+		Format:       TextFormat,
+		Output:       buf,
+		MemoryBudget: &MemoryBudget{MaxBufferBytes: 1024},
+	})
+
+	logger.Info("small")
+	logger.Info(strings.Repeat("x", 4096))
+	logger.Info("small again")
+
+	output := buf.String()
+	assert.Contains(t, output, "INFO small\n")
+	assert.Contains(t, output, "INFO small again\n")
+	assert.NotContains(t, output, "xxxx")
+
+	stats := logger.Stats()
+	assert.Equal(t, uint64(1), stats.MemoryDropped)
+	assert.LessOrEqual(t, stats.BufferBytes, int64(1024))
+	assert.Positive(t, stats.BufferBytes, "buffers are kept for reuse")
+}
+
+func TestBufferBudget_Accounting(t *testing.T) {
+	b := newBufferBudget(&MemoryBudget{MaxBufferBytes: 1000}, defaultInitialBufferSize, defaultMaxPooledBufferSize)
+
+	// Room is reserved before an entry is encoded.
+	first := b.get()
+	second := b.get()
+	require.True(t, b.grow(first, 100))
+	require.True(t, b.grow(second, 100))
+	assert.Equal(t, int64(2*defaultInitialBufferSize), b.bytes())
+
+	// Growing the first buffer beyond the budget fails before allocating.
+	assert.False(t, b.grow(first, 900))
+	assert.Equal(t, defaultInitialBufferSize, cap(*first))
+
+	// So does an encoding that outgrew the reserved room.
+	before := cap(*first)
+	*first = append(*first, make([]byte, 900)...)
+	assert.False(t, b.account(first, before))
+	assert.Equal(t, int64(defaultInitialBufferSize), b.bytes())
+
+	// A buffer that fits is kept and reused.
+	require.True(t, b.account(second, cap(*second)))
+	b.put(second)
+	assert.Equal(t, int64(defaultInitialBufferSize), b.bytes())
+	assert.Same(t, second, b.get())
+
+	// Free buffers are discarded to make room for a growing one.
+	third := b.get()
+	require.True(t, b.grow(third, 100))
+	b.put(third)
+	fourth := b.get()
+	assert.True(t, b.grow(fourth, 600))
+	assert.LessOrEqual(t, b.bytes(), int64(1000))
+}
+
+func TestMemoryBudget_CountsBatchBuffers(t *testing.T) {
+	w := &limitedWriter{}
+	logger := New(Config{
+		Level:           InfoLevel,
+		Output:          w,
+		TimestampFormat: TimestampDisabled,
+		BufferSize:      64 << 10,
+		MemoryBudget:    &MemoryBudget{MaxBufferBytes: 4096},
+	})
+
+	// The batch buffer cannot take BufferSize bytes, so batches are
+	// written early instead of growing past the budget.
+	for range 100 {
+		logger.Info(strings.Repeat("x", 100))
+	}
+	assert.LessOrEqual(t, logger.Stats().BufferBytes, int64(4096))
+	assert.NotEmpty(t, w.Writes())
+	logger.Flush()
+
+	lines := 0
+	for _, batch := range w.Writes() {
+		lines += strings.Count(batch, "\n")
+	}
+	assert.Equal(t, 100, lines)
+	assert.Zero(t, logger.Stats().MemoryDropped)
+}
+
+func TestMemoryBudget_CountsBatchSender(t *testing.T) {
+	release := make(chan struct{})
+	sender, err := NewBatchSender(BatchSenderConfig{
+		Send: func(ctx context.Context, batch [][]byte) error {
+			<-release
+			return nil
+		},
+		MaxEvents: 1,
+	})
+	require.NoError(t, err)
+	logger := New(Config{
+		Level:           InfoLevel,
+		Output:          sender,
+		TimestampFormat: TimestampDisabled,
+		MemoryBudget:    &MemoryBudget{MaxBufferBytes: 2048},
+	})
+
+	for range 10 {
+		logger.Info(strings.Repeat("x", 400))
+	}
+	assert.LessOrEqual(t, logger.Stats().BufferBytes, int64(2048))
+	assert.Positive(t, sender.Dropped(), "batches beyond the budget are dropped")
+
+	close(release)
+	logger.Flush()
+	require.NoError(t, sender.Close())
+	assert.Equal(t, uint64(10), sender.Sent()+sender.Dropped())
+}
+
+func TestMemoryBudget_QueueBytes(t *testing.T) {
+	w := newGatedWriter()
+	small := &Record{Message: "queued"}
+	budget := 3 * recordSize(small)
+
+	logger := New(Config{
+		Level:          DebugLevel,
+		Format:         TextFormat,
+		Output:         w,
+		AsyncQueueSize: 100,
+		MemoryBudget:   &MemoryBudget{MaxQueueBytes: int(budget)},
+	})
+
+	saturate(t, logger, w)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("queued")
+	}
+	stats := logger.Stats()
+	assert.Equal(t, 3, stats.AsyncQueued, "the queue is bounded by bytes, not by length")
+	assert.Equal(t, uint64(2), stats.AsyncDropped)
+	assert.LessOrEqual(t, stats.AsyncQueuedBytes, budget)
+
+	logger.Error(strings.Repeat("x", int(budget)))
+	assert.Equal(t, uint64(1), logger.Stats().MemoryDropped)
+
+	close(w.release)
+	require.NoError(t, logger.Close())
+	assert.Equal(t, 4, strings.Count(w.String(), "\n"))
+	assert.Zero(t, logger.Stats().AsyncQueuedBytes)
+}
+
+func TestConfig_BufferPoolSizes(t *testing.T) {
+	log := New(Config{Output: &bytes.Buffer{}, InitialBufferSize: 4096, MaxPooledBufferSize: 8192})
+	buf := log.getBuffer()
+	assert.Equal(t, 4096, cap(*buf))
+	log.putBuffer(buf)
+
+	// A buffer grown beyond MaxPooledBufferSize is not kept for reuse.
+	grown := make([]byte, 0, 16384)
+	log.putBuffer(&grown)
+	for range 10 {
+		assert.NotEqual(t, 16384, cap(*log.getBuffer()))
+	}
+
+	log = New(Config{Output: &bytes.Buffer{}})
+	assert.Equal(t, defaultInitialBufferSize, cap(*log.getBuffer()))
+	assert.Equal(t, defaultMaxPooledBufferSize, log.maxPooledBuffer)
+}
+
+func TestBufferBudget_DiscardsOversizedBuffers(t *testing.T) {
+	b := newBufferBudget(&MemoryBudget{MaxBufferBytes: 1 << 20}, 64, 1024)
+
+	buf := b.get()
+	require.True(t, b.grow(buf, 10))
+	assert.Equal(t, 64, cap(*buf))
+	before := cap(*buf)
+	*buf = append(*buf, make([]byte, 2048)...)
+	require.True(t, b.account(buf, before))
+	b.put(buf)
+
+	assert.Zero(t, b.bytes(), "the grown buffer is released")
+	assert.NotSame(t, buf, b.get())
+}
+
+func TestConfig_BufferPoolValidation(t *testing.T) {
+	_, err := NewE(Config{InitialBufferSize: -1, MaxPooledBufferSize: -1})
+	assert.ErrorContains(t, err, "negative InitialBufferSize -1")
+	assert.ErrorContains(t, err, "negative MaxPooledBufferSize -1")
+
+	_, err = NewE(Config{InitialBufferSize: 4096, MaxPooledBufferSize: 1024})
+	assert.ErrorContains(t, err, "MaxPooledBufferSize 1024 is smaller than InitialBufferSize 4096")
+}
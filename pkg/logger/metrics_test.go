@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+func TestConfig_Metrics(t *testing.T) {
+	var counters logmetrics.Counters
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		BufferSize:      1 << 10,
+		TimestampFormat: TimestampDisabled,
+		Sampling:        &SamplingConfig{First: 1, Window: time.Hour},
+		RateLimit:       map[Level]RateLimit{ErrorLevel: {EventsPerSecond: 0.001, Burst: 1}},
+		Metrics:         &counters,
+	})
+	log.AddSink(SinkConfig{Output: failingWriter{}, Level: ErrorLevel})
+
+	log.Debug("disabled")
+	log.Info("first")
+	log.Info("first")
+	log.Error("boom")
+	log.Error("boom again")
+	log.Flush()
+
+	// Flush reports the rate-limited entry in a second ERROR entry.
+	s := counters.Snapshot()
+	assert.Equal(t, map[string]uint64{"INFO": 1, "ERROR": 2}, s.Emitted)
+	assert.Equal(t, map[string]uint64{logmetrics.DropSampled: 1, logmetrics.DropRateLimited: 1}, s.Dropped)
+	assert.Equal(t, uint64(buf.Len()), s.BytesWritten)
+	assert.Equal(t, uint64(1), s.Flushes)
+	assert.Equal(t, uint64(2), s.WriteErrors)
+}
+
+func TestConfig_MetricsAsyncShed(t *testing.T) {
+	var counters logmetrics.Counters
+	block := make(chan struct{})
+	log := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}, AsyncQueueSize: 1, Metrics: &counters})
+	log.AddHook(HookFunc(func(*Record) error { <-block; return nil }))
+
+	for range 5 {
+		log.Info("shed")
+	}
+	close(block)
+	assert.NoError(t, log.Close())
+	assert.Positive(t, counters.Snapshot().Dropped[logmetrics.DropAsyncQueue])
+}
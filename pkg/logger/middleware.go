@@ -0,0 +1,265 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// maxRequestIDLength is the longest request ID accepted from a request
+// header.
+const maxRequestIDLength = 128
+
+// httpOptions holds the settings of HTTPMiddleware.
+type httpOptions struct {
+	requestIDHeader string
+	exclude         map[string]bool
+	level           func(status int) Level
+	message         string
+}
+
+// HTTPOption configures HTTPMiddleware.
+type HTTPOption func(*httpOptions)
+
+// WithHTTPRequestIDHeader sets the header carrying the request ID.
+// Defaults to "X-Request-ID". An empty name disables request IDs.
+func WithHTTPRequestIDHeader(name string) HTTPOption {
+	return func(o *httpOptions) {
+		o.requestIDHeader = name
+	}
+}
+
+// WithHTTPExcludePaths disables the request entry for requests whose URL
+// path equals one of paths, such as health checks. The request-scoped
+// logger is still available to the handler.
+func WithHTTPExcludePaths(paths ...string) HTTPOption {
+	return func(o *httpOptions) {
+		for _, path := range paths {
+			o.exclude[path] = true
+		}
+	}
+}
+
+// WithHTTPLevel sets the level of the request entry by response status.
+// Defaults to ErrorLevel for 5xx, WarnLevel for 4xx and InfoLevel
+// otherwise.
+func WithHTTPLevel(level func(status int) Level) HTTPOption {
+	return func(o *httpOptions) {
+		o.level = level
+	}
+}
+
+// WithHTTPMessage sets the message of the request entry. Defaults to
+// "http request".
+func WithHTTPMessage(msg string) HTTPOption {
+	return func(o *httpOptions) {
+		o.message = msg
+	}
+}
+
+// httpStatusLevel is the default level of request entries.
+func httpStatusLevel(status int) Level {
+	switch {
+	case status >= 500:
+		return ErrorLevel
+	case status >= 400:
+		return WarnLevel
+	default:
+		return InfoLevel
+	}
+}
+
+// HTTPMiddleware returns middleware that logs one entry per request with
+// its method, path, status, bytes written, latency (e.g. "12.5ms") and
+// remote IP.
+//
+// Every request gets a request-scoped logger derived from l, available to
+// handlers through FromContext, and a fresh MDC map. The logger and the
+// request entry include a requestID, taken from the X-Request-ID header or
+// generated by Config.IDGenerator and echoed in the response. IDs longer
+// than 128 bytes or with characters other than ASCII letters, digits and
+// "-_.:+/=" are replaced by a generated one. When the request carries a
+// W3C traceparent header, they also include its traceID and spanID, the
+// keys used by the context extraction of WithContext.
+//
+// A handler panic is logged at ErrorLevel or above with a "panic" field
+// and the stack, even for excluded paths, and then re-raised so net/http
+// handles it as usual.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	handler := logger.HTTPMiddleware(log, logger.WithHTTPExcludePaths("/healthz"))(mux)
+//	http.ListenAndServe(":8080", handler)
+func HTTPMiddleware(l *Logger, opts ...HTTPOption) func(http.Handler) http.Handler {
+	o := httpOptions{
+		requestIDHeader: "X-Request-ID",
+		exclude:         make(map[string]bool),
+		level:           httpStatusLevel,
+		message:         "http request",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			var scope [3]Field
+			fields := scope[:0]
+			if o.requestIDHeader != "" {
+				id := r.Header.Get(o.requestIDHeader)
+				if !validRequestID(id) {
+					id = l.NewID()
+				}
+				w.Header().Set(o.requestIDHeader, id)
+				fields = append(fields, Field{Key: "requestID", Value: id})
+			}
+			if traceID, spanID, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+				fields = append(fields, Field{Key: "traceID", Value: traceID}, Field{Key: "spanID", Value: spanID})
+			}
+			reqLog := l.With(fields...)
+			ctx := NewContext(MDC.Begin(r.Context()), reqLog)
+
+			rw := &responseRecorder{ResponseWriter: w}
+			defer func() {
+				// A panicking handler still gets its request entry, with
+				// the panic and its stack, before the panic continues to
+				// net/http. ErrAbortHandler is a deliberate abort.
+				p := recover()
+				switch {
+				case p != nil && p != http.ErrAbortHandler:
+					o.logRequest(reqLog, ctx, r, rw, start, []Field{
+						String("panic", fmt.Sprint(p)),
+						Block(StacktraceKey, string(debug.Stack())),
+					})
+				case !o.exclude[r.URL.Path]:
+					o.logRequest(reqLog, ctx, r, rw, start, nil)
+				}
+				if p != nil {
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
+
+// logRequest writes the request entry. Entries with panic fields are
+// written at ErrorLevel with status 500 unless the handler had already
+// sent a status.
+func (o *httpOptions) logRequest(reqLog *Logger, ctx context.Context, r *http.Request, rw *responseRecorder, start time.Time, panicFields []Field) {
+	status := rw.status
+	if status == 0 {
+		status = http.StatusOK
+		if panicFields != nil {
+			status = http.StatusInternalServerError
+		}
+	}
+	level := o.level(status)
+	if panicFields != nil {
+		level = max(level, ErrorLevel)
+	}
+	if !reqLog.enabled(level) {
+		return
+	}
+
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+	reqLog.WithStaticContext(ctx).log(level, o.message, append([]Field{
+		{Key: "method", Value: r.Method},
+		{Key: "path", Value: r.URL.Path},
+		{Key: "status", Value: status},
+		{Key: "bytes", Value: rw.bytes},
+		{Key: "latency", Value: fieldValue(time.Since(start))},
+		{Key: "remoteIP", Value: remoteIP},
+	}, panicFields...))
+}
+
+// validRequestID reports whether id is a non-empty request ID of at most
+// maxRequestIDLength bytes made of safe characters.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("-_.:+/=", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseTraceparent returns the trace and span ID of a W3C traceparent
+// header, e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func parseTraceparent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false
+	}
+	traceID, spanID = parts[1], parts[2]
+	if len(traceID) != 32 || len(spanID) != 16 || !isLowerHex(traceID) || !isLowerHex(spanID) {
+		return "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// isLowerHex reports whether s consists of lowercase hex digits only.
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// responseRecorder captures the status and size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	// Informational 1xx responses precede the final status.
+	if w.status == 0 && status >= 200 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher for handlers that stream responses.
+func (w *responseRecorder) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController reaches
+// its optional methods such as Hijack.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	handler := HTTPMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?id=1", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "req-42")
+	w := serve(t, handler, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "req-42", w.Header().Get("X-Request-ID"))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), `"message":"handling","requestID":"req-42","traceID":"4bf92f3577b34da6a3ce929d0e0e4736","spanID":"00f067aa0ba902b7"`)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(lines[1], &entry))
+	assert.Equal(t, "http request", entry["message"])
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, "/orders", entry["path"])
+	assert.Equal(t, float64(201), entry["status"])
+	assert.Equal(t, float64(5), entry["bytes"])
+	assert.Equal(t, "203.0.113.7", entry["remoteIP"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["traceID"])
+	assert.Equal(t, "req-42", entry["requestID"])
+	assert.IsType(t, "", entry["latency"])
+}
+
+func TestHTTPMiddleware_Options(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	handler := HTTPMiddleware(log,
+		WithHTTPRequestIDHeader(""),
+		WithHTTPExcludePaths("/healthz"),
+		WithHTTPMessage("served"),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	serve(t, handler, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Empty(t, buf.String())
+
+	serve(t, handler, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, buf.String(), "INFO served method=GET path=/ status=200 bytes=0")
+
+	serve(t, handler, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Contains(t, buf.String(), "WARN served method=GET path=/missing status=404")
+
+	serve(t, handler, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	assert.Contains(t, buf.String(), "ERROR served method=GET path=/broken status=500")
+
+	buf.Reset()
+	quiet := HTTPMiddleware(log, WithHTTPLevel(func(int) Level { return DebugLevel }))(http.NotFoundHandler())
+	serve(t, quiet, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, buf.String())
+}
+
+func TestHTTPMiddleware_GeneratedRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	next := 0
+	log := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: buf,
+		IDGenerator: IDGeneratorFunc(func() string {
+			next++
+			return "id-" + strconv.Itoa(next)
+		}),
+	})
+
+	handler := HTTPMiddleware(log, WithHTTPRequestIDHeader("X-Correlation-ID"))(http.NotFoundHandler())
+	w := serve(t, handler, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "id-1", w.Header().Get("X-Correlation-ID"))
+	assert.Contains(t, buf.String(), "requestID=id-1 method=GET")
+
+	for _, id := range []string{"bad id\nERROR forged", strings.Repeat("a", 129), "<script>"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Correlation-ID", id)
+		w = serve(t, handler, req)
+		assert.Equal(t, "id-"+strconv.Itoa(next), w.Header().Get("X-Correlation-ID"), "invalid IDs are replaced")
+	}
+	assert.NotContains(t, buf.String(), "forged")
+}
+
+func TestHTTPMiddleware_MDCPerRequest(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	handler := HTTPMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, seen := MDC.Get(r.Context(), "user")
+		assert.False(t, seen, "values of earlier requests are cleared")
+		MDC.Set(r.Context(), "user", r.URL.Query().Get("user"))
+		w.(http.Flusher).Flush()
+	}))
+
+	w := serve(t, handler, httptest.NewRequest(http.MethodGet, "/?user=ann", nil))
+	assert.True(t, w.Flushed)
+	serve(t, handler, httptest.NewRequest(http.MethodGet, "/?user=bob", nil))
+
+	assert.Contains(t, buf.String(), "user=ann")
+	assert.Contains(t, buf.String(), "user=bob")
+}
+
+func TestHTTPMiddleware_Panic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	handler := HTTPMiddleware(log, WithHTTPExcludePaths("/healthz"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/abort" {
+			panic(http.ErrAbortHandler)
+		}
+		panic("nil map")
+	}))
+
+	assert.PanicsWithValue(t, "nil map", func() {
+		serve(t, handler, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	})
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, float64(500), entry["status"])
+	assert.Equal(t, "nil map", entry["panic"])
+	assert.Contains(t, entry[StacktraceKey], "middleware_test.go")
+
+	buf.Reset()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(t, handler, httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+	assert.NotContains(t, buf.String(), `"panic"`)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	traceID, spanID, ok := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.Equal(t, "00f067aa0ba902b7", spanID)
+
+	for _, header := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6-00f067aa0ba902b7-01",
+	} {
+		_, _, ok := parseTraceparent(header)
+		assert.False(t, ok, header)
+	}
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// noNamedLevel is the cached level of loggers no named level applies to.
+const noNamedLevel = Level(math.MinInt8)
+
+// namedLevels is the registry of levels set with SetNamedLevel. Every
+// change bumps gen, which invalidates the levels cached by the loggers.
+type namedLevels struct {
+	mu     sync.Mutex
+	levels map[string]Level
+
+	// caches maps logger names to their shared *levelCache.
+	caches sync.Map
+
+	// gen is zero while no level was ever set, so loggers skip the
+	// registry entirely.
+	gen atomic.Uint64
+}
+
+// levelCache holds the effective named level of a logger together with the
+// registry generation it was resolved in, packed by packNamedLevel so a
+// single atomic load tells whether it is current. The zero value is
+// noNamedLevel in generation zero.
+type levelCache struct {
+	atomic.Uint64
+}
+
+// cache returns the levelCache shared by the loggers named name.
+func (n *namedLevels) cache(name string) *levelCache {
+	if c, ok := n.caches.Load(name); ok {
+		return c.(*levelCache)
+	}
+	c, _ := n.caches.LoadOrStore(name, &levelCache{})
+	return c.(*levelCache)
+}
+
+// packNamedLevel packs level and gen for a levelCache.
+func packNamedLevel(gen uint64, level Level) uint64 {
+	return gen<<8 | uint64(uint8(level)^0x80)
+}
+
+// SetNamedLevel sets the minimum level of the entries of loggers named
+// name and of their descendants, e.g. "http" applies to "http.client"
+// unless "http.client" has a level of its own. The empty name applies to
+// every logger, including unnamed ones. Levels are shared by all loggers
+// derived from the same New.
+//
+// A named level filters entries in addition to the sink levels, so it can
+// only make a logger quieter than its sinks. To make one component more
+// verbose, lower the sink levels and set the default with the empty name:
+//
+//	log := logger.New(logger.Config{Level: logger.DebugLevel})
+//	log.SetNamedLevel("", logger.InfoLevel)
+//	log.SetNamedLevel("db", logger.DebugLevel)
+//
+// Named loggers cache their effective level, so checking a level costs two
+// atomic loads however many names are registered.
+func (l *Logger) SetNamedLevel(name string, level Level) {
+	l.namedLevels.mu.Lock()
+	defer l.namedLevels.mu.Unlock()
+
+	if l.namedLevels.levels == nil {
+		l.namedLevels.levels = make(map[string]Level)
+	}
+	l.namedLevels.levels[name] = level
+	l.namedLevels.gen.Add(1)
+}
+
+// ResetNamedLevel removes the level set for name, so the level of its
+// closest ancestor applies again.
+func (l *Logger) ResetNamedLevel(name string) {
+	l.namedLevels.mu.Lock()
+	defer l.namedLevels.mu.Unlock()
+
+	if _, ok := l.namedLevels.levels[name]; !ok {
+		return
+	}
+	delete(l.namedLevels.levels, name)
+	l.namedLevels.gen.Add(1)
+}
+
+// namedEnabled reports whether the named level of l lets entries at level
+// through.
+func (l *Logger) namedEnabled(level Level) bool {
+	cached := l.levelCache.Load()
+	if cached>>8 != l.namedLevels.gen.Load() {
+		cached = l.resolveNamedLevel()
+	}
+	return level >= Level(int8(uint8(cached)^0x80))
+}
+
+// resolveNamedLevel looks up the level of the closest registered ancestor
+// of l's name, caches it and returns the packed cache value.
+func (l *Logger) resolveNamedLevel() uint64 {
+	l.namedLevels.mu.Lock()
+	defer l.namedLevels.mu.Unlock()
+
+	gen := l.namedLevels.gen.Load()
+	level := noNamedLevel
+	for name := l.name; ; {
+		if v, ok := l.namedLevels.levels[name]; ok {
+			level = v
+			break
+		}
+		if name == "" {
+			break
+		}
+		i := strings.LastIndexByte(name, '.')
+		name = name[:max(i, 0)]
+	}
+
+	cached := packNamedLevel(gen, level)
+	l.levelCache.Store(cached)
+	return cached
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_SetNamedLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: DebugLevel, Output: buf, TimestampFormat: TimestampDisabled})
+	db := log.Named("db")
+	query := db.Named("query").With(String("table", "orders"))
+	http := log.Named("http")
+
+	assert.True(t, http.Enabled(DebugLevel))
+
+	log.SetNamedLevel("", InfoLevel)
+	log.SetNamedLevel("db", DebugLevel)
+	log.SetNamedLevel("db.query", WarnLevel)
+
+	assert.False(t, log.Enabled(DebugLevel), "the empty name applies to unnamed loggers")
+	assert.False(t, http.Enabled(DebugLevel))
+	assert.True(t, http.Enabled(InfoLevel))
+	assert.True(t, db.Enabled(DebugLevel))
+	assert.False(t, query.Enabled(InfoLevel))
+	assert.True(t, log.Named("db").Named("pool").Enabled(DebugLevel), "the closest ancestor applies")
+
+	query.Info("dropped")
+	db.Debug("kept")
+	assert.Equal(t, "DEBUG kept logger=db\n", buf.String())
+
+	log.ResetNamedLevel("db.query")
+	log.ResetNamedLevel("unknown")
+	assert.True(t, query.Enabled(DebugLevel))
+
+	log.SetNamedLevel("", ErrorLevel)
+	assert.False(t, http.Enabled(WarnLevel), "cached levels are refreshed after a change")
+}
+
+func TestLogger_NamedLevelCacheShared(t *testing.T) {
+	log := New(Config{Level: DebugLevel, Output: &bytes.Buffer{}})
+	db := log.Named("db")
+
+	assert.Same(t, db.levelCache, log.Named("db").levelCache, "loggers with the same name share the cache")
+	assert.Same(t, db.Named("query").levelCache, log.With(String("k", "v")).Named("db").Named("query").levelCache)
+	assert.NotSame(t, db.levelCache, log.Named("http").levelCache)
+}
+
+func TestLogger_NamedLevelCannotLowerSinkLevel(t *testing.T) {
+	log := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}, NamedLevels: map[string]Level{"db": DebugLevel}})
+	assert.False(t, log.Named("db").Enabled(DebugLevel))
+}
+
+func TestConfig_NamedLevels(t *testing.T) {
+	log := New(Config{Level: DebugLevel, Output: &bytes.Buffer{}, NamedLevels: map[string]Level{"noisy": ErrorLevel}})
+	assert.False(t, log.Named("noisy").Named("child").Enabled(WarnLevel))
+	assert.True(t, log.Named("quiet").Enabled(DebugLevel))
+
+	assert.ErrorContains(t, Config{NamedLevels: map[string]Level{"x": Level(99)}}.Validate(), `unknown NamedLevels["x"]`)
+}
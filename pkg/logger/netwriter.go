@@ -0,0 +1,366 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of a NetWriter.
+const (
+	defaultNetBufferSize   = 1 << 20
+	defaultNetMinBackoff   = 100 * time.Millisecond
+	defaultNetMaxBackoff   = 30 * time.Second
+	defaultNetDialTimeout  = 5 * time.Second
+	defaultNetWriteTimeout = 5 * time.Second
+)
+
+// netOptions holds the settings of a NetWriter.
+type netOptions struct {
+	bufferSize   int
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+// NetOption configures a NetWriter.
+type NetOption func(*netOptions)
+
+// WithNetBufferSize caps the number of bytes buffered while the collector
+// is unreachable. When the cap is reached the oldest entries are dropped.
+// Defaults to 1 MiB.
+func WithNetBufferSize(bytes int) NetOption {
+	return func(o *netOptions) {
+		o.bufferSize = bytes
+	}
+}
+
+// WithNetBackoff sets the delay before the first reconnect attempt and the
+// cap of the exponentially growing delay. Defaults to 100ms and 30s.
+func WithNetBackoff(minDelay, maxDelay time.Duration) NetOption {
+	return func(o *netOptions) {
+		o.minBackoff = minDelay
+		o.maxBackoff = maxDelay
+	}
+}
+
+// WithNetDialTimeout bounds every connection attempt. Defaults to 5s.
+func WithNetDialTimeout(d time.Duration) NetOption {
+	return func(o *netOptions) {
+		o.dialTimeout = d
+	}
+}
+
+// WithNetWriteTimeout bounds every write to the connection. A write that
+// times out is treated as a disconnect. Defaults to 5s.
+func WithNetWriteTimeout(d time.Duration) NetOption {
+	return func(o *netOptions) {
+		o.writeTimeout = d
+	}
+}
+
+// NetWriter ships newline-delimited entries to a remote collector such as
+// Logstash, Vector or Fluent Bit over TCP, UDP or a unix socket.
+//
+// Write never blocks on the network for longer than the write timeout and
+// never reports connection errors. While the collector is unreachable,
+// entries are buffered up to a cap and a background goroutine reconnects
+// with exponential backoff; the buffer is sent first once the connection is
+// back. After a failed write only the entries that were not completely
+// sent are buffered, and on UDP every buffered entry is sent as its own
+// datagram. Entries that do not fit into the buffer are dropped and
+// counted.
+// Disconnects, reconnects and dropped entries are reported to the
+// Config.InternalOutput of the logger the writer is an output of.
+type NetWriter struct {
+	network  string
+	addr     string
+	opts     netOptions
+	datagram bool
+
+	mu           sync.Mutex
+	conn         net.Conn
+	pending      []byte
+	reconnecting bool
+	closed       bool
+	done         chan struct{}
+
+	dropped  atomic.Uint64
+	internal atomic.Pointer[Logger]
+}
+
+// NewNetWriter creates a writer for the collector at addr. network is one
+// of "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6" or "unix". The first
+// connection attempt is made right away; if it fails, entries are buffered
+// until a reconnect succeeds.
+//
+// Example:
+//
+//	w, err := logger.NewNetWriter("tcp", "vector.internal:9000",
+//		logger.WithNetBufferSize(4<<20),
+//		logger.WithNetBackoff(time.Second, time.Minute),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Close()
+//
+//	log := logger.New(logger.Config{Format: logger.JSONFormat, Output: w})
+func NewNetWriter(network, addr string, opts ...NetOption) (*NetWriter, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix":
+	default:
+		return nil, fmt.Errorf("logger: unsupported network %q", network)
+	}
+
+	o := netOptions{
+		bufferSize:   defaultNetBufferSize,
+		minBackoff:   defaultNetMinBackoff,
+		maxBackoff:   defaultNetMaxBackoff,
+		dialTimeout:  defaultNetDialTimeout,
+		writeTimeout: defaultNetWriteTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.minBackoff <= 0 {
+		o.minBackoff = defaultNetMinBackoff
+	}
+	o.maxBackoff = max(o.maxBackoff, o.minBackoff)
+
+	w := &NetWriter{
+		network:  network,
+		addr:     addr,
+		opts:     o,
+		datagram: strings.HasPrefix(network, "udp"),
+		done:     make(chan struct{}),
+	}
+
+	conn, err := net.DialTimeout(network, addr, o.dialTimeout)
+	if err != nil {
+		w.mu.Lock()
+		w.startReconnect()
+		w.mu.Unlock()
+	} else {
+		w.conn = conn
+	}
+
+	return w, nil
+}
+
+// Write sends p to the collector, or buffers it while disconnected. It
+// always reports len(p) bytes written.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		w.dropped.Add(uint64(countEntries(p)))
+		return len(p), nil
+	}
+
+	if w.conn != nil {
+		sent, err := w.send(p)
+		if err == nil {
+			return len(p), nil
+		}
+		w.internal.Load().report(WarnLevel, "collector disconnected",
+			String("network", w.network), String("addr", w.addr), Err(err))
+		w.disconnect()
+		p = p[sent:]
+	}
+
+	w.buffer(p)
+	return len(p), nil
+}
+
+// lineOutput implements lineOutput.
+func (w *NetWriter) lineOutput() {}
+
+// Connected reports whether the writer currently holds a connection.
+func (w *NetWriter) Connected() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.conn != nil
+}
+
+// Buffered returns the number of bytes waiting for a connection.
+func (w *NetWriter) Buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.pending)
+}
+
+// Dropped returns the number of entries discarded because the buffer was
+// full or the writer was closed.
+func (w *NetWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close stops reconnecting and closes the connection. Entries still
+// buffered are dropped.
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+
+	w.dropped.Add(uint64(countEntries(w.pending)))
+	w.pending = nil
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// setInternal implements internalReporter.
+func (w *NetWriter) setInternal(internal *Logger) {
+	w.internal.Store(internal)
+}
+
+// send writes p with the write timeout, one datagram per entry on UDP. It
+// returns the number of bytes of the entries completely sent; an entry cut
+// by a failed write is not counted, so it is sent again whole. It must be
+// called with w.mu held and a connection present.
+func (w *NetWriter) send(p []byte) (int, error) {
+	if w.opts.writeTimeout > 0 {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(w.opts.writeTimeout))
+	}
+
+	if !w.datagram {
+		n, err := w.conn.Write(p)
+		if err != nil {
+			return bytes.LastIndexByte(p[:n], '\n') + 1, err
+		}
+		return len(p), nil
+	}
+
+	sent := 0
+	for sent < len(p) {
+		end := len(p)
+		if i := bytes.IndexByte(p[sent:], '\n'); i >= 0 {
+			end = sent + i + 1
+		}
+		if _, err := w.conn.Write(p[sent:end]); err != nil {
+			return sent, err
+		}
+		sent = end
+	}
+	return sent, nil
+}
+
+// disconnect drops the connection and starts reconnecting. It must be
+// called with w.mu held.
+func (w *NetWriter) disconnect() {
+	_ = w.conn.Close()
+	w.conn = nil
+	w.startReconnect()
+}
+
+// buffer appends p to the pending entries, dropping the oldest entries when
+// the cap is exceeded. It must be called with w.mu held.
+func (w *NetWriter) buffer(p []byte) {
+	if len(p) > w.opts.bufferSize {
+		w.drop(countEntries(p))
+		return
+	}
+
+	w.pending = append(w.pending, p...)
+
+	excess := len(w.pending) - w.opts.bufferSize
+	if excess <= 0 {
+		return
+	}
+
+	// Cut at an entry boundary so no partial entry is ever sent.
+	cut := len(w.pending)
+	if i := bytes.IndexByte(w.pending[excess-1:], '\n'); i >= 0 {
+		cut = excess + i
+	}
+	w.drop(countEntries(w.pending[:cut]))
+	w.pending = append(w.pending[:0], w.pending[cut:]...)
+}
+
+// drop counts n entries dropped from the full buffer.
+func (w *NetWriter) drop(n int) {
+	w.dropped.Add(uint64(n))
+	w.internal.Load().report(WarnLevel, "collector buffer full, entries dropped",
+		String("network", w.network), String("addr", w.addr), Int("entries", n))
+}
+
+// startReconnect launches the reconnect loop unless it is running. It must
+// be called with w.mu held.
+func (w *NetWriter) startReconnect() {
+	if w.reconnecting || w.closed {
+		return
+	}
+	w.reconnecting = true
+	go w.reconnect()
+}
+
+// reconnect dials with exponential backoff until a connection is
+// established and the buffered entries are sent, or the writer is closed.
+func (w *NetWriter) reconnect() {
+	delay := w.opts.minBackoff
+
+	for {
+		// Up to 20% jitter keeps many clients from reconnecting in lockstep.
+		jitter := time.Duration(rand.Int64N(int64(delay)/5 + 1))
+		select {
+		case <-w.done:
+			return
+		case <-time.After(delay + jitter):
+		}
+
+		conn, err := net.DialTimeout(w.network, w.addr, w.opts.dialTimeout)
+		if err == nil {
+			w.mu.Lock()
+			if w.closed {
+				w.mu.Unlock()
+				_ = conn.Close()
+				return
+			}
+
+			w.conn = conn
+			sent, err := w.send(w.pending)
+			w.pending = append(w.pending[:0], w.pending[sent:]...)
+			if err == nil {
+				w.reconnecting = false
+				w.mu.Unlock()
+				w.internal.Load().report(InfoLevel, "collector reconnected",
+					String("network", w.network), String("addr", w.addr), Int("sent_bytes", sent))
+				return
+			}
+			_ = conn.Close()
+			w.conn = nil
+			w.mu.Unlock()
+		}
+
+		delay = min(delay*2, w.opts.maxBackoff)
+	}
+}
+
+// countEntries returns the number of newline-terminated entries in p,
+// counting a trailing partial entry as one.
+func countEntries(p []byte) int {
+	n := bytes.Count(p, []byte{'\n'})
+	if len(p) > 0 && p[len(p)-1] != '\n' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unusedAddr returns a local TCP address with nothing listening on it.
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+func TestNetWriter_Connected(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	w, err := NewNetWriter("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer w.Close()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: w})
+	logger.Info("shipped")
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"message":"shipped"`)
+	assert.True(t, w.Connected())
+}
+
+func TestNetWriter_BuffersUntilReconnect(t *testing.T) {
+	addr := unusedAddr(t)
+
+	w, err := NewNetWriter("tcp", addr, WithNetBackoff(10*time.Millisecond, 50*time.Millisecond))
+	require.NoError(t, err)
+	defer w.Close()
+
+	assert.False(t, w.Connected())
+	_, _ = w.Write([]byte("first\n"))
+	_, _ = w.Write([]byte("second\n"))
+	assert.Equal(t, len("first\nsecond\n"), w.Buffered())
+
+	listener, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	reader := bufio.NewReader(conn)
+	for _, want := range []string{"first\n", "second\n"} {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, want, line)
+	}
+
+	assert.Eventually(t, w.Connected, time.Second, 5*time.Millisecond)
+	assert.Zero(t, w.Buffered())
+}
+
+func TestNetWriter_BufferCapDropsOldest(t *testing.T) {
+	w, err := NewNetWriter("tcp", unusedAddr(t),
+		WithNetBufferSize(12),
+		WithNetBackoff(time.Hour, time.Hour),
+	)
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, _ = w.Write([]byte("aaaa\n"))
+	_, _ = w.Write([]byte("bbbb\n"))
+	_, _ = w.Write([]byte("cccc\n"))
+	_, _ = w.Write([]byte("this entry is too large\n"))
+
+	assert.Equal(t, uint64(2), w.Dropped())
+	assert.Equal(t, "bbbb\ncccc\n", string(w.pending))
+}
+
+func TestNetWriter_CloseDropsPending(t *testing.T) {
+	w, err := NewNetWriter("tcp", unusedAddr(t), WithNetBackoff(time.Hour, time.Hour))
+	require.NoError(t, err)
+
+	_, _ = w.Write([]byte("a\nb\n"))
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	assert.Equal(t, uint64(2), w.Dropped())
+
+	n, err := w.Write([]byte("c\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, uint64(3), w.Dropped())
+}
+
+func TestNewNetWriter_UnsupportedNetwork(t *testing.T) {
+	_, err := NewNetWriter("ip4:icmp", "127.0.0.1")
+	assert.Error(t, err)
+}
+
+// shortConn accepts limit bytes, then fails every write.
+type shortConn struct {
+	net.Conn
+	limit   int
+	written []byte
+}
+
+func (c *shortConn) Write(p []byte) (int, error) {
+	n := min(len(p), c.limit-len(c.written))
+	c.written = append(c.written, p[:n]...)
+	if n < len(p) {
+		return n, net.ErrClosed
+	}
+	return n, nil
+}
+
+func (c *shortConn) SetWriteDeadline(time.Time) error { return nil }
+
+func (c *shortConn) Close() error { return nil }
+
+func TestNetWriter_PartialWriteBuffersRemainder(t *testing.T) {
+	w, err := NewNetWriter("tcp", unusedAddr(t), WithNetBackoff(time.Hour, time.Hour))
+	require.NoError(t, err)
+	defer w.Close()
+
+	conn := &shortConn{limit: 8}
+	w.mu.Lock()
+	w.conn = conn
+	w.mu.Unlock()
+
+	_, _ = w.Write([]byte("one\ntwo\nthree\n"))
+	assert.Equal(t, "one\ntwo\n", string(conn.written))
+	assert.Equal(t, "three\n", string(w.pending), "only entries not completely sent are buffered")
+
+	conn = &shortConn{limit: 3}
+	w.mu.Lock()
+	w.conn = conn
+	w.mu.Unlock()
+	_, _ = w.Write([]byte("four\nfive\n"))
+	assert.Equal(t, "three\nfour\nfive\n", string(w.pending), "an entry cut by the write is buffered whole")
+}
+
+func TestNetWriter_UDPReplaysEntriesAsDatagrams(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	w, err := NewNetWriter("udp", listener.LocalAddr().String())
+	require.NoError(t, err)
+	defer w.Close()
+
+	w.mu.Lock()
+	w.pending = append(w.pending, "first\nsecond\n"...)
+	sent, err := w.send(w.pending)
+	w.mu.Unlock()
+	require.NoError(t, err)
+	assert.Equal(t, len("first\nsecond\n"), sent)
+
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, 64)
+	for _, want := range []string{"first\n", "second\n"} {
+		n, _, err := listener.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(buf[:n]))
+	}
+}
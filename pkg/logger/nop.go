@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"io"
+	"math"
+)
+
+// Nop returns a logger that discards every entry, as a safe default for
+// libraries that accept an optional *Logger. Every level is disabled, so
+// its logging methods return after a single atomic load, without encoding,
+// locking or allocating; Check returns nil and Enabled false. As for any
+// disabled level, the fields passed to a call may still be allocated by
+// the caller.
+// Fatal still exits and Panic still panics, since callers rely on them not
+// returning.
+//
+// Each call returns a new logger, so AddSink, AddHook or SetNamedLevel on
+// one do not affect others. Adding a sink turns it into a regular logger
+// writing to that sink.
+func Nop() *Logger {
+	l := New(Config{Output: io.Discard})
+
+	l.sinksMu.Lock()
+	l.storeSinks(nil)
+	l.sinksMu.Unlock()
+	// storeSinks leaves MaxInt8 enabled; disable every level.
+	l.minLevel.Store(math.MaxInt32)
+	return l
+}
@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNop(t *testing.T) {
+	log := Nop()
+	assert.NotSame(t, log, Nop())
+
+	for _, level := range []Level{DebugLevel, InfoLevel, ErrorLevel, Level(127)} {
+		assert.False(t, log.Enabled(level))
+	}
+	assert.Nil(t, log.Check(ErrorLevel, "dropped"))
+
+	child := log.Named("child").With(String("k", "v"))
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Info("dropped")
+		child.Error("dropped")
+	})
+	assert.Zero(t, allocs)
+	assert.PanicsWithValue(t, "boom", func() { log.Panic("boom") })
+
+	buf := &bytes.Buffer{}
+	log.AddSink(SinkConfig{Output: buf, Level: InfoLevel})
+	log.Info("written")
+	assert.Contains(t, buf.String(), "INFO written")
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"strings"
+	"time"
+)
+
+// Normalizer converts a field value into its canonical form. Normalizers
+// are registered per key through Config.Normalizers and must return the
+// value unchanged when it is of an unexpected type.
+type Normalizer func(value interface{}) interface{}
+
+// NormalizeLower lowercases string values, e.g. "GET" becomes "get".
+func NormalizeLower(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.ToLower(s)
+	}
+	return value
+}
+
+// NormalizeUpper uppercases string values, e.g. "get" becomes "GET".
+func NormalizeUpper(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.ToUpper(s)
+	}
+	return value
+}
+
+// NormalizeDurationMillis converts time.Duration values to a float64 number
+// of milliseconds so latencies share a single unit.
+func NormalizeDurationMillis(value interface{}) interface{} {
+	if d, ok := value.(time.Duration); ok {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return value
+}
+
+// NormalizeSecondsToMillis converts numeric values expressed in seconds to
+// milliseconds. Integers stay integers.
+func NormalizeSecondsToMillis(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return v * 1000
+	case int64:
+		return v * 1000
+	case float64:
+		return v * 1000
+	default:
+		return value
+	}
+}
+
+// NormalizeClamp returns a Normalizer that limits numeric values to the
+// [lower, upper] range while preserving their type.
+func NormalizeClamp(lower, upper float64) Normalizer {
+	return func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case int:
+			return int(clamp(float64(v), lower, upper))
+		case int64:
+			return int64(clamp(float64(v), lower, upper))
+		case float64:
+			return clamp(v, lower, upper)
+		default:
+			return value
+		}
+	}
+}
+
+func clamp(v, lower, upper float64) float64 {
+	return max(lower, min(v, upper))
+}
+
+// normalizeFields applies the normalizers registered for each field key.
+func normalizeFields(normalizers map[string]Normalizer, r *Record) {
+	for i := range r.Fields {
+		if normalize, ok := normalizers[r.Fields[i].Key]; ok {
+			r.Fields[i].Value = normalize(r.Fields[i].Value)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizers_AppliedPerKey(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: buf,
+		Normalizers: map[string]Normalizer{
+			"method":  NormalizeUpper,
+			"latency": NormalizeDurationMillis,
+			"timeout": NormalizeSecondsToMillis,
+			"ratio":   NormalizeClamp(0, 1),
+		},
+	})
+
+	logger.Info("request",
+		Field{Key: "method", Value: "get"},
+		Field{Key: "latency", Value: 1500 * time.Millisecond},
+		Field{Key: "timeout", Value: 30},
+		Field{Key: "ratio", Value: 1.7},
+		Field{Key: "path", Value: "/Users"},
+	)
+
+	output := buf.String()
+	assert.Contains(t, output, `"method":"GET"`)
+	assert.Contains(t, output, `"latency":1500`)
+	assert.Contains(t, output, `"timeout":30000`)
+	assert.Contains(t, output, `"ratio":1`)
+	assert.Contains(t, output, `"path":"/Users"`)
+}
+
+func TestNormalizers_KeepUnexpectedTypes(t *testing.T) {
+	assert.Equal(t, 42, NormalizeLower(42))
+	assert.Equal(t, "get", NormalizeLower("GET"))
+	assert.Equal(t, "x", NormalizeDurationMillis("x"))
+	assert.Equal(t, true, NormalizeSecondsToMillis(true))
+	assert.Equal(t, int64(-5), NormalizeClamp(-5, 5)(int64(-10)))
+	assert.Equal(t, 3, NormalizeClamp(-5, 5)(3))
+	assert.Equal(t, "x", NormalizeClamp(0, 1)("x"))
+}
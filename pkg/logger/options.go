@@ -0,0 +1,356 @@
+package logger
+
+import (
+	"io"
+	"regexp"
+	"time"
+)
+
+// builder collects the settings applied by Options.
+type builder struct {
+	config Config
+	name   string
+	fields []Field
+	hooks  []Hook
+
+	// testFailLevel is set by WithTestFailure.
+	testFailLevel *Level
+}
+
+// Option configures a logger created by NewWithOptions.
+type Option func(*builder)
+
+// NewWithOptions creates a logger from options applied in order to a
+// Config with InfoLevel, TextFormat and os.Stdout, so that later options
+// override earlier ones and presets can be layered:
+//
+//	log, err := logger.NewWithOptions(
+//		logger.WithConfig(logger.ConfigFromEnv()),
+//		logger.WithFormat(logger.JSONFormat),
+//		logger.WithCaller(),
+//		logger.WithFields(logger.String("service", "billing")),
+//	)
+//
+// The resulting configuration is validated like NewE does.
+func NewWithOptions(opts ...Option) (*Logger, error) {
+	b := &builder{config: Config{Level: InfoLevel, Format: TextFormat}}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	l, err := NewE(b.config)
+	if err != nil {
+		return nil, err
+	}
+	return b.derive(l), nil
+}
+
+// derive adds the hooks of b to l and returns l with the name and fields
+// of b.
+func (b *builder) derive(l *Logger) *Logger {
+	for _, hook := range b.hooks {
+		l.AddHook(hook)
+	}
+	return l.Named(b.name).With(b.fields...)
+}
+
+// WithConfig replaces the configuration built so far with config. Use it
+// first to start from an existing Config.
+func WithConfig(config Config) Option {
+	return func(b *builder) {
+		b.config = config
+	}
+}
+
+// WithLevel sets Config.Level.
+func WithLevel(level Level) Option {
+	return func(b *builder) {
+		b.config.Level = level
+	}
+}
+
+// WithFormat sets Config.Format.
+func WithFormat(format Format) Option {
+	return func(b *builder) {
+		b.config.Format = format
+	}
+}
+
+// WithTimestampFormat sets Config.TimestampFormat.
+func WithTimestampFormat(format TimestampFormat) Option {
+	return func(b *builder) {
+		b.config.TimestampFormat = format
+	}
+}
+
+// WithClock sets Config.Clock.
+func WithClock(clock func() time.Time) Option {
+	return func(b *builder) {
+		b.config.Clock = clock
+	}
+}
+
+// WithTimestampCache sets Config.TimestampCache.
+func WithTimestampCache(period time.Duration) Option {
+	return func(b *builder) {
+		b.config.TimestampCache = period
+	}
+}
+
+// WithOutput sets Config.Output.
+func WithOutput(w io.Writer) Option {
+	return func(b *builder) {
+		b.config.Output = w
+	}
+}
+
+// WithErrorOutput sets Config.ErrorOutput.
+func WithErrorOutput(w io.Writer) Option {
+	return func(b *builder) {
+		b.config.ErrorOutput = w
+	}
+}
+
+// WithSinks adds sinks to Config.Outputs.
+func WithSinks(sinks ...SinkConfig) Option {
+	return func(b *builder) {
+		b.config.Outputs = append(b.config.Outputs[:len(b.config.Outputs):len(b.config.Outputs)], sinks...)
+	}
+}
+
+// WithBuffering sets Config.BufferSize and Config.FlushInterval.
+func WithBuffering(size int, flushInterval time.Duration) Option {
+	return func(b *builder) {
+		b.config.BufferSize = size
+		b.config.FlushInterval = flushInterval
+	}
+}
+
+// WithBufferShards sets Config.BufferShards.
+func WithBufferShards(shards int) Option {
+	return func(b *builder) {
+		b.config.BufferShards = shards
+	}
+}
+
+// WithAsync sets Config.AsyncQueueSize.
+func WithAsync(queueSize int) Option {
+	return func(b *builder) {
+		b.config.AsyncQueueSize = queueSize
+	}
+}
+
+// WithBufferPool sets Config.InitialBufferSize and
+// Config.MaxPooledBufferSize.
+func WithBufferPool(initialSize, maxPooledSize int) Option {
+	return func(b *builder) {
+		b.config.InitialBufferSize = initialSize
+		b.config.MaxPooledBufferSize = maxPooledSize
+	}
+}
+
+// WithProfile sets Config.Profile.
+func WithProfile(profile Profile) Option {
+	return func(b *builder) {
+		b.config.Profile = profile
+	}
+}
+
+// WithCaller sets Config.AddCaller.
+func WithCaller() Option {
+	return func(b *builder) {
+		b.config.AddCaller = true
+	}
+}
+
+// WithStacktrace sets Config.AddStacktrace for entries at level and above.
+func WithStacktrace(level Level) Option {
+	return func(b *builder) {
+		b.config.AddStacktrace = true
+		b.config.StacktraceLevel = level
+	}
+}
+
+// WithExitFunc sets Config.ExitFunc.
+func WithExitFunc(exit func(code int)) Option {
+	return func(b *builder) {
+		b.config.ExitFunc = exit
+	}
+}
+
+// WithoutExit sets Config.ExitFunc to a function that does not exit, so
+// Fatal returns after logging, e.g. in tests.
+func WithoutExit() Option {
+	return WithExitFunc(func(int) {})
+}
+
+// WithOnFatal adds functions to Config.OnFatal.
+func WithOnFatal(fns ...func()) Option {
+	return func(b *builder) {
+		b.config.OnFatal = append(b.config.OnFatal[:len(b.config.OnFatal):len(b.config.OnFatal)], fns...)
+	}
+}
+
+// WithWriteErrors sets Config.WriteErrors.
+func WithWriteErrors(policy WriteErrorPolicy) Option {
+	return func(b *builder) {
+		b.config.WriteErrors = policy
+	}
+}
+
+// WithOnWriteError sets Config.OnWriteError.
+func WithOnWriteError(fn func(err error)) Option {
+	return func(b *builder) {
+		b.config.OnWriteError = fn
+	}
+}
+
+// WithInternalOutput sets Config.InternalOutput.
+func WithInternalOutput(w io.Writer) Option {
+	return func(b *builder) {
+		b.config.InternalOutput = w
+	}
+}
+
+// WithStrictPanics sets Config.StrictPanics.
+func WithStrictPanics() Option {
+	return func(b *builder) {
+		b.config.StrictPanics = true
+	}
+}
+
+// WithSampling sets Config.Sampling.
+func WithSampling(sampling SamplingConfig) Option {
+	return func(b *builder) {
+		b.config.Sampling = &sampling
+	}
+}
+
+// WithRateLimit adds the rate limit of level to Config.RateLimit.
+func WithRateLimit(level Level, limit RateLimit) Option {
+	return func(b *builder) {
+		limits := make(map[Level]RateLimit, len(b.config.RateLimit)+1)
+		for l, rl := range b.config.RateLimit {
+			limits[l] = rl
+		}
+		limits[level] = limit
+		b.config.RateLimit = limits
+	}
+}
+
+// WithFilters adds filters to Config.Filters.
+func WithFilters(filters ...Filter) Option {
+	return func(b *builder) {
+		b.config.Filters = append(b.config.Filters[:len(b.config.Filters):len(b.config.Filters)], filters...)
+	}
+}
+
+// WithRepeatWindow sets Config.RepeatWindow.
+func WithRepeatWindow(window time.Duration) Option {
+	return func(b *builder) {
+		b.config.RepeatWindow = window
+	}
+}
+
+// WithBreadcrumbs sets Config.Breadcrumbs to keep the last size entries
+// at level and above.
+func WithBreadcrumbs(size int, level Level) Option {
+	return func(b *builder) {
+		b.config.Breadcrumbs = &BreadcrumbConfig{Size: size, Level: level}
+	}
+}
+
+// WithRedaction adds key patterns to Config.RedactKeys and value patterns
+// to Config.RedactValuePatterns.
+func WithRedaction(keys []string, patterns ...*regexp.Regexp) Option {
+	return func(b *builder) {
+		b.config.RedactKeys = append(b.config.RedactKeys[:len(b.config.RedactKeys):len(b.config.RedactKeys)], keys...)
+		b.config.RedactValuePatterns = append(b.config.RedactValuePatterns[:len(b.config.RedactValuePatterns):len(b.config.RedactValuePatterns)], patterns...)
+	}
+}
+
+// WithKeyMap adds renames to Config.KeyMap.
+func WithKeyMap(keyMap map[string]string) Option {
+	return func(b *builder) {
+		merged := make(map[string]string, len(b.config.KeyMap)+len(keyMap))
+		for from, to := range b.config.KeyMap {
+			merged[from] = to
+		}
+		for from, to := range keyMap {
+			merged[from] = to
+		}
+		b.config.KeyMap = merged
+	}
+}
+
+// WithFloatPrecision sets Config.FloatPrecision.
+func WithFloatPrecision(digits int) Option {
+	return func(b *builder) {
+		b.config.FloatPrecision = digits
+	}
+}
+
+// WithNonFiniteFloats sets Config.NonFiniteFloats.
+func WithNonFiniteFloats(policy NonFiniteFloatPolicy) Option {
+	return func(b *builder) {
+		b.config.NonFiniteFloats = policy
+	}
+}
+
+// WithMaxMessageBytes sets Config.MaxMessageBytes.
+func WithMaxMessageBytes(limit int) Option {
+	return func(b *builder) {
+		b.config.MaxMessageBytes = limit
+	}
+}
+
+// WithMaxFieldBytes sets Config.MaxFieldBytes.
+func WithMaxFieldBytes(limit int) Option {
+	return func(b *builder) {
+		b.config.MaxFieldBytes = limit
+	}
+}
+
+// WithMaxFields sets Config.MaxFields.
+func WithMaxFields(limit int) Option {
+	return func(b *builder) {
+		b.config.MaxFields = limit
+	}
+}
+
+// WithMaxRecordBytes sets Config.MaxRecordBytes and
+// Config.OversizedRecords.
+func WithMaxRecordBytes(limit int, policy OversizedRecordPolicy) Option {
+	return func(b *builder) {
+		b.config.MaxRecordBytes = limit
+		b.config.OversizedRecords = policy
+	}
+}
+
+// WithDuplicateKeys sets Config.DuplicateKeys.
+func WithDuplicateKeys(policy DuplicateKeyPolicy) Option {
+	return func(b *builder) {
+		b.config.DuplicateKeys = policy
+	}
+}
+
+// WithHooks adds hooks to the logger, like AddHook.
+func WithHooks(hooks ...Hook) Option {
+	return func(b *builder) {
+		b.hooks = append(b.hooks, hooks...)
+	}
+}
+
+// WithName names the logger, like Named.
+func WithName(name string) Option {
+	return func(b *builder) {
+		b.name = name
+	}
+}
+
+// WithFields adds fields to every entry of the logger, like With.
+func WithFields(fields ...Field) Option {
+	return func(b *builder) {
+		b.fields = append(b.fields, fields...)
+	}
+}
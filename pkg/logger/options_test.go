@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	buf := &bytes.Buffer{}
+	errs := &bytes.Buffer{}
+	base := Config{Level: ErrorLevel, Format: TextFormat, KeyMap: map[string]string{"a": "b"}}
+
+	log, err := NewWithOptions(
+		WithConfig(base),
+		WithLevel(DebugLevel),
+		WithFormat(JSONFormat),
+		WithOutput(buf),
+		WithSinks(SinkConfig{Output: errs, Level: ErrorLevel, Format: TextFormat}),
+		WithCaller(),
+		WithRedaction([]string{"password"}, regexp.MustCompile(`\d{4}-\d{4}`)),
+		WithKeyMap(map[string]string{"user": "usr"}),
+		WithRateLimit(DebugLevel, RateLimit{EventsPerSecond: 1000, Burst: 100}),
+		WithHooks(HookFunc(func(r *Record) error {
+			r.Fields = append(r.Fields, String("hooked", "yes"))
+			return nil
+		})),
+		WithName("api"),
+		WithFields(String("user", "ada")),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b"}, base.KeyMap, "the base config is not modified")
+
+	log.Debug("login", String("password", "hunter2"), String("card", "1234-5678"))
+	log.Error("failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "DEBUG", entry["level"])
+	assert.Equal(t, "api", entry[LoggerKey])
+	assert.Equal(t, "ada", entry["usr"])
+	assert.Equal(t, RedactedValue, entry["password"])
+	assert.Equal(t, RedactedValue, entry["card"])
+	assert.Equal(t, "yes", entry["hooked"])
+	assert.Contains(t, entry[CallerKey], "logger/options_test.go:")
+
+	assert.Contains(t, errs.String(), "ERROR failed")
+	assert.NotContains(t, errs.String(), "login")
+}
+
+func TestNewWithOptions_Defaults(t *testing.T) {
+	log, err := NewWithOptions()
+	require.NoError(t, err)
+	assert.Equal(t, InfoLevel, log.config.Level)
+	assert.Equal(t, TextFormat, log.config.Format)
+
+	log, err = NewWithOptions(WithSampling(SamplingConfig{First: 1}), WithBuffering(1024, time.Second), WithAsync(16), WithBufferPool(512, 1<<20), WithProfile(""))
+	require.NoError(t, err)
+	assert.Equal(t, &SamplingConfig{First: 1}, log.config.Sampling)
+	assert.Equal(t, 1024, log.config.BufferSize)
+	assert.Equal(t, 16, log.config.AsyncQueueSize)
+	assert.Equal(t, 512, log.config.InitialBufferSize)
+	assert.Equal(t, 1<<20, log.config.MaxPooledBufferSize)
+	require.NoError(t, log.Close())
+
+	_, err = NewWithOptions(WithProfile("turbo"))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
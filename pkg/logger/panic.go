@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// Sources of recovered panics, as reported to Config.InternalOutput.
+const (
+	panicHook      = "hook"
+	panicExtractor = "context extractor"
+	panicValuer    = "LogValue"
+	panicEncoder   = "encoder"
+)
+
+// handlePanic deals with the panic p recovered from user code called while
+// logging, named by source. With Config.StrictPanics it panics again with
+// p; otherwise the panic and its stack are reported to the internal logger.
+// It must be called from the deferred function that recovered p, so the
+// stack still shows where p was raised.
+func (l *Logger) handlePanic(source string, p any) {
+	if l.config.StrictPanics {
+		panic(p)
+	}
+	l.internal.report(ErrorLevel, "panic in "+source,
+		String("panic", fmt.Sprint(p)), Block(StacktraceKey, string(debug.Stack())))
+}
+
+// panicError returns the error reported for the recovered panic p,
+// wrapping p when it is an error.
+func panicError(p any) error {
+	if err, ok := p.(error); ok {
+		return fmt.Errorf("logger: panic: %w", err)
+	}
+	return fmt.Errorf("logger: panic: %v", p)
+}
+
+// runHook runs hook against r, treating a panic like an error.
+func (l *Logger) runHook(hook Hook, r *Record) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			l.handlePanic(panicHook, p)
+			err = panicError(p)
+		}
+	}()
+	return hook.Run(r)
+}
+
+// runExtractor runs extract against ctx. Fields appended before a panic
+// are kept.
+func (l *Logger) runExtractor(extract ContextExtractor, ctx context.Context, appendField func(Field)) {
+	defer func() {
+		if p := recover(); p != nil {
+			l.handlePanic(panicExtractor, p)
+		}
+	}()
+	extract(ctx, appendField)
+}
+
+// encodeSink replaces *bufPtr with the encoding of r for s. It reports
+// false when a panic in user code, such as a json.Marshaler or a custom
+// Encoder, aborted the encoding.
+func (l *Logger) encodeSink(s *sink, r *Record, bufPtr *[]byte) (ok bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			l.handlePanic(panicEncoder, p)
+			ok = false
+		}
+	}()
+	if s.encoder != nil {
+		*bufPtr = s.encoder.Encode((*bufPtr)[:0], r)
+	} else {
+		*bufPtr = l.encode((*bufPtr)[:0], s.format, s.color, s.key.singleLine, s.keyMap.jsonKeys(), r)
+	}
+	return true
+}
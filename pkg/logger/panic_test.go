@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panickingMarshaler panics when encoded as JSON.
+type panickingMarshaler struct{}
+
+func (panickingMarshaler) MarshalJSON() ([]byte, error) {
+	panic("marshal broke")
+}
+
+func TestPanics_Hook(t *testing.T) {
+	out, internal := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: out, InternalOutput: internal})
+	log.AddHook(HookFunc(func(*Record) error { panic("hook broke") }))
+	log.AddHook(HookFunc(func(r *Record) error {
+		r.Fields = append(r.Fields, String("next", "ran"))
+		return nil
+	}))
+
+	require.NotPanics(t, func() { log.Info("survived") })
+	assert.Contains(t, out.String(), "survived")
+	assert.Contains(t, out.String(), "next=ran")
+	assert.Contains(t, internal.String(), "ERROR panic in hook")
+	assert.Contains(t, internal.String(), "panic=\"hook broke\"")
+	assert.Contains(t, internal.String(), "panic_test.go")
+	assert.Contains(t, internal.String(), `WARN hook failed logger=logslib hook=logger.HookFunc error="logger: panic: hook broke"`)
+}
+
+func TestPanics_ContextExtractor(t *testing.T) {
+	out, internal := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Config{
+		Level:          InfoLevel,
+		Output:         out,
+		InternalOutput: internal,
+		ContextExtractor: func(_ context.Context, appendField func(Field)) {
+			appendField(String("tenant", "acme"))
+			panic("extractor broke")
+		},
+	})
+
+	require.NotPanics(t, func() { log.WithContext(context.Background).Info("survived") })
+	assert.Contains(t, out.String(), "tenant=acme")
+	assert.Contains(t, internal.String(), "panic in context extractor")
+}
+
+func TestPanics_Valuer(t *testing.T) {
+	out, internal := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: out, InternalOutput: internal})
+
+	log.Info("lazy", F("value", LogValuerFunc(func() any { panic("valuer broke") })))
+	assert.Contains(t, out.String(), `value="!PANIC: valuer broke"`)
+	assert.Contains(t, internal.String(), "panic in LogValue")
+}
+
+func TestPanics_Marshaler(t *testing.T) {
+	out, other, internal := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Config{
+		Level:          InfoLevel,
+		Format:         JSONFormat,
+		Output:         out,
+		InternalOutput: internal,
+		Outputs: []SinkConfig{{
+			Output:  other,
+			Encoder: EncoderFunc(func(buf []byte, r *Record) []byte { return append(buf, r.Message+"\n"...) }),
+		}},
+	})
+
+	require.NotPanics(t, func() { log.Info("object", Any("obj", panickingMarshaler{})) })
+	assert.Empty(t, out.String(), "the entry is dropped for the panicking sink")
+	assert.Equal(t, "object\n", other.String())
+	assert.Contains(t, internal.String(), "panic in encoder")
+	assert.Contains(t, internal.String(), "marshal broke")
+
+	log.Info("next")
+	assert.Contains(t, out.String(), `"message":"next"`)
+}
+
+func TestPanics_Strict(t *testing.T) {
+	log := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}, StrictPanics: true})
+	log.AddHook(HookFunc(func(*Record) error { panic("hook broke") }))
+	assert.PanicsWithValue(t, "hook broke", func() { log.Info("crash") })
+
+	log = New(Config{Level: InfoLevel, Format: JSONFormat, Output: &bytes.Buffer{}, StrictPanics: true})
+	assert.PanicsWithValue(t, "marshal broke", func() { log.Info("crash", Any("obj", panickingMarshaler{})) })
+
+	log, err := NewWithOptions(WithOutput(&bytes.Buffer{}), WithStrictPanics())
+	require.NoError(t, err)
+	assert.PanicsWithValue(t, "valuer broke", func() {
+		log.Info("crash", F("value", LogValuerFunc(func() any { panic("valuer broke") })))
+	})
+}
@@ -0,0 +1,402 @@
+package logger
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of a PartitionWriter.
+const (
+	defaultPartitionMaxOpen  = 64
+	defaultPartitionFallback = "unknown"
+)
+
+// Placeholders of a partition pattern with a fixed meaning. Any other
+// placeholder refers to a field by key.
+const (
+	partitionDate  = "date"
+	partitionLevel = "level"
+)
+
+// ErrInvalidPartitionPattern is returned by NewPartitionWriter for an empty
+// pattern, an unterminated or empty placeholder, or a pattern without any
+// placeholder.
+var ErrInvalidPartitionPattern = errors.New("logger: invalid partition pattern")
+
+// partitionOptions holds the settings of a PartitionWriter.
+type partitionOptions struct {
+	maxOpen    int
+	maxSize    int64
+	maxBackups int
+	fallback   string
+	location   *time.Location
+}
+
+// PartitionOption configures a PartitionWriter.
+type PartitionOption func(*partitionOptions)
+
+// WithPartitionMaxOpen caps the number of files kept open at once. When
+// the cap is reached, the least recently written file is closed; it is
+// reopened for appending when an entry for it arrives. Defaults to 64.
+func WithPartitionMaxOpen(n int) PartitionOption {
+	return func(o *partitionOptions) {
+		o.maxOpen = n
+	}
+}
+
+// WithPartitionRotation rotates a partition file once writing an entry
+// would grow it beyond maxSize bytes. The file is renamed to path.1, older
+// backups shift to path.2 and so on, and at most maxBackups backups are
+// kept per partition. Rotation is disabled by default.
+func WithPartitionRotation(maxSize int64, maxBackups int) PartitionOption {
+	return func(o *partitionOptions) {
+		o.maxSize = maxSize
+		o.maxBackups = maxBackups
+	}
+}
+
+// WithPartitionFallback sets the path element used for entries without
+// the field a placeholder refers to, or with an empty value. Defaults to
+// "unknown".
+func WithPartitionFallback(value string) PartitionOption {
+	return func(o *partitionOptions) {
+		o.fallback = value
+	}
+}
+
+// WithPartitionLocation sets the time zone of the {date} placeholder.
+// Defaults to UTC.
+func WithPartitionLocation(loc *time.Location) PartitionOption {
+	return func(o *partitionOptions) {
+		o.location = loc
+	}
+}
+
+// PartitionWriter is an output that routes every entry to a file chosen by
+// the entry itself, e.g. one file per tenant and day with the pattern
+// "logs/{tenant}/{date}.log". Placeholders name a field of the entry, or
+// are one of:
+//
+//	{date}   the day of the entry timestamp in UTC, as 2006-01-02
+//	{level}  the lowercase level name
+//
+// Field values are sanitized so they cannot escape the pattern directory.
+// Directories are created as needed. Lines passed to Logger.WriteRaw carry
+// no fields and go to the fallback partition of the current day.
+type PartitionWriter struct {
+	segments []templateSegment
+	opts     partitionOptions
+
+	mu    sync.Mutex
+	files map[string]*list.Element
+	lru   list.List // of *partitionFile, most recently written first
+
+	internal atomic.Pointer[Logger]
+}
+
+// partitionFile is an open partition.
+type partitionFile struct {
+	path string
+	file *os.File
+	size int64
+}
+
+// NewPartitionWriter returns a PartitionWriter for pattern.
+//
+// Example:
+//
+//	w, err := logger.NewPartitionWriter("logs/{tenant}/{date}.log",
+//		logger.WithPartitionRotation(100<<20, 5),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Close()
+//
+//	log := logger.New(logger.Config{Format: logger.JSONFormat, Output: w})
+//	log.Info("Invoice sent", logger.String("tenant", "acme"))
+func NewPartitionWriter(pattern string, opts ...PartitionOption) (*PartitionWriter, error) {
+	segments, err := compilePartitionPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	o := partitionOptions{
+		maxOpen:  defaultPartitionMaxOpen,
+		fallback: defaultPartitionFallback,
+		location: time.UTC,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxOpen < 1 {
+		o.maxOpen = 1
+	}
+	if o.fallback == "" {
+		o.fallback = defaultPartitionFallback
+	}
+	if o.location == nil {
+		o.location = time.UTC
+	}
+
+	return &PartitionWriter{
+		segments: segments,
+		opts:     o,
+		files:    make(map[string]*list.Element),
+	}, nil
+}
+
+// compilePartitionPattern splits pattern into literal and placeholder
+// segments.
+func compilePartitionPattern(pattern string) ([]templateSegment, error) {
+	var segments []templateSegment
+	placeholders := 0
+
+	for rest := pattern; rest != ""; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			segments = append(segments, templateSegment{kind: segmentLiteral, value: rest})
+			break
+		}
+		if start > 0 {
+			segments = append(segments, templateSegment{kind: segmentLiteral, value: rest[:start]})
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated placeholder in %q", ErrInvalidPartitionPattern, pattern)
+		}
+		name := rest[start+1 : start+end]
+		if name == "" {
+			return nil, fmt.Errorf("%w: empty placeholder in %q", ErrInvalidPartitionPattern, pattern)
+		}
+
+		switch name {
+		case partitionDate:
+			segments = append(segments, templateSegment{kind: segmentTimestamp})
+		case partitionLevel:
+			segments = append(segments, templateSegment{kind: segmentLevel})
+		default:
+			segments = append(segments, templateSegment{kind: segmentField, value: name})
+		}
+		placeholders++
+		rest = rest[start+end+1:]
+	}
+
+	if placeholders == 0 {
+		return nil, fmt.Errorf("%w: %q has no placeholder", ErrInvalidPartitionPattern, pattern)
+	}
+	return segments, nil
+}
+
+// WriteRecord appends entry to the partition of r.
+func (w *PartitionWriter) WriteRecord(r *Record, entry []byte) error {
+	var stack [256]byte
+	path := string(w.appendPath(stack[:0], r))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.write(path, entry)
+}
+
+// Write appends p to the fallback partition of the current day.
+func (w *PartitionWriter) Write(p []byte) (int, error) {
+	r := Record{Time: time.Now(), Level: InfoLevel}
+	if err := w.WriteRecord(&r, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// appendPath renders the partition path of r.
+func (w *PartitionWriter) appendPath(buf []byte, r *Record) []byte {
+	for _, seg := range w.segments {
+		switch seg.kind {
+		case segmentLiteral:
+			buf = append(buf, seg.value...)
+		case segmentTimestamp:
+			buf = r.Time.In(w.opts.location).AppendFormat(buf, "2006-01-02")
+		case segmentLevel:
+			buf = append(buf, strings.ToLower(r.Level.String())...)
+		case segmentField:
+			buf = w.appendPathValue(buf, r, seg.value)
+		}
+	}
+	return buf
+}
+
+// appendPathValue appends the value of the field key as a single path
+// element: separators and other unsafe bytes become '_', and values that
+// would name the current or parent directory are replaced.
+func (w *PartitionWriter) appendPathValue(buf []byte, r *Record, key string) []byte {
+	field, ok := findField(r.Fields, key)
+	if !ok || field.Value == nil {
+		return append(buf, w.opts.fallback...)
+	}
+
+	start := len(buf)
+	if s, isString := field.Value.(string); isString {
+		buf = append(buf, s...)
+	} else {
+		buf = appendValue(buf, field.Value)
+	}
+
+	value := buf[start:]
+	for i, c := range value {
+		if c == '/' || c == '\\' || c == ':' || c < ' ' || c == 0x7f {
+			value[i] = '_'
+		}
+	}
+	if v := string(value); v == "" || v == "." || v == ".." {
+		buf = append(buf[:start], w.opts.fallback...)
+	}
+	return buf
+}
+
+// write appends entry to the file at path, opening and rotating it as
+// needed. w.mu must be held.
+func (w *PartitionWriter) write(path string, entry []byte) error {
+	pf, err := w.open(path)
+	if err != nil {
+		return err
+	}
+
+	if w.opts.maxSize > 0 && pf.size > 0 && pf.size+int64(len(entry)) > w.opts.maxSize {
+		if err := w.rotate(pf); err != nil {
+			w.internal.Load().report(ErrorLevel, "partition rotation failed", String("path", path), Err(err))
+			return err
+		}
+	}
+
+	n, err := pf.file.Write(entry)
+	pf.size += int64(n)
+	return err
+}
+
+// setInternal implements internalReporter.
+func (w *PartitionWriter) setInternal(internal *Logger) {
+	w.internal.Store(internal)
+}
+
+// open returns the open partition for path, opening it and closing the
+// least recently written one when the cap is reached. w.mu must be held.
+func (w *PartitionWriter) open(path string) (*partitionFile, error) {
+	if elem, ok := w.files[path]; ok {
+		w.lru.MoveToFront(elem)
+		return elem.Value.(*partitionFile), nil
+	}
+
+	for w.lru.Len() >= w.opts.maxOpen {
+		oldest := w.lru.Back()
+		w.lru.Remove(oldest)
+		pf := oldest.Value.(*partitionFile)
+		delete(w.files, pf.path)
+		_ = pf.file.Close()
+	}
+
+	file, size, err := openAppend(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pf := &partitionFile{path: path, file: file, size: size}
+	w.files[path] = w.lru.PushFront(pf)
+	return pf, nil
+}
+
+// rotate moves the file of pf to the first backup slot, shifting older
+// backups, and reopens an empty file in its place. On failure the
+// partition is forgotten, so the next entry opens it again.
+func (w *PartitionWriter) rotate(pf *partitionFile) error {
+	err := pf.file.Close()
+	if err == nil {
+		err = w.rotateBackups(pf.path)
+	}
+	if err == nil {
+		pf.file, pf.size, err = openAppend(pf.path)
+	}
+	if err != nil {
+		w.lru.Remove(w.files[pf.path])
+		delete(w.files, pf.path)
+	}
+	return err
+}
+
+// rotateBackups moves the file at path to the first backup slot and
+// shifts older backups, or removes it when no backups are kept.
+func (w *PartitionWriter) rotateBackups(path string) error {
+	if w.opts.maxBackups <= 0 {
+		return os.Remove(path)
+	}
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", path, w.opts.maxBackups))
+	for i := w.opts.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	return os.Rename(path, path+".1")
+}
+
+// openAppend opens path for appending, creating it and its directory as
+// needed, and returns its current size.
+func openAppend(path string) (*os.File, int64, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+// Open returns the number of partition files currently open.
+func (w *PartitionWriter) Open() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.lru.Len()
+}
+
+// Sync commits the open partition files to stable storage.
+func (w *PartitionWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	for elem := w.lru.Front(); elem != nil; elem = elem.Next() {
+		errs = append(errs, elem.Value.(*partitionFile).file.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+// Close syncs and closes all open partition files. Entries written after
+// Close reopen their partitions.
+func (w *PartitionWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	for elem := w.lru.Front(); elem != nil; elem = elem.Next() {
+		file := elem.Value.(*partitionFile).file
+		errs = append(errs, file.Sync(), file.Close())
+	}
+	w.lru.Init()
+	clear(w.files)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readPartition(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPartitionWriter(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewPartitionWriter(filepath.Join(dir, "{tenant}", "{date}-{level}.log"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: w})
+	log.Info("invoice sent", String("tenant", "acme"))
+	log.Warn("quota low", String("tenant", "globex"))
+	log.With(String("tenant", "acme")).Info("invoice paid")
+	log.Info("no tenant")
+
+	day := time.Now().UTC().Format("2006-01-02")
+	acme := readPartition(t, filepath.Join(dir, "acme", day+"-info.log"))
+	assert.Equal(t, 2, strings.Count(acme, "\n"))
+	assert.Contains(t, acme, "invoice sent")
+	assert.Contains(t, acme, "invoice paid")
+	assert.Contains(t, readPartition(t, filepath.Join(dir, "globex", day+"-warn.log")), "quota low")
+	assert.Contains(t, readPartition(t, filepath.Join(dir, "unknown", day+"-info.log")), "no tenant")
+
+	require.NoError(t, log.WriteRaw(InfoLevel, []byte("raw line")))
+	assert.Contains(t, readPartition(t, filepath.Join(dir, "unknown", day+"-info.log")), "raw line\n")
+}
+
+func TestPartitionWriter_SanitizesValues(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewPartitionWriter(filepath.Join(dir, "{tenant}.log"), WithPartitionFallback("other"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: w})
+	log.Info("escape", String("tenant", "../../etc/passwd"))
+	log.Info("dots", String("tenant", ".."))
+	log.Info("number", Int("tenant", 42))
+
+	assert.Contains(t, readPartition(t, filepath.Join(dir, ".._.._etc_passwd.log")), "escape")
+	assert.Contains(t, readPartition(t, filepath.Join(dir, "other.log")), "dots")
+	assert.Contains(t, readPartition(t, filepath.Join(dir, "42.log")), "number")
+}
+
+func TestPartitionWriter_LRU(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewPartitionWriter(filepath.Join(dir, "{tenant}.log"), WithPartitionMaxOpen(2))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: w})
+	for _, tenant := range []string{"a", "b", "a", "c", "a", "b"} {
+		log.Info("entry", String("tenant", tenant))
+		assert.LessOrEqual(t, w.Open(), 2)
+	}
+
+	assert.Equal(t, 3, strings.Count(readPartition(t, filepath.Join(dir, "a.log")), "\n"))
+	assert.Equal(t, 2, strings.Count(readPartition(t, filepath.Join(dir, "b.log")), "\n"), "reopened for appending")
+	assert.Equal(t, 1, strings.Count(readPartition(t, filepath.Join(dir, "c.log")), "\n"))
+
+	require.NoError(t, w.Close())
+	assert.Zero(t, w.Open())
+}
+
+func TestPartitionWriter_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewPartitionWriter(filepath.Join(dir, "{tenant}.log"), WithPartitionRotation(100, 2))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: w})
+	for i := 0; i < 8; i++ {
+		log.Info("rotating entry", String("tenant", "acme"), Int("i", i))
+	}
+
+	path := filepath.Join(dir, "acme.log")
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(200), name)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "at most two backups")
+	assert.Contains(t, readPartition(t, path), `"i":7`)
+}
+
+func TestPartitionWriter_RotationFailure(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewPartitionWriter(filepath.Join(dir, "{tenant}.log"), WithPartitionRotation(10, 1))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	path := filepath.Join(dir, "acme.log")
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "busy"), 0o755))
+
+	r := &Record{Fields: []Field{String("tenant", "acme")}}
+	require.NoError(t, w.WriteRecord(r, []byte("first entry\n")))
+	require.Error(t, w.WriteRecord(r, []byte("second entry\n")))
+	assert.Zero(t, w.Open(), "a partition that failed to rotate is not kept open")
+
+	require.NoError(t, os.RemoveAll(path+".1"))
+	require.NoError(t, w.WriteRecord(r, []byte("third entry\n")))
+	assert.Equal(t, "first entry\n", readPartition(t, path+".1"))
+	assert.Equal(t, "third entry\n", readPartition(t, path))
+}
+
+func TestPartitionWriter_DateLocation(t *testing.T) {
+	dir := t.TempDir()
+	ts := time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)
+	r := &Record{Time: ts.In(time.FixedZone("CET", 3600))}
+
+	w, err := NewPartitionWriter(filepath.Join(dir, "utc-{date}.log"))
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRecord(r, []byte("entry\n")))
+	require.NoError(t, w.Close())
+	assert.FileExists(t, filepath.Join(dir, "utc-2024-03-01.log"))
+
+	w, err = NewPartitionWriter(filepath.Join(dir, "local-{date}.log"), WithPartitionLocation(time.FixedZone("CET", 3600)))
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRecord(r, []byte("entry\n")))
+	require.NoError(t, w.Close())
+	assert.FileExists(t, filepath.Join(dir, "local-2024-03-02.log"))
+}
+
+func TestNewPartitionWriter_InvalidPattern(t *testing.T) {
+	for _, pattern := range []string{"", "logs/app.log", "logs/{tenant.log", "logs/{}.log"} {
+		_, err := NewPartitionWriter(pattern)
+		assert.ErrorIs(t, err, ErrInvalidPartitionPattern, pattern)
+	}
+}
@@ -0,0 +1,478 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// PipelineSpec declares a complete logging topology: where entries come
+// from, how they are processed and where they are written. It is plain
+// data, so the same topology can be shared between services as a Go value
+// or as a JSON file read with ParsePipelineSpec, and built with
+// BuildPipeline.
+//
+// Example file:
+//
+//	{
+//	  "level": "info",
+//	  "inputs": [{"name": "worker", "type": "writer", "level": "warn"}],
+//	  "processors": [
+//	    {"type": "redact", "keys": ["*password*"]},
+//	    {"type": "enrich", "fields": {"service": "billing"}}
+//	  ],
+//	  "outputs": [
+//	    {"type": "stdout", "format": "text"},
+//	    {"type": "file", "path": "/var/log/billing.json", "format": "json", "buffer_size": 65536}
+//	  ]
+//	}
+type PipelineSpec struct {
+	// Level is the name of the minimum level of the pipeline, e.g. "info"
+	// or a level added with RegisterLevel. Defaults to "info".
+	Level string `json:"level,omitempty"`
+
+	// Inputs declares bridges feeding the pipeline in addition to the
+	// Logger API itself.
+	Inputs []InputSpec `json:"inputs,omitempty"`
+
+	// Processors declares the processing stages. Each type may appear
+	// once, except rate_limit once per level. Stages run in the fixed
+	// order of the logger pipeline, whatever order they are listed in:
+	// sampling, rate limiting, redaction, entry hashing and key renaming,
+	// with the fields of enrich attached to every entry from the start.
+	Processors []ProcessorSpec `json:"processors,omitempty"`
+
+	// Outputs declares the sinks. At least one is required.
+	Outputs []OutputSpec `json:"outputs"`
+}
+
+// InputSpec declares a bridge into the pipeline.
+type InputSpec struct {
+	// Name identifies the input for Pipeline.Input.
+	Name string `json:"name"`
+
+	// Type is one of:
+	//
+	//	writer  an io.Writer logging every line, see Logger.Writer
+	//	stdlog  redirects the standard library's log package
+	Type string `json:"type"`
+
+	// Level is the name of the level lines are logged at. Defaults to
+	// "info".
+	Level string `json:"level,omitempty"`
+
+	// JSON parses lines holding a JSON object into fields, see
+	// WithJSONLines.
+	JSON bool `json:"json,omitempty"`
+}
+
+// ProcessorSpec declares a processing stage. Which fields apply depends on
+// Type:
+//
+//	redact      Keys, Patterns (regular expressions), see Config.RedactKeys
+//	sample      Keys, First, Thereafter, Window, MaxValues, see SamplingConfig
+//	rate_limit  Level, EventsPerSecond, Burst, see RateLimit; may repeat
+//	            for different levels
+//	enrich      Fields added to every entry
+//	rename      Rename, see Config.KeyMap
+//	entry_hash  no settings, see Config.EntryHash
+type ProcessorSpec struct {
+	Type string `json:"type"`
+
+	Keys     []string `json:"keys,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+
+	First      int    `json:"first,omitempty"`
+	Thereafter int    `json:"thereafter,omitempty"`
+	Window     string `json:"window,omitempty"`
+	MaxValues  int    `json:"max_values,omitempty"`
+
+	Level           string  `json:"level,omitempty"`
+	EventsPerSecond float64 `json:"events_per_second,omitempty"`
+	Burst           int     `json:"burst,omitempty"`
+
+	Fields map[string]any    `json:"fields,omitempty"`
+	Rename map[string]string `json:"rename,omitempty"`
+}
+
+// OutputSpec declares a sink. Which fields apply depends on Type:
+//
+//	stdout, stderr  no settings
+//	file            Path, opened for appending
+//	wal             Path, see OpenWAL
+//	partition       Path as a pattern, MaxOpen, MaxSize, MaxBackups, see
+//	                NewPartitionWriter
+//	net             Network, Address, see NewNetWriter
+//	syslog          Network, Address, AppName, see NewSyslogWriter
+type OutputSpec struct {
+	Type string `json:"type"`
+
+	// Level is the name of the minimum level of the sink. Defaults to the
+	// level of the pipeline.
+	Level string `json:"level,omitempty"`
+
+	// Format is one of "text", "json", "gelf", "ecs", "gcp", "datadog" or
+	// "console". Defaults to "text".
+	Format string `json:"format,omitempty"`
+
+	BufferSize    int    `json:"buffer_size,omitempty"`
+	FlushInterval string `json:"flush_interval,omitempty"`
+
+	// Rename renames keys for this sink in place of a rename stage, see
+	// SinkConfig.KeyMap.
+	Rename map[string]string `json:"rename,omitempty"`
+
+	Path       string `json:"path,omitempty"`
+	MaxOpen    int    `json:"max_open,omitempty"`
+	MaxSize    int64  `json:"max_size,omitempty"`
+	MaxBackups int    `json:"max_backups,omitempty"`
+
+	Network string `json:"network,omitempty"`
+	Address string `json:"address,omitempty"`
+	AppName string `json:"app_name,omitempty"`
+}
+
+// formatNames maps the format names of OutputSpec to formats.
+var formatNames = map[string]Format{
+	"text":    TextFormat,
+	"json":    JSONFormat,
+	"gelf":    GELFFormat,
+	"ecs":     ECSFormat,
+	"gcp":     CloudLoggingFormat,
+	"datadog": DatadogFormat,
+	"console": ConsoleFormat,
+}
+
+// ParsePipelineSpec decodes a JSON pipeline description. Unknown keys are
+// rejected, so typos do not silently drop a stage.
+func ParsePipelineSpec(data []byte) (PipelineSpec, error) {
+	var spec PipelineSpec
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(&spec); err != nil {
+		return PipelineSpec{}, fmt.Errorf("logger: pipeline: %w", err)
+	}
+	return spec, nil
+}
+
+// Pipeline is a logger built from a PipelineSpec together with the
+// resources it owns: its inputs and the files and connections of its
+// outputs.
+type Pipeline struct {
+	logger  *Logger
+	inputs  map[string]io.Writer
+	closers []io.Closer
+
+	stdlogOutput io.Writer
+	stdlogFlags  int
+	stdlogSet    bool
+}
+
+// BuildPipeline opens the outputs of spec and returns the pipeline
+// connecting them. The configuration is validated like NewE does; on error
+// every resource opened so far is closed again.
+//
+// Example:
+//
+//	spec, err := logger.ParsePipelineSpec(data)
+//	if err != nil {
+//		return err
+//	}
+//	p, err := logger.BuildPipeline(spec)
+//	if err != nil {
+//		return err
+//	}
+//	defer p.Close()
+//
+//	log := p.Logger()
+func BuildPipeline(spec PipelineSpec) (_ *Pipeline, err error) {
+	p := &Pipeline{inputs: make(map[string]io.Writer)}
+	defer func() {
+		if err != nil {
+			_ = p.closeOutputs()
+			err = fmt.Errorf("logger: pipeline: %w", err)
+		}
+	}()
+
+	level, err := parseLevelName(spec.Level)
+	if err != nil {
+		return nil, err
+	}
+	config := Config{Level: level}
+
+	if len(spec.Outputs) == 0 {
+		return nil, errors.New("no outputs")
+	}
+	for i, out := range spec.Outputs {
+		sink, err := p.buildOutput(out, level)
+		if err != nil {
+			return nil, fmt.Errorf("outputs[%d]: %w", i, err)
+		}
+		config.Outputs = append(config.Outputs, sink)
+	}
+
+	var enrich []Field
+	seen := make(map[string]bool)
+	for i, proc := range spec.Processors {
+		if seen[proc.Type] && proc.Type != "rate_limit" {
+			return nil, fmt.Errorf("processors[%d]: duplicate %s processor", i, proc.Type)
+		}
+		seen[proc.Type] = true
+
+		fields, err := applyProcessor(&config, proc)
+		if err != nil {
+			return nil, fmt.Errorf("processors[%d]: %w", i, err)
+		}
+		enrich = append(enrich, fields...)
+	}
+
+	l, err := NewE(config)
+	if err != nil {
+		return nil, err
+	}
+	p.logger = l.With(enrich...)
+
+	for i, in := range spec.Inputs {
+		if err := p.buildInput(in); err != nil {
+			_ = p.Close()
+			return nil, fmt.Errorf("inputs[%d]: %w", i, err)
+		}
+	}
+
+	return p, nil
+}
+
+// parseLevelName returns the level named name, or InfoLevel for "".
+func parseLevelName(name string) (Level, error) {
+	if name == "" {
+		return InfoLevel, nil
+	}
+	return ParseLevel(name)
+}
+
+// buildOutput opens the output of spec.
+func (p *Pipeline) buildOutput(spec OutputSpec, level Level) (SinkConfig, error) {
+	cfg := SinkConfig{Level: level, BufferSize: spec.BufferSize, KeyMap: spec.Rename}
+
+	if spec.Level != "" {
+		var err error
+		if cfg.Level, err = parseLevelName(spec.Level); err != nil {
+			return SinkConfig{}, err
+		}
+	}
+	if spec.Format != "" {
+		format, ok := formatNames[spec.Format]
+		if !ok {
+			return SinkConfig{}, fmt.Errorf("unknown format %q", spec.Format)
+		}
+		cfg.Format = format
+	}
+	if spec.FlushInterval != "" {
+		d, err := time.ParseDuration(spec.FlushInterval)
+		if err != nil {
+			return SinkConfig{}, fmt.Errorf("flush_interval: %w", err)
+		}
+		cfg.FlushInterval = d
+	}
+
+	var (
+		w   io.Writer
+		err error
+	)
+	switch spec.Type {
+	case "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	case "file":
+		w, err = os.OpenFile(spec.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	case "wal":
+		w, err = OpenWAL(spec.Path)
+	case "partition":
+		opts := []PartitionOption{WithPartitionRotation(spec.MaxSize, spec.MaxBackups)}
+		if spec.MaxOpen > 0 {
+			opts = append(opts, WithPartitionMaxOpen(spec.MaxOpen))
+		}
+		w, err = NewPartitionWriter(spec.Path, opts...)
+	case "net":
+		w, err = NewNetWriter(spec.Network, spec.Address)
+	case "syslog":
+		w, err = NewSyslogWriter(spec.Network, spec.Address, SyslogConfig{AppName: spec.AppName})
+	default:
+		return SinkConfig{}, fmt.Errorf("unknown output type %q", spec.Type)
+	}
+	if err != nil {
+		return SinkConfig{}, err
+	}
+
+	if c, ok := w.(io.Closer); ok && w != os.Stdout && w != os.Stderr {
+		p.closers = append(p.closers, c)
+	}
+	cfg.Output = w
+	return cfg, nil
+}
+
+// applyProcessor adds the stage spec to config and returns the fields of
+// an enrich stage.
+func applyProcessor(config *Config, spec ProcessorSpec) ([]Field, error) {
+	switch spec.Type {
+	case "redact":
+		config.RedactKeys = spec.Keys
+		for _, pattern := range spec.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			config.RedactValuePatterns = append(config.RedactValuePatterns, re)
+		}
+	case "sample":
+		sampling := &SamplingConfig{
+			Keys:       spec.Keys,
+			First:      spec.First,
+			Thereafter: spec.Thereafter,
+			MaxValues:  spec.MaxValues,
+		}
+		if spec.Window != "" {
+			d, err := time.ParseDuration(spec.Window)
+			if err != nil {
+				return nil, fmt.Errorf("window: %w", err)
+			}
+			sampling.Window = d
+		}
+		config.Sampling = sampling
+	case "rate_limit":
+		level, err := parseLevelName(spec.Level)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := config.RateLimit[level]; ok {
+			return nil, fmt.Errorf("duplicate rate_limit for level %s", level)
+		}
+		if config.RateLimit == nil {
+			config.RateLimit = make(map[Level]RateLimit)
+		}
+		config.RateLimit[level] = RateLimit{EventsPerSecond: spec.EventsPerSecond, Burst: spec.Burst}
+	case "enrich":
+		fields := make([]Field, 0, len(spec.Fields))
+		for key, value := range spec.Fields {
+			fields = append(fields, Field{Key: key, Value: specFieldValue(value)})
+		}
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+		return fields, nil
+	case "rename":
+		config.KeyMap = spec.Rename
+	case "entry_hash":
+		config.EntryHash = true
+	default:
+		return nil, fmt.Errorf("unknown processor type %q", spec.Type)
+	}
+	return nil, nil
+}
+
+// specFieldValue converts a value of ProcessorSpec.Fields, keeping JSON
+// integers integral.
+func specFieldValue(value any) any {
+	if n, ok := value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		f, _ := n.Float64()
+		return f
+	}
+	if f, ok := value.(float64); ok && f == float64(int64(f)) {
+		return int64(f)
+	}
+	return fieldValue(value)
+}
+
+// buildInput creates the input of spec.
+func (p *Pipeline) buildInput(spec InputSpec) error {
+	if spec.Name == "" {
+		return errors.New("missing name")
+	}
+	if _, ok := p.inputs[spec.Name]; ok {
+		return fmt.Errorf("duplicate input name %q", spec.Name)
+	}
+	level, err := parseLevelName(spec.Level)
+	if err != nil {
+		return err
+	}
+
+	var opts []WriterOption
+	if spec.JSON {
+		opts = append(opts, WithJSONLines())
+	}
+	w := p.logger.Writer(level, opts...)
+
+	switch spec.Type {
+	case "writer":
+	case "stdlog":
+		if p.stdlogSet {
+			return errors.New("duplicate stdlog input")
+		}
+		p.stdlogOutput, p.stdlogFlags, p.stdlogSet = stdlog.Writer(), stdlog.Flags(), true
+		stdlog.SetOutput(w)
+		stdlog.SetFlags(0)
+	default:
+		return fmt.Errorf("unknown input type %q", spec.Type)
+	}
+
+	p.inputs[spec.Name] = w
+	return nil
+}
+
+// Logger returns the logger of the pipeline, which carries the fields of
+// an enrich processor.
+func (p *Pipeline) Logger() *Logger {
+	return p.logger
+}
+
+// Input returns the writer of the input named name, or nil if there is no
+// such input.
+func (p *Pipeline) Input(name string) io.Writer {
+	return p.inputs[name]
+}
+
+// Close restores the standard logger of a stdlog input, flushes pending
+// input lines, closes the logger and then the outputs opened by
+// BuildPipeline.
+func (p *Pipeline) Close() error {
+	if p.stdlogSet {
+		stdlog.SetOutput(p.stdlogOutput)
+		stdlog.SetFlags(p.stdlogFlags)
+		p.stdlogSet = false
+	}
+
+	names := make([]string, 0, len(p.inputs))
+	for name := range p.inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if c, ok := p.inputs[name].(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	errs = append(errs, p.logger.Close(), p.closeOutputs())
+	return errors.Join(errs...)
+}
+
+// closeOutputs closes the outputs in reverse order of opening.
+func (p *Pipeline) closeOutputs() error {
+	var errs []error
+	for i := len(p.closers) - 1; i >= 0; i-- {
+		errs = append(errs, p.closers[i].Close())
+	}
+	p.closers = nil
+	return errors.Join(errs...)
+}
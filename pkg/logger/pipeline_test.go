@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"encoding/json"
+	stdlog "log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPipeline(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "app.json")
+	errorPath := filepath.Join(dir, "errors.log")
+
+	spec, err := ParsePipelineSpec([]byte(`{
+		"level": "debug",
+		"inputs": [
+			{"name": "worker", "type": "writer", "level": "warn", "json": true},
+			{"name": "std", "type": "stdlog"}
+		],
+		"processors": [
+			{"type": "redact", "keys": ["*password*"], "patterns": ["\\d{4}-\\d{4}"]},
+			{"type": "enrich", "fields": {"service": "billing", "shard": 3}},
+			{"type": "rename", "rename": {"service": "svc"}},
+			{"type": "rate_limit", "level": "debug", "events_per_second": 1000, "burst": 100}
+		],
+		"outputs": [
+			{"type": "file", "path": "` + jsonPath + `", "format": "json", "buffer_size": 4096},
+			{"type": "file", "path": "` + errorPath + `", "level": "error"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	p, err := BuildPipeline(spec)
+	require.NoError(t, err)
+
+	log := p.Logger()
+	log.Debug("login", String("password", "hunter2"), String("card", "1234-5678"))
+	log.Error("charge failed")
+	_, _ = p.Input("worker").Write([]byte(`{"msg":"job stuck","job":42}` + "\n"))
+	stdlog.Print("from the standard logger")
+	assert.Nil(t, p.Input("missing"))
+
+	require.NoError(t, p.Close())
+	assert.Equal(t, os.Stderr, stdlog.Writer(), "the standard logger is restored")
+
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 4)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "DEBUG", entry["level"])
+	assert.Equal(t, "billing", entry["svc"])
+	assert.Equal(t, float64(3), entry["shard"])
+	assert.Equal(t, RedactedValue, entry["password"])
+	assert.Equal(t, RedactedValue, entry["card"])
+
+	assert.Contains(t, lines[2], `"level":"WARN","message":"job stuck"`)
+	assert.Contains(t, lines[3], `"message":"from the standard logger"`)
+
+	errorsOnly, err := os.ReadFile(errorPath)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(errorsOnly), "\n"))
+	assert.Contains(t, string(errorsOnly), "ERROR charge failed")
+}
+
+func TestBuildPipeline_Errors(t *testing.T) {
+	dir := t.TempDir()
+	file := OutputSpec{Type: "file", Path: filepath.Join(dir, "app.log")}
+
+	tests := map[string]PipelineSpec{
+		"no outputs":        {},
+		"unknown level":     {Level: "loud", Outputs: []OutputSpec{file}},
+		"unknown output":    {Outputs: []OutputSpec{{Type: "kafka"}}},
+		"unknown format":    {Outputs: []OutputSpec{{Type: "stdout", Format: "xml"}}},
+		"bad interval":      {Outputs: []OutputSpec{{Type: "stdout", BufferSize: 1, FlushInterval: "soon"}}},
+		"unknown processor": {Outputs: []OutputSpec{file}, Processors: []ProcessorSpec{{Type: "compress"}}},
+		"duplicate stage":   {Outputs: []OutputSpec{file}, Processors: []ProcessorSpec{{Type: "entry_hash"}, {Type: "entry_hash"}}},
+		"bad pattern":       {Outputs: []OutputSpec{file}, Processors: []ProcessorSpec{{Type: "redact", Patterns: []string{"("}}}},
+		"invalid config":    {Outputs: []OutputSpec{{Type: "stdout", FlushInterval: "1s"}}},
+		"unnamed input":     {Outputs: []OutputSpec{file}, Inputs: []InputSpec{{Type: "writer"}}},
+		"unknown input":     {Outputs: []OutputSpec{file}, Inputs: []InputSpec{{Name: "x", Type: "kafka"}}},
+	}
+	for name, spec := range tests {
+		_, err := BuildPipeline(spec)
+		assert.Error(t, err, name)
+	}
+
+	_, err := ParsePipelineSpec([]byte(`{"outputs": [{"type": "stdout", "colour": "red"}]}`))
+	assert.ErrorContains(t, err, "unknown field")
+}
@@ -0,0 +1,44 @@
+package logger
+
+import "time"
+
+// DevelopmentConfig returns the configuration of NewDevelopment: every
+// entry from DebugLevel, written to os.Stdout in ConsoleFormat with the
+// caller, and stack traces on WarnLevel and above.
+func DevelopmentConfig() Config {
+	return Config{
+		Level:           DebugLevel,
+		Format:          ConsoleFormat,
+		AddCaller:       true,
+		AddStacktrace:   true,
+		StacktraceLevel: WarnLevel,
+	}
+}
+
+// ProductionConfig returns the configuration of NewProduction: entries
+// from InfoLevel, written to os.Stdout in JSONFormat, with repetitive
+// entries below WarnLevel sampled to the first 100 per second and every
+// 100th thereafter.
+func ProductionConfig() Config {
+	return Config{
+		Level:    InfoLevel,
+		Format:   JSONFormat,
+		Sampling: &SamplingConfig{First: 100, Thereafter: 100, Window: time.Second},
+	}
+}
+
+// NewDevelopment creates a logger for local development from
+// DevelopmentConfig. Options are applied on top of the preset:
+//
+//	log, err := logger.NewDevelopment(logger.WithOutput(os.Stderr))
+func NewDevelopment(opts ...Option) (*Logger, error) {
+	return NewWithOptions(append([]Option{WithConfig(DevelopmentConfig())}, opts...)...)
+}
+
+// NewProduction creates a logger for production from ProductionConfig.
+// Options are applied on top of the preset:
+//
+//	log, err := logger.NewProduction(logger.WithFields(logger.String("service", "billing")))
+func NewProduction(opts ...Option) (*Logger, error) {
+	return NewWithOptions(append([]Option{WithConfig(ProductionConfig())}, opts...)...)
+}
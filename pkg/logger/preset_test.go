@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDevelopment(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log, err := NewDevelopment(WithOutput(buf), WithTimestampFormat(TimestampDisabled))
+	require.NoError(t, err)
+
+	line := callerLine() + 1
+	log.Debug("starting")
+	assert.Equal(t, "DEBUG starting                                 caller=logger/preset_test.go:"+strconv.Itoa(line)+"\n", buf.String())
+
+	buf.Reset()
+	log.Warn("slow")
+	lines := strings.Split(buf.String(), "\n")
+	require.Greater(t, len(lines), 3)
+	assert.True(t, strings.HasPrefix(lines[0], "WARN  slow"), lines[0])
+	assert.Equal(t, "  "+StacktraceKey+":", lines[1])
+	assert.Contains(t, lines[2], "logger.TestNewDevelopment", "the stack starts at the caller")
+}
+
+func TestNewProduction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log, err := NewProduction(WithOutput(buf))
+	require.NoError(t, err)
+
+	log.Debug("dropped")
+	for range 150 {
+		log.Info("repeated")
+	}
+	for range 150 {
+		log.Error("failed")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 100+150, "errors are not sampled")
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "repeated", entry[MessageKey])
+	assert.NotContains(t, entry, CallerKey)
+}
+
+func TestConfig_AddStacktrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf, AddStacktrace: true, StacktraceLevel: ErrorLevel})
+
+	log.Warn("no stack")
+	log.Error("with stack")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.NotContains(t, lines[0], StacktraceKey)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	stack, _ := entry[StacktraceKey].(string)
+	assert.True(t, strings.HasPrefix(stack, "github.com/barnowlsnest/go-logslib/pkg/logger.TestConfig_AddStacktrace\n\t"), stack)
+	assert.Contains(t, stack, "preset_test.go:")
+
+	assert.ErrorContains(t, Config{AddStacktrace: true, StacktraceLevel: Level(3)}.Validate(), "unknown StacktraceLevel")
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_PrettyJSON(t *testing.T) {
+	ts := time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC)
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:      InfoLevel,
+		Format:     JSONFormat,
+		Output:     buf,
+		Clock:      func() time.Time { return ts },
+		PrettyJSON: true,
+	})
+
+	log.Info("user logged in", Int("user_id", 7))
+	assert.Equal(t, `{
+  "timestamp": "2024-01-20T15:04:05Z",
+  "level": "INFO",
+  "message": "user logged in",
+  "user_id": 7
+}
+`, buf.String())
+}
+
+func TestConfig_PrettyJSONSinks(t *testing.T) {
+	text, ecs := &bytes.Buffer{}, &bytes.Buffer{}
+	log := New(Config{
+		Level: InfoLevel,
+		Outputs: []SinkConfig{
+			{Output: text, Format: TextFormat},
+			{Output: ecs, Format: ECSFormat},
+		},
+		TimestampFormat: TimestampDisabled,
+		PrettyJSON:      true,
+	})
+
+	log.Info("hi")
+	assert.Equal(t, "INFO hi\n", text.String(), "text sinks are unaffected")
+	assert.Contains(t, ecs.String(), "{\n  ")
+	assert.Equal(t, byte('\n'), ecs.Bytes()[ecs.Len()-1])
+}
+
+func TestConfig_PrettyJSONLineOutputs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:      InfoLevel,
+		Format:     JSONFormat,
+		Output:     NewIntegrityWriter(buf),
+		PrettyJSON: true,
+	})
+
+	log.Info("hi", Int("n", 1))
+	log.Info("again")
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"), "line outputs get one line per entry")
+}
+
+func TestIsJSONFormat(t *testing.T) {
+	assert.True(t, isJSONFormat(JSONFormat))
+	assert.True(t, isJSONFormat(DatadogFormat))
+	assert.False(t, isJSONFormat(GELFFormat))
+	assert.False(t, isJSONFormat(ConsoleFormat))
+}
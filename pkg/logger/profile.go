@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnknownProfile is returned by SetProfile for a profile that is not
+// one of the predefined ones.
+var ErrUnknownProfile = errors.New("logger: unknown profile")
+
+// Profile names a bundle of operating settings for buffering, async
+// logging, sampling, flushing and syncing, so services pick a tested
+// combination instead of tuning each option.
+type Profile string
+
+const (
+	// ProfileThroughput favors volume: a large async queue, 64 KiB buffers
+	// flushed every second and sampling of repetitive entries below WARN.
+	ProfileThroughput Profile = "throughput"
+
+	// ProfileDurability favors never losing an entry: synchronous,
+	// unbuffered writes synced to stable storage, and no sampling.
+	ProfileDurability Profile = "durability"
+
+	// ProfileLowMemory favors a small footprint: a small async queue and
+	// buffers, aggressive sampling below WARN and a MemoryBudget.
+	ProfileLowMemory Profile = "low-memory"
+
+	// ProfileDebug writes every entry immediately and completely:
+	// synchronous, unbuffered and without sampling.
+	ProfileDebug Profile = "debug"
+)
+
+// profileSettings are the values a Profile bundles.
+type profileSettings struct {
+	asyncQueueSize int // zero disables async logging
+	bufferSize     int
+	flushInterval  time.Duration
+	syncWrites     bool
+	sampling       *SamplingConfig
+	memory         *MemoryBudget
+}
+
+var profiles = map[Profile]profileSettings{
+	ProfileThroughput: {
+		asyncQueueSize: 8192,
+		bufferSize:     64 << 10,
+		flushInterval:  time.Second,
+		sampling:       &SamplingConfig{First: 100, Thereafter: 100, Window: time.Second},
+	},
+	ProfileDurability: {
+		syncWrites: true,
+	},
+	ProfileLowMemory: {
+		asyncQueueSize: 256,
+		bufferSize:     4 << 10,
+		flushInterval:  time.Second,
+		sampling:       &SamplingConfig{First: 10, Thereafter: 100, Window: time.Second, MaxValues: 256},
+		memory:         &MemoryBudget{MaxBufferBytes: 256 << 10, MaxQueueBytes: 512 << 10},
+	},
+	ProfileDebug: {},
+}
+
+// configProfile returns the profile settings set explicitly in config.
+func configProfile(config Config) profileSettings {
+	return profileSettings{
+		asyncQueueSize: config.AsyncQueueSize,
+		bufferSize:     config.BufferSize,
+		flushInterval:  config.FlushInterval,
+		syncWrites:     config.SyncWrites,
+		sampling:       config.Sampling,
+		memory:         config.MemoryBudget,
+	}
+}
+
+// override returns p with the settings set in explicit taken from
+// explicit. A false syncWrites counts as unset.
+func (p profileSettings) override(explicit profileSettings) profileSettings {
+	if explicit.asyncQueueSize != 0 {
+		p.asyncQueueSize = explicit.asyncQueueSize
+	}
+	if explicit.bufferSize != 0 {
+		p.bufferSize = explicit.bufferSize
+	}
+	if explicit.flushInterval != 0 {
+		p.flushInterval = explicit.flushInterval
+	}
+	if explicit.syncWrites {
+		p.syncWrites = true
+	}
+	if explicit.sampling != nil {
+		p.sampling = explicit.sampling
+	}
+	if explicit.memory != nil {
+		p.memory = explicit.memory
+	}
+	return p
+}
+
+// applyProfile fills the settings of config.Profile into the fields of
+// config that are not set. Unknown profiles are ignored.
+func applyProfile(config Config) Config {
+	p, ok := profiles[config.Profile]
+	if !ok {
+		return config
+	}
+
+	p = p.override(configProfile(config))
+	config.AsyncQueueSize = p.asyncQueueSize
+	config.BufferSize = p.bufferSize
+	config.FlushInterval = p.flushInterval
+	config.SyncWrites = p.syncWrites
+	config.Sampling = p.sampling
+	config.MemoryBudget = p.memory
+	return config
+}
+
+// SetProfile switches the logger to another profile at runtime, e.g. to
+// ProfileDebug while investigating an incident. It replaces the buffering,
+// flush interval and sync policy of Config.Output and Config.ErrorOutput,
+// the sampling and whether entries go through the async queue. Buffered
+// entries are flushed first. Settings passed explicitly to New keep their
+// values, as they do for Config.Profile.
+//
+// Sinks added through Config.Outputs or AddSink keep their settings, and
+// the MemoryBudget only applies from New. The async queue is only created
+// by New, so a logger whose settings disable async logging stays
+// synchronous.
+func (l *Logger) SetProfile(profile Profile) error {
+	p, ok := profiles[profile]
+	if !ok {
+		return ErrUnknownProfile
+	}
+	p = p.override(l.explicit)
+
+	l.profileMu.Lock()
+	defer l.profileMu.Unlock()
+
+	if l.explicit.sampling == nil {
+		if old := l.sampler.Swap(newSampler(p.sampling)); old != nil {
+			l.sampled.Add(old.dropped.Load())
+		}
+	}
+
+	if l.async != nil {
+		bypass := p.asyncQueueSize == 0
+		l.bypassAsync.Store(bypass)
+		if bypass {
+			l.async.drain()
+		}
+	}
+
+	for _, s := range *l.sinks.Load() {
+		if s.profiled {
+			s.configure(p.bufferSize, p.flushInterval, p.syncWrites)
+		}
+	}
+
+	l.profile.Store(&profile)
+	return nil
+}
+
+// Profile returns the active profile, or "" when none was set.
+func (l *Logger) Profile() Profile {
+	if p := l.profile.Load(); p != nil {
+		return *p
+	}
+	return ""
+}
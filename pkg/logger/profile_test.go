@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a concurrency-safe buffer counting Sync calls.
+type syncBuffer struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	syncs int
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Sync() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.syncs++
+	return nil
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Syncs() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.syncs
+}
+
+func TestApplyProfile_ExplicitOptionsWin(t *testing.T) {
+	cfg := applyProfile(Config{Profile: ProfileThroughput, BufferSize: 1024})
+
+	assert.Equal(t, 1024, cfg.BufferSize)
+	assert.Equal(t, 8192, cfg.AsyncQueueSize)
+	assert.Equal(t, time.Second, cfg.FlushInterval)
+	assert.NotNil(t, cfg.Sampling)
+
+	assert.Equal(t, Config{Profile: "unknown"}, applyProfile(Config{Profile: "unknown"}))
+}
+
+func TestProfile_SwitchAtRuntime(t *testing.T) {
+	out := &syncBuffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: out, Profile: ProfileThroughput})
+	defer logger.Close()
+
+	assert.Equal(t, ProfileThroughput, logger.Profile())
+	assert.False(t, logger.bypassAsync.Load())
+
+	logger.Info("buffered")
+	logger.async.drain()
+	assert.Empty(t, out.String(), "throughput buffers entries")
+
+	require.NoError(t, logger.SetProfile(ProfileDebug))
+	assert.Equal(t, ProfileDebug, logger.Profile())
+	assert.Contains(t, out.String(), "INFO buffered", "switching flushes buffered entries")
+
+	logger.Info("immediate")
+	assert.Contains(t, out.String(), "INFO immediate", "debug writes synchronously and unbuffered")
+	assert.Zero(t, out.Syncs())
+
+	require.NoError(t, logger.SetProfile(ProfileDurability))
+	logger.Info("durable")
+	assert.Contains(t, out.String(), "INFO durable")
+	assert.Equal(t, 1, out.Syncs())
+
+	assert.ErrorIs(t, logger.SetProfile("reckless"), ErrUnknownProfile)
+	assert.Equal(t, ProfileDurability, logger.Profile())
+}
+
+func TestProfile_SynchronousWithoutQueue(t *testing.T) {
+	out := &syncBuffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: out, Profile: ProfileDurability})
+	defer logger.Close()
+
+	assert.Nil(t, logger.async, "profiles without async logging start no queue")
+
+	require.NoError(t, logger.SetProfile(ProfileThroughput))
+	logger.Info("direct")
+	logger.Flush()
+	assert.Contains(t, out.String(), "INFO direct")
+}
+
+func TestProfile_SwitchKeepsExplicitOptions(t *testing.T) {
+	out := &syncBuffer{}
+	logger := New(Config{
+		Level:          InfoLevel,
+		Format:         TextFormat,
+		Output:         out,
+		Profile:        ProfileThroughput,
+		AsyncQueueSize: 16,
+		BufferSize:     1 << 10,
+		Sampling:       &SamplingConfig{First: 1, Window: time.Hour},
+	})
+	defer logger.Close()
+	sampler := logger.sampler.Load()
+
+	require.NoError(t, logger.SetProfile(ProfileDebug))
+	assert.Same(t, sampler, logger.sampler.Load(), "explicit sampling is kept")
+	assert.False(t, logger.bypassAsync.Load(), "explicit async logging is kept")
+
+	logger.Info("buffered")
+	logger.async.drain()
+	assert.Empty(t, out.String(), "explicit buffering is kept")
+	logger.Flush()
+	assert.Contains(t, out.String(), "INFO buffered")
+}
+
+func TestProfile_SamplingReplaced(t *testing.T) {
+	out := &syncBuffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: out, Profile: ProfileLowMemory})
+	defer logger.Close()
+
+	for i := 0; i < 20; i++ {
+		logger.Info("repeated")
+	}
+	logger.Flush()
+	sampled := logger.Stats().Sampled
+	assert.Equal(t, uint64(10), sampled)
+
+	require.NoError(t, logger.SetProfile(ProfileDebug))
+	for i := 0; i < 20; i++ {
+		logger.Info("repeated")
+	}
+	assert.Equal(t, sampled, logger.Stats().Sampled, "debug does not sample")
+}
+
+func TestFlushInterval(t *testing.T) {
+	out := &syncBuffer{}
+	logger := New(Config{
+		Level:         InfoLevel,
+		Format:        TextFormat,
+		Output:        out,
+		BufferSize:    4096,
+		FlushInterval: 20 * time.Millisecond,
+	})
+	defer logger.Close()
+
+	logger.Info("eventually")
+	assert.Empty(t, out.String())
+	assert.Eventually(t, func() bool {
+		return bytes.Contains([]byte(out.String()), []byte("INFO eventually"))
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSyncWrites_BufferedSyncsPerFlush(t *testing.T) {
+	out := &syncBuffer{}
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: out, BufferSize: 4096, SyncWrites: true})
+
+	logger.Info("one")
+	logger.Info("two")
+	assert.Zero(t, out.Syncs())
+
+	logger.Flush()
+	assert.Equal(t, 1, out.Syncs())
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitReportInterval is used when Config.RateLimitReportInterval
+// is not set.
+const defaultRateLimitReportInterval = time.Second
+
+// RateLimit configures a token bucket that caps the number of entries
+// emitted for a single level.
+type RateLimit struct {
+	// EventsPerSecond is the sustained number of entries allowed per second.
+	EventsPerSecond float64
+
+	// Burst is the number of entries that may be emitted at once before
+	// the sustained rate applies. Values below 1 are treated as 1.
+	Burst int
+}
+
+// tokenBucket tracks the budget of a single level.
+type tokenBucket struct {
+	mu         sync.Mutex
+	rate       float64
+	burst      float64
+	tokens     float64
+	last       time.Time
+	suppressed uint64
+	lastReport time.Time
+
+	// timer writes the summary one report interval after the first
+	// suppressed entry, so a flood followed by silence is still reported.
+	// episode counts the summaries taken, so a stale timer finds nothing
+	// to write. logger is the logger of the last suppressed entry.
+	timer   *time.Timer
+	episode uint64
+	logger  *Logger
+}
+
+// rateLimiter holds one token bucket per limited level. The map is built
+// once in New and only read afterwards.
+type rateLimiter struct {
+	buckets  map[Level]*tokenBucket
+	interval time.Duration
+}
+
+func newRateLimiter(limits map[Level]RateLimit, interval time.Duration) *rateLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	if interval <= 0 {
+		interval = defaultRateLimitReportInterval
+	}
+
+	now := time.Now()
+	rl := &rateLimiter{
+		buckets:  make(map[Level]*tokenBucket, len(limits)),
+		interval: interval,
+	}
+
+	for level, limit := range limits {
+		burst := float64(limit.Burst)
+		if burst < 1 {
+			burst = 1
+		}
+		rl.buckets[level] = &tokenBucket{
+			rate:       limit.EventsPerSecond,
+			burst:      burst,
+			tokens:     burst,
+			last:       now,
+			lastReport: now,
+		}
+	}
+
+	return rl
+}
+
+// allow reports whether an entry of l at level fits into the budget. When
+// it does and entries were suppressed since the last report, at least one
+// report interval ago, it also returns the number of suppressed entries so
+// the caller can emit a summary.
+func (rl *rateLimiter) allow(l *Logger, level Level, now time.Time) (ok bool, suppressed uint64) {
+	b, found := rl.buckets[level]
+	if !found {
+		return true, 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		b.suppressed++
+		b.logger = l
+		if b.suppressed == 1 {
+			episode := b.episode
+			b.timer = time.AfterFunc(rl.interval, func() { b.expire(level, episode) })
+		}
+		return false, 0
+	}
+	b.tokens--
+
+	if b.suppressed > 0 && now.Sub(b.lastReport) >= rl.interval {
+		suppressed, _ = b.take(now)
+	}
+
+	return true, suppressed
+}
+
+// take returns and resets the number of suppressed entries and the logger
+// to report them with. It must be called with b.mu held.
+func (b *tokenBucket) take(now time.Time) (uint64, *Logger) {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.episode++
+	suppressed, l := b.suppressed, b.logger
+	b.suppressed = 0
+	b.logger = nil
+	b.lastReport = now
+	return suppressed, l
+}
+
+// expire writes the summary of level when no admitted entry or Flush wrote
+// it within the report interval.
+func (b *tokenBucket) expire(level Level, episode uint64) {
+	b.mu.Lock()
+	if b.episode != episode {
+		b.mu.Unlock()
+		return
+	}
+	suppressed, l := b.take(time.Now())
+	b.mu.Unlock()
+
+	if suppressed > 0 {
+		l.logSuppressed(level, suppressed)
+	}
+}
+
+// drain returns and resets the suppressed counters of all levels.
+func (rl *rateLimiter) drain(report func(level Level, suppressed uint64)) {
+	for level, b := range rl.buckets {
+		b.mu.Lock()
+		suppressed, _ := b.take(time.Now())
+		b.mu.Unlock()
+
+		if suppressed > 0 {
+			report(level, suppressed)
+		}
+	}
+}
+
+// logSuppressed emits the summary entry for entries dropped by the rate limiter.
+func (l *Logger) logSuppressed(level Level, suppressed uint64) {
+	msg := strconv.FormatUint(suppressed, 10) + " records suppressed"
+	l.emit(context.Background(), l.now(), level, msg, Field{Key: "suppressed", Value: int64(suppressed)})
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit_DropsOverBudget(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: buf,
+		RateLimit: map[Level]RateLimit{
+			InfoLevel: {EventsPerSecond: 0.001, Burst: 2},
+		},
+	})
+
+	for i := 0; i < 10; i++ {
+		logger.Info("flood")
+		logger.Warn("unlimited")
+	}
+
+	output := buf.String()
+	assert.Equal(t, 2, strings.Count(output, "flood"))
+	assert.Equal(t, 10, strings.Count(output, "unlimited"))
+}
+
+func TestRateLimit_ReportsSuppressedOnFlush(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: buf,
+		RateLimit: map[Level]RateLimit{
+			ErrorLevel: {EventsPerSecond: 0.001, Burst: 1},
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		logger.Error("flood")
+	}
+	logger.Flush()
+
+	output := buf.String()
+	assert.Contains(t, output, `"message":"4 records suppressed"`)
+	assert.Contains(t, output, `"suppressed":4`)
+
+	buf.Reset()
+	logger.Flush()
+	assert.Empty(t, buf.String())
+}
+
+func TestRateLimiter_ReportsWithNextAdmittedEntry(t *testing.T) {
+	rl := newRateLimiter(map[Level]RateLimit{
+		InfoLevel: {EventsPerSecond: 1, Burst: 1},
+	}, time.Second)
+	log := New(Config{Level: InfoLevel, Output: io.Discard})
+
+	start := time.Now()
+
+	ok, suppressed := rl.allow(log, InfoLevel, start)
+	assert.True(t, ok)
+	assert.Zero(t, suppressed)
+
+	ok, _ = rl.allow(log, InfoLevel, start)
+	assert.False(t, ok)
+	ok, _ = rl.allow(log, InfoLevel, start)
+	assert.False(t, ok)
+
+	ok, suppressed = rl.allow(log, InfoLevel, start.Add(2*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), suppressed)
+
+	ok, suppressed = rl.allow(log, DebugLevel, start)
+	assert.True(t, ok)
+	assert.Zero(t, suppressed)
+}
+
+func TestRateLimit_ReportsSuppressedAfterInterval(t *testing.T) {
+	buf := &syncBuffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: buf,
+		RateLimit: map[Level]RateLimit{
+			InfoLevel: {EventsPerSecond: 0.001, Burst: 1},
+		},
+		RateLimitReportInterval: 20 * time.Millisecond,
+	})
+
+	for i := 0; i < 5; i++ {
+		logger.With(String("worker", "a")).Info("flood")
+	}
+	assert.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "INFO 4 records suppressed worker=a suppressed=4")
+	}, time.Second, 5*time.Millisecond, "reported without another entry or Flush")
+
+	logger.Flush()
+	assert.Equal(t, 1, strings.Count(buf.String(), "records suppressed"))
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+)
+
+// ErrInvalidRawEntry is returned by WriteRaw for empty or multi-line input.
+var ErrInvalidRawEntry = errors.New("logger: raw entry must be a single non-empty line")
+
+// WriteRaw injects an already-encoded line into the pipeline, for bridges
+// that receive upstream logs and only need routing and buffering. The line
+// is written as is to every sink that accepts level, regardless of the sink
+// format; a trailing newline is added when missing.
+//
+// Hooks, rate limiting, redaction and key remapping do not apply to raw
+// entries. Outputs implementing RecordWriter receive the line through
+// Write. preEncoded is not retained after WriteRaw returns.
+//
+// Example:
+//
+//	for scanner.Scan() {
+//		if err := log.WriteRaw(logger.InfoLevel, scanner.Bytes()); err != nil {
+//			// skip malformed input
+//		}
+//	}
+func (l *Logger) WriteRaw(level Level, preEncoded []byte) error {
+	line := bytes.TrimSuffix(preEncoded, []byte{'\n'})
+	if len(line) == 0 || bytes.IndexByte(line, '\n') >= 0 {
+		return ErrInvalidRawEntry
+	}
+
+	if !l.enabled(level) {
+		return nil
+	}
+	if l.tee != nil {
+		var err error
+		for _, t := range l.tee {
+			err = errors.Join(err, t.WriteRaw(level, preEncoded))
+		}
+		return err
+	}
+
+	r := l.records.Get().(*Record)
+	r.Time = l.now()
+	r.Level = level
+	r.raw = append(append(r.raw[:0], line...), '\n')
+
+	if l.async != nil && !l.bypassAsync.Load() {
+		l.async.push(context.Background(), r)
+		return nil
+	}
+
+	l.process(r)
+	l.putRecord(r)
+	return nil
+}
+
+// processRaw writes a record created by WriteRaw to every sink that
+// accepts it.
+func (l *Logger) processRaw(r *Record) {
+	for _, s := range *l.sinks.Load() {
+		if s.accepts(r.Level) {
+			s.writeRaw(r.raw)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRaw_RoutesByLevel(t *testing.T) {
+	info := &bytes.Buffer{}
+	errs := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:       InfoLevel,
+		Format:      TextFormat,
+		Output:      info,
+		ErrorOutput: errs,
+	})
+
+	require.NoError(t, logger.WriteRaw(InfoLevel, []byte(`{"msg":"upstream"}`)))
+	require.NoError(t, logger.WriteRaw(ErrorLevel, []byte(`{"msg":"failed"}`+"\n")))
+	require.NoError(t, logger.WriteRaw(DebugLevel, []byte(`{"msg":"filtered"}`)))
+
+	assert.Equal(t, `{"msg":"upstream"}`+"\n", info.String())
+	assert.Equal(t, `{"msg":"failed"}`+"\n", errs.String())
+}
+
+func TestWriteRaw_Validation(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{Output: buf})
+
+	assert.ErrorIs(t, logger.WriteRaw(InfoLevel, nil), ErrInvalidRawEntry)
+	assert.ErrorIs(t, logger.WriteRaw(InfoLevel, []byte("\n")), ErrInvalidRawEntry)
+	assert.ErrorIs(t, logger.WriteRaw(InfoLevel, []byte("a\nb")), ErrInvalidRawEntry)
+	assert.Empty(t, buf.String())
+}
+
+func TestWriteRaw_BufferedAndHooksSkipped(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{
+		Level:      InfoLevel,
+		Output:     buf,
+		BufferSize: 1024,
+		RedactKeys: []string{"password"},
+	})
+	logger.AddHook(HookFunc(func(r *Record) error {
+		return ErrDropRecord
+	}))
+
+	line := []byte(`{"password":"upstream-already-masked"}`)
+	require.NoError(t, logger.WriteRaw(WarnLevel, line))
+	line[0] = 'X'
+	assert.Empty(t, buf.String())
+
+	logger.Flush()
+	assert.Equal(t, `{"password":"upstream-already-masked"}`+"\n", buf.String())
+}
+
+func TestWriteRaw_Async(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{
+		Level:          InfoLevel,
+		Format:         JSONFormat,
+		Output:         buf,
+		AsyncQueueSize: 8,
+	})
+
+	line := []byte(`{"msg":"upstream"}`)
+	require.NoError(t, logger.WriteRaw(InfoLevel, line))
+	copy(line, "XXXXXXXX")
+	logger.Info("native")
+	require.NoError(t, logger.Close())
+
+	assert.Contains(t, buf.String(), `{"msg":"upstream"}`+"\n")
+	assert.Contains(t, buf.String(), `"message":"native"`)
+}
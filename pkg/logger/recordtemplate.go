@@ -0,0 +1,207 @@
+package logger
+
+import "context"
+
+// RecordTemplate logs entries with a fixed message and fixed field keys,
+// for hot paths such as per-request telemetry. The keys are encoded once
+// when the template is created, so logging only encodes the values:
+//
+//	served := log.Template("request served", "method", "status", "latency_ms")
+//	...
+//	served.Log(logger.InfoLevel, r.Method, status, elapsed.Milliseconds())
+//
+// Entries are written directly to the sinks, without building a Record,
+// when the logger does nothing a template cannot precompute: no hooks,
+// context fields, groups, filters, repeat suppression, breadcrumbs,
+// sampling, rate limiting, caller, field rewriting or size limits, no
+// async queue, and only JSONFormat and TextFormat sinks without an Encoder
+// or RecordWriter output. Otherwise, and for values that are LogValuers,
+// blocks or groups, the entry takes the regular path with identical
+// output.
+//
+// Values are passed as any, so numbers that are not constants are boxed,
+// which typically costs an allocation per value and entry.
+//
+// A RecordTemplate is safe for concurrent use.
+type RecordTemplate struct {
+	l    *Logger
+	msg  string
+	keys []string
+
+	// direct is set when the configuration of l allows writing entries
+	// directly; hooks, sampling, the async queue and the sinks can still
+	// change and are checked on every entry.
+	direct bool
+
+	// jsonMessage holds `,"message":"<msg>"` and jsonKeys `,"<key>":` for
+	// each key; textKeys holds " <key>=".
+	jsonMessage []byte
+	jsonKeys    [][]byte
+	textKeys    [][]byte
+}
+
+// Template returns a RecordTemplate logging msg with fields named keys.
+// The context fields and groups of l apply to its entries.
+func (l *Logger) Template(msg string, keys ...string) *RecordTemplate {
+	t := &RecordTemplate{
+		l:    l,
+		msg:  msg,
+		keys: append([]string(nil), keys...),
+		direct: l.tee == nil && l.limiter == nil && l.filters == nil && l.repeats == nil && l.breadcrumbs == nil &&
+			len(l.context) == 0 && len(l.groups) == 0 &&
+			!l.config.AddCaller && !l.config.AddStacktrace && l.config.ShardID == "" &&
+			!l.rewritesFields && l.config.OnError == nil && l.config.MaxRecordBytes == 0 &&
+			l.buffers == nil && !l.config.EscapeHTML && !l.config.PrettyJSON,
+	}
+
+	t.jsonMessage = append([]byte(nil), defaultJSONKeys.message...)
+	t.jsonMessage = appendJSONString(t.jsonMessage, msg)
+	t.jsonMessage = append(t.jsonMessage, '"')
+	t.jsonKeys = make([][]byte, len(keys))
+	t.textKeys = make([][]byte, len(keys))
+	for i, key := range keys {
+		t.jsonKeys[i] = append(appendJSONString([]byte(`,"`), key), '"', ':')
+		t.textKeys[i] = append(append([]byte{' '}, key...), '=')
+	}
+	return t
+}
+
+// Log logs an entry at level with the values of the template keys, in
+// order. Values are converted like F does. Keys without a value are logged
+// with a nil value, and values without a key under BadKey. Like
+// CheckedEntry.Write, an entry at FatalLevel exits the program and an
+// entry at PanicLevel panics with the message.
+func (t *RecordTemplate) Log(level Level, values ...any) {
+	l := t.l
+	if l.enabled(level) {
+		if !t.logDirect(level, values) {
+			l.logAt(context.Background(), l.now(), level, t.msg, t.fields(values))
+		}
+	}
+
+	switch level {
+	case FatalLevel:
+		l.exit()
+	case PanicLevel:
+		l.panic(t.msg)
+	}
+}
+
+// fields returns the fields of values for the regular logging path.
+func (t *RecordTemplate) fields(values []any) []Field {
+	fields := make([]Field, 0, max(len(t.keys), len(values)))
+	for i, key := range t.keys {
+		var value any
+		if i < len(values) {
+			value = fieldValue(values[i])
+		}
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	for i := len(t.keys); i < len(values); i++ {
+		fields = append(fields, Field{Key: BadKey, Value: fieldValue(values[i])})
+	}
+	return fields
+}
+
+// logDirect encodes the entry straight into the sink buffers, and reports
+// false when the entry must take the regular path instead.
+func (t *RecordTemplate) logDirect(level Level, values []any) bool {
+	l := t.l
+	if !t.direct || len(values) != len(t.keys) || l.sampler.Load() != nil || l.hooks.Load() != nil ||
+		(l.async != nil && !l.bypassAsync.Load()) {
+		return false
+	}
+	sinks := *l.sinks.Load()
+	for _, s := range sinks {
+		if s.accepts(level) && !t.encodes(s) {
+			return false
+		}
+	}
+	for _, v := range values {
+		switch fieldValue(v).(type) {
+		case valuerValue, blockValue, groupValue:
+			return false
+		}
+	}
+
+	r := l.records.Get().(*Record)
+	defer l.putRecord(r)
+	r.Time = l.now()
+	r.Level = level
+	r.Message = t.msg
+	if l.config.Metrics != nil {
+		l.config.Metrics.Emitted(level.String())
+	}
+
+	var encoded encodings
+	defer encoded.release(l)
+	for _, s := range sinks {
+		if !s.accepts(level) {
+			continue
+		}
+		if buf := encoded.get(s.key); buf != nil {
+			s.write(r, buf)
+			continue
+		}
+
+		bufPtr := l.getBuffer()
+		if s.format == JSONFormat {
+			*bufPtr = t.appendJSON((*bufPtr)[:0], r, values)
+		} else {
+			*bufPtr = t.appendText((*bufPtr)[:0], r, values)
+		}
+		s.write(r, *bufPtr)
+		if !encoded.put(s.key, bufPtr) {
+			l.putBuffer(bufPtr)
+		}
+	}
+	return true
+}
+
+// encodes reports whether the template can encode the entries of s.
+func (t *RecordTemplate) encodes(s *sink) bool {
+	if s.encoder != nil || s.records != nil || s.keyMap != nil {
+		return false
+	}
+	return s.format == JSONFormat || (s.format == TextFormat && t.l.template == nil)
+}
+
+// appendJSON appends the entry like Logger.appendJSON, using the encoded
+// message and keys.
+func (t *RecordTemplate) appendJSON(buf []byte, r *Record, values []any) []byte {
+	l := t.l
+	buf = append(buf, '{')
+	if omitsTimestamp(r.Time, l.config.TimestampFormat) {
+		buf = append(buf, defaultJSONKeys.level[1:]...)
+	} else {
+		buf = append(buf, defaultJSONKeys.timestamp...)
+		buf = l.timeCache.appendJSON(buf, r.Time, l.config.TimestampFormat)
+		buf = append(buf, defaultJSONKeys.level...)
+	}
+	buf = append(buf, r.Level.String()...)
+	buf = append(buf, '"')
+	buf = append(buf, t.jsonMessage...)
+	for i, key := range t.jsonKeys {
+		buf = append(buf, key...)
+		buf = appendJSONValue(buf, fieldValue(values[i]))
+	}
+	return append(buf, '}', '\n')
+}
+
+// appendText appends the entry like Logger.appendText, using the encoded
+// keys.
+func (t *RecordTemplate) appendText(buf []byte, r *Record, values []any) []byte {
+	l := t.l
+	if !omitsTimestamp(r.Time, l.config.TimestampFormat) {
+		buf = l.timeCache.appendText(buf, r.Time, l.config.TimestampFormat)
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, r.Level.String()...)
+	buf = append(buf, ' ')
+	buf = append(buf, t.msg...)
+	for i, key := range t.textKeys {
+		buf = append(buf, key...)
+		buf = appendValue(buf, fieldValue(values[i]))
+	}
+	return append(buf, '\n')
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordTemplate_MatchesFields(t *testing.T) {
+	ts := time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC)
+	for name, format := range map[string]Format{"json": JSONFormat, "text": TextFormat} {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			log := New(Config{Level: InfoLevel, Format: format, Output: buf, Clock: func() time.Time { return ts }})
+			served := log.Template(`request "served"`, "method", "status", "latency", "err", "tenant")
+			assert.True(t, served.direct)
+
+			served.Log(InfoLevel, "GET", 200, 12.5, errors.New("slow"), uint16(7))
+			direct := buf.String()
+			buf.Reset()
+			log.Info(`request "served"`, F("method", "GET"), F("status", 200), F("latency", 12.5),
+				F("err", errors.New("slow")), F("tenant", uint16(7)))
+			assert.Equal(t, buf.String(), direct)
+
+			buf.Reset()
+			served.Log(DebugLevel, "GET", 200, 12.5, nil, 7)
+			assert.Empty(t, buf.String())
+		})
+	}
+}
+
+func TestRecordTemplate_RegularPath(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf, TimestampFormat: TimestampDisabled})
+	log.AddHook(HookFunc(func(r *Record) error {
+		r.Fields = append(r.Fields, String("hooked", "yes"))
+		return nil
+	}))
+
+	log.With(String("service", "api")).Template("served", "status").Log(WarnLevel, 200)
+	assert.Equal(t, `{"level":"WARN","message":"served","service":"api","status":200,"hooked":"yes"}`+"\n", buf.String())
+}
+
+func TestRecordTemplate_ValueCount(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled})
+	tmpl := log.Template("job", "id", "state")
+
+	tmpl.Log(InfoLevel, 1)
+	tmpl.Log(InfoLevel, 2, "done", "extra")
+	assert.Equal(t, "INFO job id=1 state=null\nINFO job id=2 state=done !BADKEY=extra\n", buf.String())
+}
+
+func TestRecordTemplate_Fatal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exited := 0
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled, ExitFunc: func(int) { exited++ }})
+
+	log.Template("shutdown", "reason").Log(FatalLevel, "disk full")
+	assert.Equal(t, 1, exited)
+	assert.Equal(t, "FATAL shutdown reason=\"disk full\"\n", buf.String())
+	assert.PanicsWithValue(t, "corrupt", func() { log.Template("corrupt").Log(PanicLevel) })
+}
@@ -0,0 +1,189 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces masked data in log output.
+const RedactedValue = "[REDACTED]"
+
+// Common patterns for Config.RedactValuePatterns.
+var (
+	// RedactEmailPattern matches e-mail addresses.
+	RedactEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// RedactCreditCardPattern matches 13 to 19 digit card numbers, optionally
+	// grouped with spaces or dashes.
+	RedactCreditCardPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+
+	// RedactBearerTokenPattern matches HTTP bearer credentials.
+	RedactBearerTokenPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// redactor masks sensitive fields and values before encoding. It is built
+// once in New and is read-only afterwards.
+type redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+}
+
+func newRedactor(keys []string, patterns []*regexp.Regexp) *redactor {
+	if len(keys) == 0 && len(patterns) == 0 {
+		return nil
+	}
+
+	r := &redactor{patterns: patterns}
+	for _, key := range keys {
+		r.keys = append(r.keys, strings.ToLower(key))
+	}
+
+	return r
+}
+
+// matchKey reports whether key matches one of the configured key patterns.
+// Matching is case-insensitive.
+func (rd *redactor) matchKey(key string) bool {
+	if len(rd.keys) == 0 {
+		return false
+	}
+
+	key = strings.ToLower(key)
+	for _, pattern := range rd.keys {
+		if pattern == key {
+			return true
+		}
+		if matched, err := path.Match(pattern, key); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString replaces every match of the value patterns in s.
+func (rd *redactor) redactString(s string) string {
+	for _, pattern := range rd.patterns {
+		if pattern.MatchString(s) {
+			s = pattern.ReplaceAllLiteralString(s, RedactedValue)
+		}
+	}
+	return s
+}
+
+// redact masks the record in place. Fields with a matching key have their
+// whole value replaced; string values and the message have only the matching
+// parts replaced.
+func (rd *redactor) redact(r *Record) {
+	r.Message = rd.redactString(r.Message)
+	rd.redactFields(r.Fields)
+}
+
+// redactFields masks fields in place, including the fields of groups.
+func (rd *redactor) redactFields(fields []Field) {
+	for i := range fields {
+		field := &fields[i]
+		if rd.matchKey(field.Key) {
+			field.Value = RedactedValue
+			continue
+		}
+		switch v := field.Value.(type) {
+		case string:
+			field.Value = rd.redactString(v)
+		case blockValue:
+			field.Value = blockValue(rd.redactString(string(v)))
+		case bytesValue:
+			// Only ByteString payloads are readable; the slice is the
+			// caller's, so a masked copy replaces it.
+			if v.enc != bytesText {
+				continue
+			}
+			if s := string(v.b); rd.redactString(s) != s {
+				field.Value = bytesValue{b: []byte(rd.redactString(s)), enc: bytesText}
+			}
+		case groupValue:
+			// The members may be shared with the logger, so mask a copy.
+			g := append(groupValue(nil), v...)
+			rd.redactFields(g)
+			field.Value = g
+		case reflectValue:
+			field.Value = rd.redactReflect(v)
+		}
+	}
+}
+
+// redactReflect masks an Any value. The cached encoders know nothing of
+// redaction, so the value is encoded and its JSON masked like fields:
+// object members with a matching key, whether struct fields by their JSON
+// name or map keys, have their value replaced, and strings have the
+// matching parts replaced.
+func (rd *redactor) redactReflect(v reflectValue) reflectValue {
+	dec := json.NewDecoder(bytes.NewReader(v.appendJSON(nil)))
+	dec.UseNumber()
+	buf, err := rd.appendRedactedJSON(nil, dec)
+	if err != nil {
+		// appendJSON always produces valid JSON, so this is not expected;
+		// drop the value rather than write it unmasked.
+		return reflectValue{v: RedactedValue}
+	}
+	return reflectValue{v: json.RawMessage(buf)}
+}
+
+// appendRedactedJSON appends the next JSON value of dec to buf, masked.
+func (rd *redactor) appendRedactedJSON(buf []byte, dec *json.Decoder) ([]byte, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return buf, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		closing := byte(']')
+		if t == '{' {
+			closing = '}'
+		}
+		buf = append(buf, byte(t))
+		for first := true; dec.More(); first = false {
+			if !first {
+				buf = append(buf, ',')
+			}
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return buf, err
+				}
+				buf = append(buf, '"')
+				buf = appendJSONString(buf, key.(string))
+				buf = append(buf, '"', ':')
+				if rd.matchKey(key.(string)) {
+					var skipped json.RawMessage
+					if err := dec.Decode(&skipped); err != nil {
+						return buf, err
+					}
+					buf = append(buf, `"`+RedactedValue+`"`...)
+					continue
+				}
+			}
+			if buf, err = rd.appendRedactedJSON(buf, dec); err != nil {
+				return buf, err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return buf, err
+		}
+		return append(buf, closing), nil
+	case string:
+		buf = append(buf, '"')
+		buf = appendJSONString(buf, rd.redactString(t))
+		return append(buf, '"'), nil
+	case json.Number:
+		return append(buf, t...), nil
+	case bool:
+		return strconv.AppendBool(buf, t), nil
+	default:
+		return append(buf, "null"...), nil
+	}
+}
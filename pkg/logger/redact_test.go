@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact_Keys(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:      InfoLevel,
+		Format:     JSONFormat,
+		Output:     buf,
+		RedactKeys: []string{"password", "*token*"},
+	})
+
+	fields := []Field{
+		{Key: "Password", Value: "hunter2"},
+		{Key: "refresh_token_id", Value: 12345},
+		{Key: "user", Value: "alice"},
+	}
+	logger.Info("login", fields...)
+
+	output := buf.String()
+	assert.Contains(t, output, `"Password":"[REDACTED]"`)
+	assert.Contains(t, output, `"refresh_token_id":"[REDACTED]"`)
+	assert.Contains(t, output, `"user":"alice"`)
+	assert.Equal(t, "hunter2", fields[0].Value, "caller fields must not be modified")
+}
+
+func TestRedact_ValuePatterns(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: buf,
+		RedactValuePatterns: []*regexp.Regexp{
+			RedactEmailPattern,
+			RedactCreditCardPattern,
+			RedactBearerTokenPattern,
+		},
+	})
+
+	logger.Info("mail sent to alice@example.com",
+		Field{Key: "card", Value: "4111 1111 1111 1111"},
+		Field{Key: "auth", Value: "Bearer abc.def-ghi"},
+		Field{Key: "orderID", Value: 4111111111111111},
+	)
+
+	output := buf.String()
+	assert.NotContains(t, output, "alice@example.com")
+	assert.NotContains(t, output, "4111 1111")
+	assert.NotContains(t, output, "abc.def-ghi")
+	assert.Contains(t, output, `mail sent to [REDACTED]`)
+	assert.Contains(t, output, `card=[REDACTED]`)
+	assert.Contains(t, output, `orderID=4111111111111111`, "only string values are matched")
+}
+
+func TestRedact_RunsAfterHooks(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:      InfoLevel,
+		Format:     JSONFormat,
+		Output:     buf,
+		RedactKeys: []string{"secret"},
+	})
+	logger.AddHook(HookFunc(func(r *Record) error {
+		r.Fields = append(r.Fields, Field{Key: "secret", Value: "added-by-hook"})
+		return nil
+	}))
+
+	logger.Info("enriched")
+
+	assert.Contains(t, buf.String(), `"secret":"[REDACTED]"`)
+}
+
+func TestRedact_AnyValues(t *testing.T) {
+	type credentials struct {
+		Password string `json:"password"`
+		Token    string
+	}
+	type user struct {
+		Name        string
+		Password    string
+		Email       string
+		Credentials credentials
+		Labels      map[string]string
+		Aliases     []string
+		Age         int
+	}
+
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:               InfoLevel,
+		Format:              JSONFormat,
+		Output:              buf,
+		RedactKeys:          []string{"*password*", "token"},
+		RedactValuePatterns: []*regexp.Regexp{RedactEmailPattern},
+	})
+
+	log.Info("signup", Any("user", user{
+		Name:        "bob",
+		Password:    "hunter2",
+		Email:       "bob@example.com",
+		Credentials: credentials{Password: "hunter3", Token: "t0k3n"},
+		Labels:      map[string]string{"api_token": "x", "Token": "secret", "team": "ops"},
+		Aliases:     []string{"b@example.org"},
+		Age:         42,
+	}))
+
+	output := buf.String()
+	assert.Contains(t, output, `"user":{"Name":"bob","Password":"[REDACTED]","Email":"[REDACTED]",`+
+		`"Credentials":{"password":"[REDACTED]","Token":"[REDACTED]"},`+
+		`"Labels":{"Token":"[REDACTED]","api_token":"x","team":"ops"},"Aliases":["[REDACTED]"],"Age":42}`)
+	assert.NotContains(t, output, "hunter")
+	assert.NotContains(t, output, "example")
+}
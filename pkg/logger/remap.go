@@ -0,0 +1,79 @@
+package logger
+
+// Names of the keys the JSON encoder emits for every entry. They can be
+// renamed through Config.KeyMap or SinkConfig.KeyMap like any field key.
+const (
+	TimestampKey = "timestamp"
+	LevelKey     = "level"
+	MessageKey   = "message"
+)
+
+// jsonKeys holds the pre-encoded built-in JSON keys, including the
+// surrounding punctuation, so encoding an entry does not re-escape them.
+type jsonKeys struct {
+	timestamp string
+	level     string
+	message   string
+}
+
+var defaultJSONKeys = newJSONKeys(nil)
+
+func newJSONKeys(keyMap map[string]string) jsonKeys {
+	return jsonKeys{
+		timestamp: `"` + jsonKeyName(remapKey(keyMap, TimestampKey)) + `":`,
+		level:     `,"` + jsonKeyName(remapKey(keyMap, LevelKey)) + `":"`,
+		message:   `,"` + jsonKeyName(remapKey(keyMap, MessageKey)) + `":"`,
+	}
+}
+
+// jsonKeyName escapes a key for use inside a JSON string.
+func jsonKeyName(key string) string {
+	return string(appendJSONString(nil, key))
+}
+
+// remapKey returns the configured replacement for key, or key itself.
+func remapKey(keyMap map[string]string, key string) string {
+	if renamed, ok := keyMap[key]; ok && renamed != "" {
+		return renamed
+	}
+	return key
+}
+
+// keyMap is the key renaming of a sink, from SinkConfig.KeyMap or
+// Config.KeyMap. Sinks sharing a key map share a *keyMap, so their
+// encodings are shared as well.
+type keyMap struct {
+	names map[string]string
+	json  jsonKeys
+}
+
+// newKeyMap returns the key map for names, or nil when names renames
+// nothing.
+func newKeyMap(names map[string]string) *keyMap {
+	if len(names) == 0 {
+		return nil
+	}
+	return &keyMap{names: names, json: newJSONKeys(names)}
+}
+
+// jsonKeys returns the built-in JSON keys renamed by k.
+func (k *keyMap) jsonKeys() *jsonKeys {
+	if k == nil {
+		return &defaultJSONKeys
+	}
+	return &k.json
+}
+
+// remapFields renames the fields of r according to k. The renamed fields
+// are written to the scratch storage of r, so fields are left untouched
+// for sinks with another key map; it returns the fields to encode.
+func (k *keyMap) remapFields(r *Record, fields []Field) []Field {
+	if k == nil {
+		return fields
+	}
+	r.remapped = append(r.remapped[:0], fields...)
+	for i := range r.remapped {
+		r.remapped[i].Key = remapKey(k.names, r.remapped[i].Key)
+	}
+	return r.remapped
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyMap_RenamesFieldsAndBuiltinKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: buf,
+		KeyMap: map[string]string{
+			"trace_id":   "traceID",
+			MessageKey:   "msg",
+			TimestampKey: "@timestamp",
+		},
+	})
+
+	fields := []Field{{Key: "trace_id", Value: "abc"}, {Key: "user", Value: "alice"}}
+	logger.Info("remapped", fields...)
+
+	output := buf.String()
+	assert.Contains(t, output, `"@timestamp":"`)
+	assert.Contains(t, output, `"level":"INFO"`)
+	assert.Contains(t, output, `"msg":"remapped"`)
+	assert.Contains(t, output, `"traceID":"abc"`)
+	assert.Contains(t, output, `"user":"alice"`)
+	assert.NotContains(t, output, `"trace_id"`)
+	assert.Equal(t, "trace_id", fields[0].Key, "caller fields must not be modified")
+}
+
+func TestKeyMap_RedactionSeesOriginalKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:      InfoLevel,
+		Format:     TextFormat,
+		Output:     buf,
+		RedactKeys: []string{"pwd"},
+		KeyMap:     map[string]string{"pwd": "password"},
+	})
+
+	logger.Info("login", Field{Key: "pwd", Value: "hunter2"})
+
+	assert.Contains(t, buf.String(), "password=[REDACTED]")
+}
+
+func TestKeyMap_PerSink(t *testing.T) {
+	primary, elastic, plain := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: primary,
+		KeyMap: map[string]string{"trace_id": "traceID"},
+		Outputs: []SinkConfig{
+			{Output: elastic, Format: JSONFormat, KeyMap: map[string]string{"trace_id": "trace.id", MessageKey: "msg"}},
+			{Output: plain, Format: JSONFormat, KeyMap: map[string]string{}},
+		},
+	})
+	inherited := &bytes.Buffer{}
+	logger.AddSink(SinkConfig{Output: inherited, Format: JSONFormat})
+
+	fields := []Field{{Key: "trace_id", Value: "abc"}}
+	logger.Info("per sink", fields...)
+
+	assert.Contains(t, primary.String(), `"message":"per sink","traceID":"abc"}`)
+	assert.Contains(t, elastic.String(), `"msg":"per sink","trace.id":"abc"}`)
+	assert.Contains(t, plain.String(), `"message":"per sink","trace_id":"abc"}`)
+	assert.Equal(t, primary.String(), inherited.String())
+	assert.Equal(t, "trace_id", fields[0].Key, "caller fields must not be modified")
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"context"
+	"hash/maphash"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+// RepeatedKey is the key of the field holding the number of suppressed
+// repeats in the summary entry of Config.RepeatWindow.
+const RepeatedKey = "repeated"
+
+// repeatSuppressor drops entries identical to the previous one, the way
+// syslog collapses a crash loop into "last message repeated N times".
+type repeatSuppressor struct {
+	window time.Duration
+	seed   maphash.Seed
+
+	mu       sync.Mutex
+	last     uint64
+	since    time.Time
+	repeated uint64
+
+	// timer writes the summary once the window of the last written copy
+	// ends, so a burst followed by silence is still summarized. episode
+	// counts the summaries taken, so a stale timer finds nothing to write.
+	timer   *time.Timer
+	episode uint64
+
+	// logger and level belong to the repeated entry, so the summary
+	// carries its context fields and level.
+	logger *Logger
+	level  Level
+}
+
+func newRepeatSuppressor(window time.Duration) *repeatSuppressor {
+	if window <= 0 {
+		return nil
+	}
+	return &repeatSuppressor{window: window, seed: maphash.MakeSeed()}
+}
+
+// repeatSummary is a pending "last message repeated N times" entry.
+type repeatSummary struct {
+	logger   *Logger
+	level    Level
+	repeated uint64
+}
+
+// check reports whether the entry of l is a repeat of the previous entry
+// within the window and must be dropped. Otherwise the entry becomes the
+// one repeats are compared to, and the summary of the repeats of the
+// previous entry, if any, is returned to be written first.
+func (r *repeatSuppressor) check(l *Logger, level Level, msg string, fields []Field, now time.Time) (bool, repeatSummary) {
+	key := r.hash(level, msg, l.context, fields)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key == r.last && level < FatalLevel && !r.since.IsZero() && now.Sub(r.since) < r.window {
+		r.repeated++
+		if r.repeated == 1 {
+			episode := r.episode
+			r.timer = time.AfterFunc(r.window-now.Sub(r.since), func() { r.expire(episode) })
+		}
+		return true, repeatSummary{}
+	}
+
+	summary := r.take()
+	r.last = key
+	r.since = now
+	r.logger = l
+	r.level = level
+	return false, summary
+}
+
+// take returns and resets the pending summary. It must be called with
+// r.mu held.
+func (r *repeatSuppressor) take() repeatSummary {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.episode++
+	summary := repeatSummary{logger: r.logger, level: r.level, repeated: r.repeated}
+	r.repeated = 0
+	return summary
+}
+
+// expire writes the pending summary when its window ended before it was
+// taken for another reason.
+func (r *repeatSuppressor) expire(episode uint64) {
+	r.mu.Lock()
+	if r.episode != episode {
+		r.mu.Unlock()
+		return
+	}
+	summary := r.take()
+	r.mu.Unlock()
+	summary.log()
+}
+
+// hash identifies an entry by its level, message and fields.
+func (r *repeatSuppressor) hash(level Level, msg string, context, fields []Field) uint64 {
+	var h maphash.Hash
+	h.SetSeed(r.seed)
+	_ = h.WriteByte(byte(level))
+	h.WriteString(msg)
+	for _, list := range [2][]Field{context, fields} {
+		for _, field := range list {
+			_ = h.WriteByte(0)
+			h.WriteString(field.Key)
+			_ = h.WriteByte('=')
+			writeHashValue(&h, field.Value)
+		}
+	}
+	return h.Sum64()
+}
+
+// log writes the summary of suppressed repeats, if any.
+func (s repeatSummary) log() {
+	if s.repeated == 0 {
+		return
+	}
+	msg := "last message repeated " + strconv.FormatUint(s.repeated, 10) + " times"
+	s.logger.emit(context.Background(), s.logger.now(), s.level, msg, Field{Key: RepeatedKey, Value: int64(s.repeated)})
+}
+
+// suppressRepeat reports whether the entry is a repeat to drop, writing the
+// summary of the previous entry's repeats when it is not.
+func (l *Logger) suppressRepeat(level Level, msg string, fields []Field, now time.Time) bool {
+	drop, summary := l.repeats.check(l, level, msg, fields, now)
+	if drop {
+		l.countDropped(logmetrics.DropRepeated)
+		return true
+	}
+	summary.log()
+	return false
+}
+
+// flushRepeats writes the summary of the repeats suppressed so far, for
+// Flush.
+func (l *Logger) flushRepeats() {
+	l.repeats.mu.Lock()
+	summary := l.repeats.take()
+	l.repeats.mu.Unlock()
+	summary.log()
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+func TestConfig_RepeatWindow(t *testing.T) {
+	now := time.Date(2024, 1, 20, 15, 4, 5, 0, time.UTC)
+	var counters logmetrics.Counters
+	buf := &bytes.Buffer{}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		Clock:           func() time.Time { return now },
+		Metrics:         &counters,
+		RepeatWindow:    time.Minute,
+	})
+
+	for range 4 {
+		log.Error("worker crashed", String("worker", "a"))
+	}
+	log.Error("worker crashed", String("worker", "b"))
+	log.Error("worker crashed", String("worker", "b"))
+	log.Warn("worker crashed", String("worker", "b"))
+
+	assert.Equal(t, "ERROR worker crashed worker=a\n"+
+		"ERROR last message repeated 3 times repeated=3\n"+
+		"ERROR worker crashed worker=b\n"+
+		"ERROR last message repeated 1 times repeated=1\n"+
+		"WARN worker crashed worker=b\n", buf.String())
+	assert.Equal(t, uint64(4), counters.Snapshot().Dropped[logmetrics.DropRepeated])
+
+	// Past the window a repeat is written again, after the summary.
+	buf.Reset()
+	log.Warn("worker crashed", String("worker", "b"))
+	now = now.Add(time.Minute)
+	log.Warn("worker crashed", String("worker", "b"))
+	assert.Equal(t, "WARN last message repeated 1 times repeated=1\nWARN worker crashed worker=b\n", buf.String())
+}
+
+func TestConfig_RepeatWindowFlush(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled, RepeatWindow: time.Hour})
+	svc := log.With(String("service", "api"))
+
+	svc.Info("retrying")
+	svc.Info("retrying")
+	log.Info("retrying")
+	log.Info("retrying")
+	assert.Equal(t, "INFO retrying service=api\n"+
+		"INFO last message repeated 1 times service=api repeated=1\n"+
+		"INFO retrying\n", buf.String())
+
+	buf.Reset()
+	log.Flush()
+	assert.Equal(t, "INFO last message repeated 1 times repeated=1\n", buf.String())
+	log.Flush()
+	assert.Equal(t, "INFO last message repeated 1 times repeated=1\n", buf.String())
+}
+
+func TestConfig_RepeatWindowFatal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exited := 0
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		RepeatWindow:    time.Hour,
+		ExitFunc:        func(int) { exited++ },
+	})
+
+	log.Fatal("out of memory")
+	log.Fatal("out of memory")
+	assert.Equal(t, 2, exited)
+	assert.Equal(t, "FATAL out of memory\nFATAL out of memory\n", buf.String())
+}
+
+func TestConfig_RepeatWindowValidation(t *testing.T) {
+	_, err := NewE(Config{RepeatWindow: -time.Second})
+	assert.ErrorContains(t, err, "negative RepeatWindow -1s")
+}
+
+func TestConfig_RepeatWindowExpires(t *testing.T) {
+	buf := &syncBuffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampFormat: TimestampDisabled, RepeatWindow: 20 * time.Millisecond})
+
+	for range 3 {
+		log.Error("disk full")
+	}
+	assert.Equal(t, "ERROR disk full\n", buf.String())
+	assert.Eventually(t, func() bool {
+		return buf.String() == "ERROR disk full\nERROR last message repeated 2 times repeated=2\n"
+	}, time.Second, 5*time.Millisecond, "a burst followed by silence is summarized")
+}
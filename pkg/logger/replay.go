@@ -0,0 +1,425 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Recorder is a Hook that captures the calls behind every entry, rather
+// than their encoded output, so they can be replayed later against other
+// configurations or encoders with Replay. Recording real traffic, e.g. on
+// staging, gives a corpus to check an encoder change against.
+//
+// Calls are written as JSON lines holding the time, level, message and
+// fields of the entry, with the Go type of every field value, so a replayed
+// entry is identical to the recorded one. Groups are recorded member by
+// member. Secrets, and values with a String, Error or MarshalText method,
+// are recorded in their rendered form only and replayed as strings.
+//
+// Entries are recorded after the redaction of Config.RedactKeys and
+// Config.RedactValuePatterns, even though hooks run before it, so a
+// recording holds no more than the logger writes. Masked values are
+// replayed as "[REDACTED]".
+//
+// The recorder sees the entries that pass level filtering, sampling and
+// rate limiting, with the changes of hooks added before it. Add it as the
+// first hook of a logger without sampling to capture all traffic.
+//
+// Example:
+//
+//	rec := logger.NewRecorder(file)
+//	log.AddHook(rec)
+type Recorder struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+// NewRecorder returns a Recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// recordedCall is the serialized form of one call.
+type recordedCall struct {
+	Time    time.Time       `json:"time"`
+	Level   string          `json:"level"`
+	Message string          `json:"message"`
+	Fields  []recordedField `json:"fields,omitempty"`
+}
+
+// recordedField is a field with the type needed to restore its value.
+type recordedField struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// recordedSource is the serialized form of a SourceLocation value.
+type recordedSource struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function,omitempty"`
+}
+
+// recordedRequest is the serialized form of an HTTPRequest value.
+type recordedRequest struct {
+	Method       string        `json:"method"`
+	URL          string        `json:"url"`
+	UserAgent    string        `json:"userAgent,omitempty"`
+	RemoteIP     string        `json:"remoteIp,omitempty"`
+	Referer      string        `json:"referer,omitempty"`
+	Protocol     string        `json:"protocol,omitempty"`
+	Status       int           `json:"status"`
+	RequestSize  int64         `json:"requestSize"`
+	ResponseSize int64         `json:"responseSize"`
+	Latency      time.Duration `json:"latency"`
+}
+
+// bytesRecordTypes holds the recorded types of Binary, Hex and ByteString
+// values by encoding.
+var bytesRecordTypes = [...]string{bytesBase64: "binary", bytesHex: "hex", bytesText: "bytestring"}
+
+// unknownValue stands in for recorded values of types the encoders do not
+// support, so replaying them encodes "unknown" again.
+type unknownValue struct{}
+
+// Run records r. Errors writing the recording do not affect logging; they
+// are reported by Err.
+func (rec *Recorder) Run(r *Record) error {
+	message, fields := r.Message, r.Fields
+	if r.redactor != nil {
+		// Hooks run before redaction, so a copy is masked as the sinks
+		// will mask it.
+		message = r.redactor.redactString(message)
+		fields = append([]Field(nil), fields...)
+		r.redactor.redactFields(fields)
+	}
+
+	call := recordedCall{
+		Time:    r.Time,
+		Level:   r.Level.String(),
+		Message: message,
+		Fields:  make([]recordedField, len(fields)),
+	}
+	for i, field := range fields {
+		call.Fields[i] = recordField(field)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.err != nil {
+		return nil
+	}
+	line, err := json.Marshal(call)
+	if err == nil {
+		rec.buf = append(append(rec.buf[:0], line...), '\n')
+		_, err = rec.w.Write(rec.buf)
+	}
+	rec.err = err
+	return nil
+}
+
+// Err returns the first error that occurred while recording. Recording
+// stops after an error.
+func (rec *Recorder) Err() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.err
+}
+
+// recordField returns the serialized form of field.
+func recordField(field Field) recordedField {
+	f := recordedField{Key: field.Key}
+
+	var value any
+	switch v := field.Value.(type) {
+	case nil:
+		f.Type = "nil"
+	case string:
+		f.Type, value = "string", v
+	case blockValue:
+		f.Type, value = "block", string(v)
+	case bytesValue:
+		f.Type, value = bytesRecordTypes[v.enc], v.b
+	case int:
+		f.Type, value = "int", v
+	case int8:
+		f.Type, value = "int8", v
+	case int16:
+		f.Type, value = "int16", v
+	case int32:
+		f.Type, value = "int32", v
+	case int64:
+		// As a string, since JSON numbers lose precision beyond 2^53.
+		f.Type, value = "int64", strconv.FormatInt(v, 10)
+	case uint:
+		f.Type, value = "uint", strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		f.Type, value = "uint8", v
+	case uint16:
+		f.Type, value = "uint16", v
+	case uint32:
+		f.Type, value = "uint32", v
+	case uint64:
+		f.Type, value = "uint64", strconv.FormatUint(v, 10)
+	case uintptr:
+		f.Type, value = "uintptr", strconv.FormatUint(uint64(v), 10)
+	case float32:
+		f.Type, value = "float32", strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		// As a string, since JSON has no NaN or infinities.
+		f.Type, value = "float64", strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		f.Type, value = "bool", v
+	case time.Time:
+		f.Type, value = "time", v
+	case secretValue:
+		f.Type, value = "string", v.String()
+	case sourceLocation:
+		f.Type, value = "sourceLocation", recordedSource{File: v.file, Line: v.line, Function: v.function}
+	case httpRequest:
+		f.Type, value = "httpRequest", recordedRequest{
+			Method:       v.method,
+			URL:          v.url,
+			UserAgent:    v.userAgent,
+			RemoteIP:     v.remoteIP,
+			Referer:      v.referer,
+			Protocol:     v.protocol,
+			Status:       v.status,
+			RequestSize:  v.requestSize,
+			ResponseSize: v.responseSize,
+			Latency:      v.latency,
+		}
+	case reflectValue:
+		// Stored as the JSON it encodes to, which replays identically.
+		f.Type, f.Value = "json", v.appendJSON(nil)
+	case groupValue:
+		group := make([]recordedField, len(v))
+		for i, member := range v {
+			group[i] = recordField(member)
+		}
+		f.Type, value = "group", group
+	default:
+		// Stringers and errors encode as their text, so they are stored as
+		// the string they render to.
+		if s, ok := textValue(v); ok {
+			f.Type, value = "string", s
+		} else {
+			f.Type = "unknown"
+		}
+	}
+
+	if value != nil {
+		// Values of the types above always marshal.
+		f.Value, _ = json.Marshal(value)
+	}
+	return f
+}
+
+// value restores the recorded value of f.
+func (f recordedField) value() (any, error) {
+	switch f.Type {
+	case "nil":
+		return nil, nil
+	case "unknown":
+		return unknownValue{}, nil
+	case "string":
+		var s string
+		err := json.Unmarshal(f.Value, &s)
+		return s, err
+	case "block":
+		var s string
+		err := json.Unmarshal(f.Value, &s)
+		return blockValue(s), err
+	case "json":
+		return reflectValue{v: f.Value}, nil
+	case "binary", "hex", "bytestring":
+		var b []byte
+		err := json.Unmarshal(f.Value, &b)
+		return bytesValue{b: b, enc: bytesEncoding(slices.Index(bytesRecordTypes[:], f.Type))}, err
+	case "int":
+		var i int
+		err := json.Unmarshal(f.Value, &i)
+		return i, err
+	case "int8":
+		var i int8
+		err := json.Unmarshal(f.Value, &i)
+		return i, err
+	case "int16":
+		var i int16
+		err := json.Unmarshal(f.Value, &i)
+		return i, err
+	case "int32":
+		var i int32
+		err := json.Unmarshal(f.Value, &i)
+		return i, err
+	case "int64":
+		var s string
+		if err := json.Unmarshal(f.Value, &s); err != nil {
+			return nil, err
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case "uint":
+		var s string
+		if err := json.Unmarshal(f.Value, &s); err != nil {
+			return nil, err
+		}
+		u, err := strconv.ParseUint(s, 10, strconv.IntSize)
+		return uint(u), err
+	case "uint8":
+		var u uint8
+		err := json.Unmarshal(f.Value, &u)
+		return u, err
+	case "uint16":
+		var u uint16
+		err := json.Unmarshal(f.Value, &u)
+		return u, err
+	case "uint32":
+		var u uint32
+		err := json.Unmarshal(f.Value, &u)
+		return u, err
+	case "uint64":
+		var s string
+		if err := json.Unmarshal(f.Value, &s); err != nil {
+			return nil, err
+		}
+		return strconv.ParseUint(s, 10, 64)
+	case "uintptr":
+		var s string
+		if err := json.Unmarshal(f.Value, &s); err != nil {
+			return nil, err
+		}
+		u, err := strconv.ParseUint(s, 10, 64)
+		return uintptr(u), err
+	case "float32":
+		var s string
+		if err := json.Unmarshal(f.Value, &s); err != nil {
+			return nil, err
+		}
+		v, err := strconv.ParseFloat(s, 32)
+		return float32(v), err
+	case "float64":
+		var s string
+		if err := json.Unmarshal(f.Value, &s); err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(s, 64)
+	case "bool":
+		var b bool
+		err := json.Unmarshal(f.Value, &b)
+		return b, err
+	case "time":
+		var t time.Time
+		err := json.Unmarshal(f.Value, &t)
+		return t, err
+	case "sourceLocation":
+		var v recordedSource
+		err := json.Unmarshal(f.Value, &v)
+		return sourceLocation{file: v.File, line: v.Line, function: v.Function}, err
+	case "group":
+		var members []recordedField
+		if err := json.Unmarshal(f.Value, &members); err != nil {
+			return nil, err
+		}
+		group := make(groupValue, len(members))
+		for i, member := range members {
+			value, err := member.value()
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", member.Key, err)
+			}
+			group[i] = Field{Key: member.Key, Value: value}
+		}
+		return group, nil
+	case "httpRequest":
+		var v recordedRequest
+		err := json.Unmarshal(f.Value, &v)
+		return httpRequest{
+			method:       v.Method,
+			url:          v.URL,
+			userAgent:    v.UserAgent,
+			remoteIP:     v.RemoteIP,
+			referer:      v.Referer,
+			protocol:     v.Protocol,
+			status:       v.Status,
+			requestSize:  v.RequestSize,
+			responseSize: v.ResponseSize,
+			latency:      v.Latency,
+		}, err
+	default:
+		return nil, fmt.Errorf("unknown field type %q", f.Type)
+	}
+}
+
+// Replay reads calls recorded by a Recorder from src and logs them through
+// l with their original timestamps, as if they were made again. Entries go
+// through the level filtering, sampling, hooks and sinks of l, so the same
+// recording can be replayed against several configurations to compare
+// their output.
+//
+// Replay returns the number of calls read. It stops at the first malformed
+// line and reports its line number.
+func Replay(src io.Reader, l *Logger) (int, error) {
+	return readRecordedCalls(src, func(call replayedCall) {
+		if l.enabled(call.level) {
+			l.logAt(context.Background(), call.time, call.level, call.message, call.fields)
+		}
+	})
+}
+
+// replayedCall is a decoded recorded call.
+type replayedCall struct {
+	time    time.Time
+	level   Level
+	message string
+	fields  []Field
+}
+
+// readRecordedCalls decodes the calls recorded in src and passes them to fn
+// in order. It returns the number of calls read and stops at the first
+// malformed line.
+func readRecordedCalls(src io.Reader, fn func(replayedCall)) (int, error) {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+
+	n := 0
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var call recordedCall
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			return n, fmt.Errorf("logger: replay line %d: %w", line, err)
+		}
+		level, ok := levelFromName(call.Level)
+		if !ok {
+			return n, fmt.Errorf("logger: replay line %d: unknown level %q", line, call.Level)
+		}
+		fields := make([]Field, len(call.Fields))
+		for i, f := range call.Fields {
+			value, err := f.value()
+			if err != nil {
+				return n, fmt.Errorf("logger: replay line %d: field %q: %w", line, f.Key, err)
+			}
+			fields[i] = Field{Key: f.Key, Value: value}
+		}
+
+		n++
+		fn(replayedCall{time: call.Time, level: level, message: call.Message, fields: fields})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return n, fmt.Errorf("logger: replay: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_ReplayIsIdentical(t *testing.T) {
+	recording := &bytes.Buffer{}
+	original := &bytes.Buffer{}
+	logger := New(Config{Level: DebugLevel, Format: CloudLoggingFormat, Output: original, CloudProjectID: "p"})
+	rec := NewRecorder(recording)
+	logger.AddHook(rec)
+
+	req := httptest.NewRequest("GET", "/orders?id=1", nil)
+	logger.Debug("start")
+	logger.Info("request",
+		F("path", "/orders"),
+		F("count", 3),
+		F("big", int64(math.MaxInt64)),
+		F("ratio", math.NaN()),
+		F("ok", true),
+		F("at", time.Date(2024, 1, 20, 15, 4, 5, 123, time.FixedZone("CET", 3600))),
+		Err(nil),
+		Secret("password", "hunter2"),
+		HashedSecret("token", "abc"),
+		SourceLocation(0),
+		HTTPRequest(req, 200, 512, 12*time.Millisecond),
+		Field{Key: "opaque", Value: struct{}{}},
+	)
+	logger.With(F("id", 7)).Error("failed")
+	require.NoError(t, rec.Err())
+	assert.NotContains(t, recording.String(), "hunter2")
+
+	replayed := &bytes.Buffer{}
+	n, err := Replay(bytes.NewReader(recording.Bytes()), New(Config{Level: DebugLevel, Format: CloudLoggingFormat, Output: replayed, CloudProjectID: "p"}))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, original.String(), replayed.String())
+}
+
+func TestRecorder_RecordsRedactedFields(t *testing.T) {
+	newLogger := func(out *bytes.Buffer) *Logger {
+		return New(Config{
+			Format:              JSONFormat,
+			Output:              out,
+			RedactKeys:          []string{"*password*"},
+			RedactValuePatterns: []*regexp.Regexp{regexp.MustCompile(`sk-\w+`)},
+		})
+	}
+	recording := &bytes.Buffer{}
+	original := &bytes.Buffer{}
+	logger := newLogger(original)
+	rec := NewRecorder(recording)
+	logger.AddHook(rec)
+
+	fields := []Field{
+		F("db_password", "hunter2"),
+		F("note", "key sk-live123"),
+		Group("user", F("password", "hunter3")),
+	}
+	logger.Info("login with sk-live456", fields...)
+	require.NoError(t, rec.Err())
+	for _, secret := range []string{"hunter2", "hunter3", "sk-live123", "sk-live456"} {
+		assert.NotContains(t, recording.String(), secret)
+	}
+	assert.Equal(t, "hunter2", fields[0].Value, "the caller's fields are not masked")
+
+	replayed := &bytes.Buffer{}
+	_, err := Replay(recording, newLogger(replayed))
+	require.NoError(t, err)
+	assert.Equal(t, original.String(), replayed.String())
+}
+
+func TestReplay_OtherConfig(t *testing.T) {
+	recording := &bytes.Buffer{}
+	logger := New(Config{Level: DebugLevel, Format: JSONFormat, Output: &bytes.Buffer{}})
+	logger.AddHook(NewRecorder(recording))
+
+	logger.Debug("noise")
+	logger.Warn("disk almost full", F("free", 0.05))
+
+	out := &bytes.Buffer{}
+	n, err := Replay(recording, New(Config{Level: InfoLevel, Format: TextFormat, Output: out}))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "filtered calls are still read")
+	assert.Contains(t, out.String(), "WARN disk almost full free=0.05")
+	assert.NotContains(t, out.String(), "noise")
+}
+
+func TestReplay_Malformed(t *testing.T) {
+	logger := New(Config{Output: &bytes.Buffer{}})
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"json", "{", "replay line 1"},
+		{"level", `{"level":"LOUD","message":"m"}`, `unknown level "LOUD"`},
+		{"type", `{"level":"INFO","message":"m","fields":[{"key":"k","type":"complex"}]}`, `field "k": unknown field type "complex"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Replay(strings.NewReader(tt.input), logger)
+			assert.Zero(t, n)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRecorder_WriteError(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := New(Config{Level: InfoLevel, Output: out})
+	rec := NewRecorder(failingWriter{})
+	logger.AddHook(rec)
+
+	logger.Info("still logged")
+	assert.Contains(t, out.String(), "still logged")
+	assert.EqualError(t, rec.Err(), "disk full")
+}
+
+func TestRecorder_ReplaysFieldTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []Field
+	}{
+		{"group", []Field{Group("http", F("method", "GET"), Group("response", F("status", 200), F("ratio", 0.5)))}},
+		{"small integers", []Field{
+			{Key: "i8", Value: int8(math.MinInt8)},
+			{Key: "i16", Value: int16(math.MinInt16)},
+			{Key: "i32", Value: int32(math.MinInt32)},
+			{Key: "u", Value: uint(math.MaxUint)},
+			{Key: "u8", Value: uint8(math.MaxUint8)},
+			{Key: "u16", Value: uint16(math.MaxUint16)},
+			{Key: "u32", Value: uint32(math.MaxUint32)},
+			{Key: "uptr", Value: uintptr(0xdead)},
+		}},
+		{"float32", []Field{{Key: "f", Value: float32(0.1)}, {Key: "nan", Value: float32(math.NaN())}}},
+		{"stringer", []Field{{Key: "d", Value: 1500 * time.Millisecond}}},
+		{"error", []Field{{Key: "err", Value: errors.New("no route")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []Format{JSONFormat, TextFormat} {
+				recording := &bytes.Buffer{}
+				original := &bytes.Buffer{}
+				logger := New(Config{Format: format, Output: original})
+				rec := NewRecorder(recording)
+				logger.AddHook(rec)
+
+				logger.WithGroup("req").Info("m", tt.fields...)
+				require.NoError(t, rec.Err())
+				assert.NotContains(t, recording.String(), `"unknown"`)
+
+				replayed := &bytes.Buffer{}
+				_, err := Replay(recording, New(Config{Format: format, Output: replayed}))
+				require.NoError(t, err)
+				assert.Equal(t, original.String(), replayed.String())
+			}
+		})
+	}
+}
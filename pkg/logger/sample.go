@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"hash/maphash"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultSamplingWindow is used when SamplingConfig.Window is not set.
+	defaultSamplingWindow = time.Minute
+
+	// defaultSamplingMaxValues is used when SamplingConfig.MaxValues is not set.
+	defaultSamplingMaxValues = 4096
+)
+
+// SamplingConfig configures a sampler keyed on the cardinality of entry
+// values. Every entry is identified by its message and the values of Keys.
+// Values seen rarely, such as a new error message or a new endpoint, are
+// always kept, while values that repeat often are sampled, which surfaces
+// novel events while controlling volume. Entries at WarnLevel and above
+// are never sampled.
+type SamplingConfig struct {
+	// Keys lists the fields that, together with the message, identify a
+	// value, e.g. []string{"endpoint"}. Entries without one of the fields
+	// are identified by the remaining ones.
+	Keys []string
+
+	// First is the number of entries kept per value and window before
+	// sampling starts. Values below 1 are treated as 1.
+	First int
+
+	// Thereafter keeps every Thereafter-th entry of a value once First is
+	// exceeded. Zero drops all of them.
+	Thereafter int
+
+	// Window is the period after which all counters reset. Defaults to one
+	// minute.
+	Window time.Duration
+
+	// MaxValues caps the number of distinct values tracked at once. When
+	// the cap is reached the counters reset early, so memory stays bounded
+	// under unbounded cardinality. Defaults to 4096.
+	MaxValues int
+}
+
+// sampler counts entries per value hash within the current window.
+type sampler struct {
+	keys       []string
+	first      uint64
+	thereafter uint64
+	window     time.Duration
+	maxValues  int
+	seed       maphash.Seed
+
+	mu          sync.Mutex
+	counts      map[uint64]uint64
+	windowStart time.Time
+
+	dropped atomic.Uint64
+}
+
+func newSampler(cfg *SamplingConfig) *sampler {
+	if cfg == nil {
+		return nil
+	}
+
+	s := &sampler{
+		keys:       cfg.Keys,
+		first:      uint64(max(cfg.First, 1)),
+		thereafter: uint64(max(cfg.Thereafter, 0)),
+		window:     cfg.Window,
+		maxValues:  cfg.MaxValues,
+		seed:       maphash.MakeSeed(),
+	}
+	if s.window <= 0 {
+		s.window = defaultSamplingWindow
+	}
+	if s.maxValues <= 0 {
+		s.maxValues = defaultSamplingMaxValues
+	}
+	s.counts = make(map[uint64]uint64, min(s.maxValues, 256))
+
+	return s
+}
+
+// allow reports whether an entry is kept. context holds the fields of the
+// logger, fields those of the call.
+func (s *sampler) allow(msg string, context, fields []Field, now time.Time) bool {
+	key := s.hash(msg, context, fields)
+
+	s.mu.Lock()
+	if now.Sub(s.windowStart) >= s.window {
+		clear(s.counts)
+		s.windowStart = now
+	}
+
+	n, seen := s.counts[key]
+	if !seen && len(s.counts) >= s.maxValues {
+		clear(s.counts)
+	}
+	n++
+	s.counts[key] = n
+	s.mu.Unlock()
+
+	if n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0) {
+		return true
+	}
+
+	s.dropped.Add(1)
+	return false
+}
+
+// hash identifies an entry by its message and the values of s.keys. Fields
+// of the call take precedence over context fields with the same key.
+func (s *sampler) hash(msg string, context, fields []Field) uint64 {
+	var h maphash.Hash
+	h.SetSeed(s.seed)
+	h.WriteString(msg)
+
+	for _, key := range s.keys {
+		field, ok := findField(fields, key)
+		if !ok {
+			field, ok = findField(context, key)
+		}
+		if !ok {
+			continue
+		}
+		_ = h.WriteByte(0)
+		h.WriteString(key)
+		_ = h.WriteByte('=')
+		writeHashValue(&h, field.Value)
+	}
+
+	return h.Sum64()
+}
+
+// findField returns the first field with key.
+func findField(fields []Field, key string) (Field, bool) {
+	for _, field := range fields {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// writeHashValue adds value to h without allocating for common types.
+func writeHashValue(h *maphash.Hash, value interface{}) {
+	var scratch [24]byte
+
+	switch v := value.(type) {
+	case string:
+		h.WriteString(v)
+	case blockValue:
+		h.WriteString(string(v))
+	case bytesValue:
+		_, _ = h.Write(v.b)
+	case int:
+		_, _ = h.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int64:
+		_, _ = h.Write(strconv.AppendInt(scratch[:0], v, 10))
+	case float64:
+		bits := math.Float64bits(v)
+		for i := 0; i < 8; i++ {
+			scratch[i] = byte(bits >> (8 * i))
+		}
+		_, _ = h.Write(scratch[:8])
+	case bool:
+		if v {
+			_ = h.WriteByte(1)
+		} else {
+			_ = h.WriteByte(0)
+		}
+	default:
+		_, _ = h.Write(appendValue(scratch[:0], value))
+	}
+}
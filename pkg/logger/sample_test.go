@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampling_KeepsRareValues(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: buf,
+		Sampling: &SamplingConfig{
+			Keys:       []string{"endpoint"},
+			First:      2,
+			Thereafter: 5,
+		},
+	})
+
+	for i := 0; i < 100; i++ {
+		logger.Info("request", Field{Key: "endpoint", Value: "/health"})
+	}
+	logger.Info("request", Field{Key: "endpoint", Value: "/new"})
+	logger.Error("disk failure", Field{Key: "endpoint", Value: "/health"})
+
+	output := buf.String()
+	// 2 kept outright, then every 5th of the remaining 98.
+	assert.Equal(t, 2+19, strings.Count(output, "request endpoint=/health"))
+	assert.Contains(t, output, "endpoint=/new")
+	assert.Contains(t, output, "disk failure")
+	assert.Equal(t, uint64(100-21), logger.Stats().Sampled)
+}
+
+func TestSampler_DropsAllAfterFirst(t *testing.T) {
+	s := newSampler(&SamplingConfig{First: 1})
+	now := time.Now()
+
+	assert.True(t, s.allow("msg", nil, nil, now))
+	assert.False(t, s.allow("msg", nil, nil, now))
+	assert.True(t, s.allow("other", nil, nil, now))
+}
+
+func TestSampler_WindowResets(t *testing.T) {
+	s := newSampler(&SamplingConfig{First: 1, Window: time.Second})
+	now := time.Now()
+
+	assert.True(t, s.allow("msg", nil, nil, now))
+	assert.False(t, s.allow("msg", nil, nil, now.Add(500*time.Millisecond)))
+	assert.True(t, s.allow("msg", nil, nil, now.Add(time.Second)))
+}
+
+func TestSampler_MaxValuesBoundsMemory(t *testing.T) {
+	s := newSampler(&SamplingConfig{Keys: []string{"id"}, First: 1, MaxValues: 10})
+	now := time.Now()
+
+	for i := 0; i < 1000; i++ {
+		assert.True(t, s.allow("msg", nil, []Field{{Key: "id", Value: i}}, now))
+		assert.LessOrEqual(t, len(s.counts), 10)
+	}
+}
+
+func TestSampler_HashDistinguishesValues(t *testing.T) {
+	s := newSampler(&SamplingConfig{Keys: []string{"a", "b"}})
+
+	base := s.hash("m", nil, []Field{{Key: "a", Value: "x"}, {Key: "b", Value: 1}})
+	assert.Equal(t, base, s.hash("m", nil, []Field{{Key: "b", Value: 1}, {Key: "a", Value: "x"}, {Key: "c", Value: 2}}))
+	assert.NotEqual(t, base, s.hash("m", nil, []Field{{Key: "a", Value: "x"}, {Key: "b", Value: 2}}))
+	assert.NotEqual(t, base, s.hash("n", nil, []Field{{Key: "a", Value: "x"}, {Key: "b", Value: 1}}))
+	assert.NotEqual(t, s.hash("m", nil, []Field{{Key: "a", Value: 1.5}}), s.hash("m", nil, []Field{{Key: "a", Value: 2.5}}))
+}
+
+func TestSampling_KeepsWarnAndAbove(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(Config{
+		Level:           InfoLevel,
+		Output:          buf,
+		TimestampFormat: TimestampDisabled,
+		Sampling:        &SamplingConfig{First: 1, Window: time.Hour},
+	})
+
+	for range 3 {
+		logger.Info("retry")
+		logger.Warn("slow")
+		logger.Error("failed")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "INFO retry"))
+	assert.Equal(t, 3, strings.Count(buf.String(), "WARN slow"))
+	assert.Equal(t, 3, strings.Count(buf.String(), "ERROR failed"))
+	assert.Equal(t, uint64(2), logger.Stats().Sampled)
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync/atomic"
+)
+
+// secretHashPrefixBytes is the number of HMAC-SHA256 bytes rendered by
+// HashedSecret. Eight hex characters are enough to correlate entries.
+const secretHashPrefixBytes = 4
+
+// secretHashKey is the key HashedSecret values are hashed with, set by
+// SetSecretHashKey or generated once per process.
+var secretHashKey atomic.Pointer[[]byte]
+
+// secretValue wraps a sensitive value so that no encoder ever renders it.
+// It also implements fmt.Stringer and fmt.GoStringer so formatting the field
+// with the fmt package cannot leak it either.
+type secretValue struct {
+	value  string
+	hashed bool
+}
+
+// Secret returns a field whose value always renders as RedactedValue,
+// regardless of format. Use it for credentials that must never reach log
+// files, even by accident.
+//
+// Example:
+//
+//	logger.Info("connecting", logger.Secret("password", cfg.Password))
+func Secret(key, value string) Field {
+	return Field{Key: key, Value: secretValue{value: value}}
+}
+
+// HashedSecret returns a field that renders as a short HMAC-SHA256 prefix
+// of the value, e.g. "hmac:5b1f0e3a". This allows correlating entries that
+// share a credential without revealing it.
+//
+// The HMAC key is random per process unless set with SetSecretHashKey, so
+// by default the same value only renders the same within one process. The
+// prefix is short and only hides the value as long as the key stays
+// secret; values with little entropy, such as PINs or phone numbers, can
+// be found by trying every candidate against a known key.
+func HashedSecret(key, value string) Field {
+	return Field{Key: key, Value: secretValue{value: value, hashed: true}}
+}
+
+// SetSecretHashKey sets the HMAC key of HashedSecret values, so they render
+// the same across processes and restarts sharing the key. Keep the key out
+// of the logs it protects. A nil key restores a random per-process key. It
+// is safe to call concurrently with logging.
+func SetSecretHashKey(key []byte) {
+	if key == nil {
+		secretHashKey.Store(nil)
+		return
+	}
+	key = append([]byte(nil), key...)
+	secretHashKey.Store(&key)
+}
+
+// loadSecretHashKey returns the key set with SetSecretHashKey, generating a
+// random one on first use.
+func loadSecretHashKey() []byte {
+	if key := secretHashKey.Load(); key != nil {
+		return *key
+	}
+	key := make([]byte, sha256.Size)
+	_, _ = rand.Read(key)
+	if !secretHashKey.CompareAndSwap(nil, &key) {
+		if current := secretHashKey.Load(); current != nil {
+			return *current
+		}
+	}
+	return key
+}
+
+// String implements fmt.Stringer.
+func (s secretValue) String() string {
+	return string(s.appendTo(nil))
+}
+
+// GoString implements fmt.GoStringer.
+func (s secretValue) GoString() string {
+	return s.String()
+}
+
+// appendTo appends the rendered, non-sensitive form of the secret.
+func (s secretValue) appendTo(buf []byte) []byte {
+	if !s.hashed {
+		return append(buf, RedactedValue...)
+	}
+
+	mac := hmac.New(sha256.New, loadSecretHashKey())
+	mac.Write([]byte(s.value))
+	buf = append(buf, "hmac:"...)
+	return hex.AppendEncode(buf, mac.Sum(nil)[:secretHashPrefixBytes])
+}
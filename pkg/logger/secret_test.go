@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecret_NeverRendersValue(t *testing.T) {
+	for _, format := range []Format{TextFormat, JSONFormat} {
+		buf := &bytes.Buffer{}
+
+		logger := New(Config{Level: InfoLevel, Format: format, Output: buf})
+		logger.Info("connecting", Secret("password", "hunter2"))
+
+		output := buf.String()
+		assert.NotContains(t, output, "hunter2")
+		assert.Contains(t, output, RedactedValue)
+	}
+
+	field := Secret("password", "hunter2")
+	assert.NotContains(t, fmt.Sprintf("%v %+v %#v %s", field, field, field, field.Value), "hunter2")
+}
+
+func TestHashedSecret_RendersHMACPrefix(t *testing.T) {
+	t.Cleanup(func() { SetSecretHashKey(nil) })
+	render := func() string {
+		buf := &bytes.Buffer{}
+		logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf, TimestampFormat: TimestampDisabled})
+		logger.Info("token used", HashedSecret("token", "test"))
+		assert.NotContains(t, buf.String(), `"test"`)
+		return buf.String()
+	}
+
+	random := render()
+	assert.Regexp(t, `"token":"hmac:[0-9a-f]{8}"`, random)
+	assert.Equal(t, random, render(), "stable within the process")
+	assert.NotContains(t, random, "9f86d081", "not the unkeyed sha256")
+
+	SetSecretHashKey([]byte("key"))
+	// hmac-sha256("key", "test") = 02afb56304902c65...
+	assert.Contains(t, render(), `"token":"hmac:02afb563"`)
+
+	SetSecretHashKey(nil)
+	assert.NotContains(t, render(), "02afb563")
+}
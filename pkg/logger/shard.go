@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"bufio"
+	"cmp"
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Keys of the fields added by Config.ShardID.
+const (
+	ShardKey    = "shard"
+	SequenceKey = "seq"
+)
+
+// appendShardFields appends the ShardKey and SequenceKey fields of the
+// next entry without modifying the backing array of fields.
+func (l *Logger) appendShardFields(fields []Field) []Field {
+	return append(fields[:len(fields):len(fields)],
+		Field{Key: ShardKey, Value: l.config.ShardID},
+		Field{Key: SequenceKey, Value: int64(l.sequence.Add(1))},
+	)
+}
+
+// shardLine is a line read by MergeShards.
+type shardLine struct {
+	data  []byte
+	time  time.Time
+	seq   int64
+	index int // position in the input, keeps lines without seq in order
+}
+
+// shardStream holds the lines of one shard in sequence order.
+type shardStream struct {
+	shard string
+	lines []shardLine
+}
+
+// shardHeap orders shard streams by the timestamp of their next line.
+type shardHeap []*shardStream
+
+func (h shardHeap) Len() int { return len(h) }
+
+func (h shardHeap) Less(i, j int) bool {
+	a, b := h[i].lines[0], h[j].lines[0]
+	if c := a.time.Compare(b.time); c != 0 {
+		return c < 0
+	}
+	return h[i].shard < h[j].shard
+}
+
+func (h shardHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *shardHeap) Push(x any) { *h = append(*h, x.(*shardStream)) }
+
+func (h *shardHeap) Pop() any {
+	old := *h
+	s := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return s
+}
+
+// MergeShards reads JSON lines written by loggers configured with
+// Config.ShardID from srcs, e.g. a log file shared by several processes,
+// and writes them to dst in a single global order:
+//
+//	err := logger.MergeShards(out, shared, archived)
+//
+// The lines of each shard are written in SequenceKey order, even where
+// their timestamps disagree, and the shards are interleaved by timestamp,
+// ties broken by shard ID. Lines without a ShardKey, such as those of
+// unsharded loggers, form a shard of their own in input order.
+//
+// MergeShards expects the default key names and timestamp format; lines
+// written with Config.KeyMap or Config.TimestampFormat are merged with
+// Config.MergeShards instead.
+//
+// Lines are copied unchanged. MergeShards holds all input in memory and
+// stops at the first line that is not a JSON object, reporting its source
+// and line number.
+func MergeShards(dst io.Writer, srcs ...io.Reader) error {
+	return Config{}.MergeShards(dst, srcs...)
+}
+
+// MergeShards is like the package-level MergeShards but reads the shard,
+// sequence and timestamp keys as renamed by c.KeyMap and parses the
+// timestamps according to c.TimestampFormat, so it takes the Config the
+// lines were written with:
+//
+//	err := config.MergeShards(out, shared)
+//
+// Lines of a sink with its own SinkConfig.KeyMap are merged with a Config
+// holding that KeyMap. With TimestampDisabled the shards are interleaved by
+// shard ID alone.
+func (c Config) MergeShards(dst io.Writer, srcs ...io.Reader) error {
+	parser := newShardParser(c)
+	shards := make(map[string]*shardStream)
+	index := 0
+	for i, src := range srcs {
+		scanner := bufio.NewScanner(src)
+		scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+		for n := 1; scanner.Scan(); n++ {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			shard, line, err := parser.parse(scanner.Bytes())
+			if err != nil {
+				return fmt.Errorf("logger: merge source %d line %d: %w", i, n, err)
+			}
+			line.index = index
+			index++
+
+			s := shards[shard]
+			if s == nil {
+				s = &shardStream{shard: shard}
+				shards[shard] = s
+			}
+			s.lines = append(s.lines, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("logger: merge source %d: %w", i, err)
+		}
+	}
+
+	h := make(shardHeap, 0, len(shards))
+	for _, s := range shards {
+		slices.SortStableFunc(s.lines, func(a, b shardLine) int {
+			return cmp.Or(cmp.Compare(a.seq, b.seq), cmp.Compare(a.index, b.index))
+		})
+		h = append(h, s)
+	}
+	heap.Init(&h)
+
+	w := bufio.NewWriter(dst)
+	for h.Len() > 0 {
+		s := h[0]
+		_, _ = w.Write(s.lines[0].data)
+		if err := w.WriteByte('\n'); err != nil {
+			return err
+		}
+		s.lines = s.lines[1:]
+		if len(s.lines) == 0 {
+			heap.Pop(&h)
+		} else {
+			heap.Fix(&h, 0)
+		}
+	}
+	return w.Flush()
+}
+
+// shardParser extracts the shard, sequence number and timestamp of JSON
+// lines written with a given key map and timestamp format.
+type shardParser struct {
+	shardKey     string
+	sequenceKey  string
+	timestampKey string
+	format       TimestampFormat
+}
+
+func newShardParser(config Config) shardParser {
+	return shardParser{
+		shardKey:     remapKey(config.KeyMap, ShardKey),
+		sequenceKey:  remapKey(config.KeyMap, SequenceKey),
+		timestampKey: remapKey(config.KeyMap, TimestampKey),
+		format:       config.TimestampFormat,
+	}
+}
+
+func (p shardParser) parse(data []byte) (string, shardLine, error) {
+	var entry map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", shardLine{}, err
+	}
+	line := shardLine{data: slices.Clone(data)}
+
+	var shard string
+	if raw, ok := entry[p.shardKey]; ok {
+		if err := json.Unmarshal(raw, &shard); err != nil {
+			return "", shardLine{}, fmt.Errorf("%s: %w", p.shardKey, err)
+		}
+	}
+	if raw, ok := entry[p.sequenceKey]; ok && shard != "" {
+		if err := json.Unmarshal(raw, &line.seq); err != nil {
+			return "", shardLine{}, fmt.Errorf("%s: %w", p.sequenceKey, err)
+		}
+	}
+	if raw, ok := entry[p.timestampKey]; ok {
+		t, err := p.parseTime(raw)
+		if err != nil {
+			return "", shardLine{}, fmt.Errorf("%s: %w", p.timestampKey, err)
+		}
+		line.time = t
+	}
+	return shard, line, nil
+}
+
+// parseTime reverses appendJSONTimestamp.
+func (p shardParser) parseTime(raw json.RawMessage) (time.Time, error) {
+	switch p.format {
+	case TimestampDisabled:
+		return time.Time{}, nil
+	case TimestampUnix, TimestampUnixMilli:
+		var n int64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return time.Time{}, err
+		}
+		if p.format == TimestampUnix {
+			return time.Unix(n, 0), nil
+		}
+		return time.UnixMilli(n), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return time.Time{}, err
+	}
+	layout := string(p.format)
+	if p.format == TimestampDefault || p.format == TimestampRFC3339Nano {
+		layout = time.RFC3339Nano
+	}
+	return time.Parse(layout, s)
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ShardID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf, ShardID: "web-1"})
+
+	log.Info("first")
+	log.With(String("user", "ada")).Info("second")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"shard":"web-1","seq":1}`)
+	assert.Contains(t, lines[1], `"user":"ada","shard":"web-1","seq":2}`)
+}
+
+func TestMergeShards(t *testing.T) {
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	line := func(shard string, seq int, offset time.Duration) string {
+		ts := base.Add(offset).Format(time.RFC3339Nano)
+		if shard == "" {
+			return fmt.Sprintf(`{"timestamp":%q,"level":"INFO","message":"plain"}`, ts)
+		}
+		return fmt.Sprintf(`{"timestamp":%q,"level":"INFO","message":"%s-%d","shard":%q,"seq":%d}`, ts, shard, seq, shard, seq)
+	}
+
+	want := []string{
+		line("a", 1, 0),
+		line("b", 1, time.Millisecond),
+		line("a", 2, 2*time.Millisecond),
+		// The clock of a stepped back, but a-3 still follows a-2.
+		line("a", 3, time.Millisecond),
+		line("b", 2, 3*time.Millisecond),
+		line("", 0, 4*time.Millisecond),
+		line("b", 3, 5*time.Millisecond),
+	}
+
+	shuffled := make([]string, len(want))
+	copy(shuffled, want)
+	rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	half := len(shuffled) / 2
+
+	var out bytes.Buffer
+	err := MergeShards(&out,
+		strings.NewReader(strings.Join(shuffled[:half], "\n")+"\n\n"),
+		strings.NewReader(strings.Join(shuffled[half:], "\n")),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(want, "\n")+"\n", out.String())
+}
+
+func TestMergeShards_RoundTrip(t *testing.T) {
+	shared := &bytes.Buffer{}
+	a := New(Config{Level: InfoLevel, Format: JSONFormat, Output: shared, ShardID: "a"})
+	b := New(Config{Level: InfoLevel, Format: JSONFormat, Output: shared, ShardID: "b"})
+	for i := range 5 {
+		a.Info("tick", Int("i", i))
+		b.Info("tick", Int("i", i))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, MergeShards(&out, shared))
+
+	next := map[string]float64{"a": 1, "b": 1}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		shard := entry[ShardKey].(string)
+		assert.Equal(t, next[shard], entry[SequenceKey])
+		next[shard]++
+	}
+	assert.Equal(t, map[string]float64{"a": 6, "b": 6}, next)
+}
+
+func TestMergeShards_Malformed(t *testing.T) {
+	err := MergeShards(&bytes.Buffer{}, strings.NewReader("{}\n"), strings.NewReader("{}\nnot json\n"))
+	assert.ErrorContains(t, err, "merge source 1 line 2")
+}
+
+func TestConfig_MergeShards(t *testing.T) {
+	shared := &bytes.Buffer{}
+	config := Config{
+		Level:           InfoLevel,
+		Format:          JSONFormat,
+		Output:          shared,
+		KeyMap:          map[string]string{TimestampKey: "@t", ShardKey: "node", SequenceKey: "n"},
+		TimestampFormat: TimestampUnixMilli,
+	}
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := func(offset time.Duration) func() time.Time {
+		return func() time.Time { return base.Add(offset) }
+	}
+
+	a, b := config, config
+	a.ShardID, a.Clock = "a", clock(2*time.Millisecond)
+	b.ShardID, b.Clock = "b", clock(time.Millisecond)
+	New(a).Info("from a")
+	New(b).Info("from b")
+
+	var out bytes.Buffer
+	require.NoError(t, config.MergeShards(&out, bytes.NewReader(shared.Bytes())))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"node":"b","n":1`)
+	assert.Contains(t, lines[1], `"node":"a","n":1`)
+
+	err := MergeShards(&bytes.Buffer{}, bytes.NewReader(shared.Bytes()))
+	assert.NoError(t, err, "unknown keys are ignored")
+	err = Config{TimestampFormat: TimestampUnixMilli}.MergeShards(&bytes.Buffer{}, strings.NewReader(`{"timestamp":"now"}`))
+	assert.ErrorContains(t, err, "merge source 0 line 1: timestamp")
+}
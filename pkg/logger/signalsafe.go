@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// signalRingSize is the number of entries the ring of
+	// AsyncSignalSafe holds; a power of two.
+	signalRingSize = 1024
+
+	// signalDrainInterval is how often the ring is drained. Producers
+	// never wake the drainer, since that would take a lock.
+	signalDrainInterval = 10 * time.Millisecond
+)
+
+// SignalSafeLogger logs from contexts where the normal logging path is
+// unsafe, such as signal handlers, finalizers and GC callbacks. Its
+// methods never allocate, lock or block: they copy the entry into a
+// pre-allocated lock-free ring and return, and a background goroutine
+// writes the entries through the logger later. When the ring is full the
+// entry is dropped and counted.
+//
+// The facade is deliberately constrained: messages and keys should be
+// constants, and an entry carries at most one integer field besides the
+// fields of the logger it was created from.
+type SignalSafeLogger struct {
+	l    *Logger
+	ring *signalRing
+}
+
+// AsyncSignalSafe returns the signal-safe facade of l. Call it during
+// initialization and keep the result; it starts the background goroutine
+// on first use, which is not itself safe in a signal handler. Entries
+// still in the ring are written by Close; entries logged after Close are
+// dropped.
+//
+// Example:
+//
+//	safe := log.AsyncSignalSafe()
+//	runtime.SetFinalizer(conn, func(c *Conn) {
+//		safe.LogInt(logger.WarnLevel, "connection leaked", "fd", int64(c.fd))
+//	})
+func (l *Logger) AsyncSignalSafe() *SignalSafeLogger {
+	l.signalOnce.Do(func() {
+		l.signalRing = newSignalRing()
+	})
+	return &SignalSafeLogger{l: l, ring: l.signalRing}
+}
+
+// Log enqueues an entry without fields. It reports whether the entry was
+// enqueued; entries below the level of the logger are not.
+func (s *SignalSafeLogger) Log(level Level, msg string) bool {
+	if !s.l.enabled(level) {
+		return false
+	}
+	return s.ring.push(signalEntry{logger: s.l, time: time.Now(), level: level, msg: msg})
+}
+
+// LogInt enqueues an entry with an integer field, like Log.
+func (s *SignalSafeLogger) LogInt(level Level, msg, key string, value int64) bool {
+	if !s.l.enabled(level) {
+		return false
+	}
+	return s.ring.push(signalEntry{logger: s.l, time: time.Now(), level: level, msg: msg, key: key, value: value, hasField: true})
+}
+
+// Dropped returns the number of entries dropped because the ring was full
+// or closed.
+func (s *SignalSafeLogger) Dropped() uint64 {
+	return s.ring.dropped.Load()
+}
+
+// signalEntry is an entry waiting in the ring.
+type signalEntry struct {
+	logger   *Logger
+	time     time.Time
+	level    Level
+	msg      string
+	key      string
+	value    int64
+	hasField bool
+}
+
+// signalSlot is a ring slot. seq tells producers and the consumer whose
+// turn it is, as in Dmitry Vyukov's bounded MPMC queue.
+type signalSlot struct {
+	seq   atomic.Uint64
+	entry signalEntry
+}
+
+// signalRing is a bounded lock-free queue with many producers and a
+// single consumer.
+type signalRing struct {
+	slots   [signalRingSize]signalSlot
+	tail    atomic.Uint64 // next position to write
+	head    uint64        // next position to read, owned by the consumer
+	dropped atomic.Uint64
+	closed  atomic.Bool
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newSignalRing() *signalRing {
+	r := &signalRing{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	for i := range r.slots {
+		r.slots[i].seq.Store(uint64(i))
+	}
+	go r.run()
+	return r
+}
+
+// push copies e into the ring, or drops it when the ring is full.
+func (r *signalRing) push(e signalEntry) bool {
+	if r.closed.Load() {
+		r.dropped.Add(1)
+		return false
+	}
+	for {
+		pos := r.tail.Load()
+		slot := &r.slots[pos&(signalRingSize-1)]
+		switch diff := int64(slot.seq.Load()) - int64(pos); {
+		case diff == 0:
+			if r.tail.CompareAndSwap(pos, pos+1) {
+				slot.entry = e
+				slot.seq.Store(pos + 1)
+				return true
+			}
+		case diff < 0:
+			r.dropped.Add(1)
+			return false
+		}
+		// Another producer claimed pos; retry with the new tail.
+	}
+}
+
+// pop removes the oldest published entry. Only the drain goroutine calls
+// pop.
+func (r *signalRing) pop() (signalEntry, bool) {
+	slot := &r.slots[r.head&(signalRingSize-1)]
+	if slot.seq.Load() != r.head+1 {
+		return signalEntry{}, false
+	}
+	e := slot.entry
+	slot.entry = signalEntry{}
+	slot.seq.Store(r.head + signalRingSize)
+	r.head++
+	return e, true
+}
+
+// run drains the ring every signalDrainInterval until close.
+func (r *signalRing) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(signalDrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			r.drain()
+			return
+		case <-ticker.C:
+			r.drain()
+		}
+	}
+}
+
+// drain writes the entries in the ring through their loggers.
+func (r *signalRing) drain() {
+	for {
+		e, ok := r.pop()
+		if !ok {
+			return
+		}
+		var fields []Field
+		if e.hasField {
+			fields = []Field{{Key: e.key, Value: e.value}}
+		}
+		e.logger.logAt(context.Background(), e.time, e.level, e.msg, fields)
+	}
+}
+
+// close writes the remaining entries and stops the drain goroutine.
+func (r *signalRing) close() {
+	r.stopOnce.Do(func() {
+		r.closed.Store(true)
+		close(r.stop)
+	})
+	<-r.done
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalSafeLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf})
+	safe := log.With(String("component", "gc")).AsyncSignalSafe()
+
+	assert.False(t, safe.Log(DebugLevel, "filtered"))
+	assert.True(t, safe.Log(InfoLevel, "finalizer ran"))
+	assert.True(t, safe.LogInt(WarnLevel, "connection leaked", "fd", 7))
+	assert.Same(t, log.AsyncSignalSafe().ring, safe.ring, "the ring is shared")
+
+	require.NoError(t, log.Close())
+	out := buf.String()
+	assert.Contains(t, out, "INFO finalizer ran component=gc\n")
+	assert.Contains(t, out, "WARN connection leaked component=gc fd=7\n")
+	assert.NotContains(t, out, "filtered")
+
+	assert.False(t, safe.Log(InfoLevel, "after close"))
+	assert.Equal(t, uint64(1), safe.Dropped())
+}
+
+func TestSignalSafeLogger_NoAllocations(t *testing.T) {
+	log := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}})
+	defer log.Close()
+	safe := log.AsyncSignalSafe()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		safe.LogInt(InfoLevel, "tick", "n", 1)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestSignalRing_Full(t *testing.T) {
+	r := &signalRing{}
+	for i := range r.slots {
+		r.slots[i].seq.Store(uint64(i))
+	}
+
+	for i := range signalRingSize {
+		require.True(t, r.push(signalEntry{value: int64(i)}))
+	}
+	assert.False(t, r.push(signalEntry{}))
+	assert.Equal(t, uint64(1), r.dropped.Load())
+
+	e, ok := r.pop()
+	require.True(t, ok)
+	assert.Equal(t, int64(0), e.value)
+	assert.True(t, r.push(signalEntry{value: signalRingSize}), "a popped slot is reused")
+
+	for i := 1; i <= signalRingSize; i++ {
+		e, ok := r.pop()
+		require.True(t, ok)
+		assert.Equal(t, int64(i), e.value)
+	}
+	_, ok = r.pop()
+	assert.False(t, ok)
+}
+
+func TestSignalSafeLogger_Concurrent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf})
+	safe := log.AsyncSignalSafe()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				safe.LogInt(InfoLevel, "tick", "i", int64(i))
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, log.Close())
+
+	written := strings.Count(buf.String(), "tick")
+	assert.Equal(t, 800, written+int(safe.Dropped()))
+}
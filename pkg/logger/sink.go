@@ -0,0 +1,451 @@
+package logger
+
+import (
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+// SinkConfig describes an additional output of a Logger with its own
+// minimum level and format.
+type SinkConfig struct {
+	// Output is where entries for this sink are written. Required.
+	Output io.Writer
+
+	// Level is the minimum level written to this sink.
+	Level Level
+
+	// Format is the output format of this sink.
+	Format Format
+
+	// Color selects whether ConsoleFormat colors the entries of this
+	// sink, like Config.Color.
+	Color ColorMode
+
+	// Encoder, when set, encodes the entries of this sink instead of
+	// Format. Lines passed to WriteRaw are written as they are.
+	Encoder Encoder
+
+	// BufferSize enables buffering for this sink when > 0, like
+	// Config.BufferSize.
+	BufferSize int
+
+	// FlushInterval bounds how long entries stay buffered, like
+	// Config.FlushInterval.
+	FlushInterval time.Duration
+
+	// SyncWrites commits every write to stable storage, like
+	// Config.SyncWrites.
+	SyncWrites bool
+
+	// BufferShards splits the buffer of this sink into shards when > 1,
+	// like Config.BufferShards.
+	BufferShards int
+
+	// KeyMap renames keys for this sink, like Config.KeyMap, which applies
+	// when KeyMap is nil. An empty map keeps the original keys.
+	KeyMap map[string]string
+}
+
+// syncer is implemented by outputs that can commit written data to stable
+// storage, such as *os.File.
+type syncer interface {
+	Sync() error
+}
+
+// RecordWriter is implemented by outputs that need the structured record
+// in addition to its encoding, such as transports that derive headers or
+// metadata from the level and fields. When an output implements
+// RecordWriter, WriteRecord is called once per entry instead of Write and
+// the output is never batched by the Logger.
+//
+// entry holds the newline-terminated encoding of r in the sink format.
+// Neither r nor entry may be retained after WriteRecord returns.
+type RecordWriter interface {
+	WriteRecord(r *Record, entry []byte) error
+}
+
+// sink is a single output of a Logger. Every sink batches independently
+// according to its own BatchLimits.
+type sink struct {
+	output   io.Writer
+	records  RecordWriter
+	level    Level
+	maxLevel Level
+	format   Format
+	color    bool
+	encoder  Encoder
+	keyMap   *keyMap
+	key      encodingKey
+	syncer   syncer
+
+	// metrics, writeErrors and onWriteError are the settings of the
+	// logger configuration.
+	metrics      logmetrics.Collector
+	writeErrors  WriteErrorPolicy
+	onWriteError func(error)
+
+	// internal is the logger of Config.InternalOutput, or nil.
+	internal *Logger
+
+	// budget is the buffer budget of Config.MemoryBudget, or nil.
+	budget *bufferBudget
+
+	// profiled marks the sinks created from Config.Output and
+	// Config.ErrorOutput, which follow Logger.SetProfile.
+	profiled bool
+
+	// batching and syncWrites may change at runtime through SetProfile.
+	// batching is only set to false with mu held and the buffer flushed.
+	batching   atomic.Bool
+	syncWrites atomic.Bool
+
+	mu         sync.Mutex
+	buffer     []byte
+	batch      BatchLimits
+	batchCount int
+	batchStart time.Time
+	batchTimer *time.Timer
+
+	// delivery measures delivery trace markers; traceStart is the
+	// injection time of the oldest marker in the buffer.
+	delivery   latencyRecorder
+	traceStart time.Time
+
+	// shards are the buffers of SinkConfig.BufferShards, nil otherwise.
+	// shardHints hands out shard indexes: sync.Pool keeps an item per P,
+	// so the goroutines running on a P keep appending to the same shard.
+	// timerArmed is set while batchTimer runs for entries in the shards.
+	shards     []bufferShard
+	shardHints sync.Pool
+	nextShard  atomic.Uint32
+	timerArmed atomic.Bool
+
+	// shardSeq numbers the entries of the shards, which mergePos, the
+	// position in each shard, merges back in order.
+	shardSeq atomic.Uint64
+	mergePos []int
+}
+
+// newSink creates a sink for cfg, reporting to the metrics, write error
+// handlers and internal logger of c.
+func (c *core) newSink(cfg SinkConfig) *sink {
+	s := &sink{
+		metrics:      c.config.Metrics,
+		writeErrors:  c.config.WriteErrors,
+		onWriteError: c.config.OnWriteError,
+		internal:     c.internal,
+		budget:       c.buffers,
+		output:       cfg.Output,
+		level:        cfg.Level,
+		maxLevel:     math.MaxInt8,
+		format:       cfg.Format,
+		encoder:      cfg.Encoder,
+		keyMap:       c.keyMap,
+	}
+	if cfg.KeyMap != nil {
+		s.keyMap = newKeyMap(cfg.KeyMap)
+	}
+	if cfg.Format == ConsoleFormat {
+		s.color = useColor(cfg.Color, cfg.Output)
+	}
+	s.key = newEncodingKey(s)
+	s.records, _ = cfg.Output.(RecordWriter)
+	s.syncer, _ = cfg.Output.(syncer)
+	if r, ok := cfg.Output.(internalReporter); ok && c.internal != nil {
+		r.setInternal(c.internal)
+	}
+	if o, ok := cfg.Output.(budgetedOutput); ok && c.buffers != nil {
+		o.setBufferBudget(c.buffers)
+	}
+	if cfg.BufferShards > 1 && s.records == nil {
+		s.newBufferShards(cfg.BufferShards)
+	}
+	s.configure(cfg.BufferSize, cfg.FlushInterval, cfg.SyncWrites)
+
+	return s
+}
+
+// configure sets the buffering and sync policy of the sink, flushing
+// entries buffered under the previous policy.
+func (s *sink) configure(bufferSize int, flushInterval time.Duration, syncWrites bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Writers to shards check batching under the shard lock, so clearing
+	// it before the shards are merged leaves no entry behind.
+	s.batching.Store(false)
+	s.flush()
+	s.batch = resolveBatchLimits(bufferSize, flushInterval, s.output)
+	batching := s.batch != BatchLimits{} && s.records == nil
+	if batching && cap(s.buffer) < s.batch.MaxBytes {
+		s.allocBuffer(&s.buffer, s.batch.MaxBytes)
+	}
+	if s.shards != nil {
+		s.configureBufferShards()
+	}
+	s.batching.Store(batching)
+	s.syncWrites.Store(syncWrites && s.syncer != nil)
+}
+
+// allocBuffer gives the empty buffer buf a capacity of size bytes. When the
+// buffer budget leaves no room, buf grows as entries are added instead.
+func (s *sink) allocBuffer(buf *[]byte, size int) {
+	if s.budget == nil {
+		*buf = make([]byte, 0, size)
+		return
+	}
+	s.budget.grow(buf, size-len(*buf))
+}
+
+// growBuffer makes room for n more bytes in the batch buffer buf, and
+// reports false when the buffer budget leaves no room.
+func (s *sink) growBuffer(buf *[]byte, n int) bool {
+	return s.budget == nil || s.budget.grow(buf, n)
+}
+
+// accepts reports whether entries at level are written to the sink.
+func (s *sink) accepts(level Level) bool {
+	return level >= s.level && level <= s.maxLevel
+}
+
+// write delivers r and its newline-terminated encoding to the sink.
+func (s *sink) write(r *Record, entry []byte) {
+	if s.records != nil {
+		s.writeRecord(r, entry)
+		s.sync()
+		s.observeTrace(r)
+		return
+	}
+	if s.shards != nil && s.batching.Load() && s.writeBufferShard(r, entry) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.batching.Load() {
+		// A single Write per entry keeps message boundaries intact for
+		// datagram-oriented outputs. Holding the lock keeps the entries of
+		// concurrent goroutines from interleaving, even when a short write
+		// is retried or the output is not safe for concurrent use.
+		s.writeOutput(entry)
+		s.sync()
+		s.observeTrace(r)
+		return
+	}
+
+	if s.batch.MaxBytes > 0 && len(s.buffer)+len(entry) > s.batch.MaxBytes {
+		s.flush()
+	}
+	if !s.growBuffer(&s.buffer, len(entry)) {
+		// The buffer budget leaves no room for the entry: write the batch
+		// early, and the entry directly if it still does not fit.
+		s.flush()
+		if cap(s.buffer) < len(entry) {
+			s.writeOutput(entry)
+			s.sync()
+			s.observeTrace(r)
+			return
+		}
+	}
+	if s.batchCount == 0 {
+		s.startBatch()
+	}
+	s.buffer = append(s.buffer, entry...)
+	s.batchCount++
+	if r != nil && !r.traced.IsZero() && s.traceStart.IsZero() {
+		s.traceStart = r.traced
+	}
+
+	if s.batch.MaxEvents > 0 && s.batchCount >= s.batch.MaxEvents {
+		s.flush()
+	}
+}
+
+// writeRaw delivers a line passed to WriteRaw. RecordWriter outputs receive
+// it through Write, since there is no structured record to go with it.
+func (s *sink) writeRaw(entry []byte) {
+	if s.records != nil {
+		s.writeOutput(entry)
+		return
+	}
+	s.write(nil, entry)
+}
+
+// Flush writes all buffered entries to the output.
+func (s *sink) Flush() {
+	if s.batching.Load() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.flush()
+	}
+}
+
+// flush writes all buffered content to the output, merging the shards
+// first. It must be called with s.mu held.
+func (s *sink) flush() {
+	if s.shards != nil {
+		s.mergeBufferShards()
+	}
+	s.writeBuffer()
+}
+
+// writeBuffer writes s.buffer to the output as a single batch. It must be
+// called with s.mu held.
+func (s *sink) writeBuffer() {
+	if len(s.buffer) > 0 {
+		s.writeOutput(s.buffer)
+		s.buffer = s.buffer[:0]
+		if s.metrics != nil {
+			s.metrics.Flushed()
+		}
+		s.sync()
+		if !s.traceStart.IsZero() {
+			s.delivery.observe(time.Since(s.traceStart))
+			s.traceStart = time.Time{}
+		}
+	}
+	s.batchCount = 0
+}
+
+// writeOutput writes p to the output, applying the write error policy.
+func (s *sink) writeOutput(p []byte) {
+	err := s.retry(func() error {
+		n, err := s.output.Write(p)
+		s.observeWrite(n, err)
+		p = p[min(max(n, 0), len(p)):]
+		return err
+	})
+	if err != nil {
+		s.writeFailed(p, err)
+	}
+}
+
+// writeRecord passes r and entry to the RecordWriter output, applying the
+// write error policy.
+func (s *sink) writeRecord(r *Record, entry []byte) {
+	err := s.retry(func() error {
+		err := s.records.WriteRecord(r, entry)
+		if err == nil {
+			s.observeWrite(len(entry), nil)
+		} else {
+			s.observeWrite(0, err)
+		}
+		return err
+	})
+	if err != nil {
+		s.writeFailed(entry, err)
+	}
+}
+
+// observeWrite reports a write of n bytes that returned err to the
+// metrics.
+func (s *sink) observeWrite(n int, err error) {
+	if s.metrics == nil {
+		return
+	}
+	if err != nil {
+		s.metrics.WriteError()
+	}
+	if n > 0 {
+		s.metrics.BytesWritten(n)
+	}
+}
+
+// observeTrace records the delivery of r when it is a trace marker.
+func (s *sink) observeTrace(r *Record) {
+	if r != nil && !r.traced.IsZero() {
+		s.delivery.observe(time.Since(r.traced))
+	}
+}
+
+// sync commits written entries to stable storage when SyncWrites is set.
+func (s *sink) sync() {
+	if s.syncWrites.Load() {
+		_ = s.syncer.Sync()
+	}
+}
+
+// AddSink adds an output to the logger at runtime. It is safe to call
+// concurrently with logging methods.
+//
+// Example:
+//
+//	file, _ := os.Create("debug.log")
+//	logger.AddSink(logger.SinkConfig{
+//		Output: file,
+//		Level:  logger.DebugLevel,
+//		Format: logger.JSONFormat,
+//	})
+func (l *Logger) AddSink(cfg SinkConfig) {
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+
+	var sinks []*sink
+	if current := l.sinks.Load(); current != nil {
+		sinks = append(sinks, *current...)
+	}
+	sinks = append(sinks, l.newSink(cfg))
+
+	l.storeSinks(sinks)
+}
+
+// storeSinks publishes the sink list and the lowest level any sink accepts.
+// It must be called with l.sinksMu held.
+func (l *Logger) storeSinks(sinks []*sink) {
+	minLevel := Level(math.MaxInt8)
+	for _, s := range sinks {
+		minLevel = min(minLevel, s.level)
+	}
+
+	l.minLevel.Store(int32(minLevel))
+	l.sinks.Store(&sinks)
+}
+
+// maxCachedEncodings is the number of distinct formats and encoders whose
+// encoding of a single record is shared between sinks.
+const maxCachedEncodings = 8
+
+// encodings caches the encoded forms of one record, so sinks sharing a
+// format reuse the same bytes.
+type encodings struct {
+	n     int
+	items [maxCachedEncodings]struct {
+		key encodingKey
+		buf *[]byte
+	}
+}
+
+// get returns the cached encoding for key, or nil.
+func (e *encodings) get(key encodingKey) []byte {
+	for i := 0; i < e.n; i++ {
+		if e.items[i].key == key {
+			return *e.items[i].buf
+		}
+	}
+	return nil
+}
+
+// put caches buf as the encoding for key. It reports false when the
+// cache is full, in which case the caller still owns buf.
+func (e *encodings) put(key encodingKey, buf *[]byte) bool {
+	if e.n == maxCachedEncodings {
+		return false
+	}
+	e.items[e.n].key = key
+	e.items[e.n].buf = buf
+	e.n++
+	return true
+}
+
+// release returns all cached buffers to the logger.
+func (e *encodings) release(l *Logger) {
+	for i := 0; i < e.n; i++ {
+		l.putBuffer(e.items[i].buf)
+	}
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputs_PerSinkLevelAndFormat(t *testing.T) {
+	console := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: TextFormat,
+		Output: console,
+		Outputs: []SinkConfig{
+			{Output: file, Level: DebugLevel, Format: JSONFormat},
+		},
+	})
+
+	logger.Debug("debug details")
+	logger.Info("started")
+
+	assert.NotContains(t, console.String(), "debug details")
+	assert.Contains(t, console.String(), "INFO started")
+	assert.Contains(t, file.String(), `"message":"debug details"`)
+	assert.Contains(t, file.String(), `"message":"started"`)
+}
+
+func TestOutputs_WithoutPrimaryOutput(t *testing.T) {
+	errs := &bytes.Buffer{}
+
+	logger := New(Config{
+		Outputs: []SinkConfig{
+			{Output: errs, Level: ErrorLevel, Format: TextFormat},
+		},
+	})
+
+	logger.Warn("ignored")
+	logger.Error("failed")
+
+	assert.Equal(t, 1, strings.Count(errs.String(), "\n"))
+	assert.Contains(t, errs.String(), "ERROR failed")
+}
+
+func TestOutputs_SharedFormatEncodedOnce(t *testing.T) {
+	first := &bytes.Buffer{}
+	second := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Format: JSONFormat,
+		Output: first,
+		Outputs: []SinkConfig{
+			{Output: second, Level: InfoLevel, Format: JSONFormat},
+		},
+	})
+
+	logger.Info("same bytes")
+
+	require.NotEmpty(t, first.String())
+	assert.Equal(t, first.String(), second.String())
+}
+
+func TestOutputs_IndependentBuffering(t *testing.T) {
+	direct := &bytes.Buffer{}
+	buffered := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:  InfoLevel,
+		Output: direct,
+		Outputs: []SinkConfig{
+			{Output: buffered, Level: InfoLevel, BufferSize: 4096},
+		},
+	})
+
+	logger.Info("entry")
+	assert.Contains(t, direct.String(), "entry")
+	assert.Empty(t, buffered.String())
+
+	logger.Flush()
+	assert.Contains(t, buffered.String(), "entry")
+}
+
+func TestLogger_AddSink(t *testing.T) {
+	primary := &bytes.Buffer{}
+	debug := &bytes.Buffer{}
+
+	logger := New(Config{Level: InfoLevel, Output: primary})
+	logger.Debug("before")
+
+	logger.AddSink(SinkConfig{Output: debug, Level: DebugLevel, Format: JSONFormat})
+	logger.Debug("after")
+
+	assert.Empty(t, primary.String())
+	assert.NotContains(t, debug.String(), "before")
+	assert.Contains(t, debug.String(), `"message":"after"`)
+}
+
+func TestLogger_AddSinkConcurrent(t *testing.T) {
+	logger := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			logger.AddSink(SinkConfig{Output: discardWriter, Level: InfoLevel})
+		}()
+		go func() {
+			defer wg.Done()
+			logger.Info("concurrent")
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, *logger.sinks.Load(), 5)
+}
+
+func TestErrorOutput_SplitsStreams(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:       DebugLevel,
+		Format:      TextFormat,
+		Output:      stdout,
+		ErrorOutput: stderr,
+	})
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	assert.Contains(t, stdout.String(), "DEBUG debug")
+	assert.Contains(t, stdout.String(), "INFO info")
+	assert.NotContains(t, stdout.String(), "WARN")
+	assert.NotContains(t, stdout.String(), "ERROR")
+
+	assert.Contains(t, stderr.String(), "WARN warn")
+	assert.Contains(t, stderr.String(), "ERROR error")
+	assert.NotContains(t, stderr.String(), "INFO")
+}
+
+func TestErrorOutput_RespectsLevel(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:       ErrorLevel,
+		Output:      stdout,
+		ErrorOutput: stderr,
+	})
+
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	assert.Empty(t, stdout.String())
+	assert.NotContains(t, stderr.String(), "warn")
+	assert.Contains(t, stderr.String(), "error")
+}
@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"math"
+)
+
+// SlogHandler is a slog.Handler writing to a Logger, so libraries using
+// log/slog log through the same sinks, redaction and sampling:
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(log)))
+//
+// The handler follows the semantics of the handlers in log/slog and passes
+// the testing/slogtest harness, so code relying on them behaves the same:
+//
+//   - attributes are resolved, including slog.LogValuer values, before
+//     they are recorded, in the order they were added;
+//   - attributes with an empty key and a zero value are dropped;
+//   - groups are flattened into dotted keys ("req.method"), groups with a
+//     key are qualified, groups with an empty key are inlined and empty
+//     groups are dropped;
+//   - WithGroup qualifies the attributes added after it, and a group
+//     without any attributes does not appear in the output;
+//   - a record with a zero time is written without a timestamp by the text
+//     and JSON formats.
+//
+// slog levels map onto Level directly, since slog.LevelDebug,
+// slog.LevelInfo, slog.LevelWarn and slog.LevelError have the values of
+// DebugLevel, InfoLevel, WarnLevel and ErrorLevel; other values are
+// clamped to the range of Level. Fields carried by the context passed to
+// Handle are added like ContextLogger does.
+type SlogHandler struct {
+	l      *Logger
+	prefix string // qualifies attribute keys, "" or ends with '.'
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
+
+// NewSlogHandler returns a slog.Handler writing to l.
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{l: l}
+}
+
+// Enabled reports whether l writes entries at level.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.Enabled(slogLevel(level))
+}
+
+// Handle writes r to the logger.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := slogLevel(r.Level)
+	if !h.l.enabled(level) {
+		return nil
+	}
+
+	var fields []Field
+	if r.NumAttrs() > 0 {
+		fields = make([]Field, 0, r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			fields = appendSlogAttr(fields, h.prefix, a)
+			return true
+		})
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if len(h.l.groups) > 0 {
+		fields = h.l.groupFields(fields)
+	}
+	h.l.logGrouped(ctx, r.Time, level, r.Message, h.l.extractContextFields(ctx, fields))
+	return nil
+}
+
+// WithAttrs returns a handler adding attrs to every entry, qualified by
+// the groups opened so far.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, h.prefix, a)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	return &SlogHandler{l: h.l.With(fields...), prefix: h.prefix}
+}
+
+// WithGroup returns a handler qualifying the keys of the attributes added
+// later with name. An empty name returns h.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{l: h.l, prefix: h.prefix + name + "."}
+}
+
+// slogLevel converts a slog level, clamping it to the range of Level.
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level < math.MinInt8:
+		return Level(math.MinInt8)
+	case level > math.MaxInt8:
+		return Level(math.MaxInt8)
+	default:
+		return Level(level)
+	}
+}
+
+// appendSlogAttr resolves a and appends it to fields, flattening groups.
+func appendSlogAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendSlogAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: prefix + a.Key, Value: slogValue(a.Value)})
+}
+
+// slogValue converts a resolved slog value that is not a group.
+func slogValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return unsignedValue(v.Uint64())
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return fieldValue(v.Duration())
+	case slog.KindTime:
+		return v.Time()
+	default:
+		return Any("", v.Any()).Value
+	}
+}
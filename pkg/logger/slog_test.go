@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unflattenSlogKeys nests dotted keys back into the maps slogtest expects.
+func unflattenSlogKeys(entry map[string]any) map[string]any {
+	out := make(map[string]any, len(entry))
+	for key, value := range entry {
+		parts := strings.Split(key, ".")
+		m := out
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := m[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				m[part] = next
+			}
+			m = next
+		}
+		m[parts[len(parts)-1]] = value
+	}
+	return out
+}
+
+func TestSlogHandler_Conformance(t *testing.T) {
+	var buf bytes.Buffer
+
+	slogtest.Run(t, func(*testing.T) slog.Handler {
+		buf.Reset()
+		log := New(Config{
+			Level:  DebugLevel,
+			Format: JSONFormat,
+			Output: &buf,
+			KeyMap: map[string]string{TimestampKey: slog.TimeKey, MessageKey: slog.MessageKey},
+		})
+		return NewSlogHandler(log)
+	}, func(t *testing.T) map[string]any {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		return unflattenSlogKeys(entry)
+	})
+}
+
+type slogUser struct {
+	id   int
+	name string
+}
+
+func (u slogUser) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", u.id), slog.String("name", u.name))
+}
+
+func TestSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+	sl := slog.New(NewSlogHandler(log)).With("service", "api").WithGroup("req")
+
+	sl.Debug("dropped")
+	sl.Warn("slow request",
+		slog.Any("user", slogUser{id: 7, name: "ada"}),
+		slog.Duration("took", 1500*time.Millisecond),
+		slog.Uint64("bytes", 42),
+		slog.Group("", slog.String("inline", "yes")),
+		slog.Group("empty"),
+		slog.Any("tags", []string{"a", "b"}),
+	)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "slow request", entry["message"])
+	assert.Equal(t, "api", entry["service"])
+	assert.Equal(t, float64(7), entry["req.user.id"])
+	assert.Equal(t, "ada", entry["req.user.name"])
+	assert.Equal(t, "1.5s", entry["req.took"])
+	assert.Equal(t, float64(42), entry["req.bytes"])
+	assert.Equal(t, "yes", entry["req.inline"])
+	assert.Equal(t, []any{"a", "b"}, entry["req.tags"])
+	assert.NotContains(t, buf.String(), "empty")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestSlogHandler_Levels(t *testing.T) {
+	assert.Equal(t, DebugLevel, slogLevel(slog.LevelDebug))
+	assert.Equal(t, InfoLevel, slogLevel(slog.LevelInfo))
+	assert.Equal(t, WarnLevel, slogLevel(slog.LevelWarn))
+	assert.Equal(t, ErrorLevel, slogLevel(slog.LevelError))
+	assert.Equal(t, Level(127), slogLevel(slog.Level(1000)))
+	assert.Equal(t, Level(-128), slogLevel(slog.Level(-1000)))
+
+	h := NewSlogHandler(New(Config{Level: WarnLevel, Output: &bytes.Buffer{}}))
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelError))
+}
+
+func TestSlogHandler_ZeroTime(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewSlogHandler(New(Config{Level: InfoLevel, Format: TextFormat, Output: buf}))
+
+	require.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "no time", 0)))
+	assert.Equal(t, "INFO no time\n", buf.String())
+}
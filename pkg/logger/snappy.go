@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	// snappyBlockSize keeps copy offsets within the two-byte range.
+	snappyBlockSize = 1 << 16
+
+	snappyTableBits = 14
+
+	snappyTagLiteral = 0x00
+	snappyTagCopy2   = 0x02
+)
+
+// appendSnappy appends the snappy block-format encoding of src to dst, as
+// expected by the Loki and Prometheus remote APIs. It uses a greedy
+// single-pass matcher, trading some ratio for simplicity.
+func appendSnappy(dst, src []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(src)))
+
+	var table [1 << snappyTableBits]int32
+	for len(src) > 0 {
+		n := min(len(src), snappyBlockSize)
+		dst = appendSnappyBlock(dst, src[:n], &table)
+		src = src[n:]
+	}
+	return dst
+}
+
+func appendSnappyBlock(dst, block []byte, table *[1 << snappyTableBits]int32) []byte {
+	clear(table[:])
+
+	lit := 0
+	for i := 0; i+4 <= len(block); {
+		cur := binary.LittleEndian.Uint32(block[i:])
+		h := (cur * 0x1e35a7bd) >> (32 - snappyTableBits)
+		cand := int(table[h]) - 1
+		table[h] = int32(i + 1)
+
+		if cand < 0 || binary.LittleEndian.Uint32(block[cand:]) != cur {
+			i++
+			continue
+		}
+
+		dst = appendSnappyLiteral(dst, block[lit:i])
+
+		n := 4
+		for i+n < len(block) && block[cand+n] == block[i+n] {
+			n++
+		}
+		dst = appendSnappyCopy(dst, i-cand, n)
+
+		i += n
+		lit = i
+	}
+
+	return appendSnappyLiteral(dst, block[lit:])
+}
+
+func appendSnappyLiteral(dst, lit []byte) []byte {
+	if len(lit) == 0 {
+		return dst
+	}
+
+	n := uint32(len(lit) - 1)
+	if n < 60 {
+		dst = append(dst, byte(n)<<2|snappyTagLiteral)
+	} else {
+		size := (bits.Len32(n) + 7) / 8
+		dst = append(dst, byte(59+size)<<2|snappyTagLiteral)
+		for i := 0; i < size; i++ {
+			dst = append(dst, byte(n>>(8*i)))
+		}
+	}
+	return append(dst, lit...)
+}
+
+// appendSnappyCopy appends copies of at most 64 bytes each, keeping every
+// copy at least 4 bytes long.
+func appendSnappyCopy(dst []byte, offset, length int) []byte {
+	for length >= 68 {
+		dst = append(dst, 63<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+		length -= 64
+	}
+	if length > 64 {
+		dst = append(dst, 59<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+		length -= 60
+	}
+	return append(dst, byte(length-1)<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+}
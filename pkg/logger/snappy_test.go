@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeSnappy is a reference decoder for the snappy block format.
+func decodeSnappy(src []byte) ([]byte, error) {
+	n, k := binary.Uvarint(src)
+	if k <= 0 {
+		return nil, errors.New("bad length")
+	}
+	src = src[k:]
+	dst := make([]byte, 0, n)
+
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 3 {
+		case 0:
+			length := int(tag >> 2)
+			src = src[1:]
+			if length >= 60 {
+				size := length - 59
+				length = 0
+				for i := 0; i < size; i++ {
+					length |= int(src[i]) << (8 * i)
+				}
+				src = src[size:]
+			}
+			length++
+			dst = append(dst, src[:length]...)
+			src = src[length:]
+		case 2:
+			length := int(tag>>2) + 1
+			offset := int(binary.LittleEndian.Uint16(src[1:3]))
+			src = src[3:]
+			if offset == 0 || offset > len(dst) {
+				return nil, errors.New("bad offset")
+			}
+			for i := 0; i < length; i++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		default:
+			return nil, errors.New("unsupported tag")
+		}
+	}
+
+	if uint64(len(dst)) != n {
+		return nil, errors.New("length mismatch")
+	}
+	return dst, nil
+}
+
+func TestAppendSnappy_RoundTrip(t *testing.T) {
+	random := make([]byte, 100000)
+	for i := range random {
+		random[i] = byte(rand.IntN(256))
+	}
+
+	inputs := [][]byte{
+		nil,
+		[]byte("a"),
+		[]byte("short literal"),
+		bytes.Repeat([]byte("abcd"), 1000),
+		bytes.Repeat([]byte(`{"level":"INFO","message":"request served","status":200}`+"\n"), 3000),
+		random,
+	}
+
+	for _, input := range inputs {
+		encoded := appendSnappy(nil, input)
+		decoded, err := decodeSnappy(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, len(input), len(decoded))
+		assert.True(t, bytes.Equal(input, decoded))
+	}
+}
+
+func TestAppendSnappy_Compresses(t *testing.T) {
+	input := bytes.Repeat([]byte(`{"level":"INFO","message":"request served"}`+"\n"), 1000)
+	assert.Less(t, len(appendSnappy(nil, input)), len(input)/10)
+}
@@ -0,0 +1,73 @@
+package logger
+
+import "github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+
+// Stats is a snapshot of the logger's internal counters.
+type Stats struct {
+	// AsyncQueued is the number of entries waiting in the async queue.
+	AsyncQueued int
+
+	// AsyncDropped is the number of entries shed because the async queue
+	// was saturated.
+	AsyncDropped uint64
+
+	// Sampled is the number of entries dropped by the sampler configured
+	// with Config.Sampling.
+	Sampled uint64
+
+	// AsyncQueuedBytes is the estimated size of the entries waiting in the
+	// async queue. It is only tracked when MemoryBudget.MaxQueueBytes is set.
+	AsyncQueuedBytes int64
+
+	// BufferBytes is the memory held by encoding buffers. It is only
+	// tracked when MemoryBudget.MaxBufferBytes is set.
+	BufferBytes int64
+
+	// MemoryDropped is the number of entries, or of entry writes to a
+	// sink, dropped because they did not fit into the MemoryBudget.
+	// Entries shed from a saturated async queue are counted in
+	// AsyncDropped instead.
+	MemoryDropped uint64
+
+	// Delivery holds the latencies measured by delivery tracing. It is
+	// only populated when Config.DeliveryTraceInterval is set.
+	Delivery DeliveryStats
+}
+
+// Stats returns a snapshot of the logger's internal counters.
+func (l *Logger) Stats() Stats {
+	var stats Stats
+	if l.async != nil {
+		l.async.mu.Lock()
+		stats.AsyncQueued = l.async.n
+		stats.AsyncQueuedBytes = l.async.bytes
+		l.async.mu.Unlock()
+		stats.AsyncDropped = l.async.dropped.Load()
+		stats.MemoryDropped += l.async.memoryDropped.Load()
+	}
+	if l.buffers != nil {
+		stats.BufferBytes = l.buffers.bytes()
+		stats.MemoryDropped += l.buffers.dropped.Load()
+	}
+	if l.tracer != nil {
+		stats.Delivery = l.deliveryStats()
+	}
+	stats.Sampled = l.sampled.Load()
+	if s := l.sampler.Load(); s != nil {
+		stats.Sampled += s.dropped.Load()
+	}
+	return stats
+}
+
+// countDropped reports an entry dropped for reason to Config.Metrics and,
+// unless sampling or a rate limit dropped it as configured, to the internal
+// logger.
+func (l *Logger) countDropped(reason string) {
+	if l.config.Metrics != nil {
+		l.config.Metrics.Dropped(reason)
+	}
+	switch reason {
+	case logmetrics.DropAsyncQueue, logmetrics.DropMemory, logmetrics.DropOversized:
+		l.internal.report(WarnLevel, "entry dropped", String("reason", reason))
+	}
+}
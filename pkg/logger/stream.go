@@ -0,0 +1,443 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultStreamBufferSize is the number of entries buffered per client
+	// when StreamConfig.BufferSize is not set.
+	DefaultStreamBufferSize = 256
+
+	// streamHeartbeat keeps idle streams from being closed by proxies.
+	streamHeartbeat = 15 * time.Second
+
+	// streamWriteTimeout bounds a single write to a WebSocket client.
+	streamWriteTimeout = 10 * time.Second
+
+	// wsGUID is appended to the client key in the WebSocket handshake.
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// wsMaxClientFrame limits frames read from clients, which are not
+	// expected to send anything but control frames.
+	wsMaxClientFrame = 4096
+
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xa
+)
+
+// StreamConfig configures a StreamHandler.
+type StreamConfig struct {
+	// BufferSize is the number of entries buffered per client. Entries
+	// arriving while a client's buffer is full are dropped for that
+	// client only. Defaults to DefaultStreamBufferSize.
+	BufferSize int
+
+	// History is the number of recent entries sent to clients when they
+	// connect. Zero sends only new entries.
+	History int
+}
+
+// StreamHandler is an output that streams entries live to HTTP clients,
+// letting tooling tail a process without access to its files. Clients
+// connect with server-sent events, or with a WebSocket when the request
+// asks for an upgrade, and receive one encoded entry per event or text
+// message.
+//
+// Each client can narrow its stream with query parameters:
+//
+//	level=warn         only entries at WARN or above
+//	field=userID       only entries with a userID field
+//	field=region=eu    only entries whose region field is "eu"
+//
+// Repeated field parameters must all match. Logging never blocks on
+// clients: each has a bounded buffer, and SSE clients receive a "dropped"
+// event with the number of entries lost when theirs overflowed.
+//
+// Example:
+//
+//	stream := logger.NewStreamHandler(logger.StreamConfig{History: 100})
+//	log.AddSink(logger.SinkConfig{Output: stream, Level: logger.DebugLevel, Format: logger.JSONFormat})
+//	mux.Handle("/debug/logs/stream", stream)
+//
+// The handler exposes every entry to whoever can reach it, so protect it
+// like any other debug endpoint.
+type StreamHandler struct {
+	b          *broadcaster
+	bufferSize int
+}
+
+// NewStreamHandler creates a stream handler.
+func NewStreamHandler(cfg StreamConfig) *StreamHandler {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultStreamBufferSize
+	}
+	return &StreamHandler{
+		b:          newBroadcaster(cfg.History),
+		bufferSize: cfg.BufferSize,
+	}
+}
+
+// Write publishes p as an informational entry without fields.
+func (h *StreamHandler) Write(p []byte) (int, error) {
+	h.b.publish(InfoLevel, nil, p)
+	return len(p), nil
+}
+
+// WriteRecord implements RecordWriter.
+func (h *StreamHandler) WriteRecord(r *Record, entry []byte) error {
+	h.b.publish(r.Level, r.Fields, entry)
+	return nil
+}
+
+// lineOutput implements lineOutput.
+func (h *StreamHandler) lineOutput() {}
+
+// Subscribers returns the number of connected clients.
+func (h *StreamHandler) Subscribers() int {
+	return h.b.subscribers()
+}
+
+// ServeHTTP streams entries to the client until it disconnects.
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStreamFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if isWebSocketUpgrade(r) {
+		h.serveWebSocket(w, r, filter)
+		return
+	}
+	h.serveSSE(w, r, filter)
+}
+
+// streamFilter selects the entries sent to one client.
+type streamFilter struct {
+	level  Level
+	fields []fieldMatch
+}
+
+// fieldMatch requires a field with key, and with value unless any is set.
+type fieldMatch struct {
+	key   string
+	value string
+	any   bool
+}
+
+func parseStreamFilter(r *http.Request) (streamFilter, error) {
+	query := r.URL.Query()
+
+	filter := streamFilter{level: DebugLevel}
+	if name := query.Get("level"); name != "" {
+		level, ok := levelFromName(name)
+		if !ok {
+			return filter, errors.New("logger: unknown level " + strconv.Quote(name))
+		}
+		filter.level = level
+	}
+
+	for _, f := range query["field"] {
+		key, value, ok := strings.Cut(f, "=")
+		if key == "" {
+			return filter, errors.New("logger: empty field filter")
+		}
+		filter.fields = append(filter.fields, fieldMatch{key: key, value: value, any: !ok})
+	}
+
+	return filter, nil
+}
+
+// match reports whether e passes the filter.
+func (f *streamFilter) match(e *streamEntry) bool {
+	if e.level < f.level {
+		return false
+	}
+
+	var scratch []byte
+	for _, m := range f.fields {
+		found := false
+		for _, field := range e.fields {
+			if field.Key != m.key {
+				continue
+			}
+			if m.any {
+				found = true
+				break
+			}
+			if s, ok := field.Value.(string); ok {
+				found = s == m.value
+			} else {
+				scratch = appendValue(scratch[:0], field.Value)
+				found = string(scratch) == m.value
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// serveSSE streams entries as server-sent events.
+func (h *StreamHandler) serveSSE(w http.ResponseWriter, r *http.Request, filter streamFilter) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	sub, history := h.b.subscribe(h.bufferSize)
+	defer h.b.unsubscribe(sub)
+
+	var buf []byte
+	for i := range history {
+		if filter.match(&history[i]) {
+			buf = appendSSE(buf, history[i].data)
+		}
+	}
+	if _, err := w.Write(buf); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	var reported uint64
+	for {
+		buf = buf[:0]
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			buf = append(buf, ":\n\n"...)
+		case e := <-sub.ch:
+			if dropped := sub.dropped.Load(); dropped != reported {
+				buf = append(buf, "event: dropped\ndata: "...)
+				buf = strconv.AppendUint(buf, dropped-reported, 10)
+				buf = append(buf, '\n', '\n')
+				reported = dropped
+			}
+			if filter.match(&e) {
+				buf = appendSSE(buf, e.data)
+			}
+		}
+
+		if len(buf) == 0 {
+			continue
+		}
+		if _, err := w.Write(buf); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// appendSSE appends data as a single server-sent event. Encoded entries
+// never contain newlines, so one data line is enough.
+func appendSSE(buf, data []byte) []byte {
+	buf = append(buf, "data: "...)
+	buf = append(buf, data...)
+	return append(buf, '\n', '\n')
+}
+
+// isWebSocketUpgrade reports whether r asks for a WebSocket connection.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContains(r.Header, "Connection", "upgrade") &&
+		headerContains(r.Header, "Upgrade", "websocket")
+}
+
+// headerContains reports whether the comma-separated header name contains
+// token, ignoring case.
+func headerContains(header http.Header, name, token string) bool {
+	for _, v := range header.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serveWebSocket streams entries as WebSocket text messages.
+func (h *StreamHandler) serveWebSocket(w http.ResponseWriter, r *http.Request, filter streamFilter) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "logger: unsupported WebSocket handshake", http.StatusBadRequest)
+		return
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	ws := &wsConn{conn: conn, w: rw.Writer}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	if ws.writeRaw([]byte(handshake)) != nil {
+		return
+	}
+
+	sub, history := h.b.subscribe(h.bufferSize)
+	defer h.b.unsubscribe(sub)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		ws.readLoop(rw.Reader)
+	}()
+
+	for i := range history {
+		if filter.match(&history[i]) && ws.writeFrame(wsOpText, history[i].data) != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			if ws.writeFrame(wsOpPing, nil) != nil {
+				return
+			}
+		case e := <-sub.ch:
+			if filter.match(&e) && ws.writeFrame(wsOpText, e.data) != nil {
+				return
+			}
+		}
+	}
+}
+
+// wsConn is the server side of a WebSocket connection. Writes may come
+// from the streaming loop and from the reader answering pings.
+type wsConn struct {
+	conn net.Conn
+	mu   sync.Mutex
+	w    *bufio.Writer
+}
+
+// writeFrame writes a single unmasked, unfragmented frame.
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	var header [10]byte
+	header[0] = 0x80 | op
+	n := 2
+	switch {
+	case len(payload) < 126:
+		header[1] = byte(len(payload))
+	case len(payload) <= 0xffff:
+		header[1] = 126
+		binary.BigEndian.PutUint16(header[2:], uint16(len(payload)))
+		n = 4
+	default:
+		header[1] = 127
+		binary.BigEndian.PutUint64(header[2:], uint64(len(payload)))
+		n = 10
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	_, _ = c.w.Write(header[:n])
+	_, _ = c.w.Write(payload)
+	return c.w.Flush()
+}
+
+func (c *wsConn) writeRaw(p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	_, _ = c.w.Write(p)
+	return c.w.Flush()
+}
+
+// readLoop consumes client frames, answering pings, until the client
+// closes the connection or sends something invalid.
+func (c *wsConn) readLoop(r *bufio.Reader) {
+	var header [2]byte
+	var mask [4]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return
+		}
+		op := header[0] & 0x0f
+		masked := header[1]&0x80 != 0
+		size := uint64(header[1] & 0x7f)
+
+		switch size {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(r, ext[:]); err != nil {
+				return
+			}
+			size = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(r, ext[:]); err != nil {
+				return
+			}
+			size = binary.BigEndian.Uint64(ext[:])
+		}
+
+		// Clients must mask their frames.
+		if !masked || size > wsMaxClientFrame {
+			_ = c.writeFrame(wsOpClose, []byte{0x03, 0xea}) // 1002 protocol error
+			return
+		}
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+
+		switch op {
+		case wsOpClose:
+			_ = c.writeFrame(wsOpClose, payload[:min(len(payload), 2)])
+			return
+		case wsOpPing:
+			if c.writeFrame(wsOpPong, payload) != nil {
+				return
+			}
+		}
+	}
+}
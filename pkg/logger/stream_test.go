@@ -0,0 +1,253 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openStream connects to an SSE stream and returns its body.
+func openStream(t *testing.T, url string) io.Reader {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return resp.Body
+}
+
+func TestStreamFilter_Match(t *testing.T) {
+	entries := map[string]streamEntry{
+		"noise":      {level: DebugLevel, fields: []Field{String("region", "eu")}},
+		"eu warning": {level: WarnLevel, fields: []Field{String("region", "eu"), Int("status", 503)}},
+		"us error":   {level: ErrorLevel, fields: []Field{String("region", "us")}},
+		"eu error":   {level: ErrorLevel, fields: []Field{String("region", "eu"), Int("status", 500)}},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"noise", "eu warning", "us error", "eu error"}},
+		{"level=warn", []string{"eu warning", "us error", "eu error"}},
+		{"level=WARN&field=region=eu", []string{"eu warning", "eu error"}},
+		{"field=status", []string{"eu warning", "eu error"}},
+		{"field=status=500", []string{"eu error"}},
+		{"field=region=us&field=status", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filter, err := parseStreamFilter(httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+			require.NoError(t, err)
+
+			var got []string
+			for name, e := range entries {
+				if filter.match(&e) {
+					got = append(got, name)
+				}
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestStreamHandler_InvalidFilter(t *testing.T) {
+	stream := NewStreamHandler(StreamConfig{})
+
+	for _, query := range []string{"level=loud", "field=", "field==x"} {
+		rec := httptest.NewRecorder()
+		stream.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestStreamHandler_SSE(t *testing.T) {
+	stream := NewStreamHandler(StreamConfig{History: 10})
+	log := New(Config{Level: DebugLevel, Format: JSONFormat, Output: stream})
+	log.Info("old info", String("region", "eu"))
+	log.Warn("old warning", String("region", "eu"))
+
+	srv := httptest.NewServer(stream)
+	t.Cleanup(srv.Close)
+
+	body := openStream(t, srv.URL+"?level=warn&field=region=eu")
+	require.Eventually(t, func() bool { return stream.Subscribers() == 1 }, time.Second, 5*time.Millisecond)
+
+	log.Error("other region", String("region", "us"))
+	log.Error("new error", String("region", "eu"))
+
+	events := readEvents(t, body, 2)
+	assert.Contains(t, events[0], `"message":"old warning"`)
+	assert.Contains(t, events[1], `"message":"new error"`)
+}
+
+// gatedResponse is a ResponseWriter whose first Write blocks until
+// release is closed.
+type gatedResponse struct {
+	gatedWriter
+	header http.Header
+}
+
+func (w *gatedResponse) Header() http.Header { return w.header }
+func (w *gatedResponse) WriteHeader(int)     {}
+func (w *gatedResponse) Flush()              {}
+
+func TestStreamHandler_ReportsDropped(t *testing.T) {
+	stream := NewStreamHandler(StreamConfig{BufferSize: 1})
+	w := &gatedResponse{gatedWriter: *newGatedWriter(), header: http.Header{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stream.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	}()
+
+	// The handler is stuck writing the history, so only the first of
+	// these fits into its buffer.
+	<-w.entered
+	for _, n := range []string{"1", "2", "3"} {
+		_, _ = stream.Write([]byte(`{"n":` + n + "}\n"))
+	}
+	close(w.release)
+
+	// The loss is reported ahead of the next entry delivered.
+	require.Eventually(t, func() bool {
+		return strings.Contains(w.String(), "event: dropped\ndata: 2\n\ndata: {\"n\":1}\n\n")
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+// wsDial performs a WebSocket handshake against srv.
+func wsDial(t *testing.T, srv *httptest.Server, path string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	_, err = io.WriteString(conn, "GET "+path+" HTTP/1.1\r\n"+
+		"Host: "+srv.Listener.Addr().String()+"\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	sum := sha1.Sum([]byte(key + wsGUID))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), resp.Header.Get("Sec-WebSocket-Accept"))
+	return conn, br
+}
+
+// wsReadFrame reads one unmasked server frame.
+func wsReadFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+
+	var header [2]byte
+	_, err := io.ReadFull(r, header[:])
+	require.NoError(t, err)
+	require.Zero(t, header[1]&0x80, "server frames must not be masked")
+
+	size := int(header[1] & 0x7f)
+	switch size {
+	case 126:
+		var ext [2]byte
+		_, err = io.ReadFull(r, ext[:])
+		require.NoError(t, err)
+		size = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		t.Fatal("unexpected large frame")
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(r, payload)
+	require.NoError(t, err)
+	return header[0] & 0x0f, payload
+}
+
+// wsWriteFrame writes one masked client frame.
+func wsWriteFrame(t *testing.T, w io.Writer, op byte, payload []byte) {
+	t.Helper()
+
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | op, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := w.Write(frame)
+	require.NoError(t, err)
+}
+
+func TestStreamHandler_WebSocket(t *testing.T) {
+	stream := NewStreamHandler(StreamConfig{History: 10})
+	log := New(Config{Level: DebugLevel, Format: JSONFormat, Output: stream})
+	log.Debug("old debug")
+	log.Info("old info")
+
+	srv := httptest.NewServer(stream)
+	t.Cleanup(srv.Close)
+
+	conn, br := wsDial(t, srv, "/?level=info")
+
+	op, payload := wsReadFrame(t, br)
+	assert.Equal(t, byte(wsOpText), op)
+	assert.Contains(t, string(payload), `"message":"old info"`)
+
+	long := strings.Repeat("x", 300)
+	log.Warn(long)
+	op, payload = wsReadFrame(t, br)
+	assert.Equal(t, byte(wsOpText), op)
+	assert.Contains(t, string(payload), long)
+
+	wsWriteFrame(t, conn, wsOpPing, []byte("hi"))
+	op, payload = wsReadFrame(t, br)
+	assert.Equal(t, byte(wsOpPong), op)
+	assert.Equal(t, "hi", string(payload))
+
+	wsWriteFrame(t, conn, wsOpClose, []byte{0x03, 0xe8})
+	op, payload = wsReadFrame(t, br)
+	assert.Equal(t, byte(wsOpClose), op)
+	assert.Equal(t, []byte{0x03, 0xe8}, payload)
+
+	require.Eventually(t, func() bool { return stream.Subscribers() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestStreamHandler_WebSocketBadVersion(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "8")
+
+	rec := httptest.NewRecorder()
+	NewStreamHandler(StreamConfig{}).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "13", rec.Header().Get("Sec-WebSocket-Version"))
+}
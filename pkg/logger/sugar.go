@@ -0,0 +1,241 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// BadKey is the key of fields built from malformed key/value pairs passed
+// to the Infow family: a key that is not a string, or a final key without
+// a value.
+const BadKey = "!BADKEY"
+
+// Debugf formats a message like fmt.Sprintf and logs it at DebugLevel.
+// The message is only formatted when the level is enabled.
+func (l *Logger) Debugf(format string, args ...any) {
+	l.logf(DebugLevel, format, args)
+}
+
+// Infof formats a message like fmt.Sprintf and logs it at InfoLevel.
+func (l *Logger) Infof(format string, args ...any) {
+	l.logf(InfoLevel, format, args)
+}
+
+// Warnf formats a message like fmt.Sprintf and logs it at WarnLevel.
+func (l *Logger) Warnf(format string, args ...any) {
+	l.logf(WarnLevel, format, args)
+}
+
+// Errorf formats a message like fmt.Sprintf and logs it at ErrorLevel.
+func (l *Logger) Errorf(format string, args ...any) {
+	l.logf(ErrorLevel, format, args)
+}
+
+// Fatalf formats a message like fmt.Sprintf, logs it at FatalLevel, then
+// exits like Fatal.
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.logf(FatalLevel, format, args)
+	l.exit()
+}
+
+// Panicf formats a message like fmt.Sprintf, logs it at PanicLevel, then
+// panics like Panic.
+func (l *Logger) Panicf(format string, args ...any) {
+	msg := sprintf(format, args)
+	l.log(PanicLevel, msg)
+	l.panic(msg)
+}
+
+// Debugw logs a message at DebugLevel with fields given as alternating
+// keys and values, as in zap's SugaredLogger:
+//
+//	log.Debugw("cache miss", "key", key, "ttl", ttl)
+//
+// Values are converted like F does; a Field in place of a key is added as
+// is. A key that is not a string, or a final key without a value, is
+// logged under BadKey.
+func (l *Logger) Debugw(msg string, keysAndValues ...any) {
+	l.logw(DebugLevel, msg, keysAndValues)
+}
+
+// Infow logs a message at InfoLevel with alternating keys and values, like
+// Debugw.
+func (l *Logger) Infow(msg string, keysAndValues ...any) {
+	l.logw(InfoLevel, msg, keysAndValues)
+}
+
+// Warnw logs a message at WarnLevel with alternating keys and values, like
+// Debugw.
+func (l *Logger) Warnw(msg string, keysAndValues ...any) {
+	l.logw(WarnLevel, msg, keysAndValues)
+}
+
+// Errorw logs a message at ErrorLevel with alternating keys and values,
+// like Debugw.
+func (l *Logger) Errorw(msg string, keysAndValues ...any) {
+	l.logw(ErrorLevel, msg, keysAndValues)
+}
+
+// Fatalw logs a message at FatalLevel with alternating keys and values,
+// like Debugw, then exits like Fatal.
+func (l *Logger) Fatalw(msg string, keysAndValues ...any) {
+	l.logw(FatalLevel, msg, keysAndValues)
+	l.exit()
+}
+
+// Panicw logs a message at PanicLevel with alternating keys and values,
+// like Debugw, then panics like Panic.
+func (l *Logger) Panicw(msg string, keysAndValues ...any) {
+	l.logw(PanicLevel, msg, keysAndValues)
+	l.panic(msg)
+}
+
+func (l *Logger) logf(level Level, format string, args []any) {
+	if !l.enabled(level) {
+		return
+	}
+	l.log(level, sprintf(format, args))
+}
+
+func (l *Logger) logw(level Level, msg string, keysAndValues []any) {
+	if !l.enabled(level) {
+		return
+	}
+	l.log(level, msg, sweetenFields(keysAndValues)...)
+}
+
+// sweetenFields turns alternating keys and values into fields.
+func sweetenFields(keysAndValues []any) []Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); {
+		switch key := keysAndValues[i].(type) {
+		case Field:
+			fields = append(fields, key)
+			i++
+		case string:
+			if i+1 == len(keysAndValues) {
+				fields = append(fields, Field{Key: BadKey, Value: key})
+				i++
+				continue
+			}
+			fields = append(fields, Field{Key: key, Value: fieldValue(keysAndValues[i+1])})
+			i += 2
+		default:
+			fields = append(fields, Field{Key: BadKey, Value: fieldValue(key)})
+			i++
+		}
+	}
+	return fields
+}
+
+// sprintf formats like fmt.Sprintf. Formats using only the %s, %d, %v and
+// %% verbs without flags, applied to strings, integers, floats, bools,
+// errors and fmt.Stringers, are rendered without fmt; anything else,
+// including mismatched argument counts, falls back to fmt.Sprintf so the
+// output is always identical.
+func sprintf(format string, args []any) string {
+	var stack [256]byte
+	if buf, ok := appendPrintf(stack[:0], format, args); ok {
+		return string(buf)
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// appendPrintf appends the fast-path rendering of format to buf, or
+// reports false when the format needs fmt.
+func appendPrintf(buf []byte, format string, args []any) ([]byte, bool) {
+	next := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			buf = append(buf, c)
+			continue
+		}
+
+		i++
+		if i == len(format) {
+			return buf, false
+		}
+		verb := format[i]
+		if verb == '%' {
+			buf = append(buf, '%')
+			continue
+		}
+		if next == len(args) {
+			return buf, false
+		}
+
+		var ok bool
+		buf, ok = appendPrintfArg(buf, verb, args[next])
+		if !ok {
+			return buf, false
+		}
+		next++
+	}
+
+	return buf, next == len(args)
+}
+
+// appendPrintfArg renders a single argument for verb like fmt does, or
+// reports false for combinations left to fmt.
+func appendPrintfArg(buf []byte, verb byte, arg any) ([]byte, bool) {
+	switch verb {
+	case 's', 'v':
+		switch v := arg.(type) {
+		case string:
+			return append(buf, v...), true
+		case fmt.Formatter:
+			return buf, false
+		}
+		if isNilPointer(arg) {
+			// fmt recovers from methods called on nil receivers.
+			return buf, false
+		}
+		switch v := arg.(type) {
+		case error:
+			return append(buf, v.Error()...), true
+		case fmt.Stringer:
+			return append(buf, v.String()...), true
+		}
+		if verb == 's' {
+			return buf, false
+		}
+		switch v := arg.(type) {
+		case int:
+			return strconv.AppendInt(buf, int64(v), 10), true
+		case int64:
+			return strconv.AppendInt(buf, v, 10), true
+		case float64:
+			return strconv.AppendFloat(buf, v, 'g', -1, 64), true
+		case bool:
+			return strconv.AppendBool(buf, v), true
+		}
+	case 'd':
+		switch v := arg.(type) {
+		case int:
+			return strconv.AppendInt(buf, int64(v), 10), true
+		case int64:
+			return strconv.AppendInt(buf, v, 10), true
+		case int32:
+			return strconv.AppendInt(buf, int64(v), 10), true
+		case uint:
+			return strconv.AppendUint(buf, uint64(v), 10), true
+		case uint64:
+			return strconv.AppendUint(buf, v, 10), true
+		case uint32:
+			return strconv.AppendUint(buf, uint64(v), 10), true
+		}
+	}
+	return buf, false
+}
+
+// isNilPointer reports whether v holds a nil pointer.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sugarStringer struct{ name string }
+
+func (s *sugarStringer) String() string { return "stringer:" + s.name }
+
+func TestSprintf_MatchesFmt(t *testing.T) {
+	var nilStringer *sugarStringer
+	tests := []struct {
+		format string
+		args   []any
+	}{
+		{"plain message", nil},
+		{"user %s logged in %d times", []any{"ann", 3}},
+		{"100%% done: %v %v %v", []any{true, 2.5, int64(-7)}},
+		{"failed: %v", []any{errors.New("boom")}},
+		{"peer %s", []any{&sugarStringer{name: "a"}}},
+		{"addr %v", []any{net.IPv4(10, 0, 0, 1)}},
+		{"took %s", []any{1500 * time.Millisecond}},
+		{"unsigned %d %d", []any{uint(7), uint64(1 << 63)}},
+		{"width %5d", []any{42}},
+		{"quoted %q", []any{"x"}},
+		{"missing %s %s", []any{"one"}},
+		{"extra %s", []any{"one", "two"}},
+		{"trailing %", nil},
+		{"nil %v %s", []any{nil, nilStringer}},
+		{"slice %v", []any{[]int{1, 2}}},
+		{"wrong %d", []any{"text"}},
+		{"float %v", []any{1e21}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, fmt.Sprintf(tt.format, tt.args...), sprintf(tt.format, tt.args), tt.format)
+	}
+}
+
+func TestSprintf_FastPathAllocations(t *testing.T) {
+	args := []any{"ann", 3}
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = sprintf("user %s logged in %d times", args)
+	})
+	assert.LessOrEqual(t, allocs, float64(1), "only the resulting string is allocated")
+}
+
+func TestLogger_Printf(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	log.Debugf("hidden %s", "debug")
+	log.Infof("user %s logged in", "ann")
+	log.Warnf("disk %d%% full", 91)
+	log.Errorf("request failed: %v", errors.New("timeout"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "INFO user ann logged in")
+	assert.Contains(t, lines[1], "WARN disk 91% full")
+	assert.Contains(t, lines[2], "ERROR request failed: timeout")
+
+	assert.PanicsWithValue(t, "bad state 7", func() { log.Panicf("bad state %d", 7) })
+}
+
+func TestLogger_KeyValues(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: TextFormat, Output: buf})
+
+	log.Debugw("hidden", "key", "value")
+	log.Infow("cache miss", "key", "user:1", "ttl", 30*time.Second, Int("shard", 2))
+	log.Warnw("malformed", 42, "orphan")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `INFO cache miss key=user:1 ttl=30s shard=2`)
+	assert.Contains(t, lines[1], `WARN malformed !BADKEY=42 !BADKEY=orphan`)
+
+	assert.PanicsWithValue(t, "invariant", func() { log.Panicw("invariant", "id", 1) })
+}
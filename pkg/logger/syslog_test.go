@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogWriter_UDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	w, err := NewSyslogWriter("udp", server.LocalAddr().String(), SyslogConfig{
+		Facility: FacilityLocal0,
+		Hostname: "host1",
+		AppName:  "billing",
+		ProcID:   "42",
+		MsgID:    "REQ",
+	})
+	require.NoError(t, err)
+	defer w.Close()
+
+	logger := New(Config{
+		Level:        InfoLevel,
+		Output:       w,
+		TextTemplate: "{msg}",
+	})
+	logger.Error("payment failed", Field{Key: "order", Value: "a\"b]c"}, Field{Key: "amount", Value: 42})
+
+	require.NoError(t, server.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 2048)
+	n, _, err := server.ReadFrom(buf)
+	require.NoError(t, err)
+
+	// PRI = 16*8 + 3 (error)
+	pattern := `^<131>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}Z host1 billing 42 REQ ` +
+		`\[fields@32473 order="a\\"b\\]c" amount="42"\] payment failed$`
+	assert.Regexp(t, regexp.MustCompile(pattern), string(buf[:n]))
+}
+
+func TestSyslogWriter_TCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for i := 0; i < 2; i++ {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				return
+			}
+			messages = append(messages, string(msg))
+		}
+		received <- messages
+	}()
+
+	w, err := NewSyslogWriter("tcp", listener.Addr().String(), SyslogConfig{AppName: "app"})
+	require.NoError(t, err)
+	defer w.Close()
+
+	logger := New(Config{Level: DebugLevel, Format: JSONFormat, Output: w})
+	logger.Debug("first")
+	logger.Warn("second")
+
+	select {
+	case messages := <-received:
+		require.Len(t, messages, 2)
+		assert.True(t, strings.HasPrefix(messages[0], "<15>1 "), messages[0])
+		assert.Contains(t, messages[0], `"message":"first"`)
+		assert.True(t, strings.HasPrefix(messages[1], "<12>1 "), messages[1])
+		assert.Contains(t, messages[1], " - {")
+	case <-time.After(time.Second):
+		t.Fatal("no messages received")
+	}
+}
+
+func TestSyslogWriter_PlainWrite(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	w, err := NewSyslogWriter("udp", server.LocalAddr().String(), SyslogConfig{Hostname: "h", ProcID: "1"})
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("plain line\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, server.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 2048)
+	n, _, err := server.ReadFrom(buf)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(buf[:n], []byte("<14>1 ")))
+	assert.True(t, bytes.HasSuffix(buf[:n], []byte(" h - 1 - - plain line")))
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	assert.Equal(t, 7, syslogSeverity(DebugLevel))
+	assert.Equal(t, 6, syslogSeverity(InfoLevel))
+	assert.Equal(t, 4, syslogSeverity(WarnLevel))
+	assert.Equal(t, 3, syslogSeverity(ErrorLevel))
+	assert.Equal(t, 2, syslogSeverity(FatalLevel))
+	assert.Equal(t, 1, syslogSeverity(PanicLevel))
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// Tee returns a logger that passes every entry to each of loggers, so one
+// call site can feed differently configured loggers, e.g. an audit trail
+// in JSON to a file and an operational log in text to stdout:
+//
+//	audit := logger.New(logger.Config{Level: logger.InfoLevel, Format: logger.JSONFormat, Output: file})
+//	ops := logger.New(logger.Config{Level: logger.WarnLevel, Output: os.Stdout})
+//	log := logger.Tee(audit, ops)
+//
+//	log.Info("user deleted", logger.String("user", id)) // audit only
+//	log.Error("payment failed", logger.Err(err))        // both
+//
+// Each logger applies its own levels, named levels, sampling, rate limits,
+// hooks, redaction and formats to the entries it receives, and an entry is
+// only passed to the loggers enabled for its level. The fields of With and
+// Named on the returned logger are added before passing entries on, and
+// its named levels filter entries before any logger sees them. Its own
+// sinks, hooks and the rest of its configuration are not used; configure
+// the loggers passed to Tee instead.
+//
+// Flush and Close apply to every logger. Fatal runs the Config.OnFatal
+// functions of every logger and exits through the Config.ExitFunc of the
+// first logger that sets one. Tee of no loggers returns Nop(), and Tee of a
+// single logger returns it unchanged.
+func Tee(loggers ...*Logger) *Logger {
+	switch len(loggers) {
+	case 0:
+		return Nop()
+	case 1:
+		return loggers[0]
+	}
+
+	config := Config{Output: io.Discard}
+	for _, t := range loggers {
+		config.OnFatal = append(config.OnFatal, t.config.OnFatal...)
+		if config.ExitFunc == nil {
+			config.ExitFunc = t.config.ExitFunc
+		}
+	}
+	l := New(config)
+	l.tee = slices.Clone(loggers)
+
+	l.sinksMu.Lock()
+	l.storeSinks(nil)
+	l.sinksMu.Unlock()
+	// Every level passes the sink check; teeEnabled decides.
+	l.minLevel.Store(math.MinInt32)
+	return l
+}
+
+// teeEnabled reports whether any logger l fans out to writes entries at
+// level.
+func (l *Logger) teeEnabled(level Level) bool {
+	for _, t := range l.tee {
+		if t.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// teeLog passes an entry to every logger l fans out to that is enabled for
+// its level, with the fields of l first.
+func (l *Logger) teeLog(ctx context.Context, t time.Time, level Level, msg string, fields []Field) {
+	if len(l.context) > 0 {
+		fields = append(l.context[:len(l.context):len(l.context)], fields...)
+	}
+	for _, target := range l.tee {
+		if target.Enabled(level) {
+			target.logAt(ctx, t, level, msg, fields)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTee(t *testing.T) {
+	auditOut, opsOut := &bytes.Buffer{}, &bytes.Buffer{}
+	audit := New(Config{Level: InfoLevel, Format: JSONFormat, Output: auditOut, TimestampFormat: TimestampDisabled})
+	ops := New(Config{Level: WarnLevel, Output: opsOut, TimestampFormat: TimestampDisabled})
+	log := Tee(audit, ops).With(String("service", "billing"))
+
+	log.Debug("hidden")
+	log.Info("user deleted", String("user", "42"))
+	log.Error("payment failed")
+
+	assert.Equal(t, `{"level":"INFO","message":"user deleted","service":"billing","user":"42"}`+"\n"+
+		`{"level":"ERROR","message":"payment failed","service":"billing"}`+"\n", auditOut.String())
+	assert.Equal(t, "ERROR payment failed service=billing\n", opsOut.String())
+}
+
+func TestTee_Enabled(t *testing.T) {
+	a := New(Config{Level: WarnLevel, Output: &bytes.Buffer{}})
+	b := New(Config{Level: ErrorLevel, Output: &bytes.Buffer{}})
+	log := Tee(a, b)
+
+	assert.False(t, log.Enabled(InfoLevel))
+	assert.True(t, log.Enabled(WarnLevel))
+	assert.Nil(t, log.Check(InfoLevel, "skipped"))
+
+	log.SetNamedLevel("", ErrorLevel)
+	assert.False(t, log.Enabled(WarnLevel), "the named levels of the tee apply")
+}
+
+func TestTee_ChildSettings(t *testing.T) {
+	sampledOut, hookedOut := &bytes.Buffer{}, &bytes.Buffer{}
+	sampled := New(Config{
+		Level:           InfoLevel,
+		Output:          sampledOut,
+		TimestampFormat: TimestampDisabled,
+		Sampling:        &SamplingConfig{First: 1, Window: time.Hour},
+	})
+	hooked := New(Config{Level: InfoLevel, Output: hookedOut, TimestampFormat: TimestampDisabled})
+	hooked.AddHook(HookFunc(func(r *Record) error {
+		r.Fields = append(r.Fields, String("hooked", "yes"))
+		return nil
+	}))
+	log := Tee(sampled, hooked.Named("ops"))
+
+	log.Info("repeated")
+	log.Info("repeated")
+	assert.Equal(t, "INFO repeated\n", sampledOut.String())
+	assert.Equal(t, "INFO repeated logger=ops hooked=yes\nINFO repeated logger=ops hooked=yes\n", hookedOut.String())
+}
+
+func TestTee_FlushAndClose(t *testing.T) {
+	aOut, bOut := &bytes.Buffer{}, &bytes.Buffer{}
+	a := New(Config{Level: InfoLevel, Output: aOut, BufferSize: 1 << 10})
+	b := New(Config{Level: InfoLevel, Output: bOut, AsyncQueueSize: 16})
+	log := Tee(a, b)
+
+	log.Info("buffered")
+	log.Flush()
+	assert.Contains(t, aOut.String(), "buffered")
+
+	log.Info("queued")
+	require.NoError(t, log.Close())
+	assert.Contains(t, bOut.String(), "queued")
+}
+
+func TestTee_Fatal(t *testing.T) {
+	var ran []string
+	exitCode := -1
+	a := New(Config{Level: InfoLevel, Output: &bytes.Buffer{}, OnFatal: []func(){func() { ran = append(ran, "a") }}})
+	b := New(Config{
+		Level:    InfoLevel,
+		Output:   &bytes.Buffer{},
+		OnFatal:  []func(){func() { ran = append(ran, "b") }},
+		ExitFunc: func(code int) { exitCode = code },
+	})
+
+	Tee(a, b).Fatal("giving up")
+	assert.Equal(t, []string{"a", "b"}, ran)
+	assert.Equal(t, 1, exitCode)
+}
+
+func TestTee_WriteRaw(t *testing.T) {
+	aOut, bOut := &bytes.Buffer{}, &bytes.Buffer{}
+	log := Tee(New(Config{Level: InfoLevel, Output: aOut}), New(Config{Level: ErrorLevel, Output: bOut}))
+
+	require.NoError(t, log.WriteRaw(InfoLevel, []byte(`{"raw":true}`)))
+	assert.Equal(t, `{"raw":true}`+"\n", aOut.String())
+	assert.Empty(t, bOut.String())
+}
+
+func TestTee_Degenerate(t *testing.T) {
+	assert.False(t, Tee().Enabled(PanicLevel))
+
+	single := New(Config{Output: &bytes.Buffer{}})
+	assert.Same(t, single, Tee(single))
+}
@@ -0,0 +1,189 @@
+package logger
+
+import "strings"
+
+// Placeholders understood by Config.TextTemplate. Any other placeholder
+// refers to a field by key.
+const (
+	templateTimestamp = "ts"
+	templateLevel     = "level"
+	templateMessage   = "msg"
+	templateFields    = "fields"
+)
+
+type templateSegmentKind int8
+
+const (
+	segmentLiteral templateSegmentKind = iota
+	segmentTimestamp
+	segmentLevel
+	segmentMessage
+	segmentFields
+	segmentField
+)
+
+// templateSegment is either a literal chunk of text or a placeholder.
+type templateSegment struct {
+	kind  templateSegmentKind
+	value string
+}
+
+// textTemplate is a pre-compiled line layout for TextFormat. It is compiled
+// once in New so rendering an entry only walks a slice of segments.
+type textTemplate struct {
+	segments []templateSegment
+
+	// named holds the keys of fields referenced directly by the template.
+	// These fields are not repeated by the {fields} placeholder.
+	named []string
+
+	// timestamps is the Config.TimestampFormat of the logger.
+	timestamps TimestampFormat
+
+	// timeCache is the timestamp cache of the logger, if any.
+	timeCache *timeCache
+}
+
+// compileTextTemplate parses a layout such as
+// "[{ts}] {level} {service} {msg} {fields}". A '{' without a matching '}'
+// is kept as literal text, and "{{" produces a literal '{'.
+func compileTextTemplate(layout string, timestamps TimestampFormat) *textTemplate {
+	if layout == "" {
+		return nil
+	}
+
+	t := &textTemplate{timestamps: timestamps}
+	var literal strings.Builder
+
+	flushLiteral := func() {
+		if literal.Len() > 0 {
+			t.segments = append(t.segments, templateSegment{kind: segmentLiteral, value: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(layout); i++ {
+		c := layout[i]
+		if c != '{' {
+			literal.WriteByte(c)
+			continue
+		}
+
+		if i+1 < len(layout) && layout[i+1] == '{' {
+			literal.WriteByte('{')
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(layout[i+1:], '}')
+		if end < 0 {
+			literal.WriteString(layout[i:])
+			break
+		}
+
+		name := layout[i+1 : i+1+end]
+		i += end + 1
+
+		flushLiteral()
+		t.segments = append(t.segments, t.placeholder(name))
+	}
+	flushLiteral()
+
+	return t
+}
+
+func (t *textTemplate) placeholder(name string) templateSegment {
+	switch name {
+	case templateTimestamp:
+		return templateSegment{kind: segmentTimestamp}
+	case templateLevel:
+		return templateSegment{kind: segmentLevel}
+	case templateMessage:
+		return templateSegment{kind: segmentMessage}
+	case templateFields:
+		return templateSegment{kind: segmentFields}
+	default:
+		t.named = append(t.named, name)
+		return templateSegment{kind: segmentField, value: name}
+	}
+}
+
+// isNamed reports whether key is rendered by its own placeholder.
+func (t *textTemplate) isNamed(key string) bool {
+	for _, name := range t.named {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// appendTemplate renders an entry according to the compiled template.
+// Placeholders for missing fields render as empty strings, and trailing
+// spaces left by empty placeholders are trimmed. Block fields go below the
+// entry line when blocks is set, and into {fields} otherwise.
+func (t *textTemplate) appendTemplate(buf []byte, r *Record, blocks bool) []byte {
+	start := len(buf)
+
+	for _, seg := range t.segments {
+		switch seg.kind {
+		case segmentLiteral:
+			buf = append(buf, seg.value...)
+		case segmentTimestamp:
+			if !omitsTimestamp(r.Time, t.timestamps) {
+				buf = t.timeCache.appendText(buf, r.Time, t.timestamps)
+			}
+		case segmentLevel:
+			buf = append(buf, r.Level.String()...)
+		case segmentMessage:
+			buf = append(buf, r.Message...)
+		case segmentFields:
+			buf = t.appendFields(buf, r.Fields, blocks)
+		case segmentField:
+			for _, field := range r.Fields {
+				if field.Key == seg.value {
+					buf = appendValue(buf, field.Value)
+					break
+				}
+			}
+		}
+	}
+
+	for len(buf) > start && buf[len(buf)-1] == ' ' {
+		buf = buf[:len(buf)-1]
+	}
+
+	if !blocks {
+		return buf
+	}
+	for _, field := range r.Fields {
+		if block, ok := field.Value.(blockValue); ok && !t.isNamed(field.Key) {
+			buf = appendBlock(buf, field.Key, block)
+		}
+	}
+	return buf
+}
+
+// appendFields renders all fields not referenced by name as key=value
+// pairs, skipping block fields when blocks is set.
+func (t *textTemplate) appendFields(buf []byte, fields []Field, blocks bool) []byte {
+	first := true
+	for _, field := range fields {
+		if t.isNamed(field.Key) || (blocks && isBlock(field.Value)) {
+			continue
+		}
+		if !first {
+			buf = append(buf, ' ')
+		}
+		first = false
+
+		if g, ok := field.Value.(groupValue); ok {
+			buf = appendTextGroup(buf, field.Key, g, false)
+			continue
+		}
+		buf = append(buf, field.Key...)
+		buf = append(buf, '=')
+		buf = appendValue(buf, field.Value)
+	}
+	return buf
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextTemplate_Layout(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:        InfoLevel,
+		Format:       TextFormat,
+		Output:       buf,
+		TextTemplate: "[{ts}] {level} {service} {msg} {fields}",
+	})
+
+	logger.Info("user logged in",
+		Field{Key: "service", Value: "auth"},
+		Field{Key: "userID", Value: 42},
+		Field{Key: "ok", Value: true},
+	)
+
+	pattern := `^\[\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z\] INFO auth user logged in userID=42 ok=true\n$`
+	assert.Regexp(t, regexp.MustCompile(pattern), buf.String())
+}
+
+func TestTextTemplate_MissingFieldsAndEscapes(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:        InfoLevel,
+		Format:       TextFormat,
+		Output:       buf,
+		TextTemplate: "{{literal} {level}|{service}|{msg} {fields} {unterminated",
+	})
+
+	logger.Warn("hello")
+
+	assert.Equal(t, "{literal} WARN||hello  {unterminated\n", buf.String())
+}
+
+func TestTextTemplate_IgnoredForJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger := New(Config{
+		Level:        InfoLevel,
+		Format:       JSONFormat,
+		Output:       buf,
+		TextTemplate: "{msg}",
+	})
+
+	logger.Info("json")
+
+	assert.Contains(t, buf.String(), `"message":"json"`)
+}
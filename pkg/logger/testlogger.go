@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// TestingT is the subset of testing.TB used by NewTestLogger, so the
+// logger package does not depend on package testing.
+type TestingT interface {
+	Helper()
+	Log(args ...any)
+	Errorf(format string, args ...any)
+	FailNow()
+	Cleanup(func())
+}
+
+// NewTestLogger returns a logger writing every entry from DebugLevel to
+// t.Log, so the output of code under test is shown with the test that
+// produced it, and only when it fails or runs with -v:
+//
+//	func TestCharge(t *testing.T) {
+//		svc := billing.New(logger.NewTestLogger(t, logger.WithTestFailure(logger.ErrorLevel)))
+//		...
+//	}
+//
+// Entries are in TextFormat without timestamps and with the caller, since
+// the location reported by t.Log is inside the logger. Options are applied
+// on top; output options other than the format are ignored. Fatal fails
+// the test with t.FailNow instead of exiting, so it must be called on the
+// test goroutine. Entries logged after the test completed are dropped.
+func NewTestLogger(t TestingT, opts ...Option) *Logger {
+	b := &builder{config: Config{
+		Level:           DebugLevel,
+		Format:          TextFormat,
+		TimestampFormat: TimestampDisabled,
+		AddCaller:       true,
+	}}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	w := &testWriter{t: t, failLevel: b.testFailLevel}
+	t.Cleanup(w.done)
+
+	config := b.config
+	config.Output = w
+	config.ErrorOutput = nil
+	config.Outputs = nil
+	config.ExitFunc = func(int) { t.FailNow() }
+
+	return b.derive(New(config))
+}
+
+// WithTestFailure makes a logger created by NewTestLogger fail the test
+// with t.Errorf for every entry at level and above, e.g. ErrorLevel to
+// catch unexpected errors. Other constructors ignore it.
+func WithTestFailure(level Level) Option {
+	return func(b *builder) {
+		b.testFailLevel = &level
+	}
+}
+
+// testWriter writes the entries of NewTestLogger to a test.
+type testWriter struct {
+	t         TestingT
+	failLevel *Level
+
+	mu       sync.Mutex
+	finished bool
+}
+
+// WriteRecord implements RecordWriter.
+func (w *testWriter) WriteRecord(r *Record, entry []byte) error {
+	w.log(entry, w.failLevel != nil && r.Level >= *w.failLevel)
+	return nil
+}
+
+// Write logs a raw entry.
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.log(p, false)
+	return len(p), nil
+}
+
+func (w *testWriter) log(entry []byte, fail bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.finished {
+		return
+	}
+
+	w.t.Helper()
+	line := string(bytes.TrimSuffix(entry, []byte{'\n'}))
+	if fail {
+		w.t.Errorf("%s", line)
+	} else {
+		w.t.Log(line)
+	}
+}
+
+// done stops the writer when the test completes, since logging to a
+// finished test panics.
+func (w *testWriter) done() {
+	w.mu.Lock()
+	w.finished = true
+	w.mu.Unlock()
+}
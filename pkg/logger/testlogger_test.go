@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeT records the calls NewTestLogger makes.
+type fakeT struct {
+	logs, errors []string
+	failed       bool
+	cleanups     []func()
+}
+
+func (t *fakeT) Helper()         {}
+func (t *fakeT) Log(args ...any) { t.logs = append(t.logs, fmt.Sprint(args...)) }
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+func (t *fakeT) FailNow()          { t.failed = true }
+func (t *fakeT) Cleanup(fn func()) { t.cleanups = append(t.cleanups, fn) }
+
+func TestNewTestLogger(t *testing.T) {
+	ft := &fakeT{}
+	log := NewTestLogger(ft, WithTestFailure(ErrorLevel), WithName("svc"))
+
+	line := callerLine() + 1
+	log.Debug("starting", Int("port", 80))
+	log.Warn("slow")
+	log.Error("broken")
+	log.Fatal("fatal")
+
+	caller := "caller=logger/testlogger_test.go:" + strconv.Itoa(line)
+	require.Len(t, ft.logs, 2)
+	assert.Equal(t, "DEBUG starting logger=svc port=80 "+caller, ft.logs[0])
+	assert.Contains(t, ft.logs[1], "WARN slow")
+	require.Len(t, ft.errors, 2)
+	assert.Contains(t, ft.errors[0], "ERROR broken")
+	assert.Contains(t, ft.errors[1], "FATAL fatal")
+	assert.True(t, ft.failed, "Fatal fails the test instead of exiting")
+
+	require.Len(t, ft.cleanups, 1)
+	ft.cleanups[0]()
+	log.Info("after the test")
+	assert.Len(t, ft.logs, 2, "entries after the test are dropped")
+}
+
+func TestNewTestLogger_Real(t *testing.T) {
+	log := NewTestLogger(t, WithLevel(InfoLevel))
+	log.Debug("hidden")
+	log.Info("shown with the test output")
+	assert.False(t, t.Failed())
+}
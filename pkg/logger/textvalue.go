@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"encoding"
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxTextValueBytes caps the strings returned by String and MarshalText
+// methods, so a value rendering a huge dump does not blow up the entry.
+const maxTextValueBytes = 1024
+
+// textValueEllipsis marks a truncated text value.
+const textValueEllipsis = "..."
+
+// textValue renders v with its String, Error or MarshalText method, in that
+// order of preference, and reports whether it has one. The result is truncated to
+// maxTextValueBytes. A method that panics or fails renders as
+// "!PANIC: <value>" or "!ERROR: <error>" instead of crashing the caller.
+func textValue(v any) (s string, ok bool) {
+	switch v.(type) {
+	case fmt.Stringer, error, encoding.TextMarshaler:
+	default:
+		return "", false
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			s, ok = fmt.Sprintf("!PANIC: %v", p), true
+		}
+	}()
+
+	switch x := v.(type) {
+	case fmt.Stringer:
+		s = x.String()
+	case error:
+		s = x.Error()
+	case encoding.TextMarshaler:
+		text, err := x.MarshalText()
+		if err != nil {
+			return "!ERROR: " + err.Error(), true
+		}
+		s = string(text[:min(len(text), maxTextValueBytes+1)])
+	}
+	return truncateTextValue(s), true
+}
+
+// truncateTextValue cuts s to maxTextValueBytes on a rune boundary.
+func truncateTextValue(s string) string {
+	if len(s) <= maxTextValueBytes {
+		return s
+	}
+	n := maxTextValueBytes - len(textValueEllipsis)
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + textValueEllipsis
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type panickingStringer struct{}
+
+func (panickingStringer) String() string { panic("boom") }
+
+type textOnly struct{ id string }
+
+func (t textOnly) MarshalText() ([]byte, error) {
+	if t.id == "" {
+		return nil, errors.New("empty id")
+	}
+	return []byte("id-" + t.id), nil
+}
+
+type longStringer struct{}
+
+func (longStringer) String() string { return strings.Repeat("é", maxTextValueBytes) }
+
+func TestEncoders_TextValues(t *testing.T) {
+	loc, err := time.LoadLocation("UTC")
+	assert.NoError(t, err)
+	fields := []Field{
+		{Key: "ip", Value: net.ParseIP("10.0.0.1")},
+		{Key: "addr", Value: netip.MustParseAddr("::1")},
+		{Key: "zone", Value: loc},
+		{Key: "id", Value: textOnly{id: "7"}},
+		{Key: "bad", Value: textOnly{}},
+		{Key: "boom", Value: panickingStringer{}},
+		{Key: "err", Value: errors.New("no route")},
+		{Key: "other", Value: struct{}{}},
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONFormat, `{"level":"INFO","message":"m","ip":"10.0.0.1","addr":"::1","zone":"UTC","id":"id-7","bad":"!ERROR: empty id","boom":"!PANIC: boom","err":"no route","other":"unknown"}`},
+		{TextFormat, `INFO m ip=10.0.0.1 addr=::1 zone=UTC id=id-7 bad="!ERROR: empty id" boom="!PANIC: boom" err="no route" other="unknown"`},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		log := New(Config{Level: InfoLevel, Format: tt.format, Output: buf, TimestampFormat: TimestampDisabled})
+
+		log.Info("m", fields...)
+		assert.Equal(t, tt.want+"\n", buf.String())
+	}
+}
+
+func TestF_TextValues(t *testing.T) {
+	assert.Equal(t, "id-7", F("id", textOnly{id: "7"}).Value)
+	assert.Equal(t, "!PANIC: boom", F("boom", panickingStringer{}).Value)
+}
+
+func TestTextValue_Truncated(t *testing.T) {
+	s, ok := textValue(longStringer{})
+	assert.True(t, ok)
+	assert.LessOrEqual(t, len(s), maxTextValueBytes)
+	assert.True(t, strings.HasSuffix(s, textValueEllipsis))
+	assert.True(t, strings.HasPrefix(s, "éé"))
+	assert.NotContains(t, s, "�")
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// timeCache reads the clock and formats the timestamp once per period, for
+// Config.TimestampCache.
+type timeCache struct {
+	stamp  atomic.Pointer[cachedStamp]
+	format TimestampFormat
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// cachedStamp is a time with its pre-rendered timestamps.
+type cachedStamp struct {
+	time time.Time
+	text []byte
+	json []byte
+}
+
+func newTimeCache(period time.Duration, format TimestampFormat) *timeCache {
+	c := &timeCache{
+		format: format,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	c.update(time.Now())
+	go c.run(period)
+	return c
+}
+
+func (c *timeCache) run(period time.Duration) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-ticker.C:
+			c.update(now)
+		}
+	}
+}
+
+// update caches now, unless the clock stepped back.
+func (c *timeCache) update(now time.Time) {
+	if prev := c.stamp.Load(); prev != nil && now.Before(prev.time) {
+		return
+	}
+	c.stamp.Store(&cachedStamp{
+		time: now,
+		text: appendTextTimestamp(nil, now, c.format),
+		json: appendJSONTimestamp(nil, now, c.format),
+	})
+}
+
+// now returns the cached time, or the current time once the cache is
+// closed.
+func (c *timeCache) now() time.Time {
+	if s := c.stamp.Load(); s != nil {
+		return s.time
+	}
+	return time.Now()
+}
+
+// appendText appends the text timestamp of t, from the cache when t is the
+// cached time.
+func (c *timeCache) appendText(buf []byte, t time.Time, format TimestampFormat) []byte {
+	if c != nil {
+		if s := c.stamp.Load(); s != nil && s.time == t {
+			return append(buf, s.text...)
+		}
+	}
+	return appendTextTimestamp(buf, t, format)
+}
+
+// appendJSON appends the JSON timestamp of t like appendText.
+func (c *timeCache) appendJSON(buf []byte, t time.Time, format TimestampFormat) []byte {
+	if c != nil {
+		if s := c.stamp.Load(); s != nil && s.time == t {
+			return append(buf, s.json...)
+		}
+	}
+	return appendJSONTimestamp(buf, t, format)
+}
+
+// close stops the updates. Entries logged afterwards read the clock.
+func (c *timeCache) close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+	<-c.done
+	c.stamp.Store(nil)
+}
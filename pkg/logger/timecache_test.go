@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_TimestampCache(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf, TimestampCache: time.Hour})
+
+	cached := log.timeCache.now()
+	log.Info("one")
+	log.Info("two")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	want := `{"timestamp":"` + cached.Format(time.RFC3339Nano) + `"`
+	assert.True(t, strings.HasPrefix(lines[0], want), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], want), lines[1])
+
+	log.timeCache.update(cached.Add(-time.Second))
+	assert.Equal(t, cached, log.timeCache.now(), "the cached time never goes backwards")
+	log.timeCache.update(cached.Add(time.Second))
+	assert.Equal(t, cached.Add(time.Second), log.timeCache.now())
+
+	require.NoError(t, log.Close())
+	buf.Reset()
+	before := time.Now()
+	log.Info("after close")
+	var entry struct{ Timestamp time.Time }
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.False(t, entry.Timestamp.Before(before.Truncate(time.Millisecond)), "the clock is read again after Close")
+}
+
+func TestConfig_TimestampCacheFormats(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, TimestampCache: time.Hour, TimestampFormat: TimestampUnixMilli})
+	defer log.Close()
+
+	log.Info("hi")
+	assert.Equal(t, strconv.FormatInt(log.timeCache.now().UnixMilli(), 10)+" INFO hi\n", buf.String())
+
+	buf.Reset()
+	tmpl := New(Config{Level: InfoLevel, Output: buf, TimestampCache: time.Hour, TextTemplate: "{ts}|{msg}"})
+	defer tmpl.Close()
+	tmpl.Info("hi")
+	assert.Equal(t, tmpl.timeCache.now().UTC().Format(textTimestampLayout)+"|hi\n", buf.String())
+
+	// Entries with other times, e.g. replayed ones, are formatted as usual.
+	other := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, "1767323045000", string(log.timeCache.appendText(nil, other, TimestampUnixMilli)))
+}
+
+func TestConfig_TimestampCacheValidation(t *testing.T) {
+	assert.ErrorContains(t, Config{TimestampCache: -time.Second}.Validate(), "negative TimestampCache")
+	assert.ErrorContains(t, Config{TimestampCache: time.Millisecond, Clock: time.Now}.Validate(), "cannot be combined with Clock")
+}
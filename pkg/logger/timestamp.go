@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"strconv"
+	"time"
+)
+
+// TimestampFormat selects how TextFormat, JSONFormat and text templates
+// render the time of an entry. Any value other than the constants below is
+// a layout for time.Format, e.g. time.Kitchen. Formats with a schema of
+// their own, such as ECSFormat or GELFFormat, are not affected.
+type TimestampFormat string
+
+const (
+	// TimestampDefault renders RFC 3339 with nanoseconds in JSON and with
+	// milliseconds in UTC in text.
+	TimestampDefault TimestampFormat = ""
+
+	// TimestampRFC3339Nano renders time.RFC3339Nano in both formats.
+	TimestampRFC3339Nano TimestampFormat = time.RFC3339Nano
+
+	// TimestampUnix renders the seconds since the Unix epoch as an integer.
+	TimestampUnix TimestampFormat = "unix"
+
+	// TimestampUnixMilli renders the milliseconds since the Unix epoch as
+	// an integer, which is cheaper to produce than a formatted string.
+	TimestampUnixMilli TimestampFormat = "unixmilli"
+
+	// TimestampDisabled omits the timestamp, e.g. when the collector adds
+	// its own.
+	TimestampDisabled TimestampFormat = "disabled"
+)
+
+// now returns the current time of the configured clock.
+func (l *Logger) now() time.Time {
+	if l.config.Clock != nil {
+		return l.config.Clock()
+	}
+	if l.timeCache != nil {
+		return l.timeCache.now()
+	}
+	return time.Now()
+}
+
+// omitsTimestamp reports whether entries at t are written without a
+// timestamp.
+func omitsTimestamp(t time.Time, format TimestampFormat) bool {
+	return t.IsZero() || format == TimestampDisabled
+}
+
+// appendTextTimestamp renders t for TextFormat and text templates.
+func appendTextTimestamp(buf []byte, t time.Time, format TimestampFormat) []byte {
+	switch format {
+	case TimestampDefault:
+		return t.UTC().AppendFormat(buf, textTimestampLayout)
+	case TimestampUnix:
+		return strconv.AppendInt(buf, t.Unix(), 10)
+	case TimestampUnixMilli:
+		return strconv.AppendInt(buf, t.UnixMilli(), 10)
+	case TimestampDisabled:
+		return buf
+	default:
+		return t.AppendFormat(buf, string(format))
+	}
+}
+
+// appendJSONTimestamp renders t as a JSON value.
+func appendJSONTimestamp(buf []byte, t time.Time, format TimestampFormat) []byte {
+	switch format {
+	case TimestampDefault, TimestampRFC3339Nano:
+		buf = append(buf, '"')
+		buf = t.AppendFormat(buf, time.RFC3339Nano)
+		return append(buf, '"')
+	case TimestampUnix:
+		return strconv.AppendInt(buf, t.Unix(), 10)
+	case TimestampUnixMilli:
+		return strconv.AppendInt(buf, t.UnixMilli(), 10)
+	default:
+		var stack [64]byte
+		buf = append(buf, '"')
+		buf = appendJSONString(buf, string(t.AppendFormat(stack[:0], string(format))))
+		return append(buf, '"')
+	}
+}
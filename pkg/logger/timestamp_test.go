@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_TimestampFormat(t *testing.T) {
+	at := time.Date(2026, 3, 4, 5, 6, 7, 890123456, time.FixedZone("CET", 3600))
+	clock := func() time.Time { return at }
+
+	tests := []struct {
+		format   TimestampFormat
+		text     string
+		json     string
+		template string
+	}{
+		{TimestampDefault,
+			"2026-03-04T04:06:07.890Z INFO hi\n",
+			`{"timestamp":"2026-03-04T05:06:07.890123456+01:00","level":"INFO","message":"hi"}` + "\n",
+			"[2026-03-04T04:06:07.890Z] hi\n"},
+		{TimestampRFC3339Nano,
+			"2026-03-04T05:06:07.890123456+01:00 INFO hi\n",
+			`{"timestamp":"2026-03-04T05:06:07.890123456+01:00","level":"INFO","message":"hi"}` + "\n",
+			"[2026-03-04T05:06:07.890123456+01:00] hi\n"},
+		{TimestampUnix,
+			"1772597167 INFO hi\n",
+			`{"timestamp":1772597167,"level":"INFO","message":"hi"}` + "\n",
+			"[1772597167] hi\n"},
+		{TimestampUnixMilli,
+			"1772597167890 INFO hi\n",
+			`{"timestamp":1772597167890,"level":"INFO","message":"hi"}` + "\n",
+			"[1772597167890] hi\n"},
+		{`15:04 "MST"`,
+			`05:06 "CET" INFO hi` + "\n",
+			`{"timestamp":"05:06 \"CET\"","level":"INFO","message":"hi"}` + "\n",
+			`[05:06 "CET"] hi` + "\n"},
+		{TimestampDisabled,
+			"INFO hi\n",
+			`{"level":"INFO","message":"hi"}` + "\n",
+			"[] hi\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			New(Config{Output: buf, TimestampFormat: tt.format, Clock: clock}).Info("hi")
+			assert.Equal(t, tt.text, buf.String())
+
+			buf.Reset()
+			New(Config{Output: buf, Format: JSONFormat, TimestampFormat: tt.format, Clock: clock}).Info("hi")
+			assert.Equal(t, tt.json, buf.String())
+
+			buf.Reset()
+			New(Config{Output: buf, TextTemplate: "[{ts}] {msg}", TimestampFormat: tt.format, Clock: clock}).Info("hi")
+			assert.Equal(t, tt.template, buf.String())
+		})
+	}
+}
+
+func TestConfig_Clock(t *testing.T) {
+	buf := &bytes.Buffer{}
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	log, err := NewWithOptions(WithOutput(buf), WithClock(func() time.Time { return at }), WithTimestampFormat(TimestampUnix))
+	require.NoError(t, err)
+
+	log.Info("one")
+	at = at.Add(time.Second)
+	log.Info("two")
+	require.NoError(t, log.WriteRaw(InfoLevel, []byte("raw")))
+
+	assert.Equal(t, "1767225600 INFO one\n1767225601 INFO two\nraw\n", buf.String())
+}
@@ -0,0 +1,119 @@
+//go:build unix
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync/atomic"
+	"syscall"
+)
+
+// UnixgramWriter is an io.Writer that delivers log entries to a unix datagram
+// socket, one datagram per entry. On Linux, an address starting with '@'
+// refers to a socket in the abstract namespace.
+//
+// Writes never block: when the receiving socket buffer is full (EAGAIN) the
+// entry is dropped and counted. The drop counter can be inspected with
+// Dropped, which makes the writer suitable for node-level collectors that
+// must not apply backpressure to the application.
+type UnixgramWriter struct {
+	conn    *net.UnixConn
+	raw     syscall.RawConn
+	written atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// NewUnixgramWriter connects to the unix datagram socket at addr.
+//
+// Example:
+//
+//	w, err := logger.NewUnixgramWriter("@log-collector")
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Close()
+//
+//	log := logger.New(logger.Config{Format: logger.JSONFormat, Output: w})
+func NewUnixgramWriter(addr string) (*UnixgramWriter, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &UnixgramWriter{conn: conn, raw: raw}, nil
+}
+
+// Write sends every newline-terminated entry in p as its own datagram, so
+// entries flushed together by a buffered Logger keep their boundaries.
+// Entries rejected with EAGAIN are dropped and counted rather than reported
+// as errors. Write always reports len(p) bytes written unless the socket
+// fails with an error other than EAGAIN.
+func (w *UnixgramWriter) Write(p []byte) (int, error) {
+	rest := p
+	for len(rest) > 0 {
+		var entry []byte
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			entry, rest = rest[:i], rest[i+1:]
+		} else {
+			entry, rest = rest, nil
+		}
+
+		if len(entry) == 0 {
+			continue
+		}
+
+		if err := w.send(entry); err != nil {
+			if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.ENOBUFS) {
+				w.dropped.Add(1)
+				continue
+			}
+			return len(p) - len(rest), err
+		}
+		w.written.Add(1)
+	}
+
+	return len(p), nil
+}
+
+// lineOutput implements lineOutput.
+func (w *UnixgramWriter) lineOutput() {}
+
+// send performs a single non-blocking write of entry to the socket.
+func (w *UnixgramWriter) send(entry []byte) error {
+	var writeErr error
+	err := w.raw.Write(func(fd uintptr) bool {
+		_, writeErr = syscall.Write(int(fd), entry)
+		// Returning true reports EAGAIN to the caller instead of waiting for
+		// the socket to become writable.
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeErr
+}
+
+// Written returns the number of entries delivered to the socket.
+func (w *UnixgramWriter) Written() uint64 {
+	return w.written.Load()
+}
+
+// Dropped returns the number of entries discarded because the socket
+// buffer was full.
+func (w *UnixgramWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close closes the underlying socket.
+func (w *UnixgramWriter) Close() error {
+	return w.conn.Close()
+}
@@ -0,0 +1,94 @@
+//go:build unix
+
+package logger
+
+import (
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listenUnixgram(t *testing.T, addr string) *net.UnixConn {
+	t.Helper()
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	return string(buf[:n])
+}
+
+func TestUnixgramWriter_MessageBoundaries(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "collector.sock")
+	server := listenUnixgram(t, addr)
+
+	w, err := NewUnixgramWriter(addr)
+	require.NoError(t, err)
+	defer w.Close()
+
+	logger := New(Config{
+		Level:      InfoLevel,
+		Format:     TextFormat,
+		Output:     w,
+		BufferSize: 1024,
+	})
+
+	logger.Info("first")
+	logger.Info("second")
+	logger.Flush()
+
+	assert.Contains(t, readDatagram(t, server), "first")
+	assert.Contains(t, readDatagram(t, server), "second")
+	assert.Equal(t, uint64(2), w.Written())
+}
+
+func TestUnixgramWriter_AbstractSocket(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("abstract sockets are Linux-only")
+	}
+
+	addr := "@go-logslib-test-" + t.Name()
+	server := listenUnixgram(t, addr)
+
+	w, err := NewUnixgramWriter(addr)
+	require.NoError(t, err)
+	defer w.Close()
+
+	logger := New(Config{Level: InfoLevel, Format: JSONFormat, Output: w})
+	logger.Info("abstract")
+
+	assert.Contains(t, readDatagram(t, server), `"message":"abstract"`)
+}
+
+func TestUnixgramWriter_DropsWhenFull(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "full.sock")
+	_ = listenUnixgram(t, addr)
+
+	w, err := NewUnixgramWriter(addr)
+	require.NoError(t, err)
+	defer w.Close()
+
+	entry := make([]byte, 1024)
+	for i := 0; i < 100000 && w.Dropped() == 0; i++ {
+		_, err := w.Write(entry)
+		require.NoError(t, err)
+	}
+
+	assert.Positive(t, w.Dropped())
+}
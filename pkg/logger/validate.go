@@ -0,0 +1,261 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// ErrInvalidConfig is wrapped by every error returned by Config.Validate
+// and NewE.
+var ErrInvalidConfig = errors.New("logger: invalid config")
+
+// NewE is like New but validates config first, so a misconfiguration is
+// reported when the logger is created instead of surfacing as a panic or
+// silently dropped entries at the first log call.
+//
+// Example:
+//
+//	log, err := logger.NewE(logger.Config{
+//		Level:  logger.InfoLevel,
+//		Format: logger.JSONFormat,
+//		Output: file,
+//	})
+//	if err != nil {
+//		return err
+//	}
+func NewE(config Config) (*Logger, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return New(config), nil
+}
+
+// Validate reports all problems of the configuration at once, joined into
+// a single error wrapping ErrInvalidConfig. It rejects out-of-range levels
+// and formats, negative sizes and durations, outputs that are nil pointers
+// behind a non-nil io.Writer, and options that New would silently ignore
+// because another option they depend on is not set.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+	}
+
+	if c.Profile != "" {
+		if _, ok := profiles[c.Profile]; !ok {
+			invalid("unknown Profile %q", c.Profile)
+		}
+	}
+	// Conflicts are checked against the settings New actually uses.
+	c = applyProfile(c)
+
+	if !validLevel(c.Level) {
+		invalid("unknown Level %d", c.Level)
+	}
+	for name, level := range c.NamedLevels {
+		if !validLevel(level) {
+			invalid("unknown NamedLevels[%q] %d", name, level)
+		}
+	}
+	if c.DuplicateKeys < DuplicateKeysAllow || c.DuplicateKeys > DuplicateKeysFirstWins {
+		invalid("unknown DuplicateKeys %d", c.DuplicateKeys)
+	}
+	if c.FloatPrecision < 0 || c.FloatPrecision > maxFloatPrecision {
+		invalid("FloatPrecision %d out of range [0, %d]", c.FloatPrecision, maxFloatPrecision)
+	}
+	if c.NonFiniteFloats > NonFiniteAsNull {
+		invalid("unknown NonFiniteFloats %d", c.NonFiniteFloats)
+	}
+	if c.MaxMessageBytes < 0 {
+		invalid("negative MaxMessageBytes %d", c.MaxMessageBytes)
+	}
+	if c.MaxFieldBytes < 0 {
+		invalid("negative MaxFieldBytes %d", c.MaxFieldBytes)
+	}
+	if c.MaxFields < 0 {
+		invalid("negative MaxFields %d", c.MaxFields)
+	}
+	if c.MaxRecordBytes < 0 {
+		invalid("negative MaxRecordBytes %d", c.MaxRecordBytes)
+	}
+	if c.OversizedRecords > OversizedDrop {
+		invalid("unknown OversizedRecords %d", c.OversizedRecords)
+	}
+	if !validFormat(c.Format) {
+		invalid("unknown Format %d", c.Format)
+	}
+	if c.Output != nil && isNilWriter(c.Output) {
+		invalid("Output is a nil %T", c.Output)
+	}
+	if c.ErrorOutput != nil {
+		if isNilWriter(c.ErrorOutput) {
+			invalid("ErrorOutput is a nil %T", c.ErrorOutput)
+		}
+		if c.Output == nil {
+			invalid("ErrorOutput requires Output")
+		}
+	}
+	if c.AddStacktrace && !validLevel(c.StacktraceLevel) {
+		invalid("unknown StacktraceLevel %d", c.StacktraceLevel)
+	}
+	if c.TextTemplate != "" && c.Format != TextFormat {
+		invalid("TextTemplate requires TextFormat")
+	}
+
+	if c.BufferSize < 0 {
+		invalid("negative BufferSize %d", c.BufferSize)
+	}
+	if c.FlushInterval < 0 {
+		invalid("negative FlushInterval %s", c.FlushInterval)
+	} else if c.FlushInterval > 0 && c.BufferSize <= 0 {
+		invalid("FlushInterval requires BufferSize")
+	}
+	if c.BufferShards < 0 {
+		invalid("negative BufferShards %d", c.BufferShards)
+	}
+	if c.AsyncQueueSize < 0 {
+		invalid("negative AsyncQueueSize %d", c.AsyncQueueSize)
+	}
+	if b := c.MemoryBudget; b != nil {
+		if b.MaxBufferBytes < 0 {
+			invalid("negative MemoryBudget.MaxBufferBytes %d", b.MaxBufferBytes)
+		}
+		if b.MaxQueueBytes < 0 {
+			invalid("negative MemoryBudget.MaxQueueBytes %d", b.MaxQueueBytes)
+		} else if b.MaxQueueBytes > 0 && c.AsyncQueueSize <= 0 {
+			invalid("MemoryBudget.MaxQueueBytes requires AsyncQueueSize")
+		}
+	}
+	if c.InitialBufferSize < 0 {
+		invalid("negative InitialBufferSize %d", c.InitialBufferSize)
+	}
+	if c.MaxPooledBufferSize < 0 {
+		invalid("negative MaxPooledBufferSize %d", c.MaxPooledBufferSize)
+	} else if c.MaxPooledBufferSize > 0 && c.MaxPooledBufferSize < c.InitialBufferSize {
+		invalid("MaxPooledBufferSize %d is smaller than InitialBufferSize %d", c.MaxPooledBufferSize, c.InitialBufferSize)
+	}
+	if c.TimestampCache < 0 {
+		invalid("negative TimestampCache %s", c.TimestampCache)
+	} else if c.TimestampCache > 0 && c.Clock != nil {
+		invalid("TimestampCache cannot be combined with Clock")
+	}
+	if c.InternalOutput != nil && isNilWriter(c.InternalOutput) {
+		invalid("InternalOutput is a nil %T", c.InternalOutput)
+	}
+	if c.WriteErrors.Retries < 0 {
+		invalid("negative WriteErrors.Retries %d", c.WriteErrors.Retries)
+	}
+	if c.WriteErrors.RetryInterval < 0 {
+		invalid("negative WriteErrors.RetryInterval %s", c.WriteErrors.RetryInterval)
+	}
+	if c.WriteErrors.Fallback != nil && isNilWriter(c.WriteErrors.Fallback) {
+		invalid("WriteErrors.Fallback is a nil %T", c.WriteErrors.Fallback)
+	}
+	if c.DeliveryTraceInterval < 0 {
+		invalid("negative DeliveryTraceInterval %s", c.DeliveryTraceInterval)
+	}
+
+	for level, limit := range c.RateLimit {
+		if !validLevel(level) {
+			invalid("RateLimit for unknown Level %d", level)
+		}
+		if limit.EventsPerSecond < 0 || math.IsNaN(limit.EventsPerSecond) {
+			invalid("RateLimit[%s].EventsPerSecond must not be negative", level)
+		}
+	}
+	if c.RepeatWindow < 0 {
+		invalid("negative RepeatWindow %s", c.RepeatWindow)
+	}
+	if b := c.Breadcrumbs; b != nil {
+		if b.Size <= 0 {
+			invalid("Breadcrumbs.Size must be positive, got %d", b.Size)
+		}
+		if !validLevel(b.Level) {
+			invalid("unknown Breadcrumbs.Level %d", b.Level)
+		}
+	}
+	if c.RateLimitReportInterval < 0 {
+		invalid("negative RateLimitReportInterval %s", c.RateLimitReportInterval)
+	}
+	if s := c.Sampling; s != nil {
+		if s.Thereafter < 0 {
+			invalid("negative Sampling.Thereafter %d", s.Thereafter)
+		}
+		if s.Window < 0 {
+			invalid("negative Sampling.Window %s", s.Window)
+		}
+		if s.MaxValues < 0 {
+			invalid("negative Sampling.MaxValues %d", s.MaxValues)
+		}
+	}
+
+	for i := range c.Filters {
+		f := &c.Filters[i]
+		if f.isZero() {
+			invalid("Filters[%d] has no criteria", i)
+		}
+		if f.Action > FilterInclude {
+			invalid("Filters[%d]: unknown Action %d", i, f.Action)
+		}
+		for key, pattern := range f.FieldPatterns {
+			if pattern == nil {
+				invalid("Filters[%d].FieldPatterns[%s] is nil", i, strconv.Quote(key))
+			}
+		}
+	}
+	for i, pattern := range c.RedactValuePatterns {
+		if pattern == nil {
+			invalid("RedactValuePatterns[%d] is nil", i)
+		}
+	}
+	for key, normalize := range c.Normalizers {
+		if normalize == nil {
+			invalid("Normalizers[%s] is nil", strconv.Quote(key))
+		}
+	}
+
+	for i, sink := range c.Outputs {
+		if sink.Output == nil || isNilWriter(sink.Output) {
+			invalid("Outputs[%d].Output is nil", i)
+		}
+		if !validLevel(sink.Level) {
+			invalid("Outputs[%d]: unknown Level %d", i, sink.Level)
+		}
+		if sink.Encoder == nil && !validFormat(sink.Format) {
+			invalid("Outputs[%d]: unknown Format %d", i, sink.Format)
+		}
+		if sink.BufferSize < 0 {
+			invalid("Outputs[%d]: negative BufferSize %d", i, sink.BufferSize)
+		}
+		if sink.FlushInterval < 0 {
+			invalid("Outputs[%d]: negative FlushInterval %s", i, sink.FlushInterval)
+		} else if sink.FlushInterval > 0 && sink.BufferSize <= 0 {
+			invalid("Outputs[%d]: FlushInterval requires BufferSize", i)
+		}
+		if sink.BufferShards < 0 {
+			invalid("Outputs[%d]: negative BufferShards %d", i, sink.BufferShards)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validFormat reports whether format is one of the built-in formats.
+func validFormat(format Format) bool {
+	return format >= TextFormat && format <= ConsoleFormat
+}
+
+// isNilWriter reports whether w holds a nil pointer, map, channel, func or
+// slice, which panics on the first Write.
+func isNilWriter(w io.Writer) bool {
+	v := reflect.ValueOf(w)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Chan, reflect.Func, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewE_Valid(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := NewE(Config{Level: InfoLevel, Format: JSONFormat, Output: buf, Profile: ProfileDebug})
+	require.NoError(t, err)
+
+	logger.Info("started")
+	assert.Contains(t, buf.String(), `"message":"started"`)
+
+	_, err = NewE(Config{})
+	assert.NoError(t, err, "the zero Config logs text to stdout")
+}
+
+func TestNewE_Invalid(t *testing.T) {
+	var nilFile *os.File
+
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"typed nil output", Config{Output: nilFile}, "Output is a nil *os.File"},
+		{"unknown level", Config{Level: 42}, "unknown Level 42"},
+		{"unknown format", Config{Format: 42}, "unknown Format 42"},
+		{"unknown profile", Config{Profile: "fast"}, `unknown Profile "fast"`},
+		{"negative buffer", Config{BufferSize: -1}, "negative BufferSize -1"},
+		{"negative queue", Config{AsyncQueueSize: -1}, "negative AsyncQueueSize -1"},
+		{"flush without buffer", Config{FlushInterval: time.Second}, "FlushInterval requires BufferSize"},
+		{"error output without output", Config{ErrorOutput: &bytes.Buffer{}, Outputs: []SinkConfig{{Output: &bytes.Buffer{}}}}, "ErrorOutput requires Output"},
+		{"template for json", Config{Format: JSONFormat, TextTemplate: "{msg}"}, "TextTemplate requires TextFormat"},
+		{"queue budget without queue", Config{MemoryBudget: &MemoryBudget{MaxQueueBytes: 1024}}, "MaxQueueBytes requires AsyncQueueSize"},
+		{"nil internal output", Config{InternalOutput: nilFile}, "InternalOutput is a nil *os.File"},
+		{"unknown duplicate keys", Config{DuplicateKeys: 7}, "unknown DuplicateKeys 7"},
+		{"negative retries", Config{WriteErrors: WriteErrorPolicy{Retries: -1}}, "negative WriteErrors.Retries -1"},
+		{"nil fallback", Config{WriteErrors: WriteErrorPolicy{Fallback: nilFile}}, "WriteErrors.Fallback is a nil *os.File"},
+		{"negative rate", Config{RateLimit: map[Level]RateLimit{InfoLevel: {EventsPerSecond: -1}}}, "RateLimit[INFO].EventsPerSecond"},
+		{"nil pattern", Config{RedactValuePatterns: []*regexp.Regexp{nil}}, "RedactValuePatterns[0] is nil"},
+		{"nil normalizer", Config{Normalizers: map[string]Normalizer{"method": nil}}, `Normalizers["method"] is nil`},
+		{"nil sink output", Config{Outputs: []SinkConfig{{Level: InfoLevel}}}, "Outputs[0].Output is nil"},
+		{"sink format", Config{Outputs: []SinkConfig{{Output: &bytes.Buffer{}, Format: -3}}}, "Outputs[0]: unknown Format -3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := NewE(tt.config)
+			assert.Nil(t, logger)
+			require.ErrorIs(t, err, ErrInvalidConfig)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestConfigValidate_ReportsAllProblems(t *testing.T) {
+	err := Config{Level: 42, BufferSize: -1, AsyncQueueSize: -1}.Validate()
+	require.Error(t, err)
+
+	var joined interface{ Unwrap() []error }
+	require.True(t, errors.As(err, &joined))
+	assert.Len(t, joined.Unwrap(), 3)
+}
+
+func TestConfigValidate_ProfileSettings(t *testing.T) {
+	// FlushInterval is satisfied by the buffer size of the profile.
+	assert.NoError(t, Config{Profile: ProfileThroughput, FlushInterval: 100 * time.Millisecond}.Validate())
+}
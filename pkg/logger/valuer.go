@@ -0,0 +1,86 @@
+package logger
+
+import "fmt"
+
+// maxLogValuerDepth bounds the resolution of LogValuers returning other
+// LogValuers, as slog does.
+const maxLogValuerDepth = 100
+
+// LogValuer is implemented by values that compute their logged form
+// themselves, mirroring slog.LogValuer. F, Any and the Infow family keep
+// a LogValuer as is and call LogValue only once an entry passed level
+// filtering, sampling and rate limiting, right before it is handed to the
+// pipeline, so expensive values cost nothing when the entry is dropped:
+//
+//	type Token string
+//
+//	func (t Token) LogValue() any { return "****" + string(t[len(t)-4:]) }
+//
+//	log.Info("authenticated", logger.F("token", token))
+//
+// The result is converted like Any converts its value; a LogValuer
+// returned by LogValue is resolved in turn. A panic in LogValue is logged
+// as the value "!PANIC: <value>" instead of crashing the caller, unless
+// Config.StrictPanics is set.
+type LogValuer interface {
+	LogValue() any
+}
+
+// LogValuerFunc adapts a function to LogValuer, e.g. for a value that is
+// only worth computing when the entry is written:
+//
+//	log.Debug("state", logger.F("dump", logger.LogValuerFunc(cache.Dump)))
+type LogValuerFunc func() any
+
+// LogValue returns f().
+func (f LogValuerFunc) LogValue() any {
+	return f()
+}
+
+// valuerValue holds a LogValuer until its entry is emitted.
+type valuerValue struct {
+	v LogValuer
+}
+
+// resolve returns the converted value of the LogValuer, reporting a panic
+// to l.
+func (v valuerValue) resolve(l *Logger) (value any) {
+	defer func() {
+		if p := recover(); p != nil {
+			l.handlePanic(panicValuer, p)
+			value = fmt.Sprintf("!PANIC: %v", p)
+		}
+	}()
+
+	var x any = v.v
+	for range maxLogValuerDepth {
+		lv, ok := x.(LogValuer)
+		if !ok {
+			return Any("", x).Value
+		}
+		x = lv.LogValue()
+	}
+	return "!ERROR: LogValue did not resolve after " + fmt.Sprint(maxLogValuerDepth) + " calls"
+}
+
+// resolveValuers replaces the LogValuers among the fields of r with their
+// values. The record may still reference the caller's slice, so r.Fields
+// is copied into the record before it is modified unless it already is.
+func (l *Logger) resolveValuers(r *Record) {
+	owned := false
+	for i, field := range r.Fields {
+		v, ok := field.Value.(valuerValue)
+		if !ok {
+			continue
+		}
+		if !owned {
+			owned = len(r.fields) > 0 && &r.fields[0] == &r.Fields[0]
+			if !owned {
+				r.fields = append(r.fields[:0], r.Fields...)
+				r.Fields = r.fields
+				owned = true
+			}
+		}
+		r.Fields[i].Value = v.resolve(l)
+	}
+}
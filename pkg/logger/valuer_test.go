@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maskedToken string
+
+func (t maskedToken) LogValue() any {
+	return "****" + string(t[len(t)-4:])
+}
+
+type countingValuer struct {
+	calls *int
+}
+
+func (v countingValuer) LogValue() any {
+	*v.calls++
+	return map[string]int{"calls": *v.calls}
+}
+
+type nestedValuer int
+
+func (v nestedValuer) LogValue() any {
+	if v == 0 {
+		return "done"
+	}
+	return v - 1
+}
+
+type loopingValuer struct{}
+
+func (v loopingValuer) LogValue() any { return v }
+
+type panickingValuer struct{}
+
+func (panickingValuer) LogValue() any { panic("boom") }
+
+func TestLogValuer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Format: JSONFormat, Output: buf})
+
+	calls := 0
+	fields := []Field{
+		F("token", maskedToken("s3cr3t-abcd")),
+		Any("stats", countingValuer{calls: &calls}),
+		F("nested", nestedValuer(3)),
+		F("loop", loopingValuer{}),
+		F("panic", panickingValuer{}),
+	}
+
+	log.Debug("dropped", fields...)
+	assert.Zero(t, calls, "LogValue is not called for disabled entries")
+
+	log.With(F("lazy", LogValuerFunc(func() any { return 42 }))).Info("login", fields...)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, valuerValue{v: maskedToken("s3cr3t-abcd")}, fields[0].Value, "the caller's fields are not modified")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "****abcd", entry["token"])
+	assert.Equal(t, map[string]any{"calls": float64(1)}, entry["stats"])
+	assert.Equal(t, "done", entry["nested"])
+	assert.Contains(t, entry["loop"], "!ERROR: LogValue did not resolve")
+	assert.Equal(t, "!PANIC: boom", entry["panic"])
+	assert.Equal(t, float64(42), entry["lazy"])
+	assert.NotContains(t, buf.String(), "s3cr3t")
+}
+
+func TestLogValuer_Async(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(Config{Level: InfoLevel, Output: buf, AsyncQueueSize: 8})
+
+	token := maskedToken("s3cr3t-wxyz")
+	log.Infow("login", "token", token)
+	require.NoError(t, log.Close())
+
+	assert.Contains(t, buf.String(), "token=****wxyz")
+	assert.NotContains(t, buf.String(), "s3cr3t")
+}
@@ -0,0 +1,362 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	// walHeaderSize is the size of the frame header: a big-endian uint32
+	// payload length followed by the CRC-32C of the payload.
+	walHeaderSize = 8
+
+	// maxWALFrameSize bounds the payload length accepted by the scanner, so
+	// a corrupt length cannot trigger a huge allocation.
+	maxWALFrameSize = 64 << 20
+)
+
+// ErrWALCorrupt is reported by WALScanner when the log ends in a torn or
+// corrupt frame.
+var ErrWALCorrupt = errors.New("logger: corrupt WAL frame")
+
+var walTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WALWriter is a crash-consistent file output. Every entry is stored as a
+// length-prefixed frame with a checksum, so a crash in the middle of a write
+// leaves a torn frame that readers detect and skip instead of a half-written
+// JSON line.
+//
+// WALWriter implements RecordWriter, so a Logger writes every entry as one
+// frame, whatever its format and even when it spans several lines; the
+// Logger does not batch its entries. Use WALScanner to read the entries
+// back, or the logslib-wal command to export or repair a file.
+type WALWriter struct {
+	mu        sync.Mutex
+	file      *os.File
+	buf       []byte
+	recovered int64
+	skipped   int64
+}
+
+// OpenWAL opens or creates the framed log at path for appending. A torn
+// frame left at the end of the file by an earlier crash is truncated first;
+// the number of bytes removed is reported by Recovered. Corrupt frames
+// followed by valid ones are left in place, see Skipped.
+//
+// Example:
+//
+//	w, err := logger.OpenWAL("/var/log/app.wal")
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Close()
+//
+//	log := logger.New(logger.Config{
+//		Format: logger.JSONFormat,
+//		Output: w,
+//	})
+func OpenWAL(path string) (*WALWriter, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	repair, err := repairWAL(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(repair.size, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &WALWriter{file: file, recovered: repair.removed, skipped: repair.skipped}, nil
+}
+
+// Write stores p as a single entry, in one frame. The trailing newline is
+// not part of the stored entry.
+func (w *WALWriter) Write(p []byte) (int, error) {
+	if err := w.writeEntry(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// lineOutput implements lineOutput.
+func (w *WALWriter) lineOutput() {}
+
+// WriteRecord stores the entry of r in one frame, for the Logger.
+func (w *WALWriter) WriteRecord(_ *Record, entry []byte) error {
+	return w.writeEntry(entry)
+}
+
+func (w *WALWriter) writeEntry(entry []byte) error {
+	entry = bytes.TrimSuffix(entry, []byte{'\n'})
+	if len(entry) == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = appendWALFrame(w.buf[:0], entry)
+	_, err := w.file.Write(w.buf)
+	return err
+}
+
+// Recovered returns the number of bytes truncated from a torn tail when the
+// file was opened.
+func (w *WALWriter) Recovered() int64 {
+	return w.recovered
+}
+
+// Skipped returns the number of bytes of corrupt frames found before valid
+// frames when the file was opened. They are kept and skipped by readers.
+func (w *WALWriter) Skipped() int64 {
+	return w.skipped
+}
+
+// Sync commits the written frames to stable storage.
+func (w *WALWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Sync()
+}
+
+// Close syncs and closes the file.
+func (w *WALWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return errors.Join(w.file.Sync(), w.file.Close())
+}
+
+// appendWALFrame appends entry as a single frame.
+func appendWALFrame(buf, entry []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(entry)))
+	buf = binary.BigEndian.AppendUint32(buf, crc32.Checksum(entry, walTable))
+	return append(buf, entry...)
+}
+
+// WALScanner reads the entries of a framed log written by WALWriter. A
+// corrupt region followed by valid frames, such as a torn write that later
+// appends went past, is skipped and counted by Skipped; a torn or corrupt
+// tail ends the scan.
+//
+// Example:
+//
+//	s := logger.NewWALScanner(file)
+//	for s.Scan() {
+//		fmt.Printf("%s\n", s.Entry())
+//	}
+//	if err := s.Err(); err != nil {
+//		// The file ends in a torn frame after s.Offset() bytes.
+//	}
+type WALScanner struct {
+	r       *bufio.Reader
+	header  [walHeaderSize]byte
+	entry   []byte
+	offset  int64
+	skipped int64
+	err     error
+
+	// rest holds the input after a corrupt frame, which is searched in
+	// memory for the next valid frame.
+	rest     []byte
+	resynced bool
+}
+
+// NewWALScanner returns a scanner reading frames from r.
+func NewWALScanner(r io.Reader) *WALScanner {
+	return &WALScanner{r: bufio.NewReader(r)}
+}
+
+// Scan advances to the next entry. It returns false at the end of the
+// input or at an invalid frame not followed by a valid one.
+func (s *WALScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	if s.resynced {
+		return s.scanRest()
+	}
+
+	if _, err := io.ReadFull(s.r, s.header[:]); err != nil {
+		if err != io.EOF {
+			s.err = walReadError(err)
+		}
+		return false
+	}
+
+	size := binary.BigEndian.Uint32(s.header[:4])
+	if size == 0 || size > maxWALFrameSize {
+		return s.resync(s.header[:])
+	}
+
+	if cap(s.entry) < int(size) {
+		s.entry = make([]byte, size)
+	}
+	s.entry = s.entry[:size]
+	if n, err := io.ReadFull(s.r, s.entry); err != nil {
+		if err := walReadError(err); err != ErrWALCorrupt {
+			s.err = err
+			return false
+		}
+		return s.resync(append(s.header[:], s.entry[:n]...))
+	}
+
+	if crc32.Checksum(s.entry, walTable) != binary.BigEndian.Uint32(s.header[4:]) {
+		return s.resync(append(s.header[:], s.entry...))
+	}
+
+	s.offset += walHeaderSize + int64(size)
+	return true
+}
+
+// resync reads the rest of the input after the invalid frame starting with
+// head and continues with the next valid frame in it, if any.
+func (s *WALScanner) resync(head []byte) bool {
+	rest, err := io.ReadAll(s.r)
+	if err != nil {
+		s.err = err
+		return false
+	}
+	s.rest = append(head[:len(head):len(head)], rest...)
+	s.resynced = true
+
+	return s.scanRest()
+}
+
+// scanRest scans the next frame of s.rest, skipping corrupt bytes before
+// it. At a corrupt tail it stops with ErrWALCorrupt at the last valid
+// frame.
+func (s *WALScanner) scanRest() bool {
+	for i := 0; i < len(s.rest); i++ {
+		size, ok := walFrameAt(s.rest[i:])
+		if !ok {
+			continue
+		}
+		s.skipped += int64(i)
+		s.offset += int64(i) + walHeaderSize + int64(size)
+		s.entry = s.rest[i+walHeaderSize : i+walHeaderSize+size]
+		s.rest = s.rest[i+walHeaderSize+size:]
+		return true
+	}
+
+	if len(s.rest) > 0 {
+		s.err = ErrWALCorrupt
+	}
+	return false
+}
+
+// walFrameAt reports whether buf starts with a valid frame, and its
+// payload size.
+func walFrameAt(buf []byte) (int, bool) {
+	if len(buf) < walHeaderSize {
+		return 0, false
+	}
+	size := binary.BigEndian.Uint32(buf[:4])
+	if size == 0 || size > maxWALFrameSize || int(size) > len(buf)-walHeaderSize {
+		return 0, false
+	}
+	payload := buf[walHeaderSize : walHeaderSize+size]
+	return int(size), crc32.Checksum(payload, walTable) == binary.BigEndian.Uint32(buf[4:walHeaderSize])
+}
+
+// Entry returns the current entry. It is only valid until the next call to
+// Scan.
+func (s *WALScanner) Entry() []byte {
+	return s.entry
+}
+
+// Offset returns the position just past the last valid frame.
+func (s *WALScanner) Offset() int64 {
+	return s.offset
+}
+
+// Skipped returns the number of corrupt bytes skipped so far before valid
+// frames.
+func (s *WALScanner) Skipped() int64 {
+	return s.skipped
+}
+
+// Err returns ErrWALCorrupt when scanning stopped at an invalid tail, or
+// the read error that stopped it. It returns nil at a clean end of input.
+func (s *WALScanner) Err() error {
+	return s.err
+}
+
+// walReadError maps a truncated frame to ErrWALCorrupt.
+func walReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrWALCorrupt
+	}
+	return err
+}
+
+// WALRepair is the result of RepairWAL.
+type WALRepair struct {
+	// Removed is the number of bytes truncated from a torn tail.
+	Removed int64
+
+	// Skipped is the number of bytes of corrupt frames followed by valid
+	// ones. They are left in place, and skipped by WALScanner.
+	Skipped int64
+}
+
+// RepairWAL truncates the framed log at path after its last valid frame.
+// Corruption before valid frames is only reported, so that no valid entry
+// is lost. Files written by WALWriter are repaired automatically by
+// OpenWAL; RepairWAL is meant for offline use.
+func RepairWAL(path string) (WALRepair, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return WALRepair{}, err
+	}
+
+	repair, err := repairWAL(file)
+	return WALRepair{Removed: repair.removed, Skipped: repair.skipped}, errors.Join(err, file.Close())
+}
+
+// walRepair is the result of repairWAL: the bytes removed from the tail
+// and skipped before valid frames, and the new size.
+type walRepair struct {
+	removed, skipped, size int64
+}
+
+// repairWAL truncates file after its last valid frame.
+func repairWAL(file *os.File) (walRepair, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return walRepair{}, err
+	}
+
+	s := NewWALScanner(file)
+	for s.Scan() {
+	}
+	if err := s.Err(); err != nil && err != ErrWALCorrupt {
+		return walRepair{}, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return walRepair{}, err
+	}
+
+	repair := walRepair{skipped: s.Skipped(), size: s.Offset()}
+	if info.Size() == repair.size {
+		return repair, nil
+	}
+	if err := file.Truncate(repair.size); err != nil {
+		return walRepair{}, err
+	}
+	repair.removed = info.Size() - repair.size
+	return repair, nil
+}
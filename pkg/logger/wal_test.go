@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scanWALFile(t *testing.T, path string) ([]string, error) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []string
+	s := NewWALScanner(f)
+	for s.Scan() {
+		entries = append(entries, string(s.Entry()))
+	}
+	return entries, s.Err()
+}
+
+func TestWALWriter_BufferedSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.wal")
+
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+
+	logger := New(Config{
+		Level:      InfoLevel,
+		Format:     JSONFormat,
+		Output:     w,
+		BufferSize: 4096,
+	})
+	logger.Info("first", Field{Key: "n", Value: 1})
+	logger.Info("second", Field{Key: "n", Value: 2})
+	logger.Flush()
+	require.NoError(t, w.Close())
+
+	entries, err := scanWALFile(t, path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Contains(t, entries[0], `"message":"first"`)
+	assert.Contains(t, entries[1], `"message":"second"`)
+	assert.NotContains(t, entries[0], "\n")
+}
+
+func TestWALScanner_TornTail(t *testing.T) {
+	var buf []byte
+	buf = appendWALFrame(buf, []byte(`{"message":"ok"}`))
+	valid := len(buf)
+	buf = appendWALFrame(buf, []byte(`{"message":"torn"}`))
+
+	for _, cut := range []int{valid + 3, valid + walHeaderSize + 5} {
+		s := NewWALScanner(bytes.NewReader(buf[:cut]))
+		require.True(t, s.Scan())
+		assert.Equal(t, `{"message":"ok"}`, string(s.Entry()))
+		assert.False(t, s.Scan())
+		assert.ErrorIs(t, s.Err(), ErrWALCorrupt)
+		assert.Equal(t, int64(valid), s.Offset())
+	}
+
+	s := NewWALScanner(bytes.NewReader(buf))
+	assert.True(t, s.Scan())
+	assert.True(t, s.Scan())
+	assert.False(t, s.Scan())
+	assert.NoError(t, s.Err())
+}
+
+func TestWALScanner_ChecksumMismatch(t *testing.T) {
+	buf := appendWALFrame(nil, []byte(`{"message":"ok"}`))
+	buf[len(buf)-2] ^= 0xff
+
+	s := NewWALScanner(bytes.NewReader(buf))
+	assert.False(t, s.Scan())
+	assert.ErrorIs(t, s.Err(), ErrWALCorrupt)
+	assert.Zero(t, s.Offset())
+}
+
+func TestWALScanner_SkipsCorruptMiddle(t *testing.T) {
+	buf := appendWALFrame(nil, []byte("one"))
+	torn := appendWALFrame(nil, []byte("two"))
+	buf = append(buf, torn[:len(torn)-2]...)
+	corrupt := appendWALFrame(nil, []byte("three"))
+	corrupt[len(corrupt)-1] ^= 0xff
+	buf = append(buf, corrupt...)
+	buf = appendWALFrame(buf, []byte("four"))
+
+	s := NewWALScanner(bytes.NewReader(buf))
+	var entries []string
+	for s.Scan() {
+		entries = append(entries, string(s.Entry()))
+	}
+	require.NoError(t, s.Err())
+	assert.Equal(t, []string{"one", "four"}, entries)
+	assert.Equal(t, int64(len(torn)-2+len(corrupt)), s.Skipped())
+	assert.Equal(t, int64(len(buf)), s.Offset())
+}
+
+func TestOpenWAL_RecoversTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.wal")
+
+	data := appendWALFrame(nil, []byte("one"))
+	torn := appendWALFrame(nil, []byte("two"))
+	data = append(data, torn[:len(torn)-1]...)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(torn)-1), w.Recovered())
+
+	_, err = w.Write([]byte("three\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	entries, err := scanWALFile(t, path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "three"}, entries)
+}
+
+func TestOpenWAL_KeepsFramesAfterCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.wal")
+
+	data := appendWALFrame(nil, []byte("one"))
+	torn := appendWALFrame(nil, []byte("two"))
+	data = append(data, torn[:5]...)
+	data = appendWALFrame(data, []byte("three"))
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+	assert.Zero(t, w.Recovered())
+	assert.Equal(t, int64(5), w.Skipped())
+	_, err = w.Write([]byte("four\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	entries, err := scanWALFile(t, path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "three", "four"}, entries)
+}
+
+func TestWALWriter_MultilineEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.wal")
+
+	w, err := OpenWAL(path)
+	require.NoError(t, err)
+	logger := New(Config{Level: InfoLevel, Format: TextFormat, Output: w, TimestampFormat: TimestampDisabled})
+	logger.Info("stack:\nmain.go:12")
+	logger.Info("done")
+	require.NoError(t, w.Close())
+
+	entries, err := scanWALFile(t, path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "done", entries[1][len(entries[1])-4:])
+}
+
+func TestRepairWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.wal")
+
+	data := appendWALFrame(nil, []byte("one"))
+	require.NoError(t, os.WriteFile(path, append(data, 0, 0, 0), 0o600))
+
+	repair, err := RepairWAL(path)
+	require.NoError(t, err)
+	assert.Equal(t, WALRepair{Removed: 3}, repair)
+
+	repair, err = RepairWAL(path)
+	require.NoError(t, err)
+	assert.Zero(t, repair.Removed)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), info.Size())
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// WriteErrorPolicy decides what happens to an entry whose write to an output
+// fails. The zero value drops it, which keeps a logger with a broken output
+// from blocking the program.
+//
+// Example, retrying twice and then writing to stderr:
+//
+//	log := logger.New(logger.Config{
+//		Output:      file,
+//		WriteErrors: logger.WriteErrorPolicy{Retries: 2, Fallback: os.Stderr},
+//		OnWriteError: func(err error) {
+//			writeErrors.Inc()
+//		},
+//	})
+type WriteErrorPolicy struct {
+	// Retries is the number of times a failed write is retried. Only the
+	// bytes not written yet are retried, so a short write does not
+	// duplicate output.
+	Retries int
+
+	// RetryInterval is the pause before each retry. Retries run on the
+	// writing goroutine, so with synchronous logging the pause delays the
+	// caller.
+	RetryInterval time.Duration
+
+	// Fallback, when set, receives the bytes that could still not be
+	// written after the retries, e.g. os.Stderr. Entries passed to a
+	// RecordWriter are written to it as encoded.
+	Fallback io.Writer
+}
+
+// retry calls write until it succeeds or the retries of the policy are used
+// up, and returns the last error.
+func (s *sink) retry(write func() error) error {
+	err := write()
+	for i := 0; err != nil && i < s.writeErrors.Retries; i++ {
+		if s.writeErrors.RetryInterval > 0 {
+			time.Sleep(s.writeErrors.RetryInterval)
+		}
+		err = write()
+	}
+	return err
+}
+
+// writeFailed hands p, which could not be written because of err, to the
+// fallback and reports err to the internal logger and Config.OnWriteError.
+func (s *sink) writeFailed(p []byte, err error) {
+	err = fmt.Errorf("logger: write to %T: %w", s.output, err)
+	fallback := s.writeErrors.Fallback
+	if fallback != nil {
+		_, _ = fallback.Write(p)
+	}
+	s.internal.report(ErrorLevel, "write failed", Err(err), Bool("fallback", fallback != nil))
+	if s.onWriteError != nil {
+		s.onWriteError(err)
+	}
+}
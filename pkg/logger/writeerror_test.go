@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+// flakyWriter fails the first failures writes and writes at most limit
+// bytes per call.
+type flakyWriter struct {
+	bytes.Buffer
+	failures int
+	limit    int
+	calls    int
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.calls++
+	if w.failures > 0 {
+		w.failures--
+		return 0, errors.New("broken pipe")
+	}
+	if w.limit > 0 && len(p) > w.limit {
+		n, _ := w.Buffer.Write(p[:w.limit])
+		return n, errors.New("short write")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestWriteErrors_DropByDefault(t *testing.T) {
+	var errs []error
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          failingWriter{},
+		TimestampFormat: TimestampDisabled,
+		OnWriteError:    func(err error) { errs = append(errs, err) },
+	})
+
+	log.Info("lost")
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "logger: write to logger.failingWriter: disk full")
+}
+
+func TestWriteErrors_Retry(t *testing.T) {
+	var counters logmetrics.Counters
+	out := &flakyWriter{failures: 2}
+	var errs []error
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          out,
+		TimestampFormat: TimestampDisabled,
+		Metrics:         &counters,
+		WriteErrors:     WriteErrorPolicy{Retries: 2, RetryInterval: time.Millisecond},
+		OnWriteError:    func(err error) { errs = append(errs, err) },
+	})
+
+	log.Info("delivered")
+	assert.Equal(t, "INFO delivered\n", out.String())
+	assert.Equal(t, 3, out.calls)
+	assert.Empty(t, errs)
+	assert.Equal(t, uint64(2), counters.Snapshot().WriteErrors)
+}
+
+func TestWriteErrors_RetryShortWrite(t *testing.T) {
+	out := &flakyWriter{limit: 9}
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          out,
+		TimestampFormat: TimestampDisabled,
+		WriteErrors:     WriteErrorPolicy{Retries: 1},
+	})
+
+	log.Info("hello")
+	// The retry writes only the rest of the entry.
+	assert.Equal(t, "INFO hello\n", out.String())
+	assert.Equal(t, 2, out.calls)
+}
+
+func TestWriteErrors_Fallback(t *testing.T) {
+	fallback := &bytes.Buffer{}
+	var errs []error
+	log := New(Config{
+		Level:           InfoLevel,
+		Output:          failingWriter{},
+		TimestampFormat: TimestampDisabled,
+		WriteErrors:     WriteErrorPolicy{Retries: 1, Fallback: fallback},
+		OnWriteError:    func(err error) { errs = append(errs, err) },
+	})
+
+	log.Info("rescued")
+	assert.Equal(t, "INFO rescued\n", fallback.String())
+	assert.Len(t, errs, 1)
+}
+
+type failingRecordWriter struct {
+	failures int
+	records  []string
+}
+
+func (w *failingRecordWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w *failingRecordWriter) WriteRecord(r *Record, _ []byte) error {
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("unavailable")
+	}
+	w.records = append(w.records, r.Message)
+	return nil
+}
+
+func TestWriteErrors_RecordWriter(t *testing.T) {
+	out := &failingRecordWriter{failures: 1}
+	log := New(Config{Level: InfoLevel, Output: out, WriteErrors: WriteErrorPolicy{Retries: 1}})
+
+	log.Info("retried")
+	assert.Equal(t, []string{"retried"}, out.records)
+
+	fallback := &bytes.Buffer{}
+	out = &failingRecordWriter{failures: 1}
+	log = New(Config{
+		Level:           InfoLevel,
+		Output:          out,
+		TimestampFormat: TimestampDisabled,
+		WriteErrors:     WriteErrorPolicy{Fallback: fallback},
+	})
+
+	log.Info("fell back")
+	assert.Empty(t, out.records)
+	assert.Equal(t, "INFO fell back\n", fallback.String())
+}
+
+func TestWithWriteErrors(t *testing.T) {
+	var errs []error
+	log, err := NewWithOptions(
+		WithOutput(failingWriter{}),
+		WithWriteErrors(WriteErrorPolicy{Fallback: &bytes.Buffer{}}),
+		WithOnWriteError(func(err error) { errs = append(errs, err) }),
+	)
+	require.NoError(t, err)
+
+	log.Info("lost")
+	assert.Len(t, errs, 1)
+}
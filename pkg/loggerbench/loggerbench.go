@@ -0,0 +1,173 @@
+// Package loggerbench guards the cost of logging in application tests. It
+// runs micro-benchmarks of a logger configuration under go test and fails
+// the test when they exceed a declared budget, so a dependency upgrade or
+// configuration change that makes logging slower is caught in CI:
+//
+//	func TestLoggingBudget(t *testing.T) {
+//		loggerbench.AssertBudget(t, appLoggerConfig(), loggerbench.Budget{
+//			NsPerOp:     time.Microsecond,
+//			AllocsPerOp: 2,
+//			BytesPerOp:  loggerbench.Unlimited,
+//		})
+//	}
+//
+// Timings depend on the machine and on what runs next to the test, so
+// leave headroom in NsPerOp; allocation counts are stable.
+package loggerbench
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+// Unlimited disables the check of a Budget.AllocsPerOp or
+// Budget.BytesPerOp.
+const Unlimited = -1
+
+// Budget is the cost a single log call may have.
+type Budget struct {
+	// NsPerOp is the maximum time per call. Zero disables the check.
+	NsPerOp time.Duration
+
+	// AllocsPerOp is the maximum number of allocations per call. Zero
+	// requires allocation-free logging; Unlimited disables the check.
+	AllocsPerOp int64
+
+	// BytesPerOp is the maximum number of bytes allocated per call. Zero
+	// requires allocation-free logging; Unlimited disables the check.
+	BytesPerOp int64
+}
+
+// Scenario names reported by AssertBudget.
+const (
+	ScenarioMessage  = "message"
+	ScenarioFields   = "fields"
+	ScenarioContext  = "context"
+	ScenarioDisabled = "disabled"
+)
+
+// Result is the measured cost of one scenario.
+type Result struct {
+	Scenario    string
+	NsPerOp     time.Duration
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+// String formats r like go test -benchmem.
+func (r Result) String() string {
+	return fmt.Sprintf("%s: %d ns/op, %d B/op, %d allocs/op", r.Scenario, r.NsPerOp.Nanoseconds(), r.BytesPerOp, r.AllocsPerOp)
+}
+
+// AssertBudget benchmarks a logger created from config and reports an
+// error on t for every scenario exceeding budget:
+//
+//   - message: an entry without fields;
+//   - fields: an entry with five fields of common types;
+//   - context: an entry from a logger derived with With;
+//   - disabled: an entry below config.Level, when there is such a level.
+//
+// Entries are logged at config.Level, or at InfoLevel when config.Level is
+// lower. The outputs of config are replaced with
+// io.Discard, so the budget covers the logger and not the I/O. Each
+// scenario runs for the duration set by -test.benchtime.
+//
+// Allocation counts are not meaningful under the race detector, so the
+// test is skipped when it is enabled. AssertBudget returns the measured
+// results for further checks.
+func AssertBudget(t testing.TB, config logger.Config, budget Budget) []Result {
+	t.Helper()
+	if raceEnabled {
+		t.Skip("loggerbench: budgets are not checked under the race detector")
+	}
+
+	results := Measure(config)
+	for _, r := range results {
+		if budget.NsPerOp > 0 && r.NsPerOp > budget.NsPerOp {
+			t.Errorf("loggerbench: %s exceeds the budget of %d ns/op", r, budget.NsPerOp.Nanoseconds())
+		}
+		if budget.AllocsPerOp != Unlimited && r.AllocsPerOp > budget.AllocsPerOp {
+			t.Errorf("loggerbench: %s exceeds the budget of %d allocs/op", r, budget.AllocsPerOp)
+		}
+		if budget.BytesPerOp != Unlimited && r.BytesPerOp > budget.BytesPerOp {
+			t.Errorf("loggerbench: %s exceeds the budget of %d B/op", r, budget.BytesPerOp)
+		}
+	}
+	return results
+}
+
+// Measure runs the scenarios of AssertBudget and returns their results
+// without checking them.
+func Measure(config logger.Config) []Result {
+	config = discardOutputs(config)
+	log := logger.New(config)
+	defer func() { _ = log.Close() }()
+
+	level := max(config.Level, logger.InfoLevel)
+	logAt := func(msg string, fields ...logger.Field) { log.Log(level, msg, fields...) }
+	fields := []logger.Field{
+		logger.String("user", "ada"),
+		logger.Int("attempt", 3),
+		logger.Float64("ratio", 0.25),
+		logger.Bool("cached", true),
+		logger.F("elapsed", 1500*time.Millisecond),
+	}
+	child := log.With(logger.String("service", "billing"), logger.Int64("shard", 7))
+	logChild := func(msg string, fields ...logger.Field) { child.Log(level, msg, fields...) }
+
+	scenarios := []struct {
+		name string
+		run  func()
+	}{
+		{ScenarioMessage, func() { logAt("request served") }},
+		{ScenarioFields, func() { logAt("request served", fields...) }},
+		{ScenarioContext, func() { logChild("request served", fields[0]) }},
+	}
+	if config.Level > logger.DebugLevel {
+		scenarios = append(scenarios, struct {
+			name string
+			run  func()
+		}{ScenarioDisabled, func() { log.Debug("request served", fields...) }})
+	}
+
+	results := make([]Result, 0, len(scenarios))
+	for _, s := range scenarios {
+		br := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				s.run()
+			}
+		})
+		results = append(results, Result{
+			Scenario:    s.name,
+			NsPerOp:     time.Duration(br.NsPerOp()),
+			AllocsPerOp: br.AllocsPerOp(),
+			BytesPerOp:  br.AllocedBytesPerOp(),
+		})
+	}
+	return results
+}
+
+// discardOutputs replaces the writers of config with io.Discard without
+// modifying the caller's Outputs.
+func discardOutputs(config logger.Config) logger.Config {
+	if config.Output != nil || len(config.Outputs) == 0 {
+		config.Output = io.Discard
+	}
+	if config.ErrorOutput != nil {
+		config.ErrorOutput = io.Discard
+	}
+	if len(config.Outputs) > 0 {
+		outputs := make([]logger.SinkConfig, len(config.Outputs))
+		for i, out := range config.Outputs {
+			out.Output = io.Discard
+			outputs[i] = out
+		}
+		config.Outputs = outputs
+	}
+	return config
+}
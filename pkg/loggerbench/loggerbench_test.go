@@ -0,0 +1,83 @@
+package loggerbench
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	// Keep the benchmarks run by the tests short.
+	if err := flag.Set("test.benchtime", "2000x"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// recordingT captures the failures reported by AssertBudget.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertBudget(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not meaningful under the race detector")
+	}
+
+	out := &bytes.Buffer{}
+	config := logger.Config{Level: logger.InfoLevel, Format: logger.JSONFormat, Output: out}
+
+	results := AssertBudget(t, config, Budget{NsPerOp: time.Second, AllocsPerOp: 16, BytesPerOp: Unlimited})
+	require.Len(t, results, 4)
+	assert.Equal(t, []string{ScenarioMessage, ScenarioFields, ScenarioContext, ScenarioDisabled},
+		[]string{results[0].Scenario, results[1].Scenario, results[2].Scenario, results[3].Scenario})
+	assert.Zero(t, out.Len(), "outputs are discarded")
+	for _, r := range results {
+		assert.Positive(t, r.NsPerOp, r.Scenario)
+	}
+
+	rt := &recordingT{TB: t}
+	AssertBudget(rt, config, Budget{NsPerOp: time.Nanosecond, AllocsPerOp: Unlimited, BytesPerOp: Unlimited})
+	require.GreaterOrEqual(t, len(rt.errors), 3)
+	assert.Contains(t, rt.errors[0], "message: ")
+	assert.Contains(t, rt.errors[0], "exceeds the budget of 1 ns/op")
+	assert.Contains(t, rt.errors[1], "fields: ")
+	assert.Contains(t, rt.errors[2], "context: ")
+}
+
+func TestMeasure_Outputs(t *testing.T) {
+	out := &bytes.Buffer{}
+	extra := &bytes.Buffer{}
+	outputs := []logger.SinkConfig{{Output: extra, Level: logger.WarnLevel}}
+
+	results := Measure(logger.Config{Level: logger.DebugLevel, Output: out, ErrorOutput: out, Outputs: outputs})
+	assert.Len(t, results, 3, "no level is disabled")
+	assert.Zero(t, out.Len()+extra.Len())
+	assert.Equal(t, extra, outputs[0].Output, "the caller's outputs are not modified")
+}
+
+func TestMeasure_LogsAtConfigLevel(t *testing.T) {
+	var counters logmetrics.Counters
+	Measure(logger.Config{Level: logger.FatalLevel, Output: &bytes.Buffer{}, Metrics: &counters})
+
+	emitted := counters.Snapshot().Emitted
+	assert.Positive(t, emitted["FATAL"], "entries are logged at the configured level without exiting")
+	assert.Zero(t, emitted["ERROR"])
+}
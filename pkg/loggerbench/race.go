@@ -0,0 +1,5 @@
+//go:build race
+
+package loggerbench
+
+const raceEnabled = true
@@ -0,0 +1,22 @@
+// Package expvarmetrics publishes the counts of logmetrics.Counters with
+// expvar. It is separate from logmetrics because importing expvar
+// registers the /debug/vars handler on http.DefaultServeMux, which the
+// logger must not do on behalf of every program that imports it:
+//
+//	var counters logmetrics.Counters
+//	expvarmetrics.Publish("logger", &counters)
+//	log := logger.New(logger.Config{Output: os.Stdout, Metrics: &counters})
+package expvarmetrics
+
+import (
+	"expvar"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+// Publish exports the counts of c as the expvar variable name, rendered as
+// the JSON encoding of logmetrics.Snapshot. Like expvar.Publish, it panics
+// if name is already registered.
+func Publish(name string, c *logmetrics.Counters) {
+	expvar.Publish(name, expvar.Func(func() any { return c.Snapshot() }))
+}
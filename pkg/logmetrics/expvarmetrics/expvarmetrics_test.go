@@ -0,0 +1,22 @@
+package expvarmetrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logmetrics"
+)
+
+func TestPublish(t *testing.T) {
+	var c logmetrics.Counters
+	c.Dropped(logmetrics.DropRateLimited)
+	Publish("expvarmetrics_test", &c)
+
+	var s logmetrics.Snapshot
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("expvarmetrics_test").String()), &s))
+	assert.Equal(t, map[string]uint64{logmetrics.DropRateLimited: 1}, s.Dropped)
+}
@@ -0,0 +1,153 @@
+// Package logmetrics defines the Collector through which a logger reports
+// what it emits and drops, so applications can export these counts to
+// Prometheus, expvar or any other system without the logger importing it:
+//
+//	log := logger.New(logger.Config{
+//		Output:  os.Stdout,
+//		Metrics: promCollector{},
+//	})
+//
+//	type promCollector struct{}
+//
+//	func (promCollector) Emitted(level string)  { emitted.WithLabelValues(level).Inc() }
+//	func (promCollector) BytesWritten(n int)    { written.Add(float64(n)) }
+//	func (promCollector) Flushed()              { flushes.Inc() }
+//	func (promCollector) WriteError()           { writeErrors.Inc() }
+//	func (promCollector) Dropped(reason string) { dropped.WithLabelValues(reason).Inc() }
+//
+// Counters is a ready-made Collector keeping the counts in memory, which
+// the expvarmetrics subpackage publishes with expvar.
+package logmetrics
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Reasons passed to Collector.Dropped.
+const (
+	// DropSampled counts entries dropped by sampling.
+	DropSampled = "sampled"
+
+	// DropRateLimited counts entries over a rate limit.
+	DropRateLimited = "rate_limited"
+
+	// DropAsyncQueue counts entries shed from a saturated async queue.
+	DropAsyncQueue = "async_queue"
+
+	// DropMemory counts entries, or entry writes to a sink, that did not
+	// fit into the memory budget.
+	DropMemory = "memory"
+
+	// DropFiltered counts entries dropped by a filter.
+	DropFiltered = "filtered"
+
+	// DropRepeated counts entries dropped as repeats of the previous one.
+	DropRepeated = "repeated"
+
+	// DropOversized counts entry writes to a sink whose encoding exceeded
+	// the maximum record size.
+	DropOversized = "oversized"
+)
+
+// Collector receives the events of a logger. Its methods are called on
+// the logging path, sometimes with locks held, so they must be fast, safe
+// for concurrent use, and must not log through the same logger.
+type Collector interface {
+	// Emitted is called once per entry that passed filtering and is
+	// written to the sinks, with the name of its level, e.g. "INFO".
+	Emitted(level string)
+
+	// BytesWritten is called with the number of bytes written to an
+	// output, once per write.
+	BytesWritten(n int)
+
+	// Flushed is called when a buffered sink writes its buffer.
+	Flushed()
+
+	// WriteError is called when writing to an output fails.
+	WriteError()
+
+	// Dropped is called for an entry dropped before it was written, with
+	// one of the Drop reasons.
+	Dropped(reason string)
+}
+
+// Counters is a Collector counting events in memory. The zero value is
+// ready to use.
+type Counters struct {
+	bytes       atomic.Uint64
+	flushes     atomic.Uint64
+	writeErrors atomic.Uint64
+
+	mu      sync.Mutex
+	emitted map[string]uint64
+	dropped map[string]uint64
+}
+
+var _ Collector = (*Counters)(nil)
+
+// Snapshot is a copy of the counts of Counters.
+type Snapshot struct {
+	Emitted      map[string]uint64 `json:"emitted"`
+	BytesWritten uint64            `json:"bytes_written"`
+	Flushes      uint64            `json:"flushes"`
+	WriteErrors  uint64            `json:"write_errors"`
+	Dropped      map[string]uint64 `json:"dropped"`
+}
+
+// Emitted implements Collector.
+func (c *Counters) Emitted(level string) {
+	c.mu.Lock()
+	if c.emitted == nil {
+		c.emitted = make(map[string]uint64)
+	}
+	c.emitted[level]++
+	c.mu.Unlock()
+}
+
+// BytesWritten implements Collector.
+func (c *Counters) BytesWritten(n int) {
+	c.bytes.Add(uint64(n))
+}
+
+// Flushed implements Collector.
+func (c *Counters) Flushed() {
+	c.flushes.Add(1)
+}
+
+// WriteError implements Collector.
+func (c *Counters) WriteError() {
+	c.writeErrors.Add(1)
+}
+
+// Dropped implements Collector.
+func (c *Counters) Dropped(reason string) {
+	c.mu.Lock()
+	if c.dropped == nil {
+		c.dropped = make(map[string]uint64)
+	}
+	c.dropped[reason]++
+	c.mu.Unlock()
+}
+
+// Snapshot returns the current counts.
+func (c *Counters) Snapshot() Snapshot {
+	s := Snapshot{
+		BytesWritten: c.bytes.Load(),
+		Flushes:      c.flushes.Load(),
+		WriteErrors:  c.writeErrors.Load(),
+		Emitted:      make(map[string]uint64),
+		Dropped:      make(map[string]uint64),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for level, n := range c.emitted {
+		s.Emitted[level] = n
+	}
+	for reason, n := range c.dropped {
+		s.Dropped[reason] = n
+	}
+	return s
+}
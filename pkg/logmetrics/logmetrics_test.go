@@ -0,0 +1,27 @@
+package logmetrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounters(t *testing.T) {
+	var c Counters
+	c.Emitted("INFO")
+	c.Emitted("INFO")
+	c.Emitted("ERROR")
+	c.BytesWritten(10)
+	c.BytesWritten(5)
+	c.Flushed()
+	c.WriteError()
+	c.Dropped(DropSampled)
+
+	assert.Equal(t, Snapshot{
+		Emitted:      map[string]uint64{"INFO": 2, "ERROR": 1},
+		BytesWritten: 15,
+		Flushes:      1,
+		WriteErrors:  1,
+		Dropped:      map[string]uint64{DropSampled: 1},
+	}, c.Snapshot())
+}
@@ -0,0 +1,18 @@
+module github.com/barnowlsnest/go-logslib/pkg/logotel
+
+go 1.25.0
+
+require (
+	github.com/barnowlsnest/go-logslib v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.11.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel v1.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/barnowlsnest/go-logslib => ../..
@@ -0,0 +1,49 @@
+// Package logotel adds the active OpenTelemetry span of a context to log
+// entries. Importing it registers a logger.ContextExtractor, so every entry
+// logged through a logger.ContextLogger whose context carries a valid span
+// gets its trace_id, span_id and trace_flags in W3C Trace Context form:
+//
+//	import _ "github.com/barnowlsnest/go-logslib/pkg/logotel"
+//
+//	func handle(ctx context.Context) {
+//		log.WithStaticContext(ctx).Info("handled")
+//		// trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 trace_flags=01
+//	}
+//
+// logotel is a module of its own, so the OpenTelemetry API is only a
+// dependency of programs that import this package; the logger module
+// itself does not require it.
+package logotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+// Field keys written for the active span.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+func init() {
+	logger.RegisterContextExtractor(Extract)
+}
+
+// Extract passes the trace ID, span ID and trace flags of the span in ctx
+// to appendField as lowercase hex strings, as they appear in a W3C
+// traceparent header. Nothing is appended when ctx has no valid span.
+func Extract(ctx context.Context, appendField func(logger.Field)) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	appendField(logger.Field{Key: TraceIDKey, Value: sc.TraceID().String()})
+	appendField(logger.Field{Key: SpanIDKey, Value: sc.SpanID().String()})
+	appendField(logger.Field{Key: TraceFlagsKey, Value: sc.TraceFlags().String()})
+}
@@ -0,0 +1,66 @@
+package logotel
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+func spanContext(t *testing.T) context.Context {
+	t.Helper()
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+func TestExtract(t *testing.T) {
+	var fields []logger.Field
+	Extract(spanContext(t), func(f logger.Field) { fields = append(fields, f) })
+
+	assert.Equal(t, []logger.Field{
+		{Key: "trace_id", Value: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{Key: "span_id", Value: "00f067aa0ba902b7"},
+		{Key: "trace_flags", Value: "01"},
+	}, fields)
+}
+
+func TestExtract_NoSpan(t *testing.T) {
+	Extract(context.Background(), func(logger.Field) {
+		t.Fatal("no field expected without a span")
+	})
+}
+
+func TestContextLogger_AddsSpan(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := logger.New(logger.Config{Level: logger.InfoLevel, Format: logger.JSONFormat, Output: buf})
+
+	l.WithStaticContext(spanContext(t)).Info("handled", logger.F("status", 200))
+
+	assert.Contains(t, buf.String(),
+		`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01","status":200`)
+}
+
+func TestContextLogger_DatadogFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := logger.New(logger.Config{Level: logger.InfoLevel, Format: logger.DatadogFormat, Output: buf})
+
+	l.WithStaticContext(spanContext(t)).Info("handled")
+
+	// Datadog keeps the lower 64 bits of the trace ID.
+	assert.Contains(t, buf.String(), `"dd.trace_id":"11803532876627986230","dd.span_id":"67667974448284343"`)
+}
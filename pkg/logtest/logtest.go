@@ -0,0 +1,278 @@
+// Package logtest records log entries in memory so applications can test
+// their logging with assertions on levels, messages and field values
+// instead of matching raw output:
+//
+//	func TestCharge(t *testing.T) {
+//		log, obs := logtest.NewObserver(logger.DebugLevel)
+//		charge(log, 42)
+//
+//		obs.AssertLogged(t, logger.InfoLevel, "charged", logger.Int("amount", 42))
+//		if n := obs.Records().FilterLevel(logger.ErrorLevel).Len(); n != 0 {
+//			t.Errorf("got %d errors", n)
+//		}
+//	}
+//
+// An Observer can also be added to an existing logger as a sink, e.g. with
+// Logger.AddSink, to record what a configured logger writes.
+package logtest
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+// blockType is the type of the values created by logger.Block, which are
+// recorded as plain strings.
+var blockType = reflect.TypeOf(logger.Block("", "").Value)
+
+// groupType is the type of the values created by logger.Group and
+// Logger.WithGroup, which are recorded as map[string]any.
+var groupType = reflect.TypeOf(logger.Group("").Value)
+
+// Record is an entry recorded by an Observer.
+type Record struct {
+	Time    time.Time
+	Level   logger.Level
+	Message string
+
+	// Fields holds the values of the entry fields by key, as they were
+	// written after hooks, redaction and key remapping, including the
+	// fields of With, Named and the context. Values keep the type given to
+	// the field constructor, e.g. int for logger.Int. Groups are recorded as
+	// map[string]any holding the values of their fields.
+	Fields map[string]any
+
+	// Raw holds the line of an entry passed to Logger.WriteRaw, which has
+	// no level, message or fields of its own.
+	Raw string
+}
+
+// HasField reports whether the record has a field with key and value.
+func (r Record) HasField(key string, value any) bool {
+	v, ok := r.Fields[key]
+	return ok && reflect.DeepEqual(v, value)
+}
+
+// Observer records the entries written to it. It implements
+// logger.RecordWriter and is safe for concurrent use.
+type Observer struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+var _ logger.RecordWriter = (*Observer)(nil)
+
+// NewObserver returns a logger writing entries at level and above to a new
+// Observer, and the observer.
+func NewObserver(level logger.Level) (*logger.Logger, *Observer) {
+	obs := &Observer{}
+	return logger.New(logger.Config{Level: level, Output: obs}), obs
+}
+
+// WriteRecord records r.
+func (o *Observer) WriteRecord(r *logger.Record, _ []byte) error {
+	rec := Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Fields:  recordFields(r.Fields),
+	}
+
+	o.mu.Lock()
+	o.records = append(o.records, rec)
+	o.mu.Unlock()
+	return nil
+}
+
+// recordFields returns the values of fields by key.
+func recordFields(fields []logger.Field) map[string]any {
+	m := make(map[string]any, len(fields))
+	for _, f := range fields {
+		v := f.Value
+		if v != nil {
+			switch reflect.TypeOf(v) {
+			case blockType:
+				v = reflect.ValueOf(v).String()
+			case groupType:
+				v = recordFields(reflect.ValueOf(v).Convert(reflect.TypeOf([]logger.Field(nil))).Interface().([]logger.Field))
+			}
+		}
+		m[f.Key] = v
+	}
+	return m
+}
+
+// Write records a line passed to Logger.WriteRaw.
+func (o *Observer) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	o.records = append(o.records, Record{Raw: strings.TrimSuffix(string(p), "\n")})
+	o.mu.Unlock()
+	return len(p), nil
+}
+
+// Records returns a copy of the recorded entries, oldest first.
+func (o *Observer) Records() Records {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append(Records(nil), o.records...)
+}
+
+// Len returns the number of recorded entries.
+func (o *Observer) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.records)
+}
+
+// TakeAll returns the recorded entries and forgets them.
+func (o *Observer) TakeAll() Records {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	records := o.records
+	o.records = nil
+	return records
+}
+
+// AssertLogged reports a test error unless an entry at level with msg and
+// all of fields was recorded. It returns whether one was.
+func (o *Observer) AssertLogged(t testing.TB, level logger.Level, msg string, fields ...logger.Field) bool {
+	t.Helper()
+	if o.Records().match(level, msg, fields).Len() > 0 {
+		return true
+	}
+	t.Errorf("logtest: no %s entry %q with %s; recorded:\n%s", level, msg, formatFields(fields), o.Records())
+	return false
+}
+
+// AssertNotLogged reports a test error if an entry at level with msg was
+// recorded. It returns whether none was.
+func (o *Observer) AssertNotLogged(t testing.TB, level logger.Level, msg string) bool {
+	t.Helper()
+	if matched := o.Records().match(level, msg, nil); matched.Len() > 0 {
+		t.Errorf("logtest: unexpected %s entry %q:\n%s", level, msg, matched)
+		return false
+	}
+	return true
+}
+
+// Records is a list of recorded entries with filters for assertions.
+// Filters return new lists and never modify the receiver.
+type Records []Record
+
+// Len returns the number of entries.
+func (rs Records) Len() int {
+	return len(rs)
+}
+
+// Messages returns the messages of the entries.
+func (rs Records) Messages() []string {
+	msgs := make([]string, len(rs))
+	for i, r := range rs {
+		msgs[i] = r.Message
+	}
+	return msgs
+}
+
+// Filter returns the entries for which keep returns true.
+func (rs Records) Filter(keep func(Record) bool) Records {
+	var out Records
+	for _, r := range rs {
+		if keep(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// FilterLevel returns the entries at level.
+func (rs Records) FilterLevel(level logger.Level) Records {
+	return rs.Filter(func(r Record) bool { return r.Raw == "" && r.Level == level })
+}
+
+// FilterMinLevel returns the entries at level and above.
+func (rs Records) FilterMinLevel(level logger.Level) Records {
+	return rs.Filter(func(r Record) bool { return r.Raw == "" && r.Level >= level })
+}
+
+// FilterMessage returns the entries with the message msg.
+func (rs Records) FilterMessage(msg string) Records {
+	return rs.Filter(func(r Record) bool { return r.Message == msg })
+}
+
+// FilterMessageSnippet returns the entries whose message contains snippet.
+func (rs Records) FilterMessageSnippet(snippet string) Records {
+	return rs.Filter(func(r Record) bool { return strings.Contains(r.Message, snippet) })
+}
+
+// FilterField returns the entries with a field equal to f, compared with
+// reflect.DeepEqual.
+func (rs Records) FilterField(f logger.Field) Records {
+	return rs.Filter(func(r Record) bool { return r.HasField(f.Key, f.Value) })
+}
+
+// FilterFieldKey returns the entries with a field named key.
+func (rs Records) FilterFieldKey(key string) Records {
+	return rs.Filter(func(r Record) bool {
+		_, ok := r.Fields[key]
+		return ok
+	})
+}
+
+// match returns the entries at level with msg and all of fields.
+func (rs Records) match(level logger.Level, msg string, fields []logger.Field) Records {
+	return rs.Filter(func(r Record) bool {
+		if r.Raw != "" || r.Level != level || r.Message != msg {
+			return false
+		}
+		for _, f := range fields {
+			if !r.HasField(f.Key, f.Value) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// String renders the entries one per line, for failure messages.
+func (rs Records) String() string {
+	if len(rs) == 0 {
+		return "  (none)\n"
+	}
+	var b strings.Builder
+	for _, r := range rs {
+		if r.Raw != "" {
+			fmt.Fprintf(&b, "  raw %s\n", r.Raw)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s %q", r.Level, r.Message)
+		keys := make([]string, 0, len(r.Fields))
+		for key := range r.Fields {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, " %s=%v", key, r.Fields[key])
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// formatFields renders fields for failure messages.
+func formatFields(fields []logger.Field) string {
+	if len(fields) == 0 {
+		return "any fields"
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = fmt.Sprintf("%s=%v", f.Key, f.Value)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,86 @@
+package logtest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+// recordingTB captures the errors reported by assertions.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestObserver(t *testing.T) {
+	log, obs := NewObserver(logger.InfoLevel)
+	db := log.Named("db").With(logger.String("table", "orders"))
+
+	log.Debug("dropped")
+	db.Info("query done", logger.Int("rows", 3))
+	db.Error("query failed", logger.Err(errors.New("timeout")), logger.Block("sql", "SELECT 1"))
+	require.NoError(t, log.WriteRaw(logger.WarnLevel, []byte("upstream line\n")))
+
+	records := obs.Records()
+	require.Equal(t, 3, obs.Len())
+	assert.Equal(t, []string{"query done", "query failed", ""}, records.Messages())
+	assert.Equal(t, map[string]any{"logger": "db", "table": "orders", "rows": 3}, records[0].Fields)
+	assert.Equal(t, "SELECT 1", records[1].Fields["sql"])
+	assert.Equal(t, "upstream line", records[2].Raw)
+
+	assert.Equal(t, 1, records.FilterLevel(logger.ErrorLevel).Len())
+	assert.Equal(t, 2, records.FilterMinLevel(logger.InfoLevel).Len())
+	assert.Equal(t, 1, records.FilterMessage("query done").Len())
+	assert.Equal(t, 2, records.FilterMessageSnippet("query").Len())
+	assert.Equal(t, 1, records.FilterField(logger.String("error", "timeout")).Len())
+	assert.Equal(t, 2, records.FilterFieldKey("table").Len())
+	assert.True(t, records[0].HasField("rows", 3))
+	assert.False(t, records[0].HasField("rows", int64(3)))
+
+	assert.True(t, obs.AssertLogged(t, logger.InfoLevel, "query done", logger.Int("rows", 3)))
+	assert.True(t, obs.AssertNotLogged(t, logger.DebugLevel, "dropped"))
+
+	assert.Len(t, obs.TakeAll(), 3)
+	assert.Zero(t, obs.Len())
+}
+
+func TestObserver_AssertionFailures(t *testing.T) {
+	log, obs := NewObserver(logger.DebugLevel)
+	log.Info("started", logger.Int("port", 80))
+
+	tb := &recordingTB{TB: t}
+	assert.False(t, obs.AssertLogged(tb, logger.InfoLevel, "started", logger.Int("port", 81)))
+	assert.False(t, obs.AssertNotLogged(tb, logger.InfoLevel, "started"))
+	require.Len(t, tb.errors, 2)
+	assert.Equal(t, "logtest: no INFO entry \"started\" with port=81; recorded:\n  INFO \"started\" port=80\n", tb.errors[0])
+	assert.Contains(t, tb.errors[1], `unexpected INFO entry "started"`)
+}
+
+func TestObserver_AsSink(t *testing.T) {
+	obs := &Observer{}
+	log := logger.New(logger.Config{Level: logger.InfoLevel, Outputs: []logger.SinkConfig{{Output: obs, Level: logger.WarnLevel}}})
+
+	log.Info("ignored")
+	log.Warn("kept")
+	assert.Equal(t, []string{"kept"}, obs.Records().Messages())
+}
+
+func TestObserver_Groups(t *testing.T) {
+	log, obs := NewObserver(logger.InfoLevel)
+
+	log.WithGroup("http").Info("served", logger.String("method", "GET"), logger.Group("resp", logger.Int("status", 200)))
+
+	want := map[string]any{"method": "GET", "resp": map[string]any{"status": 200}}
+	obs.AssertLogged(t, logger.InfoLevel, "served", logger.F("http", want))
+}
@@ -0,0 +1,20 @@
+// Command logslib-vet reports misuse of the go-logslib logger API, such as
+// duplicate or non-constant field keys, values encoded as "unknown", code
+// after Fatal and buffered loggers that are never closed. See package
+// logvet for the checks.
+//
+// Usage:
+//
+//	logslib-vet ./...
+//	go vet -vettool=$(which logslib-vet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logvet"
+)
+
+func main() {
+	singlechecker.Main(logvet.Analyzer)
+}
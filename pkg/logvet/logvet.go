@@ -0,0 +1,440 @@
+// Package logvet provides an analyzer that reports common misuse of the
+// logger package at the call site:
+//
+//   - the same field key passed twice to one logging call or With;
+//   - field keys that are not constants, which defeat searching the logs;
+//   - field values of types the encoders cannot represent, which are
+//     written as "unknown";
+//   - statements after a call to Fatal, which never run because Fatal
+//     exits the program;
+//   - loggers created with buffering or async logging that are neither
+//     closed nor flushed, whose last entries are lost at exit.
+//
+// The analyzer can run standalone or through go vet, using the
+// logslib-vet command:
+//
+//	go install github.com/barnowlsnest/go-logslib/pkg/logvet/cmd/logslib-vet@latest
+//	go vet -vettool=$(which logslib-vet) ./...
+//
+// logvet is a module of its own, so that importing the logger does not
+// pull in golang.org/x/tools.
+package logvet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// loggerPath is the import path of the package whose API is checked.
+const loggerPath = "github.com/barnowlsnest/go-logslib/pkg/logger"
+
+// Analyzer reports misuse of the logger package.
+var Analyzer = &analysis.Analyzer{
+	Name:     "logslib",
+	Doc:      "report misuse of the go-logslib logger API",
+	URL:      "https://pkg.go.dev/github.com/barnowlsnest/go-logslib/pkg/logvet",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// loggingMethods are the methods of Logger and ContextLogger that take a
+// message followed by fields.
+var loggingMethods = map[string]bool{
+	"Debug": true,
+	"Info":  true,
+	"Warn":  true,
+	"Error": true,
+	"Fatal": true,
+	"Panic": true,
+}
+
+// fixedKeys are the field constructors that always use the same key.
+var fixedKeys = map[string]string{
+	"Err":            "error",
+	"SourceLocation": "sourceLocation",
+	"HTTPRequest":    "httpRequest",
+}
+
+// bufferedProfiles are the values of logger.Profile that enable buffering.
+var bufferedProfiles = map[string]bool{
+	"throughput": true,
+	"low-memory": true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodes := []ast.Node{(*ast.CallExpr)(nil), (*ast.BlockStmt)(nil), (*ast.FuncDecl)(nil)}
+	ins.Preorder(nodes, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			checkFields(pass, n)
+		case *ast.BlockStmt:
+			checkAfterFatal(pass, n.List)
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				checkClose(pass, n.Body)
+			}
+		}
+	})
+
+	return nil, nil
+}
+
+// loggerMethod returns the name of the Logger or ContextLogger method
+// called by call, or "".
+func loggerMethod(pass *analysis.Pass, call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != loggerPath {
+		return ""
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return ""
+	}
+	switch named := derefNamed(recv.Type()); {
+	case named == nil:
+		return ""
+	case named.Obj().Name() == "Logger", named.Obj().Name() == "ContextLogger":
+		return fn.Name()
+	}
+	return ""
+}
+
+// loggerFunc returns the logger package function called by call, or nil.
+func loggerFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	case *ast.IndexExpr: // F[T](...)
+		switch x := fun.X.(type) {
+		case *ast.Ident:
+			ident = x
+		case *ast.SelectorExpr:
+			ident = x.Sel
+		}
+	}
+	if ident == nil {
+		return nil
+	}
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != loggerPath {
+		return nil
+	}
+	if fn.Type().(*types.Signature).Recv() != nil {
+		return nil
+	}
+	return fn
+}
+
+// checkFields reports duplicate and non-constant keys and unsupported
+// values among the fields of a logging call or With.
+func checkFields(pass *analysis.Pass, call *ast.CallExpr) {
+	method := loggerMethod(pass, call)
+
+	var fields []ast.Expr
+	switch {
+	case loggingMethods[method] && len(call.Args) > 0:
+		fields = call.Args[1:]
+	case method == "With":
+		fields = call.Args
+	default:
+		return
+	}
+	if call.Ellipsis.IsValid() {
+		// Fields passed as a slice cannot be inspected.
+		return
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for _, arg := range fields {
+		key, keyExpr, ok := fieldKey(pass, arg)
+		if !ok {
+			continue
+		}
+		if keyExpr != nil {
+			tv := pass.TypesInfo.Types[keyExpr]
+			if tv.Value == nil {
+				pass.Reportf(keyExpr.Pos(), "field key is not a constant")
+				continue
+			}
+			key = constant.StringVal(tv.Value)
+		}
+		if seen[key] {
+			pass.Reportf(arg.Pos(), "duplicate field key %s in call to %s", strconv.Quote(key), method)
+		}
+		seen[key] = true
+	}
+}
+
+// fieldKey returns the key of a field expression: either a constant key
+// or the expression holding it. It also reports unsupported values. ok is
+// false when arg is not a recognized field constructor.
+func fieldKey(pass *analysis.Pass, arg ast.Expr) (key string, keyExpr ast.Expr, ok bool) {
+	switch arg := ast.Unparen(arg).(type) {
+	case *ast.CallExpr:
+		fn := loggerFunc(pass, arg)
+		if fn == nil {
+			return "", nil, false
+		}
+		if key, ok := fixedKeys[fn.Name()]; ok {
+			return key, nil, true
+		}
+		params := fn.Type().(*types.Signature).Params()
+		if params.Len() < 1 || params.At(0).Name() != "key" || len(arg.Args) < 1 {
+			return "", nil, false
+		}
+		if fn.Name() == "F" && len(arg.Args) == 2 {
+			checkFValue(pass, arg.Args[1])
+		}
+		return "", arg.Args[0], true
+
+	case *ast.CompositeLit:
+		if !isLoggerType(pass.TypesInfo.TypeOf(arg), "Field") {
+			return "", nil, false
+		}
+		var value ast.Expr
+		for i, elt := range arg.Elts {
+			if kv, isKV := elt.(*ast.KeyValueExpr); isKV {
+				switch kv.Key.(*ast.Ident).Name {
+				case "Key":
+					keyExpr = kv.Value
+				case "Value":
+					value = kv.Value
+				}
+				continue
+			}
+			switch i {
+			case 0:
+				keyExpr = elt
+			case 1:
+				value = elt
+			}
+		}
+		if value != nil {
+			checkFieldValue(pass, value)
+		}
+		if keyExpr == nil {
+			return "", nil, false
+		}
+		return "", keyExpr, true
+	}
+	return "", nil, false
+}
+
+// checkFieldValue reports values of a Field literal that the encoders do
+// not support. Field literals store the value as it is, so only the
+// natively encoded types are accepted.
+func checkFieldValue(pass *analysis.Pass, value ast.Expr) {
+	t := pass.TypesInfo.TypeOf(value)
+	if t == nil {
+		return
+	}
+	if basic, ok := t.(*types.Basic); ok {
+		switch basic.Kind() {
+		case types.String, types.Int, types.Int64, types.Float64, types.Bool,
+			types.UntypedString, types.UntypedInt, types.UntypedFloat, types.UntypedBool, types.UntypedNil:
+			return
+		}
+	}
+	if types.IsInterface(t) || isTime(t) {
+		return
+	}
+	if named := derefNamed(t); named != nil && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == loggerPath {
+		return
+	}
+	pass.Reportf(value.Pos(), "field value of type %s is encoded as \"unknown\"; use logger.F to convert it", t)
+}
+
+// checkFValue reports values passed to F that it cannot convert: values
+// that are neither of a basic kind nor time.Time, an error or a
+// fmt.Stringer.
+func checkFValue(pass *analysis.Pass, value ast.Expr) {
+	t := pass.TypesInfo.TypeOf(value)
+	if t == nil || types.IsInterface(t) || isTime(t) || hasMethod(t, "Error") || hasMethod(t, "String") {
+		return
+	}
+	if _, ok := t.Underlying().(*types.Basic); ok {
+		return
+	}
+	pass.Reportf(value.Pos(), "field value of type %s is encoded as \"unknown\"", t)
+}
+
+// checkAfterFatal reports the first statement following a call to Fatal.
+func checkAfterFatal(pass *analysis.Pass, stmts []ast.Stmt) {
+	for i, stmt := range stmts[:max(len(stmts)-1, 0)] {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok || loggerMethod(pass, call) != "Fatal" {
+			continue
+		}
+		next := stmts[i+1]
+		if _, empty := next.(*ast.EmptyStmt); empty {
+			continue
+		}
+		pass.Reportf(next.Pos(), "unreachable code: Fatal exits the program")
+		return
+	}
+}
+
+// checkClose reports loggers created in body with buffering or async
+// logging that are neither closed nor flushed and do not leave the
+// function.
+func checkClose(pass *analysis.Pass, body *ast.BlockStmt) {
+	created := make(map[types.Object]*ast.CallExpr)
+	ast.Inspect(body, func(n ast.Node) bool {
+		var lhs []ast.Expr
+		var rhs []ast.Expr
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			lhs, rhs = n.Lhs, n.Rhs
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				lhs = append(lhs, name)
+			}
+			rhs = n.Values
+		default:
+			return true
+		}
+		if len(rhs) != 1 || len(lhs) == 0 {
+			return true
+		}
+		call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr)
+		if !ok || !isBufferedNew(pass, call) {
+			return true
+		}
+		if ident, ok := lhs[0].(*ast.Ident); ok {
+			if obj := pass.TypesInfo.ObjectOf(ident); obj != nil && obj.Parent() != pass.Pkg.Scope() {
+				created[obj] = call
+			}
+		}
+		return true
+	})
+	if len(created) == 0 {
+		return
+	}
+
+	// A logger is handled when Close or Flush is called on it, or when it
+	// is used in any other way than as the receiver of a method call, since
+	// the code it is handed to may close it.
+	handled := make(map[types.Object]bool)
+	receivers := make(map[*ast.Ident]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj := pass.TypesInfo.Uses[ident]
+		if _, tracked := created[obj]; !tracked {
+			return true
+		}
+		receivers[ident] = true
+		if sel.Sel.Name == "Close" || sel.Sel.Name == "Flush" {
+			handled[obj] = true
+		}
+		return true
+	})
+	ast.Inspect(body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || receivers[ident] {
+			return true
+		}
+		if obj := pass.TypesInfo.Uses[ident]; obj != nil {
+			if _, tracked := created[obj]; tracked {
+				handled[obj] = true
+			}
+		}
+		return true
+	})
+
+	for obj, call := range created {
+		if !handled[obj] {
+			pass.Reportf(call.Pos(), "buffered logger %s is never closed; call Close or Flush before exiting or buffered entries are lost", obj.Name())
+		}
+	}
+}
+
+// isBufferedNew reports whether call is logger.New or logger.NewE with a
+// Config literal that enables buffering or async logging.
+func isBufferedNew(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn := loggerFunc(pass, call)
+	if fn == nil || (fn.Name() != "New" && fn.Name() != "NewE") || len(call.Args) != 1 {
+		return false
+	}
+	lit, ok := ast.Unparen(call.Args[0]).(*ast.CompositeLit)
+	if !ok {
+		return false
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		value := pass.TypesInfo.Types[kv.Value].Value
+		switch key.Name {
+		case "BufferSize", "AsyncQueueSize":
+			if value == nil || value.Kind() != constant.Int || constant.Sign(value) > 0 {
+				return true
+			}
+		case "Profile":
+			if value != nil && value.Kind() == constant.String && bufferedProfiles[constant.StringVal(value)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// derefNamed returns the named type of t or of the type t points to.
+func derefNamed(t types.Type) *types.Named {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, _ := t.(*types.Named)
+	return named
+}
+
+// isLoggerType reports whether t is the named type name of the logger
+// package.
+func isLoggerType(t types.Type, name string) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == loggerPath && named.Obj().Name() == name
+}
+
+// isTime reports whether t is time.Time.
+func isTime(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+// hasMethod reports whether t or *t has a method called name.
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
@@ -0,0 +1,79 @@
+package a
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/barnowlsnest/go-logslib/pkg/logger"
+)
+
+type point struct{ x, y int }
+
+type status int
+
+type name struct{}
+
+func (name) String() string { return "n" }
+
+const userKey = "user"
+
+func keys(log *logger.Logger, dynamic string) {
+	log.Info("ok", logger.String("a", "x"), logger.F(userKey, 1), logger.Field{Key: "b", Value: 2})
+	log.Info("dup", logger.String("a", "x"), logger.F("a", 1))        // want `duplicate field key "a" in call to Info`
+	log.With(logger.Err(nil), logger.Field{"error", "x"})             // want `duplicate field key "error" in call to With`
+	log.WithContext().Warn("dup", logger.F("k", 1), logger.F("k", 2)) // want `duplicate field key "k" in call to Warn`
+	log.Info("dynamic", logger.String(dynamic, "x"))                  // want `field key is not a constant`
+
+	fields := []logger.Field{logger.F("a", 1), logger.F("a", 2)}
+	log.Info("slice", fields...)
+}
+
+func values(log *logger.Logger, p point, err error, iface any) {
+	log.Info("ok",
+		logger.F("a", status(1)),
+		logger.F("b", err),
+		logger.F("c", name{}),
+		logger.F("d", time.Now()),
+		logger.F("e", iface),
+		logger.Field{Key: "f", Value: 1.5},
+		logger.Field{Key: "g", Value: time.Second.Seconds()},
+		logger.Field{Key: "h", Value: iface},
+	)
+	log.Info("struct", logger.F("p", p))                          // want `field value of type a.point is encoded as "unknown"`
+	log.Info("map", logger.F("m", map[string]int{}))              // want `field value of type map\[string\]int is encoded as "unknown"`
+	log.Info("literal", logger.Field{Key: "s", Value: status(1)}) // want `field value of type a.status is encoded as "unknown"; use logger.F to convert it`
+	log.Info("rune", logger.Field{Key: "r", Value: 'x'})          // want `field value of type rune is encoded as "unknown"; use logger.F to convert it`
+	_ = errors.New
+}
+
+func fatal(log *logger.Logger) {
+	if len(os.Args) > 1 {
+		log.Fatal("bad arguments")
+		os.Exit(2) // want `unreachable code: Fatal exits the program`
+	}
+	log.Fatal("done")
+}
+
+func unclosed() {
+	log := logger.New(logger.Config{BufferSize: 4096}) // want `buffered logger log is never closed; call Close or Flush before exiting or buffered entries are lost`
+	log.Info("lost")
+
+	async, _ := logger.NewE(logger.Config{Profile: logger.ProfileThroughput}) // want `buffered logger async is never closed`
+	async.Info("lost")
+
+	plain := logger.New(logger.Config{BufferSize: 0})
+	plain.Info("written")
+}
+
+func closed() *logger.Logger {
+	deferred := logger.New(logger.Config{AsyncQueueSize: 16})
+	defer deferred.Close()
+
+	flushed := logger.New(logger.Config{BufferSize: 4096})
+	flushed.Info("x")
+	flushed.Flush()
+
+	returned := logger.New(logger.Config{BufferSize: 4096})
+	return returned
+}
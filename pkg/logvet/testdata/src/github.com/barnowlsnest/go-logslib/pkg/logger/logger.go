@@ -0,0 +1,41 @@
+// Package logger is a stub of the real package with the API the analyzer
+// looks at.
+package logger
+
+import "time"
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+type Profile string
+
+const ProfileThroughput Profile = "throughput"
+
+type Config struct {
+	BufferSize     int
+	AsyncQueueSize int
+	Profile        Profile
+}
+
+type Logger struct{}
+
+func New(Config) *Logger                    { return &Logger{} }
+func NewE(Config) (*Logger, error)          { return &Logger{}, nil }
+func (*Logger) Info(string, ...Field)       {}
+func (*Logger) Fatal(string, ...Field)      {}
+func (*Logger) With(...Field) *Logger       { return nil }
+func (*Logger) Flush()                      {}
+func (*Logger) Close() error                { return nil }
+func (*Logger) WithContext() *ContextLogger { return nil }
+
+type ContextLogger struct{}
+
+func (*ContextLogger) Warn(string, ...Field) {}
+
+func String(key, value string) Field     { return Field{} }
+func F[T any](key string, value T) Field { return Field{} }
+func Err(err error) Field                { return Field{} }
+
+var _ = time.Time{}